@@ -23,11 +23,15 @@ type UpdateConfig struct {
 
 // UpdateConfigSpec defines the rules to perform when updating.
 type UpdateConfigSpec struct {
-	// PullRequestLabels defines the labels to apply to created pull requests
+	// PullRequestLabels defines the labels to apply to created pull requests. Labels containing {{ are go templates
+	// rendered for each repository such as release/{{ .VersionMajorMinor }}
 	PullRequestLabels []string `json:"pullRequestLabels,omitempty"`
 
 	// Rules defines the change rules
 	Rules []Rule `json:"rules,omitempty"`
+
+	// VersionStreamRules generates rules from the contents of a version stream rather than requiring explicit rules
+	VersionStreamRules []VersionStreamRule `json:"versionStreamRules,omitempty"`
 }
 
 // Rule specifies a set of repositories and changes
@@ -35,25 +39,162 @@ type Rule struct {
 	// URLs the git URLs of the repositories to create a Pull Request on
 	URLs []string `json:"urls"`
 
+	// RepoQuery discovers the repositories of an organisation to add to the URLs so that the repositories of the rule
+	// do not have to be listed explicitly
+	RepoQuery *RepoQuery `json:"repoQuery,omitempty"`
+
+	// ExcludeURLs globs of the git URLs or owner/name of repositories which are never updated by this rule even if
+	// listed in the URLs or discovered by the RepoQuery such as https://github.com/myorg/legacy-* or myorg/archive-*
+	ExcludeURLs []string `json:"excludeURLs,omitempty"`
+
+	// Tags the tags of the rule which are used to select the rules the repositories of the --repo-file are added to
+	// with --repo-file-tag
+	Tags []string `json:"tags,omitempty"`
+
+	// ID the unique identifier of the rule which other rules can depend on. It is not called name as the name of a
+	// version stream rule is the name of the resources it matches
+	ID string `json:"id,omitempty"`
+
+	// DependsOn the IDs of the rules which must succeed before this rule runs, such as the rule which upgrades a
+	// library before the rule which upgrades the applications consuming it. The rule is skipped if any of them fail
+	DependsOn []string `json:"dependsOn,omitempty"`
+
 	// Changes the changes to perform on the repositories
 	Changes []Change `json:"changes"`
 
+	// Validate commands run in the root of each repository once the changes are applied, such as go build or helm lint.
+	// If a command fails no Pull Request is created on the repository and the failure includes the command output
+	Validate []Command `json:"validate,omitempty"`
+
 	// Fork if we should create the pull request from a fork of the repository
 	Fork bool `json:"fork,omitempty"`
 
+	// ForkOrg the organisation to fork the repositories into, such as a shared bot organisation, rather than the account
+	// of the git token user. An existing fork in the organisation is reused. Implies fork
+	ForkOrg string `json:"forkOrg,omitempty"`
+
 	// ReusePullRequest governs if existing pull requests for application are found and updated. Requires that --labels
 	// or UpdateConfigSpec.PullRequestLabels are supplied.
 	ReusePullRequest bool `json:"reusePullRequest,omitempty"`
 
-	// SparseCheckout governs if sparse checkout is made of repository. Only possible with regex and go changes.
+	// AutoMergeConditions the conditions which must be satisfied for the Pull Requests of this rule to be auto merged
+	// when auto merge is enabled. If not satisfied the Pull Request is created without auto merge
+	AutoMergeConditions *AutoMergeConditions `json:"autoMergeConditions,omitempty"`
+
+	// Draft creates the Pull Requests as drafts so they are marked ready for review by hand. Draft Pull Requests are not
+	// auto merged. Git providers which do not support drafts create a normal Pull Request with a warning
+	Draft bool `json:"draft,omitempty"`
+
+	// SparseCheckout governs if sparse checkout is made of repository. Only possible with regex, checksum, set and go changes.
 	// Note: Not all git servers support this.
 	SparseCheckout bool `json:"sparseCheckout,omitempty"`
 
+	// SparseCheckoutPaths the paths, relative to the root of the repository, to check out with sparseCheckout rather
+	// than the paths derived from the changes, such as the directories of a large monorepo the changes are applied in.
+	// Lets commands and other changes which do not support sparse checkout be used
+	SparseCheckoutPaths []string `json:"sparseCheckoutPaths,omitempty"`
+
+	// PullRequestLabels the labels added to the Pull Requests of this rule along with the pullRequestLabels of the config.
+	// Labels containing {{ are go templates rendered with the same data as the title template such as team/{{ .Application }}
+	PullRequestLabels []string `json:"pullRequestLabels,omitempty"`
+
 	// PullRequestAssignees
 	PullRequestAssignees []string `json:"pullRequestAssignees,omitempty"`
 
+	// PullRequestReviewers the users requested to review the Pull Requests. The repository fails if the git provider does
+	// not support review requests, such as GitLab
+	PullRequestReviewers []string `json:"pullRequestReviewers,omitempty"`
+
 	// AssignAuthorToPullRequests governs if downstream pull requests are automatically assigned to the upstream author
 	AssignAuthorToPullRequests bool `json:"assignAuthorToPullRequests,omitempty"`
+
+	// Milestone the title of the milestone to add the Pull Requests to, such as the release they are tracked by.
+	// Defaults to the --pr-milestone option. A missing milestone is created with --create-milestone, otherwise the
+	// Pull Requests are created without a milestone with a warning
+	Milestone string `json:"milestone,omitempty"`
+
+	// PullRequestTitleTemplate a go template used to render the title of the Pull Requests of this rule such as
+	// "chore(deps): bump {{ .Application }} to {{ .Version }}". The template can use the template data along with the
+	// Version, Application, GitURL, Rule and the built-in Title and Body. Defaults to the built-in title
+	PullRequestTitleTemplate string `json:"pullRequestTitleTemplate,omitempty"`
+
+	// PullRequestBodyTemplate a go template used to render the body of the Pull Requests of this rule. The template can
+	// use the same values as the title template. Defaults to the pullRequestBodyTemplateFile, the
+	// --pull-request-body-template option then the built-in body
+	PullRequestBodyTemplate string `json:"pullRequestBodyTemplate,omitempty"`
+
+	// PullRequestBodyTemplateFile the path of a go template file, relative to the --base-dir option, used to render the
+	// body of the Pull Requests of this rule when there is no pullRequestBodyTemplate. Defaults to the
+	// --pull-request-body-template option
+	PullRequestBodyTemplateFile string `json:"pullRequestBodyTemplateFile,omitempty"`
+
+	// TriggerLabels the labels which must all be on the Pull Request that triggered the pipeline for this rule to run.
+	// Only used with the --trigger-labels option. Rules without trigger labels always run
+	TriggerLabels []string `json:"triggerLabels,omitempty"`
+
+	// VersionConstraint an optional semantic version constraint, such as ">=1.0.0 <2.0.0", the version must satisfy for
+	// this rule to run so that a rule can skip major upgrades. The constraint is ignored with a warning if the version
+	// is not a semantic version such as a git SHA
+	VersionConstraint string `json:"versionConstraint,omitempty"`
+
+	// ExpectedChangedFiles the paths, relative to the root of the repository, of the files the changes of this rule are
+	// expected to modify. If specified the files modified by the changes must match exactly or the repository fails
+	// before the Pull Request is created, catching a greedy regex or glob touching more files than intended
+	ExpectedChangedFiles []string `json:"expectedChangedFiles,omitempty"`
+}
+
+// AutoMergeConditions the conditions for the Pull Requests of a rule to be auto merged
+type AutoMergeConditions struct {
+	// RequireLabels the labels which must all be on the Pull Request
+	RequireLabels []string `json:"requireLabels,omitempty"`
+
+	// RequireBaseBranchChecks the names of the commit statuses or check runs which must have succeeded on the latest
+	// commit of the base branch so that Pull Requests are not auto merged into a broken branch. This is a health gate
+	// of the base branch as the checks of the Pull Request have not run when it is created
+	RequireBaseBranchChecks []string `json:"requireBaseBranchChecks,omitempty"`
+}
+
+// RepoQuery a query of the repositories of an organisation to create Pull Requests on
+type RepoQuery struct {
+	// Server the URL of the git server of the organisation. Defaults to the --git-server option then https://github.com
+	Server string `json:"server,omitempty"`
+
+	// Org the organisation, or user, owning the repositories
+	Org string `json:"org"`
+
+	// Topic only includes the repositories with this topic. Topics are only supported on GitHub
+	Topic string `json:"topic,omitempty"`
+
+	// NameRegex only includes the repositories whose name matches this regular expression
+	NameRegex string `json:"nameRegex,omitempty"`
+
+	// IncludeArchived includes archived repositories which are excluded by default
+	IncludeArchived bool `json:"includeArchived,omitempty"`
+}
+
+// VersionStreamRule generates a Rule from the resources found in a source version stream.
+//
+// The rule fields (urls, fork, reusePullRequest etc) and the include/exclude pattern fields are specified inline.
+// A VersionStream change is generated for every matching resource which has a version; resources whose version
+// already matches the downstream repository make no change. Any changes listed on the rule are applied before
+// the generated changes.
+type VersionStreamRule struct {
+	Rule
+	Pattern
+
+	// Dir the source version stream directory. Relative paths are resolved against the --base-dir option which defaults
+	// to the directory of the config file. Defaults to versionStream in the --dir directory
+	Dir string `json:"dir,omitempty"`
+
+	// Kind the kind of resources to generate changes for. Defaults to charts
+	Kind string `json:"kind,omitempty"`
+
+	// TargetDir the directory of the version stream in the downstream repositories. Defaults to versionStream
+	TargetDir string `json:"targetDir,omitempty"`
+
+	// Prune also removes the matching resources from the downstream version streams which are no longer in the source
+	// version stream
+	Prune bool `json:"prune,omitempty"`
 }
 
 // Change the kind of change to make on a repository
@@ -67,21 +208,96 @@ type Change struct {
 	// Regex a regex based modification
 	Regex *Regex `json:"regex,omitempty"`
 
+	// Checksum updates a version along with the checksum of the artifact for that version
+	Checksum *Checksum `json:"checksum,omitempty"`
+
+	// Move renames a file such as when the file name contains the version
+	Move *Move `json:"move,omitempty"`
+
+	// Set sets a value in YAML files to a literal value rather than the version such as to toggle a feature flag
+	Set *SetValue `json:"set,omitempty"`
+
+	// ImageDigest updates the digest of an image which is pinned by digest to the digest of the version tag
+	ImageDigest *ImageDigest `json:"imageDigest,omitempty"`
+
+	// JSON sets a value in JSON files such as a dependency version in a package.json
+	JSON *JSONChange `json:"json,omitempty"`
+
+	// TOML sets a value in TOML files such as a dependency version in a Cargo.toml or pyproject.toml
+	TOML *TOMLChange `json:"toml,omitempty"`
+
+	// Properties sets the value of a key in properties or .env files such as IMAGE_TAG=1.2.3
+	Properties *PropertiesChange `json:"properties,omitempty"`
+
+	// YAML sets the value of a node in YAML files by path such as an image tag in a helm values.yaml
+	YAML *YAMLChange `json:"yaml,omitempty"`
+
+	// Dockerfile updates the tag of a base image in the FROM lines of Dockerfiles
+	Dockerfile *DockerfileChange `json:"dockerfile,omitempty"`
+
+	// Kustomize updates the newTag of an image in the images of kustomization files
+	Kustomize *KustomizeChange `json:"kustomize,omitempty"`
+
+	// HelmDependency updates the version of a dependency in the dependencies of helm Chart.yaml files
+	HelmDependency *HelmDependencyChange `json:"helmDependency,omitempty"`
+
+	// XML sets the text of elements in XML files such as a version in a maven pom.xml
+	XML *XMLChange `json:"xml,omitempty"`
+
+	// GitHubAction updates the ref of an action in the uses of GitHub Actions workflows such as uses: myorg/action@v1.2.3
+	GitHubAction *GitHubActionChange `json:"githubAction,omitempty"`
+
 	// VersionStream updates the charts in a version stream repository
 	VersionStream *VersionStreamChange `json:"versionStream,omitempty"`
 
 	// VersionTemplate an optional template if the version is coming from a previous Pull Request SHA
 	VersionTemplate string `json:"versionTemplate,omitempty"`
+
+	// VersionKey the name of the version, from the --versions option or versions file, to use for this change rather than
+	// the version being promoted. Lets one run update several versions such as the artifacts of a monorepo
+	VersionKey string `json:"versionKey,omitempty"`
+
+	// Marker records a content hash of the change and the version in the .jx/updatebot-markers.yaml file of the
+	// repository when it is applied. Later runs skip the change if the marker shows it is already applied for the version
+	Marker bool `json:"marker,omitempty"`
+
+	// IfMissing what to do if the file to change does not exist: error, skip or create. Defaults to skip with a warning.
+	// create writes a minimal file containing the version, or the value of a set change, and is supported by regex and
+	// set changes with a file path rather than a glob and by version stream changes with a name
+	IfMissing string `json:"ifMissing,omitempty"`
+
+	// ExpectedCurrent if specified the change is only made if the current value matches it, otherwise the change is
+	// skipped with a warning such as for repositories which intentionally pin a different version. Regex and checksum
+	// changes compare the matched version, set changes the current value and version stream changes the current version
+	ExpectedCurrent string `json:"expectedCurrent,omitempty"`
+
+	// WorkingDir an optional subdirectory of the repository the change is applied in. Globs are resolved relative to it
+	// and commands are run in it, which is useful for monorepos
+	WorkingDir string `json:"workingDir,omitempty"`
+
+	// RequireMatch fails the change if it matches nothing, such as a regex pattern which is not found in any file, so that
+	// mistakes in the config are not silently ignored. Supported by regex, checksum, set and imageDigest changes
+	RequireMatch bool `json:"requireMatch,omitempty"`
+
+	// AfterChange commands run in the same directory once the change is applied to regenerate the checksum or lock files
+	// of the new version, such as go mod tidy or helm dependency update, so their changes are in the same commit. Every
+	// command is run even if some fail and the failures are returned together
+	AfterChange []Command `json:"afterChange,omitempty"`
 }
 
 // Command runs a command line program
 type Command struct {
 	// Name the name of the command
 	Name string `json:"name,omitempty"`
-	// Args the command line arguments
+	// Args the command line arguments. The ${VERSION} and ${APP} variables are replaced with the version and application
+	// being promoted and ${KEY} with the value of a key of the template data. The name and env values are expanded too
 	Args []string `json:"args,omitempty"`
 	// Env the environment variables to pass into the command
 	Env []EnvVar `json:"env,omitempty"`
+	// Globs if specified the command is run in the directory of each file, or directory, matching the globs such as
+	// **/go.mod or **/Chart.yaml rather than the root of the repository. The UPDATEBOT_COMMAND_DIR environment variable
+	// is the path of the directory relative to the root of the repository
+	Globs []string `json:"files,omitempty"`
 }
 
 // EnvVar the environment variable
@@ -98,6 +314,222 @@ type Regex struct {
 	Pattern string `json:"pattern,omitempty"`
 	// Globs the files to apply this to
 	Globs []string `json:"files,omitempty"`
+	// ExcludeFiles globs of the files, relative to the root of the repository, which are not changed even if they match
+	// the files so that a regex can be narrowly scoped such as excluding charts/**
+	ExcludeFiles []string `json:"excludeFiles,omitempty"`
+	// Extensions if specified only files with one of these extensions, such as .yaml, are changed.
+	// Binary files are always skipped
+	Extensions []string `json:"extensions,omitempty"`
+	// Transform an optional transformation of the version before it replaces the match: stripVPrefix, addVPrefix, major,
+	// majorMinor, underscores, upper or lower. major and majorMinor require a semantic version
+	Transform string `json:"transform,omitempty"`
+}
+
+// SetValue sets the value at a path in YAML files to a literal value
+type SetValue struct {
+	// Path the dot separated path of the field to set such as features.newUI. Missing parent fields are created
+	Path string `json:"path,omitempty"`
+	// Value the literal value to set which is parsed as a YAML scalar so true and false are booleans.
+	// Quote the value to use a string such as '"true"'
+	Value string `json:"value,omitempty"`
+	// Globs the files to apply this to
+	Globs []string `json:"files,omitempty"`
+}
+
+// JSONChange sets the value at a JSONPath in JSON files such as $.dependencies.myLib in a package.json. Only the value
+// is replaced so the indentation and key order of the files are kept
+type JSONChange struct {
+	// Path the JSONPath of the value such as $.dependencies.myLib. Use brackets for keys containing dots or other special
+	// characters such as $.dependencies['@myorg/my.lib']
+	Path string `json:"path,omitempty"`
+	// Value a go template of the value which can use the {{ .Version }} being promoted. Defaults to the version.
+	// The value is written as a string unless the current value is a number or boolean and the value is one too
+	Value string `json:"value,omitempty"`
+	// Globs the files to apply this to
+	Globs []string `json:"files,omitempty"`
+}
+
+// TOMLChange sets the value of a dotted key in TOML files such as dependencies.serde in a Cargo.toml. Only the value is
+// replaced so comments and the order of the keys are kept
+type TOMLChange struct {
+	// Key the dotted key of the value such as dependencies.serde or dependencies.serde.version for an inline table.
+	// Quote parts of the key containing dots such as tool.poetry.dependencies."my.lib"
+	Key string `json:"key,omitempty"`
+	// Value a go template of the value which can use the {{ .Version }} being promoted. Defaults to the version.
+	// The value is written as a string unless the current value is a number or boolean and the value is one too
+	Value string `json:"value,omitempty"`
+	// Globs the files to apply this to
+	Globs []string `json:"files,omitempty"`
+	// CreateMissing adds the key to its table, appending the table if required, if the key is not in a file. Otherwise
+	// a missing key is an error
+	CreateMissing bool `json:"createMissing,omitempty"`
+}
+
+// YAMLChange sets the value of the node at a path in YAML files such as image.tag in a helm values.yaml. The node is
+// updated in place so comments and anchors are kept
+type YAMLChange struct {
+	// Path the dotted path of the node such as image.tag. A leading '.' is allowed like yq paths and list elements are
+	// selected by index such as .spec.containers[0].image. Quote names containing dots in brackets such as
+	// .annotations["example.com/version"]
+	Path string `json:"path,omitempty"`
+	// Value a go template of the value which can use the {{ .Version }} being promoted. Defaults to the version.
+	// Strings are quoted if required so that a version is never written as a number
+	Value string `json:"value,omitempty"`
+	// Globs the files to apply this to
+	Globs []string `json:"files,omitempty"`
+	// Mode what to do if the node is a sequence: replace replaces the elements with the value and append adds the value
+	// if it is not already an element. The value may be a flow sequence such as [a, b] for several elements.
+	// Defaults to replace
+	Mode string `json:"mode,omitempty"`
+	// Digest updates a node which is a digest, such as image.digest, or an image pinned by digest, such as
+	// myorg/myapp:1.2.3@sha256:abc..., to the digest of the value. The image of the digest is required unless --digest
+	// is specified
+	Digest *DigestOption `json:"digest,omitempty"`
+}
+
+// PropertiesChange sets the value of a key in properties or .env files such as IMAGE_TAG=1.2.3. Only the value of the
+// key is replaced so comments and the order of the keys are kept. The = or : separator is detected in each file
+type PropertiesChange struct {
+	// Key the key of the value such as IMAGE_TAG
+	Key string `json:"key,omitempty"`
+	// Value a go template of the value which can use the {{ .Version }} being promoted. Defaults to the version
+	Value string `json:"value,omitempty"`
+	// Globs the files to apply this to
+	Globs []string `json:"files,omitempty"`
+	// CreateMissing appends the key to a file if it is not in the file. Otherwise a missing key is an error
+	CreateMissing bool `json:"createMissing,omitempty"`
+}
+
+// DockerfileChange updates the tag of the base image in the FROM lines of Dockerfiles such as FROM myreg/base:1.2.3.
+// Only the tag is replaced. FROM lines of build stages are skipped as are images pinned by digest unless the digest
+// option is specified
+type DockerfileChange struct {
+	// Image the image without a tag such as myreg/base
+	Image string `json:"image,omitempty"`
+	// Value a go template of the tag which can use the {{ .Version }} being promoted. Defaults to the version
+	Value string `json:"value,omitempty"`
+	// Globs the files to apply this to. Defaults to Dockerfile
+	Globs []string `json:"files,omitempty"`
+	// Digest updates the images pinned by digest to the digest of the tag rather than skipping them
+	Digest *DigestOption `json:"digest,omitempty"`
+}
+
+// GitHubActionChange updates the ref of an action in the uses of GitHub Actions workflows such as
+// uses: myorg/action@v1.2.3. Actions pinned by commit SHA with a version comment such as
+// uses: myorg/action@3f1c9e2... # v1.2.3 are updated to the SHA of the version along with the comment
+type GitHubActionChange struct {
+	// Name the owner and name of the action without a ref such as myorg/action. Actions in a subdirectory of the
+	// repository such as myorg/action/setup are matched too
+	Name string `json:"name,omitempty"`
+	// Value a go template of the ref which can use the {{ .Version }} being promoted. Defaults to the version
+	Value string `json:"value,omitempty"`
+	// SHA a go template of the commit SHA of the version used for actions pinned by SHA. Defaults to the commit of the
+	// ref in the repository of the action on the git server of the repository being changed
+	SHA string `json:"sha,omitempty"`
+	// Globs the files to apply this to. Defaults to the workflows in .github/workflows
+	Globs []string `json:"files,omitempty"`
+}
+
+// KustomizeChange updates the newTag of an image in the images list of kustomization files. Other fields of the image
+// such as newName and digest are kept
+type KustomizeChange struct {
+	// Name the name of the image in the images list such as myorg/myapp
+	Name string `json:"name,omitempty"`
+	// NewTag a go template of the tag which can use the {{ .Version }} being promoted. Defaults to the version
+	NewTag string `json:"newTag,omitempty"`
+	// Globs the files to apply this to. Defaults to the kustomization.yaml, kustomization.yml and Kustomization files
+	// in any directory
+	Globs []string `json:"files,omitempty"`
+	// CreateMissing adds the image to the images list, adding the list if required, if it is not in a file. Otherwise
+	// files without the image are skipped
+	CreateMissing bool `json:"createMissing,omitempty"`
+	// Digest updates the digest of the images which have one to the digest of the tag rather than only the newTag
+	Digest *DigestOption `json:"digest,omitempty"`
+}
+
+// HelmDependencyChange updates the version of a subchart in the dependencies of helm Chart.yaml files. Other fields of
+// the dependency such as its condition and alias are kept
+type HelmDependencyChange struct {
+	// Name the name of the dependency
+	Name string `json:"name,omitempty"`
+	// Repository the repository of the dependency such as https://charts.example.com. If specified only dependencies
+	// with this repository are updated
+	Repository string `json:"repository,omitempty"`
+	// Version a go template of the version which can use the {{ .Version }} being promoted. Defaults to the version
+	Version string `json:"version,omitempty"`
+	// Globs the files to apply this to. Defaults to Chart.yaml and the Chart.yaml files in the charts directory
+	Globs []string `json:"files,omitempty"`
+	// CreateMissing adds the dependency, which requires the repository, if it is not in a file. Otherwise files
+	// without the dependency are skipped
+	CreateMissing bool `json:"createMissing,omitempty"`
+}
+
+// XMLChange sets the text of the elements at a path in XML files such as a version in the properties or dependencies of
+// a maven pom.xml. Only the text is replaced so the namespaces, comments and formatting of the files are kept
+type XMLChange struct {
+	// Path the path of the elements such as /project/properties/my.lib.version or
+	// //dependency[artifactId='my-lib']/version. Use // to match elements at any depth and [child='text'] to only match
+	// elements with a child element with the text. Namespace prefixes are ignored
+	Path string `json:"path,omitempty"`
+	// Value a go template of the text which can use the {{ .Version }} being promoted. Defaults to the version
+	Value string `json:"value,omitempty"`
+	// Globs the files to apply this to. Defaults to pom.xml
+	Globs []string `json:"files,omitempty"`
+}
+
+// ImageDigest updates references to an image pinned by digest, such as myorg/myapp@sha256:abc..., to the digest of the
+// image tagged with the version. The digest is resolved from the container registry using the docker config file for
+// authentication
+type ImageDigest struct {
+	// Image the image without a tag such as ghcr.io/myorg/myapp
+	Image string `json:"image,omitempty"`
+	// Globs the files to apply this to
+	Globs []string `json:"files,omitempty"`
+	// PlainHTTP uses HTTP rather than HTTPS to connect to the registry such as for a local registry
+	PlainHTTP bool `json:"plainHTTP,omitempty"`
+}
+
+// DigestOption updates the digest of images pinned by digest along with their tag. The digest is the --digest if
+// specified, otherwise the tag is resolved to its digest in the registry of the image using the docker config file
+// for authentication
+type DigestOption struct {
+	// Image the image whose tag is resolved to the digest such as ghcr.io/myorg/myapp. Defaults to the image of the change
+	Image string `json:"image,omitempty"`
+	// PlainHTTP uses HTTP rather than HTTPS to connect to the registry such as for a local registry
+	PlainHTTP bool `json:"plainHTTP,omitempty"`
+}
+
+// Move renames a file in the repository with git mv. The from and to paths are go templates which can use the
+// {{ .Version }} being promoted
+type Move struct {
+	// From the path of the file to move relative to the root of the repository
+	From string `json:"from,omitempty"`
+	// To the new path of the file relative to the root of the repository
+	To string `json:"to,omitempty"`
+	// Regex an optional regex change applied after the file is moved. Defaults to the moved file if no files are specified
+	Regex *Regex `json:"regex,omitempty"`
+}
+
+// Checksum updates a version and the matching checksum of an artifact in the same files so they are kept in sync
+type Checksum struct {
+	// VersionPattern the regex to find the version. If it has a named capture called version only that
+	// group is replaced, otherwise all capture groups are replaced
+	VersionPattern string `json:"versionPattern,omitempty"`
+	// ChecksumPattern the regex to find the checksum. If it has a named capture called checksum only that
+	// group is replaced, otherwise all capture groups are replaced
+	ChecksumPattern string `json:"checksumPattern,omitempty"`
+	// Globs the files to apply this to
+	Globs []string `json:"files,omitempty"`
+	// Checksums the checksums indexed by version. If the version is found here nothing is fetched
+	Checksums map[string]string `json:"checksums,omitempty"`
+	// URL a template of the URL of the artifact to download and calculate the SHA256 checksum of, e.g.
+	// https://example.com/releases/v{{ .Version }}/tool.tar.gz
+	URL string `json:"url,omitempty"`
+	// ChecksumURL a template of the URL of a checksums file to look up the checksum in, e.g.
+	// https://example.com/releases/v{{ .Version }}/checksums.txt
+	ChecksumURL string `json:"checksumURL,omitempty"`
+	// ChecksumFile a template of the file name to look up in the checksums file if it contains more than one checksum
+	ChecksumFile string `json:"checksumFile,omitempty"`
 }
 
 // Pattern for matching strings
@@ -124,6 +556,36 @@ type VersionStreamChange struct {
 
 	// Kind the kind of resources to change (charts, git, package etc)
 	Kind string `json:"kind,omitempty"`
+
+	// Dir the directory of the version stream in the repository. Defaults to the root of the repository
+	Dir string `json:"dir,omitempty"`
+
+	// Version the version to use. If not specified the latest chart version is looked up
+	Version string `json:"version,omitempty"`
+
+	// Prune removes the resources of the kind matching the pattern which are not in the Source version stream, such as
+	// charts deleted upstream, rather than updating versions. The --max-prune option limits how many can be removed
+	Prune bool `json:"prune,omitempty"`
+
+	// Source the source version stream directory resources are pruned against. Relative paths are resolved against the
+	// --base-dir option. Defaults to versionStream in the --dir directory
+	Source string `json:"source,omitempty"`
+
+	// Sources the source version streams whose versions of the resources of the kind are copied into the version stream
+	// of the repository. The sources are applied in order. A resource in more than one source fails the change unless
+	// LastWins is enabled
+	Sources []VersionStreamSource `json:"sources,omitempty"`
+
+	// LastWins uses the version of the last source with a resource when the resource is in more than one of the Sources
+	LastWins bool `json:"lastWins,omitempty"`
+}
+
+// VersionStreamSource a source version stream of a version stream change along with the resources to copy from it
+type VersionStreamSource struct {
+	Pattern
+
+	// Dir the source version stream directory. Relative paths are resolved against the --base-dir option
+	Dir string `json:"dir"`
 }
 
 // GoChange for upgrading go dependencies
@@ -142,4 +604,32 @@ type GoChange struct {
 
 	// NoPatch disables patch upgrades so we can import to new minor releases
 	NoPatch bool `json:"noPatch,omitempty"`
+
+	// DirectRequire only finds repositories with a direct require of the package in their go.mod rather than
+	// repositories which only replace it or require it indirectly
+	DirectRequire bool `json:"directRequire,omitempty"`
+
+	// VersionRange only finds repositories which require a version of the package in the semver range
+	// e.g. ">= 1.2.0, < 2.0.0". Implies DirectRequire
+	VersionRange string `json:"versionRange,omitempty"`
+
+	// GoSum how the go.sum file is updated after upgrading a package. Either "tidy" to run "go mod tidy"
+	// or "download-only" to only run "go mod download" for the upgraded package to keep the diff minimal.
+	// Defaults to "tidy"
+	GoSum string `json:"goSum,omitempty"`
+
+	// UpdateReplace also sets the version of the replace directives in go.mod which replace the package or pin a fork
+	// of it to the version being promoted. Replacements with a local directory are left alone
+	UpdateReplace bool `json:"updateReplace,omitempty"`
+
+	// IncludeIndirect also sets the version of the indirect requires of the package in go.mod to the version being promoted
+	IncludeIndirect bool `json:"includeIndirect,omitempty"`
+
+	// RunTidy runs "go mod tidy" after go.mod is modified by UpdateReplace or IncludeIndirect
+	RunTidy bool `json:"runTidy,omitempty"`
+
+	// Pseudo pins the package to the Go pseudo-version (v0.0.0-<timestamp>-<sha>) of the commit given by the version
+	// such as for a module which has no tags. Enabled automatically when the change has a package and the version looks
+	// like a commit sha
+	Pseudo bool `json:"pseudo,omitempty"`
 }
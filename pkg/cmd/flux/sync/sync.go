@@ -40,6 +40,9 @@ var (
 
 		# create a Pull Request if any of the versions are out of sync excluding the given repo URL strings
 		jx updatebot flux sync --source-git-url https://github.com/myorg/my-staging-repo --target-git-url https://github.com/myorg/my-production-repo --repourl-excludes water
+
+		# create a Pull Request if any of the versions are out of sync only for HelmReleases in the given namespaces
+		jx updatebot flux sync --source-git-url https://github.com/myorg/my-staging-repo --target-git-url https://github.com/myorg/my-production-repo --namespace-include team-a
 	`)
 )
 
@@ -49,6 +49,10 @@ func TestFluxSync(t *testing.T) {
 			o.AppFilter.Chart.Includes = []string{"app1"}
 		case "exclude-app1":
 			o.AppFilter.Chart.Excludes = []string{"app1"}
+		case "include-ns":
+			o.AppFilter.Namespace.Includes = []string{"team-a"}
+		case "exclude-ns":
+			o.AppFilter.Namespace.Excludes = []string{"team-a"}
 		}
 
 		srcDir := filepath.Join(dir, "source")
@@ -0,0 +1,28 @@
+package pr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+)
+
+// RunAfterChange runs the afterChange commands of a change in the dir the change was applied in so that the files
+// they regenerate, such as go.sum or Chart.lock, are committed with the change. Every command is run even if some of
+// them fail so that the error lists all the failures
+func (o *Options) RunAfterChange(dir string, commands []v1alpha1.Command) error {
+	var failures []string
+	for i := range commands {
+		command := &commands[i]
+		err := o.ApplyCommand(dir, command)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("* %s", err.Error()))
+			continue
+		}
+		o.Logger().Infof("ran afterChange command %s", info(command.Name))
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d afterChange commands failed:\n%s", len(failures), len(commands), strings.Join(failures, "\n"))
+}
@@ -0,0 +1,49 @@
+package pr_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunAfterChange(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepository(t, dir)
+	writeTestFile(t, filepath.Join(dir, "app", "Chart.yaml"), "version: 1.0.0\n")
+
+	o := &pr.Options{Version: "2.0.0", TemplateData: map[string]interface{}{"Version": "2.0.0"}}
+	o.CommandRunner = cmdrunner.QuietCommandRunner
+	change := v1alpha1.Change{
+		WorkingDir: "app",
+		Regex:      &v1alpha1.Regex{Pattern: `version: (.*)`, Globs: []string{"Chart.yaml"}},
+		AfterChange: []v1alpha1.Command{
+			{Name: "sh", Args: []string{"-c", "echo version: ${VERSION} > Chart.lock"}},
+		},
+	}
+	err := o.ApplyChanges(dir, "https://github.com/myorg/myrepo", change)
+	require.NoError(t, err, "failed to apply change")
+
+	// the lock file written by the after change command is part of the changes to commit
+	text, err := o.Git().Command(dir, "status", "--porcelain", "--untracked-files=all")
+	require.NoError(t, err, "failed to get git status")
+	assert.Equal(t, []string{"?? app/Chart.lock", "?? app/Chart.yaml"}, strings.Split(strings.TrimSpace(text), "\n"), "changed files")
+	assertFileText(t, filepath.Join(dir, "app", "Chart.lock"), "version: 2.0.0\n")
+
+	change.AfterChange = []v1alpha1.Command{
+		{Name: "false"},
+		{Name: "sh", Args: []string{"-c", "echo regenerated > Chart.lock"}},
+		{Name: "false", Args: []string{"again"}},
+	}
+	err = o.ApplyChanges(dir, "https://github.com/myorg/myrepo", change)
+	require.Error(t, err, "should fail as after change commands fail")
+	assert.Contains(t, err.Error(), "2 of 3 afterChange commands failed", "error")
+
+	// the commands after the one which failed are still run
+	assertFileText(t, filepath.Join(dir, "app", "Chart.lock"), "regenerated\n")
+}
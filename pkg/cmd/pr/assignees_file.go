@@ -0,0 +1,48 @@
+package pr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/giturl"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/yamls"
+)
+
+// LoadAssignees loads the --assignees-file which maps repositories to the users to assign to their Pull Requests.
+// The repositories are specified by their full name, such as myorg/myrepo, or their git URL
+func (o *Options) LoadAssignees() error {
+	if o.AssigneesFile == "" {
+		return nil
+	}
+	exists, err := files.FileExists(o.AssigneesFile)
+	if err != nil {
+		return fmt.Errorf("failed to check for file %s: %w", o.AssigneesFile, err)
+	}
+	if !exists {
+		return fmt.Errorf("assignees file %s does not exist", o.AssigneesFile)
+	}
+	o.repoAssignees = map[string][]string{}
+	err = yamls.LoadFile(o.AssigneesFile, &o.repoAssignees)
+	if err != nil {
+		return fmt.Errorf("failed to load assignees file %s: %w", o.AssigneesFile, err)
+	}
+	return nil
+}
+
+// RepositoryAssignees returns the users to assign to Pull Requests on the repository from the --assignees-file
+func (o *Options) RepositoryAssignees(gitURL string) []string {
+	if len(o.repoAssignees) == 0 {
+		return nil
+	}
+	keys := []string{gitURL, strings.TrimSuffix(gitURL, ".git")}
+	gitInfo, err := giturl.ParseGitURL(gitURL)
+	if err == nil {
+		keys = append(keys, gitInfo.Organisation+"/"+gitInfo.Name)
+	}
+	var answer []string
+	for _, key := range keys {
+		answer = append(answer, o.repoAssignees[key]...)
+	}
+	return answer
+}
@@ -0,0 +1,68 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssigneesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "owners.yaml")
+	mapping := `myorg/backend:
+- alice
+- bob
+https://github.com/myorg/frontend:
+- carol
+`
+	err := os.WriteFile(path, []byte(mapping), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to write %s", path)
+
+	testCases := []struct {
+		gitURL   string
+		expected []string
+	}{
+		{gitURL: "https://github.com/myorg/backend", expected: []string{"rule-owner", "default-owner", "alice", "bob"}},
+		{gitURL: "https://github.com/myorg/backend.git", expected: []string{"rule-owner", "default-owner", "alice", "bob"}},
+		{gitURL: "https://github.com/myorg/frontend.git", expected: []string{"rule-owner", "default-owner", "carol"}},
+		{gitURL: "https://github.com/myorg/other", expected: []string{"rule-owner", "default-owner"}},
+	}
+
+	for _, tc := range testCases {
+		scmClient, data := fake.NewDefault()
+		_, o := pr.NewCmdPullRequest()
+		o.ScmClient = scmClient
+		o.ScmClientFactory.ScmClient = scmClient
+		o.ScmClientFactory.GitServerURL = "https://github.com"
+		o.AssigneesFile = path
+		o.PRAssignees = []string{"default-owner", "rule-owner"}
+		err = o.LoadAssignees()
+		require.NoError(t, err, "failed to load assignees")
+
+		rule := &v1alpha1.Rule{PullRequestAssignees: []string{"rule-owner"}}
+		err = o.AssignUsersToPullRequestIssue(rule, &scm.PullRequest{Number: 1}, tc.gitURL, "", "", "fake")
+		require.NoError(t, err, "failed to assign users for %s", tc.gitURL)
+
+		var expected []string
+		for _, user := range tc.expected {
+			expected = append(expected, "myorg/"+repoName(tc.gitURL)+"#1:"+user)
+		}
+		assert.Equal(t, expected, data.AssigneesAdded, "assignees for %s", tc.gitURL)
+	}
+
+	o := &pr.Options{AssigneesFile: filepath.Join(t.TempDir(), "missing.yaml")}
+	err = o.LoadAssignees()
+	require.Error(t, err, "should fail for a missing assignees file")
+}
+
+func repoName(gitURL string) string {
+	name := filepath.Base(gitURL)
+	return name[:len(name)-len(filepath.Ext(name))]
+}
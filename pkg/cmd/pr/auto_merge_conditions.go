@@ -0,0 +1,141 @@
+package pr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+)
+
+// CheckAutoMergeConditions returns true if the Pull Request on the repository with the labels satisfies the auto merge
+// conditions of the rule. Otherwise auto merge is disabled with a log of the conditions which are not satisfied
+func (o *Options) CheckAutoMergeConditions(rule *v1alpha1.Rule, gitURL string, labels []string) bool {
+	conditions := rule.AutoMergeConditions
+	if conditions == nil {
+		return true
+	}
+	var missing []string
+	for _, l := range conditions.RequireLabels {
+		if stringhelpers.StringArrayIndex(labels, l) < 0 {
+			missing = append(missing, l)
+		}
+	}
+	if len(missing) > 0 {
+		o.Logger().Infof("disabling auto merge for repository %s as the Pull Request does not have the labels: %s", gitURL, strings.Join(missing, ", "))
+		return false
+	}
+	if len(conditions.RequireBaseBranchChecks) == 0 {
+		return true
+	}
+	failing, err := o.failingBaseBranchChecks(gitURL, conditions.RequireBaseBranchChecks)
+	if err != nil {
+		o.Logger().Warnf("disabling auto merge for repository %s as failed to verify its checks: %s", gitURL, err.Error())
+		return false
+	}
+	if len(failing) > 0 {
+		o.Logger().Infof("disabling auto merge for repository %s as the checks have not succeeded on the base branch: %s", gitURL, strings.Join(failing, ", "))
+		return false
+	}
+	return true
+}
+
+// failingBaseBranchChecks returns the names of the checks which have not succeeded on the latest commit of the base
+// branch. The Pull Request has not been created yet so the base branch is checked rather than the Pull Request. Both
+// commit statuses and, on GitHub, check runs such as GitHub Actions jobs are read
+func (o *Options) failingBaseBranchChecks(gitURL string, checks []string) ([]string, error) {
+	ctx := context.Background()
+	scmClient, repoFullName, err := o.GetScmClient(gitURL, o.GitKind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ScmClient: %w", err)
+	}
+	branch := o.BaseBranchName
+	if branch == "" {
+		repo, _, err := scmClient.Repositories.Find(ctx, repoFullName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find repository %s: %w", repoFullName, err)
+		}
+		branch = repo.Branch
+	}
+	statuses, _, err := scmClient.Repositories.ListStatus(ctx, repoFullName, branch, &scm.ListOptions{Size: 100})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the statuses of branch %s of repository %s: %w", branch, repoFullName, err)
+	}
+
+	// statuses are listed newest first so the first status of each check is its latest state
+	states := map[string]scm.State{}
+	for _, s := range statuses {
+		if _, ok := states[s.Label]; !ok {
+			states[s.Label] = s.State
+		}
+	}
+	if o.IsGitHub() {
+		checkRuns, err := listGitHubCheckRuns(ctx, scmClient, repoFullName, branch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the check runs of branch %s of repository %s: %w", branch, repoFullName, err)
+		}
+		for _, r := range checkRuns {
+			if states[r.Name] != scm.StateSuccess {
+				states[r.Name] = r.State()
+			}
+		}
+	}
+	var failing []string
+	for _, c := range checks {
+		if states[c] != scm.StateSuccess {
+			failing = append(failing, c)
+		}
+	}
+	return failing, nil
+}
+
+// gitHubCheckRun a GitHub check run which go-scm does not support
+type gitHubCheckRun struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+}
+
+// State returns the state of the check run. Neutral and skipped check runs succeed as they do for the required checks
+// of GitHub branch protection
+func (r *gitHubCheckRun) State() scm.State {
+	if r.Status != "completed" {
+		return scm.StatePending
+	}
+	switch r.Conclusion {
+	case "success", "neutral", "skipped":
+		return scm.StateSuccess
+	default:
+		return scm.StateFailure
+	}
+}
+
+// listGitHubCheckRuns lists the latest check runs of the ref using the REST API of the git server as go-scm does not
+// support check runs
+func listGitHubCheckRuns(ctx context.Context, scmClient *scm.Client, repoFullName, ref string) ([]*gitHubCheckRun, error) {
+	req := &scm.Request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("repos/%s/commits/%s/check-runs?per_page=100", repoFullName, ref),
+		Header: http.Header{"Accept": []string{"application/vnd.github+json"}},
+	}
+	res, err := scmClient.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.Status != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", res.Status)
+	}
+	out := struct {
+		CheckRuns []*gitHubCheckRun `json:"check_runs"`
+	}{}
+	err = json.NewDecoder(res.Body).Decode(&out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode check runs: %w", err)
+	}
+	return out.CheckRuns, nil
+}
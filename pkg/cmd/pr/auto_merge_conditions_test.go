@@ -0,0 +1,126 @@
+package pr_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAutoMergeConditions(t *testing.T) {
+	gitURL := "https://github.com/myorg/myrepo"
+	statuses := []*scm.Status{
+		{Label: "build", State: scm.StateSuccess},
+		{Label: "lint", State: scm.StateFailure},
+		// an older status of the lint check
+		{Label: "lint", State: scm.StateSuccess},
+	}
+
+	var checkRunPaths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checkRunPaths = append(checkRunPaths, r.URL.Path)
+		_, _ = w.Write([]byte(`{"total_count":3,"check_runs":[
+{"name":"actions-build","status":"completed","conclusion":"success"},
+{"name":"actions-lint","status":"completed","conclusion":"failure"},
+{"name":"actions-e2e","status":"in_progress","conclusion":null}]}`))
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL + "/")
+	require.NoError(t, err, "failed to parse test server URL")
+
+	testCases := []struct {
+		name       string
+		conditions *v1alpha1.AutoMergeConditions
+		labels     []string
+		expected   bool
+	}{
+		{
+			name:     "no-conditions",
+			expected: true,
+		},
+		{
+			name:       "labels",
+			conditions: &v1alpha1.AutoMergeConditions{RequireLabels: []string{"patch"}},
+			labels:     []string{"updatebot", "patch"},
+			expected:   true,
+		},
+		{
+			name:       "missing-label",
+			conditions: &v1alpha1.AutoMergeConditions{RequireLabels: []string{"patch"}},
+			labels:     []string{"updatebot", "minor"},
+		},
+		{
+			name:       "checks",
+			conditions: &v1alpha1.AutoMergeConditions{RequireBaseBranchChecks: []string{"build"}},
+			expected:   true,
+		},
+		{
+			name:       "failing-check",
+			conditions: &v1alpha1.AutoMergeConditions{RequireBaseBranchChecks: []string{"build", "lint"}},
+		},
+		{
+			name:       "missing-check",
+			conditions: &v1alpha1.AutoMergeConditions{RequireBaseBranchChecks: []string{"e2e"}},
+		},
+		{
+			name:       "check-run",
+			conditions: &v1alpha1.AutoMergeConditions{RequireBaseBranchChecks: []string{"build", "actions-build"}},
+			expected:   true,
+		},
+		{
+			name:       "failing-check-run",
+			conditions: &v1alpha1.AutoMergeConditions{RequireBaseBranchChecks: []string{"actions-lint"}},
+		},
+		{
+			name:       "pending-check-run",
+			conditions: &v1alpha1.AutoMergeConditions{RequireBaseBranchChecks: []string{"actions-e2e"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		checkRunPaths = nil
+		scmClient, _ := fake.NewDefault()
+		scmClient.BaseURL = serverURL
+		scmClient.Client = server.Client()
+		repoService := &fakeStatusRepositoryService{RepositoryService: scmClient.Repositories, statuses: statuses}
+		scmClient.Repositories = repoService
+
+		_, o := pr.NewCmdPullRequest()
+		o.ScmClient = scmClient
+		o.ScmClientFactory.ScmClient = scmClient
+		o.ScmClientFactory.GitServerURL = "https://github.com"
+		o.ScmClientFactory.GitKind = pr.GitKindGitHub
+		o.GitKind = "fake"
+
+		rule := &v1alpha1.Rule{AutoMergeConditions: tc.conditions}
+		got := o.CheckAutoMergeConditions(rule, gitURL, tc.labels)
+		assert.Equal(t, tc.expected, got, "auto merge for %s", tc.name)
+		if tc.conditions != nil && len(tc.conditions.RequireChecks) > 0 {
+			assert.Equal(t, []string{"main"}, repoService.refs, "refs of the listed statuses for %s", tc.name)
+		}
+	}
+}
+
+// fakeStatusRepositoryService returns the statuses of the default branch of the repository
+type fakeStatusRepositoryService struct {
+	scm.RepositoryService
+	statuses []*scm.Status
+	refs     []string
+}
+
+func (s *fakeStatusRepositoryService) Find(_ context.Context, repo string) (*scm.Repository, *scm.Response, error) {
+	return &scm.Repository{FullName: repo, Branch: "main"}, nil, nil
+}
+
+func (s *fakeStatusRepositoryService) ListStatus(_ context.Context, _, ref string, _ *scm.ListOptions) ([]*scm.Status, *scm.Response, error) {
+	s.refs = append(s.refs, ref)
+	return s.statuses, nil, nil
+}
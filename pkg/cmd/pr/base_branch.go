@@ -0,0 +1,79 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// EvaluateBaseBranch evaluates the --base-branch-name as a version template so that Pull Requests can target release
+// branches such as release/{{.VersionMajorMinor}}. A templated branch is verified to exist in the repository so that a
+// missing release branch gives a clear error rather than failing the clone. If no base branch is specified the default
+// branch of the repository is used as repositories may use main or master
+func (o *Options) EvaluateBaseBranch(baseBranch, gitURL string) (string, error) {
+	if baseBranch == "" {
+		return o.DefaultBranch(gitURL), nil
+	}
+	if !strings.Contains(baseBranch, "{{") {
+		return baseBranch, nil
+	}
+	text, err := o.EvaluateVersionTemplate(baseBranch, gitURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to evaluate base branch template %s: %w", baseBranch, err)
+	}
+	branch := strings.TrimSpace(text)
+	if branch == "" {
+		return "", fmt.Errorf("the base branch template %s evaluated to an empty branch for repository %s", baseBranch, gitURL)
+	}
+
+	scmClient, repoFullName, err := o.GetScmClient(gitURL, o.GitKind)
+	if err != nil {
+		return "", fmt.Errorf("failed to create ScmClient: %w", err)
+	}
+	_, _, err = scmClient.Git.FindBranch(context.Background(), repoFullName, branch)
+	if err != nil {
+		if scm.IsScmNotFound(err) {
+			return "", fmt.Errorf("the base branch %s does not exist in repository %s", branch, gitURL)
+		}
+		return "", fmt.Errorf("failed to find base branch %s in repository %s: %w", branch, gitURL, err)
+	}
+	o.Logger().Infof("using base branch %s for repository %s", branch, gitURL)
+	return branch, nil
+}
+
+// DefaultBranch returns the default branch of the repository which is cached so that each repository is only looked
+// up once. An empty branch is returned if the repository cannot be found so that the default of the git provider is used
+func (o *Options) DefaultBranch(gitURL string) string {
+	var branch string
+	found := false
+	o.withLock(func() {
+		branch, found = o.defaultBranches[gitURL]
+	})
+	if found {
+		return branch
+	}
+
+	scmClient, repoFullName, err := o.GetScmClient(gitURL, o.GitKind)
+	if err != nil {
+		o.Logger().Warnf("failed to create ScmClient to find the default branch of repository %s: %s", gitURL, err.Error())
+		return ""
+	}
+	repo, _, err := scmClient.Repositories.Find(context.Background(), repoFullName)
+	if err != nil {
+		o.Logger().Warnf("failed to find the default branch of repository %s: %s", gitURL, err.Error())
+		return ""
+	}
+	branch = repo.Branch
+	o.withLock(func() {
+		if o.defaultBranches == nil {
+			o.defaultBranches = map[string]string{}
+		}
+		o.defaultBranches[gitURL] = branch
+	})
+	if branch != "" {
+		o.Logger().Infof("using default branch %s for repository %s", info(branch), gitURL)
+	}
+	return branch
+}
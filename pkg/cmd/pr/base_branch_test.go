@@ -0,0 +1,143 @@
+package pr_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateBaseBranch(t *testing.T) {
+	gitURL := "https://github.com/myorg/myrepo"
+
+	testCases := []struct {
+		name       string
+		version    string
+		baseBranch string
+		expected   string
+		err        bool
+	}{
+		{
+			name:       "plain",
+			version:    "1.2.3",
+			baseBranch: "main",
+			expected:   "main",
+		},
+		{
+			name:       "release-branch",
+			version:    "1.2.3",
+			baseBranch: "release/{{.VersionMajorMinor}}",
+			expected:   "release/1.2",
+		},
+		{
+			name:       "prerelease",
+			version:    "v2.0.1-rc.1",
+			baseBranch: "release/{{.VersionMajor}}.{{.VersionMinor}}",
+			expected:   "release/2.0",
+		},
+		{
+			name:       "missing-branch",
+			version:    "1.3.0",
+			baseBranch: "release/{{.VersionMajorMinor}}",
+			err:        true,
+		},
+		{
+			name:       "not-semver",
+			version:    "latest",
+			baseBranch: "release/{{.VersionMajorMinor}}",
+			err:        true,
+		},
+	}
+
+	for _, tc := range testCases {
+		scmClient, _ := fake.NewDefault()
+		gitService := &fakeGitService{GitService: scmClient.Git, branches: []string{"main", "release/1.2", "release/2.0"}}
+		scmClient.Git = gitService
+
+		_, o := pr.NewCmdPullRequest()
+		o.ScmClient = scmClient
+		o.ScmClientFactory.ScmClient = scmClient
+		o.ScmClientFactory.GitServerURL = "https://github.com"
+		o.GitKind = "fake"
+		o.Version = tc.version
+		o.TemplateData = map[string]interface{}{}
+		o.AddVersionTemplateData()
+
+		got, err := o.EvaluateBaseBranch(tc.baseBranch, gitURL)
+		if tc.err {
+			require.Error(t, err, "should fail for test %s", tc.name)
+			t.Logf("test %s got expected error: %s", tc.name, err.Error())
+			continue
+		}
+		require.NoError(t, err, "failed to evaluate base branch for test %s", tc.name)
+		assert.Equal(t, tc.expected, got, "base branch for test %s", tc.name)
+	}
+}
+
+func TestEvaluateBaseBranchDefaultBranch(t *testing.T) {
+	scmClient, _ := fake.NewDefault()
+	repoService := &fakeDefaultBranchRepositoryService{
+		RepositoryService: scmClient.Repositories,
+		branches: map[string]string{
+			"myorg/main-repo":   "main",
+			"myorg/master-repo": "master",
+		},
+	}
+	scmClient.Repositories = repoService
+
+	_, o := pr.NewCmdPullRequest()
+	o.ScmClient = scmClient
+	o.ScmClientFactory.ScmClient = scmClient
+	o.ScmClientFactory.GitServerURL = "https://github.com"
+	o.GitKind = "fake"
+
+	for i := 0; i < 2; i++ {
+		got, err := o.EvaluateBaseBranch("", "https://github.com/myorg/main-repo")
+		require.NoError(t, err, "failed to evaluate base branch of main-repo")
+		assert.Equal(t, "main", got, "base branch of main-repo")
+
+		got, err = o.EvaluateBaseBranch("", "https://github.com/myorg/master-repo")
+		require.NoError(t, err, "failed to evaluate base branch of master-repo")
+		assert.Equal(t, "master", got, "base branch of master-repo")
+	}
+	assert.Equal(t, []string{"myorg/main-repo", "myorg/master-repo"}, repoService.finds, "the default branches should be cached")
+
+	got, err := o.EvaluateBaseBranch("", "https://github.com/myorg/missing")
+	require.NoError(t, err, "failed to evaluate base branch of a missing repository")
+	assert.Equal(t, "", got, "base branch of a missing repository")
+}
+
+// fakeDefaultBranchRepositoryService returns repositories with their default branches
+type fakeDefaultBranchRepositoryService struct {
+	scm.RepositoryService
+	branches map[string]string
+	finds    []string
+}
+
+func (s *fakeDefaultBranchRepositoryService) Find(_ context.Context, repo string) (*scm.Repository, *scm.Response, error) {
+	s.finds = append(s.finds, repo)
+	branch, ok := s.branches[repo]
+	if !ok {
+		return nil, nil, scm.ErrNotFound
+	}
+	return &scm.Repository{FullName: repo, Branch: branch}, nil, nil
+}
+
+// fakeGitService implements finding branches which the fake driver does not support
+type fakeGitService struct {
+	scm.GitService
+	branches []string
+}
+
+func (s *fakeGitService) FindBranch(_ context.Context, _, name string) (*scm.Reference, *scm.Response, error) {
+	for _, b := range s.branches {
+		if b == name {
+			return &scm.Reference{Name: name}, nil, nil
+		}
+	}
+	return nil, nil, scm.ErrNotFound
+}
@@ -0,0 +1,19 @@
+package pr
+
+import (
+	"path/filepath"
+)
+
+// ResolveConfigPath resolves a path referenced by the config file, such as a body template, against the --base-dir
+// option which defaults to the directory of the config file. Falls back to the --dir option if no base dir is known
+// such as when the config is not loaded from a file. Absolute paths are returned unchanged
+func (o *Options) ResolveConfigPath(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	baseDir := o.BaseDir
+	if baseDir == "" {
+		baseDir = o.Dir
+	}
+	return filepath.Join(baseDir, path)
+}
@@ -0,0 +1,55 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveConfigPath(t *testing.T) {
+	dir := t.TempDir()
+	configDir := filepath.Join(dir, "config")
+	err := os.MkdirAll(configDir, files.DefaultDirWritePermissions)
+	require.NoError(t, err, "failed to create %s", configDir)
+	configFile := filepath.Join(configDir, "updatebot.yaml")
+	err = os.WriteFile(configFile, []byte("apiVersion: updatebot.jenkins-x.io/v1alpha1\nkind: UpdateConfig\n"), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to write %s", configFile)
+
+	otherDir := filepath.Join(dir, "other")
+	absolute := filepath.Join(dir, "absolute.gotmpl")
+
+	testCases := []struct {
+		name     string
+		baseDir  string
+		path     string
+		expected string
+	}{
+		{name: "config-dir", path: "body.gotmpl", expected: filepath.Join(configDir, "body.gotmpl")},
+		{name: "parent", path: "../body.gotmpl", expected: filepath.Join(dir, "body.gotmpl")},
+		{name: "base-dir", baseDir: otherDir, path: "body.gotmpl", expected: filepath.Join(otherDir, "body.gotmpl")},
+		{name: "absolute", baseDir: otherDir, path: absolute, expected: absolute},
+		{name: "empty", path: ""},
+	}
+
+	for _, tc := range testCases {
+		o := &pr.Options{
+			Dir:        filepath.Join(dir, "cwd"),
+			ConfigFile: configFile,
+			BaseDir:    tc.baseDir,
+		}
+		err := o.LoadConfig()
+		require.NoError(t, err, "failed to load config for test %s", tc.name)
+
+		actual := o.ResolveConfigPath(tc.path)
+		assert.Equal(t, tc.expected, actual, "resolved path for test %s", tc.name)
+	}
+
+	// without a loaded config paths are resolved against --dir
+	o := &pr.Options{Dir: dir}
+	assert.Equal(t, filepath.Join(dir, "body.gotmpl"), o.ResolveConfigPath("body.gotmpl"), "resolved path without a config")
+}
@@ -0,0 +1,163 @@
+package pr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+	"github.com/sirupsen/logrus"
+)
+
+// BatchRulesByRepository groups the rules by repository so that each repository gets a single Pull Request with the
+// changes of all the rules which update it. A rule is returned for each repository, in the order they are first found,
+// with the changes of the rules in order. The other fields are taken from the first rule for the repository except
+// the assignees, reviewers, expected changed files and sparse checkout paths which are combined and sparse checkout
+// which is only used if every rule uses it. The position of the first rule of each batch in the given rules is also
+// returned. The rules should already be ordered by their dependencies as the IDs and dependencies of the rules are not
+// kept
+func BatchRulesByRepository(rules []v1alpha1.Rule) ([]v1alpha1.Rule, []int) {
+	var answer []v1alpha1.Rule
+	var positions []int
+	indexes := map[string]int{}
+	for i := range rules {
+		rule := &rules[i]
+		for _, u := range rule.URLs {
+			if u == "" {
+				continue
+			}
+			index, ok := indexes[u]
+			if !ok {
+				batch := *rule
+				batch.URLs = []string{u}
+				batch.ID = ""
+				batch.DependsOn = nil
+				batch.Changes = append([]v1alpha1.Change{}, rule.Changes...)
+				batch.PullRequestAssignees = append([]string{}, rule.PullRequestAssignees...)
+				batch.PullRequestReviewers = append([]string{}, rule.PullRequestReviewers...)
+				batch.ExpectedChangedFiles = append([]string{}, rule.ExpectedChangedFiles...)
+				batch.SparseCheckoutPaths = append([]string{}, rule.SparseCheckoutPaths...)
+				indexes[u] = len(answer)
+				answer = append(answer, batch)
+				positions = append(positions, i)
+				continue
+			}
+			batch := &answer[index]
+			batch.Changes = append(batch.Changes, rule.Changes...)
+			for _, assignee := range rule.PullRequestAssignees {
+				batch.PullRequestAssignees = stringhelpers.EnsureStringArrayContains(batch.PullRequestAssignees, assignee)
+			}
+			for _, reviewer := range rule.PullRequestReviewers {
+				batch.PullRequestReviewers = stringhelpers.EnsureStringArrayContains(batch.PullRequestReviewers, reviewer)
+			}
+			for _, path := range rule.ExpectedChangedFiles {
+				batch.ExpectedChangedFiles = stringhelpers.EnsureStringArrayContains(batch.ExpectedChangedFiles, path)
+			}
+			for _, path := range rule.SparseCheckoutPaths {
+				batch.SparseCheckoutPaths = stringhelpers.EnsureStringArrayContains(batch.SparseCheckoutPaths, path)
+			}
+			batch.AssignAuthorToPullRequests = batch.AssignAuthorToPullRequests || rule.AssignAuthorToPullRequests
+			batch.SparseCheckout = batch.SparseCheckout && rule.SparseCheckout
+			if batch.PullRequestTitleTemplate == "" {
+				batch.PullRequestTitleTemplate = rule.PullRequestTitleTemplate
+			}
+			if batch.PullRequestBodyTemplate == "" && batch.PullRequestBodyTemplateFile == "" {
+				batch.PullRequestBodyTemplate = rule.PullRequestBodyTemplate
+				batch.PullRequestBodyTemplateFile = rule.PullRequestBodyTemplateFile
+			}
+		}
+	}
+	return answer, positions
+}
+
+// ResolveRuleURLs finds the repositories of each rule, including the repositories found by a repoQuery, a go change
+// or the --repo-file, before the rules are batched so that every repository a rule updates is batched. The rules are
+// returned with their config indexes. A rule which fails is recorded as a failure, unless --fail-fast is enabled, and
+// is not returned. The repositories of the batched rules are not found again when the rules are processed
+func (o *Options) ResolveRuleURLs(rules []v1alpha1.Rule, indexes []int) ([]v1alpha1.Rule, []int, error) {
+	var answer []v1alpha1.Rule
+	var answerIndexes []int
+	for i := range rules {
+		rule := rules[i]
+		index := indexes[i]
+		o.logFields = logrus.Fields{"rule": index}
+		err := o.resolveURLs(&rule, index)
+		o.logFields = nil
+		if err != nil {
+			err = fmt.Errorf("failed to process rule #%d: %w", index, err)
+			if o.FailFast {
+				return nil, nil, err
+			}
+			o.Logger().Warnf("%s, continuing with the remaining rules", err.Error())
+			o.failures = append(o.failures, RepositoryFailure{Rule: index, Error: err})
+			continue
+		}
+		answer = append(answer, rule)
+		answerIndexes = append(answerIndexes, index)
+	}
+	o.resolvedURLs = true
+	return answer, answerIndexes, nil
+}
+
+// BatchPullRequestBody returns the list of the applications and versions updated by the changes of the batched rule
+// to add to the Pull Request body. Returns an empty string if the changes only update one application
+func (o *Options) BatchPullRequestBody(rule *v1alpha1.Rule, gitURL string) (string, error) {
+	var updates []string
+	for _, change := range rule.Changes {
+		update, err := o.changeUpdate(change, gitURL)
+		if err != nil {
+			return "", err
+		}
+		if update != "" {
+			updates = stringhelpers.EnsureStringArrayContains(updates, update)
+		}
+	}
+	if len(updates) < 2 {
+		return "", nil
+	}
+	return "\n\nThis Pull Request updates:\n\n* " + strings.Join(updates, "\n* ") + "\n", nil
+}
+
+// changeUpdate returns the application and version updated by the change or an empty string if the change does not
+// update a version
+func (o *Options) changeUpdate(change v1alpha1.Change, gitURL string) (string, error) {
+	if change.Set != nil {
+		return "", nil
+	}
+	if vs := change.VersionStream; vs != nil {
+		if vs.Name == "" || vs.Version == "" {
+			return "", nil
+		}
+		return fmt.Sprintf("`%s` to `%s`", vs.Name, vs.Version), nil
+	}
+	restoreVersion, err := o.useChangeVersion(change)
+	if err != nil {
+		return "", err
+	}
+	defer restoreVersion()
+
+	version := o.Version
+	if change.VersionTemplate != "" {
+		version, err = o.EvaluateVersionTemplate(change.VersionTemplate, gitURL)
+		if err != nil {
+			return "", fmt.Errorf("failed to evaluate version template %s: %w", change.VersionTemplate, err)
+		}
+	}
+	if version == "" {
+		return "", nil
+	}
+	name := o.Application
+	if change.VersionKey != "" {
+		name = change.VersionKey
+	}
+	if change.Go != nil && change.Go.Package != "" {
+		name = change.Go.Package
+	}
+	if change.ImageDigest != nil {
+		name = change.ImageDigest.Image
+	}
+	if name == "" {
+		return fmt.Sprintf("version `%s`", version), nil
+	}
+	return fmt.Sprintf("`%s` to `%s`", name, version), nil
+}
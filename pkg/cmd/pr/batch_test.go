@@ -0,0 +1,103 @@
+package pr_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchRulesByRepository(t *testing.T) {
+	appChange := v1alpha1.Change{Regex: &v1alpha1.Regex{Pattern: `app: (.*)`, Globs: []string{"values.yaml"}}}
+	chartChange := v1alpha1.Change{VersionStream: &v1alpha1.VersionStreamChange{Pattern: v1alpha1.Pattern{Name: "myrepo/mychart"}, Kind: "charts", Version: "2.0.0"}}
+	rules := []v1alpha1.Rule{
+		{
+			URLs:                 []string{"https://github.com/myorg/config", "https://github.com/myorg/app"},
+			Changes:              []v1alpha1.Change{appChange},
+			ReusePullRequest:     true,
+			SparseCheckout:       true,
+			PullRequestAssignees: []string{"alice"},
+		},
+		{
+			URLs:                       []string{"https://github.com/myorg/config", ""},
+			Changes:                    []v1alpha1.Change{chartChange},
+			PullRequestAssignees:       []string{"bob", "alice"},
+			AssignAuthorToPullRequests: true,
+		},
+		{
+			URLs:    []string{"https://github.com/myorg/docs"},
+			Changes: []v1alpha1.Change{chartChange},
+		},
+	}
+
+	batches, positions := pr.BatchRulesByRepository(rules)
+	require.Len(t, batches, 3)
+	assert.Equal(t, []int{0, 0, 2}, positions, "positions of the first rule of each batch")
+
+	config := batches[0]
+	assert.Equal(t, []string{"https://github.com/myorg/config"}, config.URLs)
+	assert.Equal(t, []v1alpha1.Change{appChange, chartChange}, config.Changes)
+	assert.Equal(t, []string{"alice", "bob"}, config.PullRequestAssignees)
+	assert.True(t, config.ReusePullRequest, "should use the fields of the first rule")
+	assert.True(t, config.AssignAuthorToPullRequests, "should assign the author if any rule does")
+	assert.False(t, config.SparseCheckout, "should only use sparse checkout if every rule does")
+
+	app := batches[1]
+	assert.Equal(t, []string{"https://github.com/myorg/app"}, app.URLs)
+	assert.Equal(t, []v1alpha1.Change{appChange}, app.Changes)
+	assert.True(t, app.SparseCheckout)
+	assert.Equal(t, []string{"alice"}, app.PullRequestAssignees)
+
+	assert.Len(t, rules[0].Changes, 1, "should not modify the rules")
+
+	o := &pr.Options{Version: "1.2.3"}
+	o.Application = "myorg/myapp"
+	body, err := o.BatchPullRequestBody(&config, config.URLs[0])
+	require.NoError(t, err, "failed to render batch body")
+	assert.Equal(t, "\n\nThis Pull Request updates:\n\n* `myorg/myapp` to `1.2.3`\n* `myrepo/mychart` to `2.0.0`\n", body)
+
+	body, err = o.BatchPullRequestBody(&app, app.URLs[0])
+	require.NoError(t, err, "failed to render batch body")
+	assert.Empty(t, body, "should not list a single update")
+}
+
+func TestResolveRuleURLsBeforeBatching(t *testing.T) {
+	appChange := v1alpha1.Change{Regex: &v1alpha1.Regex{Pattern: `app: (.*)`, Globs: []string{"values.yaml"}}}
+	chartChange := v1alpha1.Change{VersionStream: &v1alpha1.VersionStreamChange{Pattern: v1alpha1.Pattern{Name: "myrepo/mychart"}, Kind: "charts", Version: "2.0.0"}}
+	repoFile := filepath.Join(t.TempDir(), "repos.txt")
+	writeTestFile(t, repoFile, "https://github.com/myorg/app\n")
+
+	_, o := pr.NewCmdPullRequest()
+	o.RepoFile = repoFile
+	o.ExcludeRepos = []string{"myorg/excluded"}
+	err := o.LoadRepoFile()
+	require.NoError(t, err, "failed to load repo file")
+
+	rules := []v1alpha1.Rule{
+		{
+			URLs:    []string{"https://github.com/myorg/config", "https://github.com/myorg/excluded"},
+			Changes: []v1alpha1.Change{appChange},
+		},
+		{
+			Changes: []v1alpha1.Change{chartChange},
+		},
+	}
+	rules, indexes, err := o.ResolveRuleURLs(rules, []int{2, 5})
+	require.NoError(t, err, "failed to resolve URLs")
+	assert.Equal(t, []int{2, 5}, indexes, "config indexes of the rules")
+
+	batches, positions := pr.BatchRulesByRepository(rules)
+	require.Len(t, batches, 2)
+	assert.Equal(t, []int{0, 0}, positions, "positions of the first rule of each batch")
+	assert.Equal(t, []string{"https://github.com/myorg/config"}, batches[0].URLs)
+	assert.Equal(t, []v1alpha1.Change{appChange}, batches[0].Changes)
+	assert.Equal(t, []string{"https://github.com/myorg/app"}, batches[1].URLs)
+	assert.Equal(t, []v1alpha1.Change{appChange, chartChange}, batches[1].Changes, "should batch the changes of the repository from the repo file")
+
+	err = o.ProcessRule(&batches[0], indexes[positions[0]])
+	require.NoError(t, err, "failed to process batched rule")
+	assert.Equal(t, []string{"https://github.com/myorg/config"}, batches[0].URLs, "should not find the repositories of a batched rule again")
+}
@@ -0,0 +1,25 @@
+package pr
+
+import (
+	"strings"
+
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+)
+
+// GitKindsBitbucketServer the git kinds of Bitbucket Server and Data Center
+var GitKindsBitbucketServer = []string{"bitbucketserver", "stash"}
+
+// IsBitbucketServer returns true if the git kind is Bitbucket Server or Data Center
+func (o *Options) IsBitbucketServer() bool {
+	return stringhelpers.StringArrayIndex(GitKindsBitbucketServer, o.GitKind) >= 0 ||
+		stringhelpers.StringArrayIndex(GitKindsBitbucketServer, o.ScmClientFactory.GitKind) >= 0
+}
+
+// CommitListRef returns the ref to list the commits of. Bitbucket Server lists the commits until a fully qualified ref
+// so branch names are qualified with refs/heads/
+func (o *Options) CommitListRef(ref string) string {
+	if ref == "" || !o.IsBitbucketServer() || strings.HasPrefix(ref, "refs/") {
+		return ref
+	}
+	return "refs/heads/" + ref
+}
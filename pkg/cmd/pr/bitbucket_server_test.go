@@ -0,0 +1,61 @@
+package pr_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssignUsersToPullRequestIssueBitbucketServer(t *testing.T) {
+	gitURL := "https://bitbucket.example.com/scm/myproject/myrepo.git"
+	pullRequest := &scm.PullRequest{Number: 5}
+	rule := &v1alpha1.Rule{PullRequestAssignees: []string{"alice"}}
+
+	scmClient, _ := fake.NewDefault()
+	pullRequests := &fakeAssignPullRequestService{fakeReviewPullRequestService: &fakeReviewPullRequestService{PullRequestService: scmClient.PullRequests}}
+	scmClient.PullRequests = pullRequests
+
+	_, o := pr.NewCmdPullRequest()
+	o.ScmClient = scmClient
+	o.ScmClientFactory.ScmClient = scmClient
+	o.ScmClientFactory.GitServerURL = "https://bitbucket.example.com"
+	o.ScmClientFactory.GitKind = "bitbucketserver"
+	o.GitKind = "fake"
+	o.PRAssignees = []string{"bob"}
+
+	err := o.AssignUsersToPullRequestIssue(rule, pullRequest, gitURL, "", "", o.GitKind)
+	require.NoError(t, err, "failed to assign users")
+	assert.Empty(t, pullRequests.assigned, "users should not be assigned on Bitbucket Server")
+	require.Len(t, pullRequests.reviewers, 1, "review requests")
+	for _, reviewers := range pullRequests.reviewers {
+		assert.Equal(t, []string{"alice", "bob"}, reviewers, "reviewers added for the assignees")
+	}
+}
+
+func TestCommitListRef(t *testing.T) {
+	_, o := pr.NewCmdPullRequest()
+	o.GitKind = "github"
+	assert.Equal(t, "main", o.CommitListRef("main"), "github ref")
+
+	o.GitKind = "stash"
+	assert.Equal(t, "refs/heads/main", o.CommitListRef("main"), "bitbucket server branch")
+	assert.Equal(t, "refs/tags/v1.0.0", o.CommitListRef("refs/tags/v1.0.0"), "bitbucket server qualified ref")
+	assert.Equal(t, "", o.CommitListRef(""), "bitbucket server default branch")
+}
+
+// fakeAssignPullRequestService records the users assigned to Pull Requests along with the review requests
+type fakeAssignPullRequestService struct {
+	*fakeReviewPullRequestService
+	assigned []string
+}
+
+func (s *fakeAssignPullRequestService) AssignIssue(_ context.Context, _ string, _ int, logins []string) (*scm.Response, error) {
+	s.assigned = append(s.assigned, logins...)
+	return nil, nil
+}
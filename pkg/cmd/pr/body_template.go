@@ -0,0 +1,107 @@
+package pr
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/templater"
+)
+
+// PullRequestBodyTemplateFile returns the body template file for the rule. The rule template file is resolved relative
+// to the --base-dir option and takes precedence over the --pull-request-body-template option. Returns an empty string
+// if the rule has an inline body template or the built-in body should be used
+func (o *Options) PullRequestBodyTemplateFile(rule *v1alpha1.Rule) string {
+	if rule.PullRequestBodyTemplate != "" {
+		return ""
+	}
+	if rule.PullRequestBodyTemplateFile != "" {
+		return o.ResolveConfigPath(rule.PullRequestBodyTemplateFile)
+	}
+	return o.BodyTemplate
+}
+
+// EvaluatePullRequestBody renders the body template of the rule for the repository. The template is evaluated with
+// the TemplateData along with the Version, Application, GitURL, Rule and the built-in Title and Body.
+// Returns the built-in body if there is no template
+func (o *Options) EvaluatePullRequestBody(rule *v1alpha1.Rule, gitURL string) (string, error) {
+	if rule.PullRequestBodyTemplate != "" {
+		return templater.Evaluate(o.pullRequestFuncMap(), o.pullRequestTemplateData(rule, gitURL), rule.PullRequestBodyTemplate, "pullRequestBodyTemplate", "pull request body template for "+gitURL)
+	}
+	path := o.PullRequestBodyTemplateFile(rule)
+	if path == "" {
+		return o.CommitMessage, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read pull request body template %s: %w", path, err)
+	}
+	return templater.Evaluate(o.pullRequestFuncMap(), o.pullRequestTemplateData(rule, gitURL), string(data), path, "pull request body template for "+gitURL)
+}
+
+// EvaluatePullRequestTitle renders the title template of the rule for the repository with the same data as the body
+// template. Returns the built-in title if there is no template
+func (o *Options) EvaluatePullRequestTitle(rule *v1alpha1.Rule, gitURL string) (string, error) {
+	if rule.PullRequestTitleTemplate == "" {
+		return o.CommitTitle, nil
+	}
+	title, err := templater.Evaluate(o.pullRequestFuncMap(), o.pullRequestTemplateData(rule, gitURL), rule.PullRequestTitleTemplate, "pullRequestTitleTemplate", "pull request title template for "+gitURL)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(title), nil
+}
+
+// ValidatePullRequestTemplates parses the title and body templates of the rule, including the body template file, so
+// that a mistake fails the rule before any repository is changed
+func (o *Options) ValidatePullRequestTemplates(rule *v1alpha1.Rule) error {
+	if rule.PullRequestBodyTemplate != "" && rule.PullRequestBodyTemplateFile != "" {
+		return fmt.Errorf("only one of pullRequestBodyTemplate and pullRequestBodyTemplateFile can be specified")
+	}
+	templates := map[string]string{
+		"pullRequestTitleTemplate": rule.PullRequestTitleTemplate,
+		"pullRequestBodyTemplate":  rule.PullRequestBodyTemplate,
+	}
+	path := o.PullRequestBodyTemplateFile(rule)
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read pull request body template %s: %w", path, err)
+		}
+		templates[path] = string(data)
+	}
+	for name, text := range templates {
+		if text == "" {
+			continue
+		}
+		_, err := template.New(name).Funcs(o.pullRequestFuncMap()).Parse(text)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// pullRequestTemplateData returns the data used to render the title and body templates of the rule
+func (o *Options) pullRequestTemplateData(rule *v1alpha1.Rule, gitURL string) map[string]interface{} {
+	templateData := map[string]interface{}{}
+	for k, v := range o.TemplateData {
+		templateData[k] = v
+	}
+	templateData["Version"] = o.Version
+	templateData["Application"] = o.Application
+	templateData["GitURL"] = gitURL
+	templateData["Rule"] = rule
+	templateData["Title"] = o.CommitTitle
+	templateData["Body"] = o.CommitMessage
+	return templateData
+}
+
+func (o *Options) pullRequestFuncMap() template.FuncMap {
+	funcMap := sprig.TxtFuncMap()
+	funcMap["pullRequestSha"] = o.pullRequestSha
+	return funcMap
+}
@@ -0,0 +1,139 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluatePullRequestBody(t *testing.T) {
+	dir := t.TempDir()
+	globalFile := filepath.Join(dir, "global.gotmpl")
+	err := os.WriteFile(globalFile, []byte("global {{ .Application }} {{ .Version }}"), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to write global template")
+	err = os.WriteFile(filepath.Join(dir, "rule.gotmpl"), []byte("rule {{ .GitURL }} {{ .team }}\n{{ .Body }}"), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to write rule template")
+
+	gitURL := "https://github.com/myorg/myrepo"
+	testCases := []struct {
+		name     string
+		global   string
+		rule     string
+		expected string
+		err      bool
+	}{
+		{name: "default", expected: "from: https://github.com/myorg/myapp\n"},
+		{name: "global", global: globalFile, expected: "global myapp 1.2.3"},
+		{name: "rule", global: globalFile, rule: "rule.gotmpl", expected: "rule https://github.com/myorg/myrepo backend\nfrom: https://github.com/myorg/myapp\n"},
+		{name: "missing", rule: "missing.gotmpl", err: true},
+	}
+
+	for _, tc := range testCases {
+		o := &pr.Options{
+			Dir:          dir,
+			Version:      "1.2.3",
+			BodyTemplate: tc.global,
+			TemplateData: map[string]interface{}{"team": "backend"},
+		}
+		o.Application = "myapp"
+		o.CommitMessage = "from: https://github.com/myorg/myapp\n"
+
+		body, err := o.EvaluatePullRequestBody(&v1alpha1.Rule{PullRequestBodyTemplateFile: tc.rule}, gitURL)
+		if tc.err {
+			require.Error(t, err, "should fail for test %s", tc.name)
+			continue
+		}
+		require.NoError(t, err, "failed to evaluate body for test %s", tc.name)
+		assert.Equal(t, tc.expected, body, "body for test %s", tc.name)
+	}
+}
+
+func TestEvaluatePullRequestTitle(t *testing.T) {
+	gitURL := "https://github.com/myorg/myrepo"
+	testCases := []struct {
+		name          string
+		rule          v1alpha1.Rule
+		expectedTitle string
+		expectedBody  string
+	}{
+		{
+			name:          "default",
+			expectedTitle: "chore: upgrade myapp to version 1.2.3",
+			expectedBody:  "from: https://github.com/myorg/myapp\n",
+		},
+		{
+			name: "templates",
+			rule: v1alpha1.Rule{
+				PullRequestTitleTemplate: "fix({{ .team }}): bump {{ .Application }} to {{ .Version }} in {{ .GitURL | base }}",
+				PullRequestBodyTemplate:  "{{ .Title }}\n\nchanges: {{ len .Rule.Changes }}",
+				Changes:                  []v1alpha1.Change{{Regex: &v1alpha1.Regex{}}},
+			},
+			expectedTitle: "fix(backend): bump myapp to 1.2.3 in myrepo",
+			expectedBody:  "chore: upgrade myapp to version 1.2.3\n\nchanges: 1",
+		},
+	}
+
+	for _, tc := range testCases {
+		o := &pr.Options{
+			Version:      "1.2.3",
+			TemplateData: map[string]interface{}{"team": "backend"},
+		}
+		o.Application = "myapp"
+		o.CommitTitle = "chore: upgrade myapp to version 1.2.3"
+		o.CommitMessage = "from: https://github.com/myorg/myapp\n"
+
+		title, err := o.EvaluatePullRequestTitle(&tc.rule, gitURL)
+		require.NoError(t, err, "failed to evaluate title for test %s", tc.name)
+		assert.Equal(t, tc.expectedTitle, title, "title for test %s", tc.name)
+
+		body, err := o.EvaluatePullRequestBody(&tc.rule, gitURL)
+		require.NoError(t, err, "failed to evaluate body for test %s", tc.name)
+		assert.Equal(t, tc.expectedBody, body, "body for test %s", tc.name)
+	}
+}
+
+func TestProcessRuleInvalidPullRequestTemplate(t *testing.T) {
+	o := &pr.Options{Version: "1.2.3"}
+	rule := &v1alpha1.Rule{
+		URLs:                     []string{"https://github.com/myorg/myrepo"},
+		PullRequestTitleTemplate: "bump {{ .Version",
+	}
+	err := o.ProcessRule(rule, 3)
+	require.Error(t, err, "should fail for an invalid title template")
+	assert.Contains(t, err.Error(), "rule #3")
+	assert.Contains(t, err.Error(), "pullRequestTitleTemplate")
+
+	rule.PullRequestTitleTemplate = ""
+	rule.PullRequestBodyTemplate = "{{ if .Version }}no end"
+	err = o.ProcessRule(rule, 1)
+	require.Error(t, err, "should fail for an invalid inline body template")
+	assert.Contains(t, err.Error(), "rule #1")
+
+	dir := t.TempDir()
+	err = os.WriteFile(filepath.Join(dir, "body.gotmpl"), []byte("{{ range .Rule.Changes }}no end"), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to write body template")
+	o.Dir = dir
+	rule.PullRequestBodyTemplate = ""
+	rule.PullRequestBodyTemplateFile = "body.gotmpl"
+	err = o.ProcessRule(rule, 4)
+	require.Error(t, err, "should fail for an invalid body template file")
+	assert.Contains(t, err.Error(), "rule #4")
+	assert.Contains(t, err.Error(), "body.gotmpl")
+
+	rule.PullRequestBodyTemplateFile = "missing.gotmpl"
+	err = o.ProcessRule(rule, 5)
+	require.Error(t, err, "should fail for a missing body template file")
+	assert.Contains(t, err.Error(), "rule #5")
+
+	rule.PullRequestBodyTemplate = "{{ .Title }}"
+	rule.PullRequestBodyTemplateFile = "body.gotmpl"
+	err = o.ProcessRule(rule, 6)
+	require.Error(t, err, "should fail for both an inline body template and a body template file")
+	assert.Contains(t, err.Error(), "rule #6")
+}
@@ -0,0 +1,139 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/templater"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/sirupsen/logrus"
+)
+
+var branchNameInvalidChars = regexp.MustCompile(`[^A-Za-z0-9._/-]+`)
+
+// EvaluateBranchName returns the name of the branch of the Pull Request on the repository from the
+// --branch-name-template so that repeated runs for the same version push to the same branch. The template can use the
+// version template data along with the App. An empty name is returned if there is no template so a new branch is
+// generated
+func (o *Options) EvaluateBranchName(gitURL string) (string, error) {
+	if o.BranchNameTemplate == "" {
+		return "", nil
+	}
+	templateData := map[string]interface{}{}
+	for k, v := range o.TemplateData {
+		templateData[k] = v
+	}
+	templateData["App"] = o.Application
+	text, err := templater.Evaluate(sprig.TxtFuncMap(), templateData, o.BranchNameTemplate, "branch.gotmpl", "branch name template for "+gitURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to evaluate branch name template %s: %w", o.BranchNameTemplate, err)
+	}
+	branch := SanitizeBranchName(text)
+	if branch == "" {
+		return "", fmt.Errorf("the branch name template %s evaluated to an empty branch name for repository %s", o.BranchNameTemplate, gitURL)
+	}
+	return branch, nil
+}
+
+// SanitizeBranchName replaces the characters which are not valid in a git branch name with a dash
+func SanitizeBranchName(text string) string {
+	branch := branchNameInvalidChars.ReplaceAllString(strings.TrimSpace(text), "-")
+	for strings.Contains(branch, "..") {
+		branch = strings.ReplaceAll(branch, "..", ".")
+	}
+	for strings.Contains(branch, "//") {
+		branch = strings.ReplaceAll(branch, "//", "/")
+	}
+	branch = strings.TrimSuffix(branch, ".lock")
+	return strings.Trim(branch, "/.-")
+}
+
+// branchPullRequestService reuses the open Pull Request of the branch if creating the Pull Request fails as the
+// branch already has one, which happens when a branch name template is used without reusePullRequest. The ScmClient
+// is shared by the repositories of a git server so the options of each repository are kept to log with its fields
+type branchPullRequestService struct {
+	scm.PullRequestService
+	mu      sync.Mutex
+	options map[string]*Options
+}
+
+// logger returns the logger of the options processing the repository
+func (s *branchPullRequestService) logger(repo string) *logrus.Entry {
+	s.mu.Lock()
+	o := s.options[repo]
+	s.mu.Unlock()
+	if o == nil {
+		return log.Logger()
+	}
+	return o.Logger()
+}
+
+// Create creates the Pull Request or updates the title and body of the open Pull Request of the head branch
+func (s *branchPullRequestService) Create(ctx context.Context, repo string, input *scm.PullRequestInput) (*scm.PullRequest, *scm.Response, error) {
+	pr, res, err := s.PullRequestService.Create(ctx, repo, input)
+	if err == nil {
+		return pr, res, nil
+	}
+	existing, findErr := FindBranchPullRequest(ctx, s.PullRequestService, repo, input.Head)
+	if findErr != nil {
+		s.logger(repo).Warnf("failed to find the open Pull Request of branch %s on repository %s: %s", input.Head, repo, findErr.Error())
+		return pr, res, err
+	}
+	if existing == nil {
+		return pr, res, err
+	}
+	s.logger(repo).Infof("reusing the open Pull Request %s of branch %s", info(existing.Link), input.Head)
+	updated, res, err := s.PullRequestService.Update(ctx, repo, existing.Number, &scm.PullRequestInput{Title: input.Title, Body: input.Body})
+	if err != nil {
+		return nil, res, fmt.Errorf("failed to update Pull Request %s: %w", existing.Link, err)
+	}
+	return updated, res, nil
+}
+
+// FindBranchPullRequest returns the open Pull Request of the repository whose source is the head branch, which may be
+// prefixed by the owner of a fork, or nil if there is none
+func FindBranchPullRequest(ctx context.Context, pullRequests scm.PullRequestService, repo, head string) (*scm.PullRequest, error) {
+	branch := head
+	if i := strings.LastIndex(head, ":"); i >= 0 {
+		branch = head[i+1:]
+	}
+	prs, _, err := pullRequests.List(ctx, repo, &scm.PullRequestListOptions{Size: 100, Open: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the open Pull Requests of repository %s: %w", repo, err)
+	}
+	for _, pr := range prs {
+		if pr.Closed || pr.Merged {
+			continue
+		}
+		if pr.Source == branch || pr.Head.Ref == branch {
+			return pr, nil
+		}
+	}
+	return nil, nil
+}
+
+// ReuseBranchPullRequests makes the ScmClient reuse the open Pull Request of a branch rather than failing to create
+// another one when --branch-name-template is used. The repository is the full name of the repository the options are
+// creating a Pull Request for
+func (o *Options) ReuseBranchPullRequests(scmClient *scm.Client, repo string) {
+	if scmClient == nil || o.BranchNameTemplate == "" {
+		return
+	}
+	var s *branchPullRequestService
+	o.withLock(func() {
+		var ok bool
+		s, ok = scmClient.PullRequests.(*branchPullRequestService)
+		if !ok {
+			s = &branchPullRequestService{PullRequestService: scmClient.PullRequests, options: map[string]*Options{}}
+			scmClient.PullRequests = s
+		}
+	})
+	s.mu.Lock()
+	s.options[repo] = o
+	s.mu.Unlock()
+}
@@ -0,0 +1,88 @@
+package pr_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateBranchName(t *testing.T) {
+	gitURL := "https://github.com/myorg/myrepo"
+	newOptions := func(version string) *pr.Options {
+		o := &pr.Options{BranchNameTemplate: "updatebot/{{.App}}-{{.Version}}", TemplateData: map[string]interface{}{}}
+		o.Application = "myorg/myapp"
+		o.Version = version
+		o.AddVersionTemplateData()
+		return o
+	}
+
+	first, err := newOptions("1.2.3").EvaluateBranchName(gitURL)
+	require.NoError(t, err, "failed to evaluate branch name")
+	second, err := newOptions("1.2.3").EvaluateBranchName(gitURL)
+	require.NoError(t, err, "failed to evaluate branch name again")
+	assert.Equal(t, "updatebot/myorg/myapp-1.2.3", first, "branch name")
+	assert.Equal(t, first, second, "the same version uses the same branch")
+
+	other, err := newOptions("1.3.0").EvaluateBranchName(gitURL)
+	require.NoError(t, err, "failed to evaluate branch name for another version")
+	assert.NotEqual(t, first, other, "another version uses another branch")
+
+	branch, err := (&pr.Options{}).EvaluateBranchName(gitURL)
+	require.NoError(t, err, "failed without a template")
+	assert.Empty(t, branch, "generated branch without a template")
+
+	assert.Equal(t, "updatebot/my-app-1.2.3", pr.SanitizeBranchName(" updatebot//my app-1.2.3.. "), "sanitized branch name")
+}
+
+func TestReuseBranchPullRequests(t *testing.T) {
+	ctx := context.Background()
+	existing := &scm.PullRequest{Number: 3, Source: "updatebot/myapp-1.2.3", Link: "https://github.com/myorg/myrepo/pull/3"}
+	scmClient, _ := fake.NewDefault()
+	pullRequests := &fakeBranchPullRequestService{PullRequestService: scmClient.PullRequests, open: []*scm.PullRequest{existing}}
+	scmClient.PullRequests = pullRequests
+
+	o := &pr.Options{BranchNameTemplate: "updatebot/myapp-{{.Version}}"}
+	o.ReuseBranchPullRequests(scmClient, "myorg/myrepo")
+	o.ReuseBranchPullRequests(scmClient, "myorg/myrepo")
+
+	input := &scm.PullRequestInput{Title: "chore: upgrade myapp to 1.2.3", Head: "updatebot/myapp-1.2.3", Base: "main"}
+	actual, _, err := scmClient.PullRequests.Create(ctx, "myorg/myrepo", input)
+	require.NoError(t, err, "failed to create Pull Request")
+	assert.Equal(t, 3, actual.Number, "reused the Pull Request of the branch")
+	assert.Equal(t, "chore: upgrade myapp to 1.2.3", actual.Title, "updated title")
+
+	input.Head = "updatebot/myapp-1.3.0"
+	_, _, err = scmClient.PullRequests.Create(ctx, "myorg/myrepo", input)
+	require.Error(t, err, "should fail for a branch without a Pull Request")
+}
+
+// fakeBranchPullRequestService fails to create Pull Requests as if the branch already has one
+type fakeBranchPullRequestService struct {
+	scm.PullRequestService
+	open []*scm.PullRequest
+}
+
+func (s *fakeBranchPullRequestService) Create(_ context.Context, _ string, input *scm.PullRequestInput) (*scm.PullRequest, *scm.Response, error) {
+	return nil, nil, errors.New("a pull request already exists for " + input.Head)
+}
+
+func (s *fakeBranchPullRequestService) List(_ context.Context, _ string, _ *scm.PullRequestListOptions) ([]*scm.PullRequest, *scm.Response, error) {
+	return s.open, nil, nil
+}
+
+func (s *fakeBranchPullRequestService) Update(_ context.Context, _ string, number int, input *scm.PullRequestInput) (*scm.PullRequest, *scm.Response, error) {
+	for _, pr := range s.open {
+		if pr.Number == number {
+			pr.Title = input.Title
+			pr.Body = input.Body
+			return pr, nil, nil
+		}
+	}
+	return nil, nil, scm.ErrNotFound
+}
@@ -0,0 +1,65 @@
+package pr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/templater"
+)
+
+// DefaultChangelogTemplate the template of each commit of a generated changelog
+const DefaultChangelogTemplate = "* {{ .Subject }} ({{ .ShortSHA }})"
+
+// changelogFieldSeparator separates the fields of each commit logged by git
+const changelogFieldSeparator = "\x1f"
+
+// ChangelogFromCommits returns a changelog of the commits of the git repository in the dir between the previous tag and
+// the latest tag. Each commit is rendered with the --changelog-template which can use the .SHA, .ShortSHA, .Subject and
+// .Author of the commit. If there is no previous tag all the commits up to the latest tag are included
+func (o *Options) ChangelogFromCommits(dir string) (string, error) {
+	g := o.Git()
+	text, err := g.Command(dir, "describe", "--tags", "--abbrev=0")
+	if err != nil {
+		o.Logger().Infof("no git tag found in %s so generating the changelog up to the current commit: %s", dir, err.Error())
+		text = "HEAD"
+	}
+	current := strings.TrimSpace(text)
+	revisions := current
+	previous, err := g.Command(dir, "describe", "--tags", "--abbrev=0", current+"^")
+	if err == nil && strings.TrimSpace(previous) != "" {
+		revisions = strings.TrimSpace(previous) + ".." + current
+	}
+
+	format := strings.Join([]string{"%H", "%an", "%s"}, changelogFieldSeparator)
+	text, err = g.Command(dir, "log", "--format="+format, revisions)
+	if err != nil {
+		return "", fmt.Errorf("failed to log the commits %s in %s: %w", revisions, dir, err)
+	}
+
+	templateText := o.ChangelogTemplate
+	if templateText == "" {
+		templateText = DefaultChangelogTemplate
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		fields := strings.SplitN(line, changelogFieldSeparator, 3)
+		if len(fields) != 3 {
+			continue
+		}
+		sha := fields[0]
+		templateData := map[string]interface{}{
+			"SHA":      sha,
+			"ShortSHA": sha[:min(len(sha), 7)],
+			"Author":   fields[1],
+			"Subject":  fields[2],
+		}
+		entry, err := templater.Evaluate(sprig.TxtFuncMap(), templateData, templateText, "changelog.gotmpl", "changelog template")
+		if err != nil {
+			return "", fmt.Errorf("failed to evaluate changelog template %s: %w", templateText, err)
+		}
+		lines = append(lines, entry)
+	}
+	o.Logger().Infof("generated a changelog of %d commits from %s", len(lines), info(revisions))
+	return strings.Join(lines, "\n"), nil
+}
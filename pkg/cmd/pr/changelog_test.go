@@ -0,0 +1,41 @@
+package pr_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateChangelog(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepository(t, dir)
+	commit := func(message string) {
+		runGit(t, dir, "-c", "user.name=Jane Doe", "-c", "user.email=jane@example.com", "commit", "--allow-empty", "-m", message)
+	}
+	commit("feat: initial release")
+	runGit(t, dir, "tag", "v1.0.0")
+	commit("fix: handle empty config")
+	commit("feat: add retries")
+	runGit(t, dir, "tag", "v1.1.0")
+	commit("chore: not released yet")
+
+	_, o := pr.NewCmdPullRequest()
+	o.Dir = dir
+	o.AddChangelog = ""
+	o.GenerateChangelog = true
+	err := o.SetChangeLog(o.AddChangelog)
+	require.NoError(t, err, "failed to set changelog")
+
+	lines := strings.Split(o.CommitChangelog, "\n")
+	require.Len(t, lines, 2, "changelog %s", o.CommitChangelog)
+	assert.Regexp(t, `^\* feat: add retries \([0-9a-f]{7}\)$`, lines[0], "newest commit")
+	assert.Regexp(t, `^\* fix: handle empty config \([0-9a-f]{7}\)$`, lines[1], "oldest commit")
+
+	o.ChangelogTemplate = "- {{ .Subject }} by {{ .Author }}"
+	changelog, err := o.ChangelogFromCommits(dir)
+	require.NoError(t, err, "failed to generate changelog with a template")
+	assert.Equal(t, "- feat: add retries by Jane Doe\n- fix: handle empty config by Jane Doe", changelog, "templated changelog")
+}
@@ -0,0 +1,118 @@
+package pr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxChangesTableRows the maximum number of rows of the --pr-body-changes-table so that large changes do not exceed
+// the size limit of Pull Request bodies
+const MaxChangesTableRows = 50
+
+// ChangeRow a line changed in a file of a repository
+type ChangeRow struct {
+	// File the path of the file relative to the root of the repository
+	File string
+	// Old the line before the change, empty if the line was added
+	Old string
+	// New the line after the change, empty if the line was removed
+	New string
+}
+
+// PullRequestChangesTable stages all the changes in the dir so that new files and the changes of commands are included
+// then returns a collapsible markdown table of the lines changed in each file to append to the Pull Request body
+func (o *Options) PullRequestChangesTable(dir string) (string, error) {
+	g := o.Git()
+	_, err := g.Command(dir, "add", "--all")
+	if err != nil {
+		return "", fmt.Errorf("failed to add changes in dir %s: %w", dir, err)
+	}
+	diff, err := g.Command(dir, "diff", "--cached", "--no-color", "--unified=0")
+	if err != nil {
+		return "", fmt.Errorf("failed to diff changes in dir %s: %w", dir, err)
+	}
+	return ChangesTable(ParseChangeRows(diff)), nil
+}
+
+// ParseChangeRows parses the lines changed in each file of a unified git diff. The removed and added lines of each
+// hunk are paired up in order so that a changed line is a single row with its old and new values
+func ParseChangeRows(diff string) []ChangeRow {
+	var rows []ChangeRow
+	file := ""
+	var removed, added []string
+	flush := func() {
+		for i := 0; i < max(len(removed), len(added)); i++ {
+			row := ChangeRow{File: file}
+			if i < len(removed) {
+				row.Old = removed[i]
+			}
+			if i < len(added) {
+				row.New = added[i]
+			}
+			rows = append(rows, row)
+		}
+		removed, added = nil, nil
+	}
+	// the --- and +++ lines are only file names in the header before the first hunk of a file
+	header := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			file = ""
+			header = true
+		case header && strings.HasPrefix(line, "--- "):
+			if name := strings.TrimPrefix(line, "--- "); name != "/dev/null" {
+				file = strings.TrimPrefix(name, "a/")
+			}
+		case header && strings.HasPrefix(line, "+++ "):
+			if name := strings.TrimPrefix(line, "+++ "); name != "/dev/null" {
+				file = strings.TrimPrefix(name, "b/")
+			}
+		case strings.HasPrefix(line, "@@"):
+			flush()
+			header = false
+		case header:
+			// skips the other header lines such as the index and file modes
+		case strings.HasPrefix(line, "-"):
+			removed = append(removed, strings.TrimSpace(line[1:]))
+		case strings.HasPrefix(line, "+"):
+			added = append(added, strings.TrimSpace(line[1:]))
+		}
+	}
+	flush()
+	return rows
+}
+
+// ChangesTable returns a markdown table of the changed lines in a collapsible details section or an empty string if
+// there are no changes. Only the first MaxChangesTableRows rows are included
+func ChangesTable(rows []ChangeRow) string {
+	if len(rows) == 0 {
+		return ""
+	}
+	sb := strings.Builder{}
+	sb.WriteString("\n\n<details>\n<summary>Changes</summary>\n\n")
+	sb.WriteString("| File | Old | New |\n")
+	sb.WriteString("| --- | --- | --- |\n")
+	for i, row := range rows {
+		if i == MaxChangesTableRows {
+			sb.WriteString(fmt.Sprintf("\n... and %d more changes\n", len(rows)-i))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", markdownCode(row.File), markdownCode(row.Old), markdownCode(row.New)))
+	}
+	sb.WriteString("\n</details>\n")
+	return sb.String()
+}
+
+// markdownCode returns the text as inline code which can be used in a markdown table cell
+func markdownCode(text string) string {
+	if text == "" {
+		return ""
+	}
+	text = strings.ReplaceAll(text, "|", "\\|")
+	if strings.Contains(text, "`") {
+		return "`` " + text + " ``"
+	}
+	return "`" + text + "`"
+}
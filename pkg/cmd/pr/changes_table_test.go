@@ -0,0 +1,52 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChangeRows(t *testing.T) {
+	diff := `diff --git a/charts/myapp/values.yaml b/charts/myapp/values.yaml
+index 1111111..2222222 100644
+--- a/charts/myapp/values.yaml
++++ b/charts/myapp/values.yaml
+@@ -3 +3 @@ image:
+-  tag: 1.2.3
++  tag: 2.0.0
+@@ -10,0 +11 @@ resources:
++  limits: {}
+diff --git a/schema.sql b/schema.sql
+index 3333333..4444444 100644
+--- a/schema.sql
++++ b/schema.sql
+@@ -1 +0,0 @@
+--- version 1.2.3
+diff --git a/NOTES.md b/NOTES.md
+new file mode 100644
+index 0000000..5555555
+--- /dev/null
++++ b/NOTES.md
+@@ -0,0 +1 @@
++uses | pipes
+`
+	rows := pr.ParseChangeRows(diff)
+	assert.Equal(t, []pr.ChangeRow{
+		{File: "charts/myapp/values.yaml", Old: "tag: 1.2.3", New: "tag: 2.0.0"},
+		{File: "charts/myapp/values.yaml", New: "limits: {}"},
+		{File: "schema.sql", Old: "-- version 1.2.3"},
+		{File: "NOTES.md", New: "uses | pipes"},
+	}, rows, "rows of the diff")
+
+	expected := "\n\n<details>\n<summary>Changes</summary>\n\n" +
+		"| File | Old | New |\n" +
+		"| --- | --- | --- |\n" +
+		"| `charts/myapp/values.yaml` | `tag: 1.2.3` | `tag: 2.0.0` |\n" +
+		"| `charts/myapp/values.yaml` |  | `limits: {}` |\n" +
+		"| `schema.sql` | `-- version 1.2.3` |  |\n" +
+		"| `NOTES.md` |  | `uses \\| pipes` |\n" +
+		"\n</details>\n"
+	assert.Equal(t, expected, pr.ChangesTable(rows), "changes table")
+	assert.Equal(t, "", pr.ChangesTable(nil), "changes table without changes")
+}
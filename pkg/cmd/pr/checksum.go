@@ -0,0 +1,219 @@
+package pr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/httphelpers"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/templater"
+
+	"github.com/yargevad/filepathx"
+)
+
+// ApplyChecksum updates the version and its checksum in the matching files
+func (o *Options) ApplyChecksum(dir, gitURL string, change v1alpha1.Change, checksum *v1alpha1.Checksum) error {
+	if checksum.VersionPattern == "" {
+		return fmt.Errorf("no versionPattern for checksum change %#v", change)
+	}
+	if checksum.ChecksumPattern == "" {
+		return fmt.Errorf("no checksumPattern for checksum change %#v", change)
+	}
+	versionRegex, err := regexp.Compile(checksum.VersionPattern)
+	if err != nil {
+		return fmt.Errorf("failed to parse version regex: %s: %w", checksum.VersionPattern, err)
+	}
+	checksumRegex, err := regexp.Compile(checksum.ChecksumPattern)
+	if err != nil {
+		return fmt.Errorf("failed to parse checksum regex: %s: %w", checksum.ChecksumPattern, err)
+	}
+
+	version := o.Version
+	if change.VersionTemplate != "" {
+		version, err = o.EvaluateVersionTemplate(change.VersionTemplate, gitURL)
+		if err != nil {
+			return fmt.Errorf("failed to valuate version template %s: %w", change.VersionTemplate, err)
+		}
+	}
+
+	// lets resolve the checksum before modifying any files so a failure leaves the repository unchanged
+	sum, err := o.FindChecksum(checksum, version)
+	if err != nil {
+		return fmt.Errorf("failed to find checksum for version %s: %w", version, err)
+	}
+
+	matched := 0
+	for _, g := range checksum.Globs {
+		path := filepath.Join(dir, g)
+		matches, err := filepathx.Glob(path)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate glob %s: %w", path, err)
+		}
+		if len(matches) == 0 {
+			_, err = o.handleMissingFile(change, g, gitURL, false)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		for _, f := range matches {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return fmt.Errorf("failed to load file %s: %w", f, err)
+			}
+			text := string(data)
+			if versionRegex.MatchString(text) {
+				matched++
+			}
+			if !o.matchesCurrentCaptures(change, versionRegex, text, "version", f) {
+				continue
+			}
+			text2 := replaceCapture(versionRegex, text, "version", version)
+			text2 = replaceCapture(checksumRegex, text2, "checksum", sum)
+			if text2 != text {
+				err = os.WriteFile(f, []byte(text2), files.DefaultFileWritePermissions)
+				if err != nil {
+					return fmt.Errorf("failed to save file %s: %w", f, err)
+				}
+				o.Logger().Infof("modified file %s with version %s and checksum %s", info(f), version, sum)
+			}
+		}
+	}
+	return checkRequireMatch(change, matched, "checksum", checksum.VersionPattern, checksum.Globs, gitURL)
+}
+
+// FindChecksum returns the checksum of the given version from the checksums map, a checksums file or by
+// downloading the artifact
+func (o *Options) FindChecksum(checksum *v1alpha1.Checksum, version string) (string, error) {
+	if sum := checksum.Checksums[version]; sum != "" {
+		return sum, nil
+	}
+	templateData := map[string]interface{}{
+		"Version": version,
+	}
+	if checksum.ChecksumURL != "" {
+		u, err := evaluateChecksumTemplate(checksum.ChecksumURL, templateData)
+		if err != nil {
+			return "", err
+		}
+		name, err := evaluateChecksumTemplate(checksum.ChecksumFile, templateData)
+		if err != nil {
+			return "", err
+		}
+		data, err := fetchURL(u)
+		if err != nil {
+			return "", err
+		}
+		return ParseChecksumFile(string(data), name)
+	}
+	if checksum.URL != "" {
+		u, err := evaluateChecksumTemplate(checksum.URL, templateData)
+		if err != nil {
+			return "", err
+		}
+		data, err := fetchURL(u)
+		if err != nil {
+			return "", err
+		}
+		hash := sha256.Sum256(data)
+		return hex.EncodeToString(hash[:]), nil
+	}
+	return "", fmt.Errorf("no checksum for version %s and no url or checksumURL specified", version)
+}
+
+// ParseChecksumFile returns the checksum for the given file name from the output of a tool like sha256sum.
+// If no name is specified the file must contain a single checksum
+func ParseChecksumFile(text, name string) (string, error) {
+	var sums []string
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if name == "" {
+			sums = append(sums, fields[0])
+			continue
+		}
+		if len(fields) > 1 && strings.TrimPrefix(fields[1], "*") == name {
+			return fields[0], nil
+		}
+	}
+	if name != "" {
+		return "", fmt.Errorf("no checksum found for file %s", name)
+	}
+	if len(sums) != 1 {
+		return "", fmt.Errorf("found %d checksums so a checksumFile must be specified", len(sums))
+	}
+	return sums[0], nil
+}
+
+func evaluateChecksumTemplate(templateText string, templateData map[string]interface{}) (string, error) {
+	if !strings.Contains(templateText, "{{") {
+		return templateText, nil
+	}
+	answer, err := templater.Evaluate(sprig.TxtFuncMap(), templateData, templateText, "checksum.gotmpl", "checksum template")
+	if err != nil {
+		return "", fmt.Errorf("failed to evaluate template %s: %w", templateText, err)
+	}
+	return answer, nil
+}
+
+func fetchURL(u string) ([]byte, error) {
+	resp, err := httphelpers.GetClient().Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", u, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: status %s", u, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", u, err)
+	}
+	return data, nil
+}
+
+// matchesCurrentCaptures returns true if all the values of the named capture, or all the capture groups if the regex
+// has no such named capture, match the expected current value of the change
+func (o *Options) matchesCurrentCaptures(change v1alpha1.Change, r *regexp.Regexp, text, name, location string) bool {
+	if change.ExpectedCurrent == "" {
+		return true
+	}
+	index := r.SubexpIndex(name)
+	for _, groups := range r.FindAllStringSubmatch(text, -1) {
+		for i, value := range groups[1:] {
+			if (index < 0 || i+1 == index) && !o.matchesExpectedCurrent(change, value, location) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// replaceCapture replaces the named capture in the matches of the regex with the value, or all the capture groups if
+// the regex has no such named capture
+func replaceCapture(r *regexp.Regexp, text, name, value string) string {
+	index := r.SubexpIndex(name)
+	return stringhelpers.ReplaceAllStringSubmatchFunc(r, text, func(groups []stringhelpers.Group) []string {
+		answer := make([]string, 0, len(groups))
+		for i, group := range groups {
+			if index < 0 || i+1 == index {
+				answer = append(answer, value)
+			} else {
+				answer = append(answer, group.Value)
+			}
+		}
+		return answer
+	})
+}
@@ -0,0 +1,111 @@
+package pr_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyChecksum(t *testing.T) {
+	artifact := []byte("my artifact 1.2.3")
+	hash := sha256.Sum256(artifact)
+	artifactSum := hex.EncodeToString(hash[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1.2.3/tool.tar.gz":
+			_, _ = w.Write(artifact)
+		case "/v1.2.3/checksums.txt":
+			_, _ = fmt.Fprintf(w, "aaaa  other.tar.gz\nbbbb *tool.tar.gz\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	source := "version: 1.0.0\nsha256: 0000\n"
+	testCases := []struct {
+		name     string
+		checksum v1alpha1.Checksum
+		expected string
+		err      bool
+	}{
+		{
+			name: "map",
+			checksum: v1alpha1.Checksum{
+				Checksums: map[string]string{"1.2.3": "cccc"},
+			},
+			expected: "cccc",
+		},
+		{
+			name: "url",
+			checksum: v1alpha1.Checksum{
+				URL: server.URL + "/v{{ .Version }}/tool.tar.gz",
+			},
+			expected: artifactSum,
+		},
+		{
+			name: "checksum-url",
+			checksum: v1alpha1.Checksum{
+				ChecksumURL:  server.URL + "/v{{ .Version }}/checksums.txt",
+				ChecksumFile: "tool.tar.gz",
+			},
+			expected: "bbbb",
+		},
+		{
+			name: "missing",
+			checksum: v1alpha1.Checksum{
+				URL: server.URL + "/v{{ .Version }}/missing.tar.gz",
+			},
+			err: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "tool.yaml")
+		err := os.WriteFile(path, []byte(source), files.DefaultFileWritePermissions)
+		require.NoError(t, err, "failed to write %s", path)
+
+		checksum := tc.checksum
+		checksum.VersionPattern = `version: (.*)`
+		checksum.ChecksumPattern = `sha256: (?P<checksum>.*)`
+		checksum.Globs = []string{"*.yaml"}
+
+		o := &pr.Options{Version: "1.2.3"}
+		err = o.ApplyChecksum(dir, "https://github.com/myorg/myrepo", v1alpha1.Change{Checksum: &checksum}, &checksum)
+
+		data, readErr := os.ReadFile(path)
+		require.NoError(t, readErr, "failed to read %s", path)
+		if tc.err {
+			require.Error(t, err, "should fail for test %s", tc.name)
+			assert.Equal(t, source, string(data), "file should be unchanged for test %s", tc.name)
+			continue
+		}
+		require.NoError(t, err, "failed to apply checksum for test %s", tc.name)
+		assert.Equal(t, "version: 1.2.3\nsha256: "+tc.expected+"\n", string(data), "file for test %s", tc.name)
+	}
+}
+
+func TestParseChecksumFile(t *testing.T) {
+	sum, err := pr.ParseChecksumFile("abcd  tool.tar.gz\n", "")
+	require.NoError(t, err, "failed to parse single checksum")
+	assert.Equal(t, "abcd", sum)
+
+	_, err = pr.ParseChecksumFile("abcd  a.tar.gz\nef01  b.tar.gz\n", "")
+	assert.Error(t, err, "should require a file name with several checksums")
+
+	_, err = pr.ParseChecksumFile("abcd  a.tar.gz\n", "b.tar.gz")
+	assert.Error(t, err, "should fail for a missing file name")
+}
@@ -3,31 +3,146 @@ package pr
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/yargevad/filepathx"
 )
 
+// commandVariableRegex matches the ${NAME} variables of the name, args and env of a command change
+var commandVariableRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_.]*)\}`)
+
+// CommandDirEnvVar the environment variable of the directory, relative to the root of the repository, a command
+// change with globs is run in
+const CommandDirEnvVar = "UPDATEBOT_COMMAND_DIR"
+
 func (o *Options) ApplyCommand(dir string, command *v1alpha1.Command) error {
+	if len(command.Globs) > 0 {
+		return o.applyCommandInDirs(dir, command)
+	}
+	return o.runCommand(dir, command, nil)
+}
+
+// applyCommandInDirs runs the command in the directory of each file matching the globs of the command. The command is
+// run in all the directories even if it fails in some of them so that the error lists every directory which failed
+func (o *Options) applyCommandInDirs(dir string, command *v1alpha1.Command) error {
+	dirs, err := CommandDirs(dir, command.Globs)
+	if err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		o.Logger().Infof("no directories match the files %s of command %s", strings.Join(command.Globs, ", "), command.Name)
+		return nil
+	}
+
+	var succeeded, failed, failures []string
+	for _, rel := range dirs {
+		err = o.runCommand(filepath.Join(dir, rel), command, map[string]string{CommandDirEnvVar: rel})
+		if err != nil {
+			failed = append(failed, rel)
+			failures = append(failures, fmt.Sprintf("* %s: %s", rel, err.Error()))
+			continue
+		}
+		succeeded = append(succeeded, rel)
+	}
+	if len(succeeded) > 0 {
+		o.Logger().Infof("ran command %s in dirs: %s", command.Name, info(strings.Join(succeeded, ", ")))
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	o.Logger().Warnf("command %s failed in dirs: %s", command.Name, strings.Join(failed, ", "))
+	return fmt.Errorf("command %s failed in %d of %d dirs:\n%s", command.Name, len(failed), len(dirs), strings.Join(failures, "\n"))
+}
+
+// CommandDirs returns the sorted directories, relative to the dir, of the files or directories matching the globs
+func CommandDirs(dir string, globs []string) ([]string, error) {
+	found := map[string]bool{}
+	for _, g := range globs {
+		matches, err := filepathx.Glob(filepath.Join(dir, g))
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate glob %s: %w", g, err)
+		}
+		for _, f := range matches {
+			s, err := os.Stat(f)
+			if err != nil {
+				return nil, fmt.Errorf("failed to stat %s: %w", f, err)
+			}
+			if !s.IsDir() {
+				f = filepath.Dir(f)
+			}
+			rel, err := filepath.Rel(dir, f)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find relative path of %s: %w", f, err)
+			}
+			found[rel] = true
+		}
+	}
+	var answer []string
+	for d := range found {
+		answer = append(answer, d)
+	}
+	sort.Strings(answer)
+	return answer, nil
+}
+
+// runCommand runs the command in the dir with the environment variables of the command along with the extra ones
+func (o *Options) runCommand(dir string, command *v1alpha1.Command, extraEnv map[string]string) error {
+	c := o.newCommand(dir, command, extraEnv)
+	_, err := o.CommandRunner(c)
+	if err != nil {
+		return fmt.Errorf("failed to run command %s: %w", c.CLI(), err)
+	}
+	return nil
+}
+
+// newCommand returns the command to run in the dir with its variables expanded and the environment variables of the
+// command along with the extra ones
+func (o *Options) newCommand(dir string, command *v1alpha1.Command, extraEnv map[string]string) *cmdrunner.Command {
+	args := make([]string, 0, len(command.Args))
+	for _, arg := range command.Args {
+		args = append(args, o.ExpandCommandVariables(arg))
+	}
 	c := &cmdrunner.Command{
 		Dir:  dir,
-		Name: command.Name,
-		Args: command.Args,
+		Name: o.ExpandCommandVariables(command.Name),
+		Args: args,
 		Out:  os.Stdout,
 		Err:  os.Stderr,
 	}
 
 	env := command.Env
-	if len(env) > 0 {
+	if len(env) > 0 || len(extraEnv) > 0 {
 		c.Env = map[string]string{}
 		for _, e := range env {
-			c.Env[e.Name] = e.Value
+			c.Env[e.Name] = o.ExpandCommandVariables(e.Value)
+		}
+		for k, v := range extraEnv {
+			c.Env[k] = v
 		}
 	}
+	return c
+}
 
-	_, err := o.CommandRunner(c)
-	if err != nil {
-		return fmt.Errorf("failed to run command %s: %w", c.CLI(), err)
-	}
-	return nil
+// ExpandCommandVariables replaces the ${VERSION} and ${APP} variables in the text with the version and application being
+// promoted along with ${KEY} variables of the keys of the template data. Unknown variables are left as they are
+func (o *Options) ExpandCommandVariables(text string) string {
+	return commandVariableRegex.ReplaceAllStringFunc(text, func(v string) string {
+		name := v[2 : len(v)-1]
+		switch name {
+		case "VERSION":
+			return o.Version
+		case "APP":
+			return o.Application
+		}
+		if value, ok := o.TemplateData[name]; ok && value != nil {
+			return fmt.Sprint(value)
+		}
+		o.Logger().Warnf("leaving unknown variable %s in command as it is not VERSION, APP or a key of the template data", v)
+		return v
+	})
 }
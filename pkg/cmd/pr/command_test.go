@@ -0,0 +1,71 @@
+package pr_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyCommandInDirs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"go.mod", "services/api/go.mod", "services/worker/go.mod", "charts/myapp/Chart.yaml"} {
+		writeTestFile(t, filepath.Join(dir, name), "")
+	}
+
+	ranDirs := map[string]string{}
+	o := &pr.Options{}
+	o.CommandRunner = func(c *cmdrunner.Command) (string, error) {
+		ranDirs[c.Dir] = c.Env[pr.CommandDirEnvVar]
+		assert.Equal(t, "true", c.Env["GOWORK"], "command env in %s", c.Dir)
+		if c.Env[pr.CommandDirEnvVar] == "services/api" {
+			return "", errors.New("go mod tidy failed")
+		}
+		return "", nil
+	}
+	command := &v1alpha1.Command{
+		Name:  "go",
+		Args:  []string{"mod", "tidy"},
+		Env:   []v1alpha1.EnvVar{{Name: "GOWORK", Value: "true"}},
+		Globs: []string{"**/go.mod"},
+	}
+	err := o.ApplyCommand(dir, command)
+	require.Error(t, err, "should fail as the command fails in a dir")
+	assert.Contains(t, err.Error(), "command go failed in 1 of 3 dirs", "error")
+	assert.Contains(t, err.Error(), "* services/api: ", "error")
+
+	// the command is still run in the dirs after the one which failed
+	assert.Equal(t, map[string]string{
+		dir:                                   ".",
+		filepath.Join(dir, "services/api"):    "services/api",
+		filepath.Join(dir, "services/worker"): "services/worker",
+	}, ranDirs, "dirs the command ran in")
+}
+
+func TestApplyCommandVariables(t *testing.T) {
+	dir := t.TempDir()
+	o := &pr.Options{
+		Version:      "1.2.3",
+		TemplateData: map[string]interface{}{"Environment": "staging"},
+	}
+	o.Application = "myorg/myapp"
+	o.CommandRunner = cmdrunner.QuietCommandRunner
+
+	command := &v1alpha1.Command{
+		Name: "sh",
+		Args: []string{"-c", `echo "${VERSION} ${APP} ${Environment} ${UNKNOWN}" > version.txt`},
+	}
+	err := o.ApplyCommand(dir, command)
+	require.NoError(t, err, "failed to apply command")
+
+	data, err := os.ReadFile(filepath.Join(dir, "version.txt"))
+	require.NoError(t, err, "failed to read version.txt")
+	// unknown variables are passed to the command which expands them itself
+	assert.Equal(t, "1.2.3 myorg/myapp staging \n", string(data), "version.txt")
+}
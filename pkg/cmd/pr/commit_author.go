@@ -0,0 +1,77 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+)
+
+// CommitAuthor returns the name and email of the author of the commits of the rule. Defaults to the
+// --commit-author-name and --commit-author-email options, otherwise the author of the pipeline commit is used if the
+// rule assigns the author to its Pull Requests. Returns empty values if the committer is also the author
+func (o *Options) CommitAuthor(rule *v1alpha1.Rule) (string, string, error) {
+	if o.CommitAuthorName != "" || o.CommitAuthorEmail != "" {
+		return o.CommitAuthorName, o.CommitAuthorEmail, nil
+	}
+	if !rule.AssignAuthorToPullRequests || o.PipelineRepoURL == "" || o.PipelineCommitSha == "" {
+		return "", "", nil
+	}
+	login, err := o.FindCommitAuthor(o.PipelineRepoURL, o.PipelineCommitSha, o.GitKind)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to find commit author: %w", err)
+	}
+	if login == "" {
+		return "", "", nil
+	}
+
+	// the author of the commit is best effort so the committer is used if the user cannot be found
+	scmClient, _, err := o.GetScmClient(o.PipelineRepoURL, o.GitKind)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create ScmClient: %w", err)
+	}
+	user, _, err := scmClient.Users.FindLogin(context.Background(), login)
+	if err != nil {
+		o.Logger().Warnf("failed to find user %s so committing as the committer: %s", login, err.Error())
+		return "", "", nil
+	}
+	if user == nil || user.Email == "" {
+		o.Logger().Warnf("user %s has no public email so committing as the committer", login)
+		return "", "", nil
+	}
+	name := user.Name
+	if name == "" {
+		name = login
+	}
+	return name, user.Email, nil
+}
+
+// ConfigureCommitAuthor configures the git repository in the dir so that its commits are authored by the commit
+// author of the rule while the committer is the --git-user-name and --git-user-email user. The local config of the
+// repository is changed so the global git config is not modified
+func (o *Options) ConfigureCommitAuthor(dir string, rule *v1alpha1.Rule) error {
+	name, email, err := o.CommitAuthor(rule)
+	if err != nil {
+		return err
+	}
+	config := [][]string{
+		{"committer.name", o.GitCommitUsername},
+		{"committer.email", o.GitCommitUserEmail},
+		{"author.name", name},
+		{"author.email", email},
+	}
+	g := o.Git()
+	for _, c := range config {
+		if c[1] == "" {
+			continue
+		}
+		_, err = g.Command(dir, "config", "--local", c[0], c[1])
+		if err != nil {
+			return fmt.Errorf("failed to configure %s in dir %s: %w", c[0], dir, err)
+		}
+	}
+	if name != "" || email != "" {
+		o.Logger().Infof("committing as author %s <%s> in dir %s", info(name), email, dir)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureCommitAuthor(t *testing.T) {
+	testCases := []struct {
+		name     string
+		options  *pr.Options
+		expected string
+	}{
+		{
+			name: "author",
+			options: &pr.Options{
+				GitCommitUsername:  "jenkins-x-bot",
+				GitCommitUserEmail: "bot@example.com",
+				CommitAuthorName:   "Jane Dev",
+				CommitAuthorEmail:  "jane@example.com",
+			},
+			expected: "Jane Dev <jane@example.com> jenkins-x-bot <bot@example.com>",
+		},
+		{
+			name: "committer",
+			options: &pr.Options{
+				GitCommitUsername:  "jenkins-x-bot",
+				GitCommitUserEmail: "bot@example.com",
+			},
+			expected: "jenkins-x-bot <bot@example.com> jenkins-x-bot <bot@example.com>",
+		},
+	}
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		initGitRepository(t, dir)
+		o := tc.options
+		g := o.Git()
+		for _, args := range [][]string{{"config", "--local", "user.name", "jenkins-x-bot"}, {"config", "--local", "user.email", "bot@example.com"}} {
+			_, err := g.Command(dir, args...)
+			require.NoError(t, err, "failed to configure git for %s", tc.name)
+		}
+
+		// the rule does not assign the author so no commit author is looked up
+		err := o.ConfigureCommitAuthor(dir, &v1alpha1.Rule{})
+		require.NoError(t, err, "failed to configure commit author for %s", tc.name)
+
+		err = os.WriteFile(filepath.Join(dir, "README.md"), []byte("hello\n"), files.DefaultFileWritePermissions)
+		require.NoError(t, err, "failed to write file for %s", tc.name)
+		_, err = g.Command(dir, "add", "README.md")
+		require.NoError(t, err, "failed to add file for %s", tc.name)
+		_, err = g.Command(dir, "commit", "--no-gpg-sign", "-m", "chore: test")
+		require.NoError(t, err, "failed to commit for %s", tc.name)
+
+		text, err := g.Command(dir, "log", "-n", "1", "--pretty=%an <%ae> %cn <%ce>")
+		require.NoError(t, err, "failed to get commit for %s", tc.name)
+		assert.Equal(t, tc.expected, strings.TrimSpace(text), "author and committer for %s", tc.name)
+	}
+}
@@ -0,0 +1,61 @@
+package pr
+
+import (
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// DefaultCommitType the conventional commit type and scope used for the generated commit title
+const DefaultCommitType = "chore(deps)"
+
+// ConventionalCommitType returns the conventional commit type and scope for the bump from the previous version to
+// the version: fix(deps) for a patch, feat(deps) for a minor and feat(deps)! for a major release. If either version
+// is not a semantic version or the version is not newer the DefaultCommitType is returned
+func ConventionalCommitType(previous, version string) string {
+	if previous == "" || version == "" {
+		return DefaultCommitType
+	}
+	pv, err := semver.NewVersion(previous)
+	if err != nil {
+		return DefaultCommitType
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return DefaultCommitType
+	}
+	switch {
+	case v.Major() > pv.Major():
+		return "feat(deps)!"
+	case v.Major() == pv.Major() && v.Minor() > pv.Minor():
+		return "feat(deps)"
+	case v.Major() == pv.Major() && v.Minor() == pv.Minor() && v.GreaterThan(pv):
+		return "fix(deps)"
+	default:
+		return DefaultCommitType
+	}
+}
+
+// CommitType returns the conventional commit type to use for the generated commit title
+func (o *Options) CommitType(dir string) string {
+	if !o.ConventionalCommit {
+		return DefaultCommitType
+	}
+	previous := o.PreviousVersion
+	if previous == "" {
+		previous = o.findPreviousVersionTag(dir)
+	}
+	commitType := ConventionalCommitType(previous, o.Version)
+	o.Logger().Debugf("using commit type %s for the upgrade from %s to %s", commitType, previous, o.Version)
+	return commitType
+}
+
+// findPreviousVersionTag returns the latest tag before the current commit of the git repository in the dir
+func (o *Options) findPreviousVersionTag(dir string) string {
+	text, err := o.Git().Command(dir, "describe", "--tags", "--abbrev=0", "HEAD^")
+	if err != nil {
+		o.Logger().Debugf("failed to find the previous version tag in %s: %s", dir, err.Error())
+		return ""
+	}
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,44 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConventionalCommitType(t *testing.T) {
+	testCases := []struct {
+		previous string
+		version  string
+		expected string
+	}{
+		{previous: "1.2.3", version: "1.2.4", expected: "fix(deps)"},
+		{previous: "v1.2.3", version: "1.3.0", expected: "feat(deps)"},
+		{previous: "1.2.3", version: "2.0.0", expected: "feat(deps)!"},
+		{previous: "1.2.3", version: "1.2.3", expected: pr.DefaultCommitType},
+		{previous: "1.2.3", version: "1.1.0", expected: pr.DefaultCommitType},
+		{previous: "", version: "1.2.3", expected: pr.DefaultCommitType},
+		{previous: "main", version: "1.2.3", expected: pr.DefaultCommitType},
+	}
+
+	for _, tc := range testCases {
+		actual := pr.ConventionalCommitType(tc.previous, tc.version)
+		assert.Equal(t, tc.expected, actual, "commit type from %s to %s", tc.previous, tc.version)
+	}
+}
+
+func TestSetCommitDetailsConventionalCommit(t *testing.T) {
+	o := &pr.Options{
+		Version:            "1.3.0",
+		PreviousVersion:    "1.2.3",
+		ConventionalCommit: true,
+	}
+	o.Application = "myorg/myapp"
+	o.CommitMessage = "from: https://github.com/myorg/myapp\n"
+
+	err := o.SetCommitDetails(t.TempDir())
+	require.NoError(t, err, "failed to set commit details")
+	assert.Equal(t, "feat(deps): upgrade myorg/myapp to version 1.3.0", o.CommitTitle)
+}
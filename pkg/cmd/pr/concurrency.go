@@ -0,0 +1,116 @@
+package pr
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+)
+
+// acquireSlot waits for a free slot and returns a function which releases it. There is no limit if slots is nil
+func acquireSlot(slots chan struct{}) func() {
+	if slots == nil {
+		return func() {}
+	}
+	slots <- struct{}{}
+	return func() {
+		<-slots
+	}
+}
+
+// releaseCloneSlot releases the clone slot of the repository if it holds one so that the next repository can be cloned
+func (o *Options) releaseCloneSlot() {
+	if o.releaseClone != nil {
+		o.releaseClone()
+		o.releaseClone = nil
+	}
+}
+
+// withLock runs the function holding the lock shared by the repositories which are processed concurrently
+func (o *Options) withLock(fn func()) {
+	if o.mu != nil {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+	}
+	fn()
+}
+
+// processRuleURLsConcurrently processes the repositories of the rule concurrently. Up to --clone-concurrency
+// repositories are cloned and changed at the same time and up to --pr-concurrency repositories are pushed and have
+// their Pull Request created at the same time so that the git provider API is not overloaded. Every repository is
+// processed even if others fail so that the returned error lists all the repositories which failed
+func (o *Options) processRuleURLsConcurrently(rule *v1alpha1.Rule, baseBranch string, labels []string, automerge bool) error {
+	if o.mu == nil {
+		o.mu = &sync.Mutex{}
+	}
+	if o.PullRequestSHAs == nil {
+		o.PullRequestSHAs = map[string]string{}
+	}
+	if o.defaultBranches == nil {
+		o.defaultBranches = map[string]string{}
+	}
+	if o.outputPullRequests == nil {
+		o.outputPullRequests = &[]OutputPullRequest{}
+	}
+	if o.scmClients == nil {
+		o.scmClients = map[scmClientKey]*cachedScmClient{}
+	}
+	cloneSlots := make(chan struct{}, max(o.CloneConcurrency, o.Concurrency, 1))
+	o.prSlots = make(chan struct{}, max(o.PRConcurrency, o.Concurrency, 1))
+	defer func() {
+		o.prSlots = nil
+	}()
+
+	errs := make([]error, len(rule.URLs))
+	wg := sync.WaitGroup{}
+	for i, ruleURL := range rule.URLs {
+		if ruleURL == "" {
+			o.Logger().Warnf("skipping empty git URL")
+			continue
+		}
+		// each repository is processed with its own copy of the options as they hold the state of the repository
+		ro := *o
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ro.releaseClone = acquireSlot(cloneSlots)
+			defer ro.releaseCloneSlot()
+
+			errs[i] = ro.processRuleURL(rule, ruleURL, baseBranch, labels, automerge)
+		}()
+	}
+	wg.Wait()
+
+	var failures []RepositoryFailure
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, RepositoryFailure{GitURL: rule.URLs[i], Rule: o.ruleIndex, Error: err})
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	for _, f := range failures {
+		if !o.continueAfterFailure(f.Error) {
+			return RepositoryFailuresError(failures)
+		}
+	}
+	for _, f := range failures {
+		o.Logger().Warnf("%s, continuing with the remaining repositories", f.Error.Error())
+	}
+	o.failures = append(o.failures, failures...)
+	return nil
+}
+
+// RepositoryFailuresError returns an error listing the repositories which failed along with their errors
+func RepositoryFailuresError(failures []RepositoryFailure) error {
+	if len(failures) == 1 {
+		return failures[0].Error
+	}
+	lines := make([]string, 0, len(failures))
+	for _, f := range failures {
+		lines = append(lines, fmt.Sprintf("* %s: %s", f.GitURL, f.Error.Error()))
+	}
+	return fmt.Errorf("failed to create Pull Requests on %d repositories:\n%s", len(failures), strings.Join(lines, "\n"))
+}
@@ -0,0 +1,44 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessAndCreatePullRequestsConcurrently(t *testing.T) {
+	// the URLs are invalid so each repository fails before it is cloned
+	rule := &v1alpha1.Rule{
+		URLs: []string{"https://github.com", "", "https://gitlab.com", "https://bitbucket.org"},
+	}
+
+	o := &pr.Options{
+		CloneConcurrency: 3,
+		PRConcurrency:    2,
+	}
+	err := o.ProcessAndCreatePullRequests(rule, "", nil, false)
+	require.Error(t, err, "should fail for invalid git URLs")
+	assert.Contains(t, err.Error(), "failed to create ScmClient for repository", "should return the error of the failed repository")
+
+	for _, u := range []string{"https://github.com", "https://gitlab.com", "https://bitbucket.org"} {
+		assert.Contains(t, err.Error(), "* "+u+": ", "should list every repository which failed")
+	}
+
+	o = &pr.Options{
+		Concurrency: 3,
+	}
+	err = o.ProcessAndCreatePullRequests(rule, "", nil, false)
+	require.Error(t, err, "should fail for invalid git URLs with --concurrency")
+	assert.Contains(t, err.Error(), "failed to create Pull Requests on 3 repositories", "should aggregate the errors")
+
+	o = &pr.Options{
+		CloneConcurrency: 3,
+		PRConcurrency:    2,
+		ContinueOnError:  true,
+	}
+	err = o.ProcessAndCreatePullRequests(rule, "", nil, false)
+	require.NoError(t, err, "should continue on errors")
+}
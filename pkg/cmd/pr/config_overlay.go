@@ -0,0 +1,50 @@
+package pr
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/kustomize/kyaml/yaml/merge2"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// ApplyConfigOverlay merges the --config-overlay over the loaded configuration.
+//
+// The overlay is either the name of a YAML file or inline YAML. Maps are merged, fields set to null are removed and
+// lists are replaced unless their elements all have a name field in which case the elements are merged by name
+func (o *Options) ApplyConfigOverlay() error {
+	if o.ConfigOverlay == "" {
+		return nil
+	}
+	overlay := o.ConfigOverlay
+	exists, err := files.FileExists(overlay)
+	if err != nil {
+		return fmt.Errorf("failed to check for file %s: %w", overlay, err)
+	}
+	if exists {
+		data, err := os.ReadFile(overlay)
+		if err != nil {
+			return fmt.Errorf("failed to read config overlay file %s: %w", overlay, err)
+		}
+		overlay = string(data)
+	}
+
+	data, err := k8syaml.Marshal(&o.UpdateConfig)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	merged, err := merge2.MergeStrings(overlay, string(data), false, yaml.MergeOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to merge config overlay: %w", err)
+	}
+	config := v1alpha1.UpdateConfig{}
+	err = k8syaml.UnmarshalStrict([]byte(merged), &config)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal config with overlay: %w", err)
+	}
+	o.UpdateConfig = config
+	return nil
+}
@@ -0,0 +1,96 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyConfigOverlay(t *testing.T) {
+	overlayFile := filepath.Join(t.TempDir(), "overlay.yaml")
+	err := os.WriteFile(overlayFile, []byte("spec:\n  pullRequestLabels:\n  - from-file\n"), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to write %s", overlayFile)
+
+	base := v1alpha1.UpdateConfig{
+		Spec: v1alpha1.UpdateConfigSpec{
+			PullRequestLabels: []string{"base"},
+			Rules: []v1alpha1.Rule{
+				{
+					URLs: []string{"https://github.com/myorg/myrepo"},
+					Changes: []v1alpha1.Change{
+						{Command: &v1alpha1.Command{Name: "echo"}},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name    string
+		overlay string
+		verify  func(t *testing.T, config *v1alpha1.UpdateConfig)
+		err     bool
+	}{
+		{
+			name:    "lists-replace",
+			overlay: "spec:\n  pullRequestLabels:\n  - overlay\n",
+			verify: func(t *testing.T, config *v1alpha1.UpdateConfig) {
+				assert.Equal(t, []string{"overlay"}, config.Spec.PullRequestLabels)
+				assert.Equal(t, base.Spec.Rules, config.Spec.Rules, "rules should be kept")
+			},
+		},
+		{
+			name:    "file",
+			overlay: overlayFile,
+			verify: func(t *testing.T, config *v1alpha1.UpdateConfig) {
+				assert.Equal(t, []string{"from-file"}, config.Spec.PullRequestLabels)
+			},
+		},
+		{
+			name:    "rules-replace",
+			overlay: "spec:\n  rules:\n  - urls:\n    - https://github.com/myorg/other\n    changes: []\n    reusePullRequest: true\n",
+			verify: func(t *testing.T, config *v1alpha1.UpdateConfig) {
+				assert.Equal(t, []string{"base"}, config.Spec.PullRequestLabels, "labels should be kept")
+				require.Len(t, config.Spec.Rules, 1)
+				assert.Equal(t, []string{"https://github.com/myorg/other"}, config.Spec.Rules[0].URLs)
+				assert.True(t, config.Spec.Rules[0].ReusePullRequest, "reusePullRequest")
+			},
+		},
+		{
+			name:    "null-removes",
+			overlay: "spec:\n  pullRequestLabels: null\n",
+			verify: func(t *testing.T, config *v1alpha1.UpdateConfig) {
+				assert.Empty(t, config.Spec.PullRequestLabels)
+			},
+		},
+		{
+			name:    "unknown-field",
+			overlay: "spec:\n  cheese: true\n",
+			err:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		o := &pr.Options{
+			ConfigOverlay: tc.overlay,
+			UpdateConfig:  base,
+		}
+		// lets avoid sharing the base slices between test cases
+		o.UpdateConfig.Spec.PullRequestLabels = append([]string{}, base.Spec.PullRequestLabels...)
+		o.UpdateConfig.Spec.Rules = append([]v1alpha1.Rule{}, base.Spec.Rules...)
+
+		err := o.ApplyConfigOverlay()
+		if tc.err {
+			require.Error(t, err, "should fail for test %s", tc.name)
+			continue
+		}
+		require.NoError(t, err, "failed to apply overlay for test %s", tc.name)
+		tc.verify(t, &o.UpdateConfig)
+	}
+}
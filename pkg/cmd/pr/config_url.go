@@ -0,0 +1,73 @@
+package pr
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/httphelpers"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultConfigURLAuthEnv the environment variable of the Authorization header used to download the --config-url
+const DefaultConfigURLAuthEnv = "UPDATEBOT_CONFIG_AUTHORIZATION"
+
+// DownloadConfig downloads the --config-url to a temporary file which is used as the config file for the rest of the
+// run. The value of the environment variable named by --config-url-auth-env, if set, is used as the Authorization
+// header. The downloaded config must parse as an updatebot config
+func (o *Options) DownloadConfig() error {
+	req, err := http.NewRequest(http.MethodGet, o.ConfigURL, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request for config URL %s: %w", o.ConfigURL, err)
+	}
+	if o.ConfigURLAuthEnv != "" {
+		if auth := os.Getenv(o.ConfigURLAuthEnv); auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+	}
+	resp, err := httphelpers.GetClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download config URL %s: %w", o.ConfigURL, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download config URL %s: status %s", o.ConfigURL, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read config URL %s: %w", o.ConfigURL, err)
+	}
+	config := v1alpha1.UpdateConfig{}
+	err = yaml.Unmarshal(data, &config)
+	if err != nil {
+		return fmt.Errorf("failed to parse the config downloaded from %s: %w", o.ConfigURL, err)
+	}
+
+	f, err := os.CreateTemp("", "updatebot-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for config URL %s: %w", o.ConfigURL, err)
+	}
+	defer f.Close() //nolint:errcheck
+	_, err = f.Write(data)
+	if err != nil {
+		return fmt.Errorf("failed to save config URL %s to %s: %w", o.ConfigURL, f.Name(), err)
+	}
+	o.downloadedConfig = f.Name()
+	o.ConfigFile = f.Name()
+	o.Logger().Infof("downloaded config %s", info(o.ConfigURL))
+	return nil
+}
+
+// RemoveDownloadedConfig removes the temporary file of the config downloaded from the --config-url
+func (o *Options) RemoveDownloadedConfig() {
+	if o.downloadedConfig == "" {
+		return
+	}
+	err := os.Remove(o.downloadedConfig)
+	if err != nil && !os.IsNotExist(err) {
+		o.Logger().Warnf("failed to remove downloaded config %s: %s", o.downloadedConfig, err.Error())
+	}
+	o.downloadedConfig = ""
+}
@@ -0,0 +1,67 @@
+package pr_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigFromURL(t *testing.T) {
+	config := `apiVersion: updatebot.jenkins-x.io/v1alpha1
+kind: UpdateConfig
+spec:
+  rules:
+  - urls:
+    - https://github.com/myorg/remote
+`
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/updatebot.yaml":
+			_, _ = w.Write([]byte(config))
+		case "/invalid.yaml":
+			_, _ = w.Write([]byte("spec: [not a config"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv(pr.DefaultConfigURLAuthEnv, "Bearer mytoken")
+
+	dir := t.TempDir()
+	o := &pr.Options{Dir: dir, ConfigURL: server.URL + "/updatebot.yaml", ConfigURLAuthEnv: pr.DefaultConfigURLAuthEnv}
+	err := o.LoadConfig()
+	require.NoError(t, err, "failed to load config from URL")
+	require.Len(t, o.UpdateConfig.Spec.Rules, 1, "rules")
+	assert.Equal(t, []string{"https://github.com/myorg/remote"}, o.UpdateConfig.Spec.Rules[0].URLs, "rule URLs")
+	assert.Equal(t, []string{"Bearer mytoken"}, authorizations, "authorization headers")
+	assert.Equal(t, filepath.Join(dir, "body.gotmpl"), o.ResolveConfigPath("body.gotmpl"), "paths are resolved against the dir")
+
+	downloaded := o.ConfigFile
+	assert.FileExists(t, downloaded, "downloaded config")
+	o.RemoveDownloadedConfig()
+	assert.NoFileExists(t, downloaded, "downloaded config should be removed")
+
+	for _, path := range []string{"/missing.yaml", "/invalid.yaml"} {
+		o = &pr.Options{Dir: dir, ConfigURL: server.URL + path}
+		err = o.LoadConfig()
+		require.Error(t, err, "should fail to load config from %s", path)
+		t.Logf("got expected error: %s", err.Error())
+	}
+
+	// a local config file takes precedence over the URL
+	configFile := filepath.Join(dir, "updatebot.yaml")
+	writeTestFile(t, configFile, "apiVersion: updatebot.jenkins-x.io/v1alpha1\nkind: UpdateConfig\n")
+	authorizations = nil
+	o = &pr.Options{Dir: dir, ConfigFile: configFile, ConfigURL: server.URL + "/updatebot.yaml"}
+	err = o.LoadConfig()
+	require.NoError(t, err, "failed to load local config")
+	assert.Empty(t, o.UpdateConfig.Spec.Rules, "rules of the local config")
+	assert.Empty(t, authorizations, "should not download the config URL")
+}
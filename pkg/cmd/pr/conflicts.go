@@ -0,0 +1,117 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/scmhelpers"
+)
+
+// LabelNeedsRebase the label added to reused Pull Requests which conflict with their base branch
+const LabelNeedsRebase = "needs-rebase"
+
+// CheckPullRequestConflicts checks if the reused Pull Request on the repository conflicts with its base branch.
+// If no Pull Request is given the existing Pull Request is looked up. Conflicting Pull Requests are rebased if
+// --auto-rebase is enabled, otherwise or if the rebase fails they are labelled as needing a rebase
+func (o *Options) CheckPullRequestConflicts(gitURL string, pr *scm.PullRequest) error {
+	scmClient, repoFullName, err := o.GetScmClient(gitURL, o.GitKind)
+	if err != nil {
+		return fmt.Errorf("failed to create ScmClient: %w", err)
+	}
+	if scmClient == nil {
+		return nil
+	}
+	if pr == nil {
+		pr, err = o.FindExistingPullRequest(scmClient, repoFullName)
+		if err != nil {
+			return fmt.Errorf("failed to find existing Pull Request: %w", err)
+		}
+		if pr == nil {
+			return nil
+		}
+	}
+
+	ctx := context.Background()
+	latest, _, err := scmClient.PullRequests.Find(ctx, repoFullName, pr.Number)
+	if err != nil {
+		return fmt.Errorf("failed to find Pull Request %d: %w", pr.Number, err)
+	}
+	pr = latest
+	hasLabel := scmhelpers.ContainsLabel(pr.Labels, LabelNeedsRebase)
+	if pr.MergeableState != scm.MergeableStateConflicting {
+		if hasLabel && pr.MergeableState == scm.MergeableStateMergeable {
+			_, err = scmClient.PullRequests.DeleteLabel(ctx, repoFullName, pr.Number, LabelNeedsRebase)
+			if err != nil {
+				return fmt.Errorf("failed to remove label %s from Pull Request %d: %w", LabelNeedsRebase, pr.Number, err)
+			}
+		}
+		return nil
+	}
+
+	if o.AutoRebase {
+		err = o.RebasePullRequest(gitURL, pr)
+		if err == nil {
+			o.Logger().Infof("rebased conflicting Pull Request %s", info(pr.Link))
+			return nil
+		}
+		o.Logger().Warnf("failed to rebase conflicting Pull Request %s: %s", pr.Link, err.Error())
+	}
+
+	o.Logger().Warnf("Pull Request %s conflicts with its base branch %s and needs a rebase", pr.Link, pr.Base.Ref)
+	if hasLabel {
+		return nil
+	}
+	_, err = scmClient.PullRequests.AddLabel(ctx, repoFullName, pr.Number, LabelNeedsRebase)
+	if err != nil {
+		return fmt.Errorf("failed to add label %s to Pull Request %d: %w", LabelNeedsRebase, pr.Number, err)
+	}
+	return nil
+}
+
+// RebasePullRequest rebases the source branch of the Pull Request on its base branch and force pushes it
+func (o *Options) RebasePullRequest(gitURL string, pr *scm.PullRequest) error {
+	if o.Fork {
+		return fmt.Errorf("cannot rebase Pull Requests created from a fork")
+	}
+	if pr.Source == "" || pr.Base.Ref == "" {
+		return fmt.Errorf("pull request %s has no source or base branch", pr.Link)
+	}
+	cloneGitURL := gitURL
+	var err error
+	if o.ScmClientFactory.GitToken != "" && o.ScmClientFactory.GitUsername != "" {
+		cloneGitURL, err = o.ScmClientFactory.CreateAuthenticatedURL(gitURL)
+		if err != nil {
+			return fmt.Errorf("failed to create authenticated git URL: %w", err)
+		}
+	}
+
+	g := o.Git()
+	dir, err := gitclient.CloneToDir(g, cloneGitURL, "")
+	if err != nil {
+		return fmt.Errorf("failed to clone git URL %s: %w", gitURL, err)
+	}
+	defer os.RemoveAll(dir) //nolint:errcheck
+
+	err = o.ConfigureCommitSigning(dir)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.Command(dir, "checkout", "-B", pr.Source, "origin/"+pr.Source)
+	if err != nil {
+		return fmt.Errorf("failed to checkout branch %s: %w", pr.Source, err)
+	}
+	_, err = g.Command(dir, "rebase", "origin/"+pr.Base.Ref)
+	if err != nil {
+		_, _ = g.Command(dir, "rebase", "--abort")
+		return fmt.Errorf("failed to rebase branch %s on %s: %w", pr.Source, pr.Base.Ref, err)
+	}
+	err = gitclient.ForcePushBranch(g, dir, "HEAD", pr.Source)
+	if err != nil {
+		return fmt.Errorf("failed to push branch %s: %w", pr.Source, err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/scmhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPullRequestConflicts(t *testing.T) {
+	gitURL := "https://github.com/myorg/myrepo"
+	testCases := []struct {
+		name          string
+		state         scm.MergeableState
+		labels        []string
+		autoRebase    bool
+		expectLabel   bool
+		expectRemoved bool
+	}{
+		{
+			name:        "conflicting",
+			state:       scm.MergeableStateConflicting,
+			expectLabel: true,
+		},
+		{
+			name:  "mergeable",
+			state: scm.MergeableStateMergeable,
+		},
+		{
+			name:          "mergeable-after-rebase",
+			state:         scm.MergeableStateMergeable,
+			labels:        []string{pr.LabelNeedsRebase},
+			expectLabel:   true,
+			expectRemoved: true,
+		},
+		{
+			// rebasing is not possible with forks so the label is added
+			name:        "auto-rebase-fork",
+			state:       scm.MergeableStateConflicting,
+			autoRebase:  true,
+			expectLabel: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		scmClient, fakeData := fake.NewDefault()
+		existing := &scm.PullRequest{
+			Number:         1,
+			Link:           "https://github.com/myorg/myrepo/pull/1",
+			Source:         "updatebot-branch",
+			MergeableState: tc.state,
+			Base: scm.PullRequestBranch{
+				Ref:  "main",
+				Repo: scm.Repository{FullName: "myorg/myrepo"},
+			},
+		}
+		for _, l := range tc.labels {
+			existing.Labels = append(existing.Labels, &scm.Label{Name: l})
+		}
+		fakeData.PullRequests[1] = existing
+
+		_, o := pr.NewCmdPullRequest()
+		o.ScmClient = scmClient
+		o.ScmClientFactory.ScmClient = scmClient
+		o.ScmClientFactory.GitServerURL = "https://github.com"
+		o.GitKind = "fake"
+		o.AutoRebase = tc.autoRebase
+		o.Fork = tc.autoRebase
+
+		err := o.CheckPullRequestConflicts(gitURL, existing)
+		require.NoError(t, err, "failed to check conflicts for test %s", tc.name)
+
+		assert.Equal(t, tc.expectLabel, scmhelpers.ContainsLabel(existing.Labels, pr.LabelNeedsRebase), "needs-rebase label for test %s", tc.name)
+		if tc.expectRemoved {
+			assert.Contains(t, fakeData.PullRequestLabelsRemoved, "myorg/myrepo#1:"+pr.LabelNeedsRebase, "should remove the label for test %s", tc.name)
+		} else {
+			assert.Empty(t, fakeData.PullRequestLabelsRemoved, "should not remove labels for test %s", tc.name)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package pr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/yargevad/filepathx"
+)
+
+var (
+	// DefaultDockerfileGlobs the files of a dockerfile change if none are specified
+	DefaultDockerfileGlobs = []string{"Dockerfile"}
+
+	dockerfileFromRegex  = regexp.MustCompile(`(?i)^(\s*FROM\s+(?:--\S+\s+)*)(\S+)(.*)$`)
+	dockerfileStageRegex = regexp.MustCompile(`(?i)^\s+AS\s+(\S+)`)
+)
+
+// ApplyDockerfile replaces the tag of the image in the FROM lines of the Dockerfiles
+func (o *Options) ApplyDockerfile(dir, gitURL string, change v1alpha1.Change, dockerfile *v1alpha1.DockerfileChange) error {
+	if dockerfile.Image == "" {
+		return fmt.Errorf("no image for dockerfile change %#v", change)
+	}
+	tag, err := o.changeValue(gitURL, change, dockerfile.Value)
+	if err != nil {
+		return err
+	}
+	digest := ""
+	if dockerfile.Digest != nil {
+		digest, err = o.ChangeDigest(dockerfile.Digest, dockerfile.Image, tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	matched := 0
+	for _, g := range DockerfileGlobs(dockerfile) {
+		matches, err := filepathx.Glob(filepath.Join(dir, g))
+		if err != nil {
+			return fmt.Errorf("failed to evaluate glob %s: %w", g, err)
+		}
+		if len(matches) == 0 {
+			_, err = o.handleMissingFile(change, g, gitURL, false)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		for _, f := range matches {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return fmt.Errorf("failed to load file %s: %w", f, err)
+			}
+			text, count := o.ReplaceDockerfileImage(change, string(data), dockerfile.Image, tag, digest, f)
+			matched += count
+			if text == string(data) {
+				continue
+			}
+			err = os.WriteFile(f, []byte(text), files.DefaultFileWritePermissions)
+			if err != nil {
+				return fmt.Errorf("failed to save file %s: %w", f, err)
+			}
+			o.Logger().Infof("modified file %s with image %s:%s", info(f), dockerfile.Image, tag)
+		}
+	}
+	return checkRequireMatch(change, matched, "dockerfile", dockerfile.Image, DockerfileGlobs(dockerfile), gitURL)
+}
+
+// DockerfileGlobs returns the files of the dockerfile change
+func DockerfileGlobs(dockerfile *v1alpha1.DockerfileChange) []string {
+	if len(dockerfile.Globs) == 0 {
+		return DefaultDockerfileGlobs
+	}
+	return dockerfile.Globs
+}
+
+// ReplaceDockerfileTag replaces the tag of the image in the FROM lines of the Dockerfile text returning the new text
+// and the number of FROM lines which use the image. FROM lines which refer to an earlier build stage are skipped as
+// are images pinned by digest or without a tag
+func (o *Options) ReplaceDockerfileTag(change v1alpha1.Change, text, image, tag, path string) (string, int) {
+	return o.ReplaceDockerfileImage(change, text, image, tag, "", path)
+}
+
+// ReplaceDockerfileImage replaces the tag of the image in the FROM lines of the Dockerfile text like
+// ReplaceDockerfileTag. If the digest is specified images pinned by digest are updated to the digest, along with the
+// tag if they have one, rather than being skipped
+func (o *Options) ReplaceDockerfileImage(change v1alpha1.Change, text, image, tag, digest, path string) (string, int) {
+	stages := map[string]bool{}
+	matched := 0
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		m := dockerfileFromRegex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		prefix, ref, rest := m[1], m[2], m[3]
+		isStage := stages[strings.ToLower(ref)]
+		if sm := dockerfileStageRegex.FindStringSubmatch(rest); sm != nil {
+			stages[strings.ToLower(sm[1])] = true
+		}
+		if isStage {
+			continue
+		}
+		name, currentTag, currentDigest := splitImageReference(ref)
+		if name != image {
+			continue
+		}
+		matched++
+		if currentDigest != "" {
+			if digest == "" {
+				o.Logger().Warnf("skipping the image %s in %s as it is pinned by digest, use the digest option to update it", ref, path)
+				continue
+			}
+			if currentTag != "" && !o.matchesExpectedCurrent(change, currentTag, image+" in "+path) {
+				continue
+			}
+			lines[i] = prefix + ReplaceReferenceDigest(ref, tag, digest) + rest
+			continue
+		}
+		if currentTag == "" {
+			o.Logger().Warnf("skipping the image %s in %s as it has no tag", ref, path)
+			continue
+		}
+		if !o.matchesExpectedCurrent(change, currentTag, image+" in "+path) {
+			continue
+		}
+		lines[i] = prefix + name + ":" + tag + rest
+	}
+	return strings.Join(lines, "\n"), matched
+}
+
+// splitImageReference splits an image reference such as myreg:5000/base:1.2.3@sha256:abc into the name, tag and digest
+func splitImageReference(ref string) (string, string, string) {
+	name, digest, _ := strings.Cut(ref, "@")
+	tag := ""
+	if i := strings.LastIndex(name, ":"); i >= 0 && !strings.Contains(name[i+1:], "/") {
+		tag = name[i+1:]
+		name = name[:i]
+	}
+	return name, tag, digest
+}
@@ -0,0 +1,72 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyDockerfile(t *testing.T) {
+	digest := "sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	source := `FROM myreg/base:1.2.3 AS builder
+RUN make build
+
+FROM --platform=linux/amd64 myreg/base:1.2.3-slim as runtime
+COPY --from=builder /app /app
+
+from myreg/base@` + digest + `
+FROM myreg/base-extra:1.2.3
+FROM builder
+FROM localhost:5000/myreg/base:1.0.0
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Dockerfile")
+	err := os.WriteFile(path, []byte(source), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to write %s", path)
+
+	o := &pr.Options{Version: "2.0.0", TemplateData: map[string]interface{}{"Version": "2.0.0"}}
+	change := v1alpha1.Change{RequireMatch: true, Dockerfile: &v1alpha1.DockerfileChange{Image: "myreg/base"}}
+	err = o.ApplyChanges(dir, "https://github.com/myorg/myrepo", change)
+	require.NoError(t, err, "failed to apply change")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err, "failed to read %s", path)
+	expected := `FROM myreg/base:2.0.0 AS builder
+RUN make build
+
+FROM --platform=linux/amd64 myreg/base:2.0.0 as runtime
+COPY --from=builder /app /app
+
+from myreg/base@` + digest + `
+FROM myreg/base-extra:1.2.3
+FROM builder
+FROM localhost:5000/myreg/base:1.0.0
+`
+	assert.Equal(t, expected, string(data), "modified Dockerfile")
+
+	change.Dockerfile = &v1alpha1.DockerfileChange{Image: "localhost:5000/myreg/base", Value: "{{ .Version }}-alpine"}
+	err = o.ApplyChanges(dir, "https://github.com/myorg/myrepo", change)
+	require.NoError(t, err, "failed to apply change with a registry port")
+	data, err = os.ReadFile(path)
+	require.NoError(t, err, "failed to read %s", path)
+	assert.Contains(t, string(data), "\nFROM localhost:5000/myreg/base:2.0.0-alpine\n")
+
+	change.Dockerfile = &v1alpha1.DockerfileChange{Image: "myreg/other"}
+	err = o.ApplyChanges(dir, "https://github.com/myorg/myrepo", change)
+	assert.Error(t, err, "should fail when the image is not used with requireMatch")
+}
+
+func TestReplaceDockerfileStages(t *testing.T) {
+	// a build stage with the same name as the image is not the image
+	source := "FROM golang:1.22 AS myreg/base\nFROM myreg/base\nFROM myreg/base:1.0.0\n"
+	o := &pr.Options{}
+	text, matched := o.ReplaceDockerfileTag(v1alpha1.Change{}, source, "myreg/base", "2.0.0", "Dockerfile")
+	assert.Equal(t, "FROM golang:1.22 AS myreg/base\nFROM myreg/base\nFROM myreg/base:2.0.0\n", text)
+	assert.Equal(t, 1, matched)
+}
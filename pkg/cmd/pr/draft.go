@@ -0,0 +1,234 @@
+package pr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/shurcooL/githubv4"
+)
+
+const (
+	// GitKindGitHub the git kind of GitHub servers
+	GitKindGitHub = "github"
+
+	// gitLabDraftPrefix the title prefix which makes GitLab create a draft merge request
+	gitLabDraftPrefix = "Draft: "
+)
+
+// ConvertPullRequestToDraftInput the input of the GitHub convertPullRequestToDraft mutation which is not in the
+// version of githubv4 used
+type ConvertPullRequestToDraftInput struct {
+	PullRequestID githubv4.ID `json:"pullRequestId"`
+}
+
+// IsDraft returns true if the Pull Requests of the rule are created as drafts by the rule or --draft
+func (o *Options) IsDraft(rule *v1alpha1.Rule) bool {
+	return o.Draft || rule.Draft
+}
+
+// IsGitHub returns true if the git kind is GitHub
+func (o *Options) IsGitHub() bool {
+	return o.GitKind == GitKindGitHub || o.ScmClientFactory.GitKind == GitKindGitHub
+}
+
+// DraftTitle returns the title of a draft Pull Request. GitLab creates a draft merge request when the title starts
+// with Draft: so the prefix is added on GitLab
+func (o *Options) DraftTitle(rule *v1alpha1.Rule, title string) string {
+	if !o.IsDraft(rule) || !o.IsGitLab() || strings.HasPrefix(title, gitLabDraftPrefix) {
+		return title
+	}
+	return gitLabDraftPrefix + title
+}
+
+// MarkPullRequestDraft marks the Pull Request as a draft if the rule creates draft Pull Requests. GitHub Pull Requests
+// are created as drafts so only reused Pull Requests which are ready for review are converted and GitLab merge requests
+// are drafts due to the title. The Pull Request has already been created so a warning is logged if it cannot be
+// converted, as for git providers which do not support drafts, rather than failing the repository
+func (o *Options) MarkPullRequestDraft(rule *v1alpha1.Rule, gitURL string, pr *scm.PullRequest) {
+	if !o.IsDraft(rule) || pr == nil || pr.Draft {
+		return
+	}
+	switch {
+	case o.IsGitLab():
+		pr.Draft = true
+	case o.IsGitHub():
+		err := o.convertGitHubPullRequestToDraft(gitURL, pr)
+		if err != nil {
+			o.Logger().Warnf("leaving Pull Request %s ready for review as failed to convert it to a draft: %s", pr.Link, err.Error())
+			return
+		}
+		pr.Draft = true
+		o.Logger().Infof("converted Pull Request %s to a draft", info(pr.Link))
+	default:
+		o.Logger().Warnf("created Pull Request %s ready for review as the git provider of %s does not support draft Pull Requests", pr.Link, gitURL)
+	}
+}
+
+// draftPullRequestService creates the Pull Requests of the repositories whose rule creates drafts as GitHub draft Pull
+// Requests. go-scm does not support drafts so the REST API of the git server is used. The ScmClient is shared by the
+// repositories of a git server so whether to create a draft is kept for each repository
+type draftPullRequestService struct {
+	scm.PullRequestService
+	client *scm.Client
+	mu     sync.Mutex
+	drafts map[string]bool
+}
+
+// gitHubPullRequest the fields of a GitHub Pull Request created as a draft
+type gitHubPullRequest struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	State   string `json:"state"`
+	Draft   bool   `json:"draft"`
+	HTMLURL string `json:"html_url"`
+	DiffURL string `json:"diff_url"`
+	Head    struct {
+		Ref string `json:"ref"`
+		Sha string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+		Sha string `json:"sha"`
+	} `json:"base"`
+	User struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+// Create creates a draft Pull Request if the rule of the repository creates drafts
+func (s *draftPullRequestService) Create(ctx context.Context, repo string, input *scm.PullRequestInput) (*scm.PullRequest, *scm.Response, error) {
+	s.mu.Lock()
+	draft := s.drafts[repo]
+	s.mu.Unlock()
+	if !draft {
+		return s.PullRequestService.Create(ctx, repo, input)
+	}
+
+	in := map[string]interface{}{
+		"title": input.Title,
+		"head":  input.Head,
+		"base":  input.Base,
+		"body":  input.Body,
+		"draft": true,
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal Pull Request: %w", err)
+	}
+	req := &scm.Request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("repos/%s/pulls", repo),
+		Header: http.Header{"Accept": []string{"application/vnd.github+json"}, "Content-Type": []string{"application/json"}},
+		Body:   bytes.NewReader(data),
+	}
+	res, err := s.client.Do(ctx, req)
+	if err != nil {
+		return nil, res, err
+	}
+	defer res.Body.Close()
+	if res.Status != http.StatusCreated {
+		return nil, res, fmt.Errorf("failed to create draft Pull Request on repository %s: unexpected status %d", repo, res.Status)
+	}
+	out := &gitHubPullRequest{}
+	err = json.NewDecoder(res.Body).Decode(out)
+	if err != nil {
+		return nil, res, fmt.Errorf("failed to decode draft Pull Request: %w", err)
+	}
+	return &scm.PullRequest{
+		Number:   out.Number,
+		Title:    out.Title,
+		Body:     out.Body,
+		Sha:      out.Head.Sha,
+		Ref:      fmt.Sprintf("refs/pull/%d/head", out.Number),
+		State:    out.State,
+		Source:   out.Head.Ref,
+		Target:   out.Base.Ref,
+		Base:     scm.PullRequestBranch{Ref: out.Base.Ref, Sha: out.Base.Sha},
+		Head:     scm.PullRequestBranch{Ref: out.Head.Ref, Sha: out.Head.Sha},
+		Closed:   out.State != "open",
+		Draft:    out.Draft,
+		Author:   scm.User{Login: out.User.Login},
+		Link:     out.HTMLURL,
+		DiffLink: out.DiffURL,
+	}, res, nil
+}
+
+// CreateDraftPullRequests makes the ScmClient create the Pull Request of the repository as a draft on GitHub if the
+// rule creates draft Pull Requests. This must be called before ReuseBranchPullRequests so that an existing Pull
+// Request of the branch is still reused if creating the draft fails
+func (o *Options) CreateDraftPullRequests(scmClient *scm.Client, repo string, rule *v1alpha1.Rule) {
+	if scmClient == nil || !o.IsGitHub() {
+		return
+	}
+	var s *draftPullRequestService
+	o.withLock(func() {
+		s = findDraftPullRequestService(scmClient.PullRequests)
+		if s == nil {
+			s = &draftPullRequestService{PullRequestService: scmClient.PullRequests, client: scmClient, drafts: map[string]bool{}}
+			scmClient.PullRequests = s
+		}
+	})
+	s.mu.Lock()
+	s.drafts[repo] = o.IsDraft(rule)
+	s.mu.Unlock()
+}
+
+// findDraftPullRequestService returns the draftPullRequestService of the ScmClient which may be wrapped by the
+// branchPullRequestService of another repository
+func findDraftPullRequestService(pullRequests scm.PullRequestService) *draftPullRequestService {
+	if b, ok := pullRequests.(*branchPullRequestService); ok {
+		pullRequests = b.PullRequestService
+	}
+	s, _ := pullRequests.(*draftPullRequestService)
+	return s
+}
+
+// convertGitHubPullRequestToDraft finds the node ID of the Pull Request and converts it to a draft
+func (o *Options) convertGitHubPullRequestToDraft(gitURL string, pr *scm.PullRequest) error {
+	_, repoFullName, err := o.GetScmClient(gitURL, o.GitKind)
+	if err != nil {
+		return fmt.Errorf("failed to create ScmClient: %w", err)
+	}
+	owner, name := scm.Split(repoFullName)
+
+	ctx := context.Background()
+	client := o.GitHubGraphQLClient(ctx)
+	var q struct {
+		Repository struct {
+			PullRequest struct {
+				ID      githubv4.ID
+				IsDraft bool
+			} `graphql:"pullRequest(number: $number)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	v := map[string]interface{}{
+		"owner":  githubv4.String(owner),
+		"name":   githubv4.String(name),
+		"number": githubv4.Int(pr.Number),
+	}
+	err = client.Query(ctx, &q, v)
+	if err != nil {
+		return fmt.Errorf("failed to find Pull Request %d of repository %s: %w", pr.Number, repoFullName, err)
+	}
+	if q.Repository.PullRequest.IsDraft {
+		return nil
+	}
+
+	var m struct {
+		ConvertPullRequestToDraft struct {
+			PullRequest struct {
+				IsDraft bool
+			}
+		} `graphql:"convertPullRequestToDraft(input: $input)"`
+	}
+	input := ConvertPullRequestToDraftInput{PullRequestID: q.Repository.PullRequest.ID}
+	return client.Mutate(ctx, &m, input, nil)
+}
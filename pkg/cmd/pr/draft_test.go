@@ -0,0 +1,151 @@
+package pr_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkPullRequestDraft(t *testing.T) {
+	gitURL := "https://github.com/myorg/myrepo"
+	rule := &v1alpha1.Rule{Draft: true}
+
+	var mutations []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}{}
+		err := json.NewDecoder(r.Body).Decode(&req)
+		require.NoError(t, err, "failed to decode GraphQL request")
+		if strings.Contains(req.Query, "convertPullRequestToDraft") {
+			mutations = append(mutations, req.Variables)
+			_, _ = w.Write([]byte(`{"data":{"convertPullRequestToDraft":{"pullRequest":{"isDraft":true}}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"repository":{"pullRequest":{"id":"PR_kwDO123","isDraft":false}}}}`))
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name          string
+		kind          string
+		expectDraft   bool
+		expectMutated bool
+	}{
+		{name: "github", kind: pr.GitKindGitHub, expectDraft: true, expectMutated: true},
+		{name: "gitlab", kind: pr.GitKindGitLab, expectDraft: true},
+		{name: "unsupported", kind: "bitbucketserver"},
+	}
+	for _, tc := range testCases {
+		mutations = nil
+		scmClient, _ := fake.NewDefault()
+
+		_, o := pr.NewCmdPullRequest()
+		o.ScmClient = scmClient
+		o.ScmClientFactory.ScmClient = scmClient
+		o.ScmClientFactory.GitServerURL = "https://github.com"
+		o.ScmClientFactory.GitKind = tc.kind
+		o.GitKind = "fake"
+		o.GraphQLClient = githubv4.NewEnterpriseClient(server.URL, server.Client())
+
+		pullRequest := &scm.PullRequest{Number: 5, Link: "https://github.com/myorg/myrepo/pull/5"}
+		o.MarkPullRequestDraft(rule, gitURL, pullRequest)
+		assert.Equal(t, tc.expectDraft, pullRequest.Draft, "draft for %s", tc.name)
+		if tc.expectMutated {
+			require.Len(t, mutations, 1, "mutations for %s", tc.name)
+			assert.Equal(t, map[string]interface{}{"pullRequestId": "PR_kwDO123"}, mutations[0]["input"], "mutation input for %s", tc.name)
+		} else {
+			assert.Empty(t, mutations, "mutations for %s", tc.name)
+		}
+	}
+}
+
+func TestMarkPullRequestDraftConversionFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	scmClient, _ := fake.NewDefault()
+	_, o := pr.NewCmdPullRequest()
+	o.ScmClient = scmClient
+	o.ScmClientFactory.ScmClient = scmClient
+	o.ScmClientFactory.GitServerURL = "https://github.com"
+	o.ScmClientFactory.GitKind = pr.GitKindGitHub
+	o.GitKind = "fake"
+	o.GraphQLClient = githubv4.NewEnterpriseClient(server.URL, server.Client())
+
+	pullRequest := &scm.PullRequest{Number: 5, Link: "https://github.com/myorg/myrepo/pull/5"}
+	o.MarkPullRequestDraft(&v1alpha1.Rule{Draft: true}, "https://github.com/myorg/myrepo", pullRequest)
+	assert.False(t, pullRequest.Draft, "the Pull Request is left ready for review")
+}
+
+func TestCreateDraftPullRequests(t *testing.T) {
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method, "method")
+		assert.Equal(t, "/repos/myorg/draftrepo/pulls", r.URL.Path, "path")
+		req := map[string]interface{}{}
+		err := json.NewDecoder(r.Body).Decode(&req)
+		require.NoError(t, err, "failed to decode Pull Request")
+		requests = append(requests, req)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"number":7,"title":"chore: upgrade","state":"open","draft":true,
+"html_url":"https://github.com/myorg/draftrepo/pull/7","head":{"ref":"updatebot","sha":"abc"},"base":{"ref":"main"}}`))
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL + "/")
+	require.NoError(t, err, "failed to parse test server URL")
+
+	scmClient, fakeData := fake.NewDefault()
+	scmClient.BaseURL = serverURL
+	scmClient.Client = server.Client()
+
+	_, o := pr.NewCmdPullRequest()
+	o.ScmClientFactory.GitKind = pr.GitKindGitHub
+	o.CreateDraftPullRequests(scmClient, "myorg/draftrepo", &v1alpha1.Rule{Draft: true})
+	o.CreateDraftPullRequests(scmClient, "myorg/readyrepo", &v1alpha1.Rule{})
+
+	ctx := context.Background()
+	input := &scm.PullRequestInput{Title: "chore: upgrade", Head: "updatebot", Base: "main", Body: "upgrades"}
+	draft, _, err := scmClient.PullRequests.Create(ctx, "myorg/draftrepo", input)
+	require.NoError(t, err, "failed to create draft Pull Request")
+	require.Len(t, requests, 1, "draft Pull Requests created")
+	assert.Equal(t, true, requests[0]["draft"], "draft input")
+	assert.Equal(t, "updatebot", requests[0]["head"], "head input")
+	assert.Equal(t, 7, draft.Number, "number")
+	assert.True(t, draft.Draft, "draft")
+	assert.Equal(t, "https://github.com/myorg/draftrepo/pull/7", draft.Link, "link")
+	assert.Equal(t, "updatebot", draft.Source, "source")
+
+	ready, _, err := scmClient.PullRequests.Create(ctx, "myorg/readyrepo", input)
+	require.NoError(t, err, "failed to create Pull Request")
+	assert.False(t, ready.Draft, "ready for review")
+	assert.Len(t, requests, 1, "the Pull Request which is not a draft is created by the fake driver")
+	assert.NotEmpty(t, fakeData.PullRequests, "Pull Requests of the fake driver")
+}
+
+func TestDraftTitle(t *testing.T) {
+	_, o := pr.NewCmdPullRequest()
+	o.GitKind = pr.GitKindGitLab
+
+	assert.Equal(t, "Draft: chore: upgrade", o.DraftTitle(&v1alpha1.Rule{Draft: true}, "chore: upgrade"), "gitlab draft title")
+	assert.Equal(t, "Draft: chore: upgrade", o.DraftTitle(&v1alpha1.Rule{Draft: true}, "Draft: chore: upgrade"), "existing prefix")
+	assert.Equal(t, "chore: upgrade", o.DraftTitle(&v1alpha1.Rule{}, "chore: upgrade"), "not a draft")
+
+	o.GitKind = pr.GitKindGitHub
+	assert.Equal(t, "chore: upgrade", o.DraftTitle(&v1alpha1.Rule{Draft: true}, "chore: upgrade"), "github draft title")
+}
@@ -0,0 +1,113 @@
+package pr
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-promote/pkg/environments"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+)
+
+// DryRunPullRequest clones the repository and applies the changes of the rule then logs the diff along with the
+// title, body, labels, assignees and reviewers of the Pull Request which would be created. No branches are pushed and no
+// forks or Pull Requests are created
+func (o *Options) DryRunPullRequest(rule *v1alpha1.Rule, gitURL string, labels []string, automerge bool) error {
+	g := o.Git()
+	cloneGitURL := gitURL
+	if o.ScmClientFactory.GitToken != "" && o.ScmClientFactory.GitUsername != "" {
+		var err error
+		cloneGitURL, err = o.ScmClientFactory.CreateAuthenticatedURL(gitURL)
+		if err != nil {
+			return fmt.Errorf("failed to create authenticated git URL to clone with for private repositories: %w", err)
+		}
+	}
+
+	var dir string
+	var err error
+	if len(o.SparseCheckoutPatterns) > 0 {
+		dir, err = gitclient.SparseCloneToDir(g, cloneGitURL, "", true, o.SparseCheckoutPatterns...)
+	} else {
+		dir, err = gitclient.CloneToDir(g, cloneGitURL, "")
+		if err == nil && o.BaseBranchName != "" {
+			err = gitclient.CheckoutRemoteBranch(g, dir, o.BaseBranchName)
+			if err != nil {
+				err = fmt.Errorf("failed to checkout remote branch %s: %w", o.BaseBranchName, err)
+			}
+		}
+	}
+	if dir != "" {
+		defer os.RemoveAll(dir) //nolint:errcheck
+	}
+	if err != nil {
+		return fmt.Errorf("failed to clone git URL %s: %w", gitURL, err)
+	}
+
+	o.OutDir = dir
+	if o.Function == nil {
+		return fmt.Errorf("no change function configured")
+	}
+	err = o.Function()
+	if o.upToDate {
+		o.Logger().Infof("dry run: no changes detected so would not create a Pull Request on %s", gitURL)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to invoke change function in dir %s: %w", dir, err)
+	}
+
+	// lets stage all the changes so that new files are included in the diff
+	_, err = g.Command(dir, "add", "--all")
+	if err != nil {
+		return fmt.Errorf("failed to add changes in dir %s: %w", dir, err)
+	}
+	diff, err := g.Command(dir, "diff", "--cached")
+	if err != nil {
+		return fmt.Errorf("failed to diff changes in dir %s: %w", dir, err)
+	}
+	if strings.TrimSpace(diff) == "" {
+		o.Logger().Infof("dry run: no changes detected so would not create a Pull Request on %s", gitURL)
+		return nil
+	}
+
+	var prLabels []string
+	if automerge {
+		prLabels = append(prLabels, environments.LabelUpdatebot)
+	}
+	for _, l := range labels {
+		if l != "" {
+			prLabels = stringhelpers.EnsureStringArrayContains(prLabels, l)
+		}
+	}
+	assignees := o.configuredAssignees(rule, gitURL)
+	if rule.AssignAuthorToPullRequests {
+		assignees = append(assignees, fmt.Sprintf("the author of commit %s", o.PipelineCommitSha))
+	}
+
+	o.Logger().Infof("dry run: would create a Pull Request on %s\ntitle: %s\nbody:\n%s\nlabels: %s\nassignees: %s\nreviewers: %s\ndiff:\n%s",
+		gitURL, strings.TrimSpace(o.CommitTitle), o.CommitMessage, strings.Join(prLabels, ", "), strings.Join(assignees, ", "),
+		strings.Join(o.configuredReviewers(rule), ", "), diff)
+
+	o.withLock(func() {
+		if o.dryRunUpdates == nil {
+			o.dryRunUpdates = map[string]bool{}
+		}
+		o.dryRunUpdates[gitURL] = true
+	})
+	return nil
+}
+
+// DryRunRepositories returns the sorted git URLs of the repositories which would be updated by the --dry-run
+func (o *Options) DryRunRepositories() []string {
+	var answer []string
+	o.withLock(func() {
+		for gitURL := range o.dryRunUpdates {
+			answer = append(answer, gitURL)
+		}
+	})
+	sort.Strings(answer)
+	return answer
+}
@@ -0,0 +1,51 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	// lets create local repositories to clone
+	var repos []string
+	for _, version := range []string{"1.0.0", "2.0.0"} {
+		dir := t.TempDir()
+		initGitRepository(t, dir)
+		writeTestFile(t, filepath.Join(dir, "values.yaml"), "version: "+version+"\n")
+		o := &pr.Options{}
+		for _, args := range [][]string{
+			{"add", "."},
+			{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", "initial"},
+		} {
+			_, err := o.Git().Command(dir, args...)
+			require.NoError(t, err, "failed to run git %v", args)
+		}
+		repos = append(repos, dir)
+	}
+
+	rule := &v1alpha1.Rule{
+		URLs:    repos,
+		Changes: []v1alpha1.Change{{Regex: &v1alpha1.Regex{Pattern: `version: (.*)`, Globs: []string{"values.yaml"}}}},
+	}
+	o := &pr.Options{
+		Version:      "2.0.0",
+		DryRun:       true,
+		TemplateData: map[string]interface{}{},
+	}
+	o.CommitTitle = "chore: upgrade to 2.0.0"
+	err := o.ProcessAndCreatePullRequests(rule, "", []string{"dependencies"}, false)
+	require.NoError(t, err, "failed to dry run")
+
+	// only the first repository has a change
+	assert.Equal(t, repos[:1], o.DryRunRepositories(), "repositories which would be updated")
+
+	data, err := os.ReadFile(filepath.Join(repos[0], "values.yaml"))
+	require.NoError(t, err, "failed to read values.yaml")
+	assert.Equal(t, "version: 1.0.0\n", string(data), "the source repository should not be changed")
+}
@@ -0,0 +1,55 @@
+package pr
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/giturl"
+)
+
+// ExcludeURLs removes the URLs of the rule which match the excludeURLs of the rule or the --exclude-repo globs so
+// that repositories discovered dynamically can be skipped. Each skipped repository is logged
+func (o *Options) ExcludeURLs(rule *v1alpha1.Rule) error {
+	patterns := append(append([]string{}, o.ExcludeRepos...), rule.ExcludeURLs...)
+	if len(patterns) == 0 {
+		return nil
+	}
+	var urls []string
+	for _, u := range rule.URLs {
+		pattern, err := MatchRepository(u, patterns)
+		if err != nil {
+			return err
+		}
+		if pattern != "" {
+			o.Logger().Infof("skipping repository %s as it matches the exclude pattern %s", info(u), pattern)
+			continue
+		}
+		urls = append(urls, u)
+	}
+	rule.URLs = urls
+	return nil
+}
+
+// MatchRepository returns the first glob which matches the git URL or the owner/name of the repository or an empty
+// string if none match
+func MatchRepository(gitURL string, patterns []string) (string, error) {
+	names := []string{strings.TrimSuffix(strings.TrimSuffix(gitURL, "/"), ".git")}
+	if gitInfo, err := giturl.ParseGitURL(gitURL); err == nil {
+		names = append(names, scm.Join(gitInfo.Organisation, gitInfo.Name))
+	}
+	for _, p := range patterns {
+		for _, name := range names {
+			matched, err := path.Match(p, name)
+			if err != nil {
+				return "", fmt.Errorf("invalid exclude pattern %s: %w", p, err)
+			}
+			if matched {
+				return p, nil
+			}
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,35 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExcludeURLs(t *testing.T) {
+	_, o := pr.NewCmdPullRequest()
+	o.ExcludeRepos = []string{"myorg/legacy-*"}
+
+	rule := &v1alpha1.Rule{
+		URLs: []string{
+			"https://github.com/myorg/app",
+			"https://github.com/myorg/legacy-app",
+			"https://github.com/myorg/archive.git",
+			"https://github.com/otherorg/app",
+		},
+		ExcludeURLs: []string{"https://github.com/myorg/archive", "otherorg/*"},
+	}
+	err := o.ExcludeURLs(rule)
+	require.NoError(t, err, "failed to exclude URLs")
+	assert.Equal(t, []string{"https://github.com/myorg/app"}, rule.URLs, "URLs of the rule")
+
+	rule = &v1alpha1.Rule{
+		URLs:        []string{"https://github.com/myorg/app"},
+		ExcludeURLs: []string{"myorg/[app"},
+	}
+	err = o.ExcludeURLs(rule)
+	require.Error(t, err, "should fail for an invalid pattern")
+}
@@ -0,0 +1,15 @@
+package pr
+
+import (
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+)
+
+// matchesExpectedCurrent returns true if the change has no expected current value or the current value matches it.
+// Otherwise a warning is logged that the change of the given location is skipped
+func (o *Options) matchesExpectedCurrent(change v1alpha1.Change, current, location string) bool {
+	if change.ExpectedCurrent == "" || change.ExpectedCurrent == current {
+		return true
+	}
+	o.Logger().Warnf("skipping change of %s as the current value %s does not match the expected value %s", location, current, change.ExpectedCurrent)
+	return false
+}
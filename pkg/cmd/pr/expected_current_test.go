@@ -0,0 +1,114 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/versionstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectedCurrent(t *testing.T) {
+	source := "app:\n  version: 1.0.0\nsidecar:\n  version: 1.0.0-fork\n"
+	testCases := []struct {
+		name            string
+		change          v1alpha1.Change
+		expectedCurrent string
+		expected        string
+	}{
+		{
+			name:     "regex-no-expected",
+			change:   v1alpha1.Change{Regex: &v1alpha1.Regex{Pattern: `version: (.*)`, Globs: []string{"values.yaml"}}},
+			expected: "app:\n  version: 2.0.0\nsidecar:\n  version: 2.0.0\n",
+		},
+		{
+			name:            "regex-matching-tokens-only",
+			change:          v1alpha1.Change{Regex: &v1alpha1.Regex{Pattern: `version: (.*)`, Globs: []string{"values.yaml"}}},
+			expectedCurrent: "1.0.0",
+			expected:        "app:\n  version: 2.0.0\nsidecar:\n  version: 1.0.0-fork\n",
+		},
+		{
+			name:            "regex-no-match",
+			change:          v1alpha1.Change{Regex: &v1alpha1.Regex{Pattern: `version: (.*)`, Globs: []string{"values.yaml"}}},
+			expectedCurrent: "0.9.0",
+			expected:        source,
+		},
+		{
+			name:            "set-matches",
+			change:          v1alpha1.Change{Set: &v1alpha1.SetValue{Path: "app.version", Value: "3.0.0", Globs: []string{"values.yaml"}}},
+			expectedCurrent: "1.0.0",
+			expected:        "app:\n  version: 3.0.0\nsidecar:\n  version: 1.0.0-fork\n",
+		},
+		{
+			name:            "set-differs",
+			change:          v1alpha1.Change{Set: &v1alpha1.SetValue{Path: "sidecar.version", Value: "3.0.0", Globs: []string{"values.yaml"}}},
+			expectedCurrent: "1.0.0",
+			expected:        source,
+		},
+		{
+			name:            "set-missing-field",
+			change:          v1alpha1.Change{Set: &v1alpha1.SetValue{Path: "other.version", Value: "3.0.0", Globs: []string{"values.yaml"}}},
+			expectedCurrent: "1.0.0",
+			expected:        source,
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "values.yaml")
+		err := os.WriteFile(path, []byte(source), files.DefaultFileWritePermissions)
+		require.NoError(t, err, "failed to write %s", path)
+
+		o := &pr.Options{Version: "2.0.0"}
+		tc.change.ExpectedCurrent = tc.expectedCurrent
+		err = o.ApplyChanges(dir, "https://github.com/myorg/myrepo", tc.change)
+		require.NoError(t, err, "failed to apply change for test %s", tc.name)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err, "failed to read %s", path)
+		assert.Equal(t, tc.expected, string(data), "values.yaml for test %s", tc.name)
+	}
+}
+
+func TestExpectedCurrentVersionStream(t *testing.T) {
+	testCases := []struct {
+		name            string
+		expectedCurrent string
+		expected        string
+	}{
+		{
+			name:            "matches",
+			expectedCurrent: "1.0.0",
+			expected:        "2.0.0",
+		},
+		{
+			name:            "differs",
+			expectedCurrent: "1.1.0",
+			expected:        "1.0.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		err := versionstream.SaveStableVersion(dir, versionstream.KindChart, "myrepo/mychart", &versionstream.StableVersion{Version: "1.0.0"})
+		require.NoError(t, err, "failed to save stable version")
+
+		o := &pr.Options{}
+		vs := &v1alpha1.VersionStreamChange{
+			Pattern: v1alpha1.Pattern{Name: "myrepo/mychart"},
+			Kind:    "charts",
+			Version: "2.0.0",
+		}
+		err = o.ApplyVersionStream(dir, "", v1alpha1.Change{ExpectedCurrent: tc.expectedCurrent}, vs)
+		require.NoError(t, err, "failed to apply version stream change for test %s", tc.name)
+
+		sv, err := versionstream.LoadStableVersion(dir, versionstream.KindChart, "myrepo/mychart")
+		require.NoError(t, err, "failed to load stable version")
+		assert.Equal(t, tc.expected, sv.Version, "version for test %s", tc.name)
+	}
+}
@@ -0,0 +1,82 @@
+package pr
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+)
+
+// VerifyChangedFiles verifies the files modified in the repository dir match the expectedChangedFiles of the rule.
+// Returns an error listing the missing and unexpected files if they do not match. Does nothing if the rule has no
+// expected changed files
+func (o *Options) VerifyChangedFiles(rule *v1alpha1.Rule, dir, gitURL string) error {
+	if len(rule.ExpectedChangedFiles) == 0 {
+		return nil
+	}
+	actual, err := o.ChangedFiles(dir)
+	if err != nil {
+		return err
+	}
+	missing, extra := DiffChangedFiles(rule.ExpectedChangedFiles, actual)
+	if len(missing) == 0 && len(extra) == 0 {
+		return nil
+	}
+	var lines []string
+	for _, path := range missing {
+		lines = append(lines, "- "+path)
+	}
+	for _, path := range extra {
+		lines = append(lines, "+ "+path)
+	}
+	return fmt.Errorf("the changed files of repository %s do not match the expectedChangedFiles of the rule (- expected but not changed, + changed but not expected):\n%s", gitURL, strings.Join(lines, "\n"))
+}
+
+// ChangedFiles returns the sorted paths, relative to the root of the repository, of the modified, deleted and new
+// files in the git repository dir
+func (o *Options) ChangedFiles(dir string) ([]string, error) {
+	g := o.Git()
+	modified, err := g.Command(dir, "diff", "--name-only", "-z", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find modified files in %s: %w", dir, err)
+	}
+	untracked, err := g.Command(dir, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find new files in %s: %w", dir, err)
+	}
+	var answer []string
+	for _, path := range strings.Split(modified+"\x00"+untracked, "\x00") {
+		path = strings.TrimSpace(path)
+		if path != "" {
+			answer = stringhelpers.EnsureStringArrayContains(answer, path)
+		}
+	}
+	sort.Strings(answer)
+	return answer, nil
+}
+
+// DiffChangedFiles returns the sorted expected files which are not in the actual changed files and the actual changed
+// files which are not expected. The expected paths are cleaned so that paths such as ./charts/values.yaml match
+func DiffChangedFiles(expected, actual []string) (missing, extra []string) {
+	var cleaned []string
+	for _, path := range expected {
+		cleaned = append(cleaned, filepath.ToSlash(filepath.Clean(path)))
+	}
+	expected = cleaned
+	for _, path := range expected {
+		if stringhelpers.StringArrayIndex(actual, path) < 0 {
+			missing = stringhelpers.EnsureStringArrayContains(missing, path)
+		}
+	}
+	for _, path := range actual {
+		if stringhelpers.StringArrayIndex(expected, path) < 0 {
+			extra = stringhelpers.EnsureStringArrayContains(extra, path)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(extra)
+	return missing, extra
+}
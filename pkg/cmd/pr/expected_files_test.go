@@ -0,0 +1,73 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffChangedFiles(t *testing.T) {
+	missing, extra := pr.DiffChangedFiles(
+		[]string{"./charts/values.yaml", "README.md", "Makefile"},
+		[]string{"charts/values.yaml", "Makefile", "docs/index.md"},
+	)
+	assert.Equal(t, []string{"README.md"}, missing, "missing files")
+	assert.Equal(t, []string{"docs/index.md"}, extra, "extra files")
+}
+
+func TestVerifyChangedFiles(t *testing.T) {
+	gitURL := "https://github.com/myorg/myrepo"
+	dir := t.TempDir()
+	initGitRepository(t, dir)
+
+	o := &pr.Options{}
+	g := o.Git()
+	for _, path := range []string{"values.yaml", "charts/values.yaml", "README.md"} {
+		writeTestFile(t, filepath.Join(dir, path), "version: 1.0.0\n")
+	}
+	for _, args := range [][]string{
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", "initial"},
+	} {
+		_, err := g.Command(dir, args...)
+		require.NoError(t, err, "failed to run git %v", args)
+	}
+
+	change := v1alpha1.Change{Regex: &v1alpha1.Regex{Pattern: `version: (.*)`, Globs: []string{"charts/*.yaml"}}}
+	o.Version = "2.0.0"
+	err := o.ApplyChanges(dir, gitURL, change)
+	require.NoError(t, err, "failed to apply changes")
+	writeTestFile(t, filepath.Join(dir, "new.txt"), "new file\n")
+
+	actual, err := o.ChangedFiles(dir)
+	require.NoError(t, err, "failed to find changed files")
+	assert.Equal(t, []string{"charts/values.yaml", "new.txt"}, actual, "changed files")
+
+	rule := &v1alpha1.Rule{}
+	err = o.VerifyChangedFiles(rule, dir, gitURL)
+	assert.NoError(t, err, "should not verify without expected files")
+
+	rule.ExpectedChangedFiles = []string{"charts/values.yaml", "new.txt"}
+	err = o.VerifyChangedFiles(rule, dir, gitURL)
+	assert.NoError(t, err, "should match the expected files")
+
+	rule.ExpectedChangedFiles = []string{"charts/values.yaml", "values.yaml"}
+	err = o.VerifyChangedFiles(rule, dir, gitURL)
+	require.Error(t, err, "should fail for unexpected files")
+	assert.Contains(t, err.Error(), "- values.yaml", "missing file")
+	assert.Contains(t, err.Error(), "+ new.txt", "extra file")
+	t.Logf("got expected error: %s", err.Error())
+}
+
+func writeTestFile(t *testing.T, path, text string) {
+	err := os.MkdirAll(filepath.Dir(path), files.DefaultDirWritePermissions)
+	require.NoError(t, err, "failed to create dir for %s", path)
+	err = os.WriteFile(path, []byte(text), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to write %s", path)
+}
@@ -0,0 +1,95 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// FailureReportTitle the title of the issue created in the pipeline repository to report failed repositories
+const FailureReportTitle = "updatebot: failed to create pull requests"
+
+// RepositoryFailure a repository which failed to be updated when using --continue-on-error
+type RepositoryFailure struct {
+	// GitURL the git URL of the repository, empty if the rule failed before processing its repositories
+	GitURL string
+	// Rule the index of the rule the repository failed in
+	Rule int
+	// Error the reason the repository failed
+	Error error
+}
+
+// FailureReport returns the markdown body of the failure report issue
+func FailureReport(version string, failures []RepositoryFailure) string {
+	sb := strings.Builder{}
+	if version == "" {
+		sb.WriteString("The following repositories failed to be updated:\n\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("The following repositories failed to be updated to version %s:\n\n", version))
+	}
+	sb.WriteString("| Repository | Error |\n")
+	sb.WriteString("| --- | --- |\n")
+	for _, f := range failures {
+		msg := strings.ReplaceAll(f.Error.Error(), "\n", " ")
+		msg = strings.ReplaceAll(msg, "|", "\\|")
+		repo := f.GitURL
+		if repo == "" {
+			repo = fmt.Sprintf("rule #%d", f.Rule)
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s |\n", repo, msg))
+	}
+	return sb.String()
+}
+
+// FailuresError returns an error summarizing each failure with the index of its rule and its repository
+func FailuresError(failures []RepositoryFailure) error {
+	lines := make([]string, 0, len(failures))
+	for _, f := range failures {
+		if f.GitURL == "" {
+			lines = append(lines, fmt.Sprintf("* rule #%d: %s", f.Rule, f.Error.Error()))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("* rule #%d %s: %s", f.Rule, f.GitURL, f.Error.Error()))
+	}
+	return fmt.Errorf("failed to create Pull Requests with %d failures:\n%s", len(failures), strings.Join(lines, "\n"))
+}
+
+// CreateFailureReport opens an issue in the pipeline repository listing the repositories which failed.
+// If the issue is already open the report is added as a comment
+func (o *Options) CreateFailureReport(failures []RepositoryFailure) (*scm.Issue, error) {
+	if o.PipelineRepoURL == "" {
+		o.Logger().Warnf("cannot create a failure report without a --pipeline-repo-url")
+		return nil, nil
+	}
+	ctx := context.Background()
+	scmClient, repoFullName, err := o.GetScmClient(o.PipelineRepoURL, o.GitKind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ScmClient: %w", err)
+	}
+	body := FailureReport(o.Version, failures)
+
+	issues, _, err := scmClient.Issues.List(ctx, repoFullName, scm.IssueListOptions{Open: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issues in repo %s: %w", repoFullName, err)
+	}
+	for _, issue := range issues {
+		if issue == nil || issue.Title != FailureReportTitle || issue.PullRequest != nil {
+			continue
+		}
+		_, _, err = scmClient.Issues.CreateComment(ctx, repoFullName, issue.Number, &scm.CommentInput{Body: body})
+		if err != nil {
+			return nil, fmt.Errorf("failed to comment on issue %d in repo %s: %w", issue.Number, repoFullName, err)
+		}
+		o.Logger().Infof("updated failure report %s", issue.Link)
+		return issue, nil
+	}
+
+	issue, _, err := scmClient.Issues.Create(ctx, repoFullName, &scm.IssueInput{Title: FailureReportTitle, Body: body})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create issue in repo %s: %w", repoFullName, err)
+	}
+	o.Logger().Infof("created failure report %s", issue.Link)
+	return issue, nil
+}
@@ -0,0 +1,98 @@
+package pr_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailureReport(t *testing.T) {
+	failures := []pr.RepositoryFailure{
+		{GitURL: "https://github.com/myorg/repo1", Error: errors.New("failed to push\nexit code 1")},
+		{GitURL: "https://github.com/myorg/repo2", Error: errors.New("a | b")},
+	}
+	expected := `The following repositories failed to be updated to version 1.2.3:
+
+| Repository | Error |
+| --- | --- |
+| https://github.com/myorg/repo1 | failed to push exit code 1 |
+| https://github.com/myorg/repo2 | a \| b |
+`
+	assert.Equal(t, expected, pr.FailureReport("1.2.3", failures), "failure report")
+}
+
+func TestFailuresError(t *testing.T) {
+	failures := []pr.RepositoryFailure{
+		{GitURL: "https://github.com/myorg/repo1", Error: errors.New("failed to push")},
+		{Rule: 1, Error: errors.New("failed to find URLs")},
+		{GitURL: "https://github.com/myorg/repo2", Rule: 2, Error: errors.New("failed to clone")},
+	}
+	err := pr.FailuresError(failures)
+	expected := `failed to create Pull Requests with 3 failures:
+* rule #0 https://github.com/myorg/repo1: failed to push
+* rule #1: failed to find URLs
+* rule #2 https://github.com/myorg/repo2: failed to clone`
+	assert.EqualError(t, err, expected, "combined error")
+	assert.Contains(t, pr.FailureReport("", failures), "| rule #1 | failed to find URLs |", "failure report of a rule")
+}
+
+func TestCreateFailureReport(t *testing.T) {
+	failures := []pr.RepositoryFailure{
+		{GitURL: "https://github.com/myorg/repo1", Error: errors.New("failed to push")},
+	}
+
+	for _, existing := range []bool{false, true} {
+		scmClient, fakeData := fake.NewDefault()
+		issues := &fakeIssueService{IssueService: scmClient.Issues}
+		if existing {
+			issues.issues = append(issues.issues, &scm.Issue{Number: 5, Title: pr.FailureReportTitle})
+			fakeData.Issues[5] = issues.issues
+		}
+		scmClient.Issues = issues
+
+		_, o := pr.NewCmdPullRequest()
+		o.ScmClient = scmClient
+		o.ScmClientFactory.ScmClient = scmClient
+		o.ScmClientFactory.GitServerURL = "https://github.com"
+		o.GitKind = "fake"
+		o.PipelineRepoURL = "https://github.com/myorg/mysource"
+
+		issue, err := o.CreateFailureReport(failures)
+		require.NoError(t, err, "failed to create failure report when existing is %v", existing)
+		require.NotNil(t, issue, "issue when existing is %v", existing)
+
+		if existing {
+			assert.Equal(t, 5, issue.Number, "should reuse the open issue")
+			assert.Empty(t, issues.created, "should not create an issue")
+			require.Len(t, fakeData.IssueCommentsAdded, 1, "comments added")
+			assert.Contains(t, fakeData.IssueCommentsAdded[0], "myorg/mysource#5:", "comment on the open issue")
+			continue
+		}
+		require.Len(t, issues.created, 1, "issues created")
+		assert.Equal(t, pr.FailureReportTitle, issues.created[0].Title, "issue title")
+		assert.Contains(t, issues.created[0].Body, "https://github.com/myorg/repo1", "issue body")
+		assert.Empty(t, fakeData.IssueCommentsAdded, "should not comment")
+	}
+}
+
+// fakeIssueService implements the issue listing and creation which the fake driver does not support
+type fakeIssueService struct {
+	scm.IssueService
+	issues  []*scm.Issue
+	created []*scm.IssueInput
+}
+
+func (s *fakeIssueService) List(context.Context, string, scm.IssueListOptions) ([]*scm.Issue, *scm.Response, error) {
+	return s.issues, nil, nil
+}
+
+func (s *fakeIssueService) Create(_ context.Context, _ string, input *scm.IssueInput) (*scm.Issue, *scm.Response, error) {
+	s.created = append(s.created, input)
+	return &scm.Issue{Number: len(s.issues) + len(s.created), Title: input.Title, Body: input.Body}, nil, nil
+}
@@ -0,0 +1,74 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// forkOrgUserService returns the fork organisation as the current user so that the head of Pull Requests created from
+// a fork references the branch in the organisation
+type forkOrgUserService struct {
+	scm.UserService
+	org string
+}
+
+// Find returns the fork organisation as the current user
+func (s *forkOrgUserService) Find(_ context.Context) (*scm.User, *scm.Response, error) {
+	return &scm.User{Login: s.org, Name: s.org}, nil, nil
+}
+
+// forkOrgRepositoryService forks repositories into the fork organisation
+type forkOrgRepositoryService struct {
+	scm.RepositoryService
+	org string
+}
+
+// Fork forks the repository into the fork organisation returning a clear error if the git token is not allowed to
+func (s *forkOrgRepositoryService) Fork(ctx context.Context, input *scm.RepositoryInput, origRepo string) (*scm.Repository, *scm.Response, error) {
+	in := *input
+	in.Namespace = s.org
+	repo, res, err := s.RepositoryService.Fork(ctx, &in, origRepo)
+	if err != nil && res != nil && (res.Status == http.StatusForbidden || res.Status == http.StatusUnauthorized) {
+		return repo, res, fmt.Errorf("the git token does not have permission to fork repository %s into organisation %s: %w", origRepo, s.org, err)
+	}
+	return repo, res, err
+}
+
+// UseForkOrg makes the Pull Requests created with the ScmClient come from forks in the organisation rather than the
+// account of the git token user. An existing fork in the organisation is found as the fork of the user would be.
+// An empty organisation restores the user forks. The ScmClient is shared by the repositories of a git server which
+// are processed concurrently so it is only switched between rules
+func (o *Options) UseForkOrg(scmClient *scm.Client, org string) {
+	if scmClient == nil {
+		return
+	}
+	o.withLock(func() {
+		users, forkUsers := scmClient.Users.(*forkOrgUserService)
+		repos, forkRepos := scmClient.Repositories.(*forkOrgRepositoryService)
+		if forkUsers && forkRepos && users.org == org {
+			return
+		}
+		if forkUsers {
+			scmClient.Users = users.UserService
+		}
+		if forkRepos {
+			scmClient.Repositories = repos.RepositoryService
+			if o.forkUsername != nil {
+				scmClient.Username = *o.forkUsername
+				o.forkUsername = nil
+			}
+		}
+		if org == "" {
+			return
+		}
+		username := scmClient.Username
+		o.forkUsername = &username
+		scmClient.Username = org
+		scmClient.Users = &forkOrgUserService{UserService: scmClient.Users, org: org}
+		scmClient.Repositories = &forkOrgRepositoryService{RepositoryService: scmClient.Repositories, org: org}
+		o.Logger().Infof("creating Pull Requests from forks in organisation %s", info(org))
+	})
+}
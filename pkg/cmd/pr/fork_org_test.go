@@ -0,0 +1,82 @@
+package pr_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseForkOrg(t *testing.T) {
+	ctx := context.Background()
+	scmClient, _ := fake.NewDefault()
+	username := scmClient.Username
+	repositories := &fakeForkRepositoryService{
+		RepositoryService: scmClient.Repositories,
+		forks:             map[string]string{"mybots/existing": "https://github.com/mybots/existing.git"},
+	}
+	scmClient.Repositories = repositories
+
+	_, o := pr.NewCmdPullRequest()
+	o.UseForkOrg(scmClient, "mybots")
+	o.UseForkOrg(scmClient, "mybots")
+
+	user, _, err := scmClient.Users.Find(ctx)
+	require.NoError(t, err, "failed to find the current user")
+	o.BranchName = "updatebot-1"
+	assert.Equal(t, "mybots:updatebot-1", user.Login+":"+o.BranchName, "head branch of the Pull Request")
+
+	cloneURL, err := o.EnsureForked(scmClient, "myorg/existing")
+	require.NoError(t, err, "failed to fork existing repository")
+	assert.Equal(t, "https://github.com/mybots/existing.git", cloneURL, "reused the fork in the organisation")
+	assert.Empty(t, repositories.forked, "should not fork again")
+
+	cloneURL, err = o.EnsureForked(scmClient, "myorg/myrepo")
+	require.NoError(t, err, "failed to fork repository")
+	assert.Equal(t, "https://github.com/mybots/myrepo.git", cloneURL, "fork in the organisation")
+	require.Len(t, repositories.forked, 1, "forked repositories")
+	assert.Equal(t, "mybots", repositories.forked[0].Namespace, "fork namespace")
+
+	repositories.status = http.StatusForbidden
+	_, err = o.EnsureForked(scmClient, "myorg/other")
+	require.Error(t, err, "should fail without permission to fork")
+	assert.Contains(t, err.Error(), "does not have permission to fork repository myorg/other into organisation mybots")
+
+	o.UseForkOrg(scmClient, "")
+	assert.Equal(t, username, scmClient.Username, "restored the username")
+	assert.Equal(t, repositories, scmClient.Repositories, "restored the repository service")
+	user, _, err = scmClient.Users.Find(ctx)
+	require.NoError(t, err, "failed to find the current user")
+	assert.NotEqual(t, "mybots", user.Login, "restored the user service")
+}
+
+// fakeForkRepositoryService finds the given forks and records the repositories which are forked
+type fakeForkRepositoryService struct {
+	scm.RepositoryService
+	forks  map[string]string
+	forked []scm.RepositoryInput
+	status int
+}
+
+func (s *fakeForkRepositoryService) Find(_ context.Context, repo string) (*scm.Repository, *scm.Response, error) {
+	cloneURL := s.forks[repo]
+	if cloneURL == "" {
+		return nil, &scm.Response{Status: http.StatusNotFound}, scm.ErrNotFound
+	}
+	return &scm.Repository{FullName: repo, Clone: cloneURL}, nil, nil
+}
+
+func (s *fakeForkRepositoryService) Fork(_ context.Context, input *scm.RepositoryInput, _ string) (*scm.Repository, *scm.Response, error) {
+	if s.status != 0 {
+		return nil, &scm.Response{Status: s.status}, errors.New("forbidden")
+	}
+	s.forked = append(s.forked, *input)
+	fullName := scm.Join(input.Namespace, input.Name)
+	return &scm.Repository{FullName: fullName, Clone: "https://github.com/" + fullName + ".git"}, nil, nil
+}
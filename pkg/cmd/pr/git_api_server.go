@@ -0,0 +1,88 @@
+package pr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/giturl"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/options"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/scmhelpers"
+)
+
+// GetScmClient creates the ScmClient for the given git URL. If --git-api-server is specified the client talks to
+// that server rather than the host the repository is cloned from and pushed to.
+// Clients are cached per git server and kind so they are reused for the rest of the run
+func (o *Options) GetScmClient(gitURL, kind string) (*scm.Client, string, error) {
+	if gitURL == "" {
+		return o.EnvironmentPullRequestOptions.GetScmClient(gitURL, kind)
+	}
+	key, err := newScmClientKey(gitURL, kind)
+	if err != nil {
+		return nil, "", err
+	}
+	kind = o.useCachedScmClient(key)
+
+	err = o.UseGitAPIServer(gitURL, kind)
+	if err != nil {
+		return nil, "", err
+	}
+	if kind == "" && o.GitAPIServerURL != "" {
+		kind = o.GitKind
+	}
+	scmClient, repoFullName, err := o.EnvironmentPullRequestOptions.GetScmClient(gitURL, kind)
+	if err != nil {
+		return nil, "", err
+	}
+	if scmClient != nil {
+		o.cacheScmClient(key, scmClient)
+	}
+	return scmClient, repoFullName, nil
+}
+
+// UseGitAPIServer registers an ScmClient for the API server as the client of the git server of the given URL so that
+// Pull Requests are created using the API server while git clone and push still use the git URL
+func (o *Options) UseGitAPIServer(gitURL, kind string) error {
+	if o.GitAPIServerURL == "" || gitURL == "" {
+		return nil
+	}
+	gitInfo, err := giturl.ParseGitURL(gitURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse git URL %s: %w", gitURL, err)
+	}
+	serverURL := gitInfo.HostURLWithoutUser()
+	f := &o.ScmClientFactory
+	if f.ScmClient != nil && f.GitServerURL == serverURL && o.gitAPIServerClient == f.ScmClient {
+		return nil
+	}
+
+	apiURL := strings.TrimSuffix(o.GitAPIServerURL, "/")
+	if kind == "" {
+		kind = o.GitKind
+	}
+	if kind == "" {
+		kind = f.GitKind
+	}
+	if kind == "" {
+		kind = giturl.SaasGitKind(apiURL)
+	}
+	if kind == "" {
+		return options.MissingOption("git-kind")
+	}
+	o.GitKind = kind
+
+	scmClient, token, err := scmhelpers.NewScmClient(kind, apiURL, f.GitToken, f.IgnoreMissingToken)
+	if err != nil {
+		return fmt.Errorf("failed to create ScmClient for git API server %s: %w", apiURL, err)
+	}
+	if token != "" {
+		f.GitToken = token
+	}
+	o.Logger().Infof("using git API server %s for repositories on %s", info(apiURL), info(serverURL))
+
+	f.GitKind = kind
+	f.GitServerURL = serverURL
+	f.ScmClient = scmClient
+	o.gitAPIServerClient = scmClient
+	return nil
+}
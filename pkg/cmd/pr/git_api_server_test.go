@@ -0,0 +1,29 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitAPIServer(t *testing.T) {
+	_, o := pr.NewCmdPullRequest()
+	o.GitAPIServerURL = "https://git-api.mirror.example.com/"
+	o.ScmClientFactory.GitToken = "dummytoken"
+	o.ScmClientFactory.GitKind = "github"
+
+	scmClient, repoFullName, err := o.GetScmClient("https://git.mirror.example.com/myorg/myrepo.git", "")
+	require.NoError(t, err, "failed to get ScmClient")
+	require.NotNil(t, scmClient, "no ScmClient")
+
+	assert.Equal(t, "myorg/myrepo", repoFullName, "repository name")
+	assert.Equal(t, "git-api.mirror.example.com", scmClient.BaseURL.Host, "API host")
+	assert.Equal(t, "https://git.mirror.example.com", o.ScmClientFactory.GitServerURL, "git server")
+
+	// the client is reused for other repositories on the same git server
+	again, _, err := o.GetScmClient("https://git.mirror.example.com/myorg/another.git", "")
+	require.NoError(t, err, "failed to get ScmClient")
+	assert.Same(t, scmClient, again, "should reuse the ScmClient")
+}
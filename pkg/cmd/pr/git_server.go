@@ -0,0 +1,44 @@
+package pr
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-gitops/pkg/cmd/git/setup"
+)
+
+// DefaultGitServerURL the git server used if none is specified or discovered
+const DefaultGitServerURL = "https://github.com"
+
+// GitServer returns the URL of the git server without a trailing slash, such as a GitHub Enterprise or self-hosted
+// GitLab server, defaulting to https://github.com
+func (o *Options) GitServer() string {
+	server := strings.TrimSuffix(o.ScmClientFactory.GitServerURL, "/")
+	if server == "" {
+		return DefaultGitServerURL
+	}
+	return server
+}
+
+// GitHubGraphQLURL returns the URL of the GraphQL API of the GitHub server. GitHub Enterprise servers serve the API
+// under /api/graphql of the server rather than on api.github.com
+func GitHubGraphQLURL(server string) string {
+	u, err := url.Parse(server)
+	if err == nil && u.Host != "" && u.Host != "github.com" && u.Host != "www.github.com" {
+		return strings.TrimSuffix(server, "/") + "/api/graphql"
+	}
+	return "https://api.github.com/graphql"
+}
+
+// GitCredentialsSetup returns the options to setup the git credentials file for the git server so that clones of a
+// GitHub Enterprise or self-hosted server are authenticated
+func (o *Options) GitCredentialsSetup() *setup.Options {
+	_, gc := setup.NewCmdGitSetup()
+	gc.Dir = o.Dir
+	gc.DisableInClusterTest = true
+	gc.UserEmail = o.GitCommitUserEmail
+	gc.UserName = o.GitCommitUsername
+	gc.Password = o.ScmClientFactory.GitToken
+	gc.GitProviderURL = o.GitServer()
+	return gc
+}
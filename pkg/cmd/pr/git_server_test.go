@@ -0,0 +1,35 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitCredentialsSetup(t *testing.T) {
+	_, o := pr.NewCmdPullRequest()
+	o.GitCommitUsername = "jenkins-x-bot"
+	o.GitCommitUserEmail = "bot@example.com"
+	o.ScmClientFactory.GitToken = "dummytoken"
+	assert.Equal(t, "https://github.com", o.GitCredentialsSetup().GitProviderURL, "default git provider")
+
+	o.ScmClientFactory.GitServerURL = "https://github.mycorp.com/"
+	gc := o.GitCredentialsSetup()
+	assert.Equal(t, "https://github.mycorp.com", gc.GitProviderURL, "git provider of the git server")
+	assert.Equal(t, "jenkins-x-bot", gc.UserName, "user name")
+	assert.Equal(t, "bot@example.com", gc.UserEmail, "user email")
+	assert.Equal(t, "dummytoken", gc.Password, "password")
+}
+
+func TestGitHubGraphQLURL(t *testing.T) {
+	testCases := map[string]string{
+		"":                           "https://api.github.com/graphql",
+		"https://github.com":         "https://api.github.com/graphql",
+		"https://github.mycorp.com":  "https://github.mycorp.com/api/graphql",
+		"https://github.mycorp.com/": "https://github.mycorp.com/api/graphql",
+	}
+	for server, expected := range testCases {
+		assert.Equal(t, expected, pr.GitHubGraphQLURL(server), "GraphQL URL of %s", server)
+	}
+}
@@ -0,0 +1,53 @@
+package pr
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/giturl"
+)
+
+// GitKindGitea the git kind of Gitea servers. Forgejo servers use the same kind
+const GitKindGitea = "gitea"
+
+// IsGitea returns true if the git kind is Gitea
+func (o *Options) IsGitea() bool {
+	return o.GitKind == GitKindGitea || o.ScmClientFactory.GitKind == GitKindGitea
+}
+
+// ScheduleGiteaAutoMerge asks Gitea to merge the Pull Request once its checks succeed. Gitea does not merge Pull
+// Requests with the updatebot label itself so this is used rather than relying on a merge bot.
+// Failures are logged as warnings as the Pull Request can still be merged by hand
+func (o *Options) ScheduleGiteaAutoMerge(gitURL string, pr *scm.PullRequest) {
+	err := o.scheduleGiteaAutoMerge(gitURL, pr)
+	if err != nil {
+		o.Logger().Warnf("failed to enable auto merge of PR %s on gitea: %s", pr.Link, err.Error())
+		return
+	}
+	o.Logger().Infof("enabled auto merge of PR %s once its checks succeed", info(pr.Link))
+}
+
+func (o *Options) scheduleGiteaAutoMerge(gitURL string, pr *scm.PullRequest) error {
+	gitInfo, err := giturl.ParseGitURL(gitURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse git URL %s: %w", gitURL, err)
+	}
+	serverURL := gitInfo.HostURLWithoutUser()
+	if o.GitAPIServerURL != "" {
+		serverURL = strings.TrimSuffix(o.GitAPIServerURL, "/")
+	}
+	client, err := gitea.NewClient(serverURL, gitea.SetToken(o.ScmClientFactory.GitToken), gitea.SetGiteaVersion(""))
+	if err != nil {
+		return fmt.Errorf("failed to create gitea client for %s: %w", serverURL, err)
+	}
+	_, _, err = client.MergePullRequest(gitInfo.Organisation, gitInfo.Name, int64(pr.Number), gitea.MergePullRequestOption{
+		Style:                  gitea.MergeStyleMerge,
+		MergeWhenChecksSucceed: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule merge of PR %d: %w", pr.Number, err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package pr_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleGiteaAutoMerge(t *testing.T) {
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/repos/myorg/myrepo/pulls/3/merge", r.URL.Path, "merge request path")
+		assert.Equal(t, "token dummytoken", r.Header.Get("Authorization"), "authorization header")
+		body := map[string]interface{}{}
+		err := json.NewDecoder(r.Body).Decode(&body)
+		require.NoError(t, err, "failed to decode merge request")
+		requests = append(requests, body)
+	}))
+	defer server.Close()
+
+	_, o := pr.NewCmdPullRequest()
+	o.GitKind = pr.GitKindGitea
+	o.GitAPIServerURL = server.URL
+	o.ScmClientFactory.GitToken = "dummytoken"
+	require.True(t, o.IsGitea(), "should be gitea")
+
+	o.ScheduleGiteaAutoMerge("https://gitea.example.com/myorg/myrepo", &scm.PullRequest{Number: 3})
+	require.Len(t, requests, 1, "merge requests")
+	assert.Equal(t, true, requests[0]["merge_when_checks_succeed"], "should merge when checks succeed")
+	assert.Equal(t, "merge", requests[0]["Do"], "merge style")
+}
+
+func TestAssignUsersToIssueGitea(t *testing.T) {
+	scmClient, _ := fake.NewDefault()
+	scmClient.PullRequests = &failingAssignService{PullRequestService: scmClient.PullRequests}
+
+	_, o := pr.NewCmdPullRequest()
+	o.ScmClient = scmClient
+	o.ScmClientFactory.ScmClient = scmClient
+	o.ScmClientFactory.GitServerURL = "https://gitea.example.com"
+
+	gitURL := "https://gitea.example.com/myorg/myrepo"
+	pullRequest := &scm.PullRequest{Number: 1}
+	err := o.AssignUsersToIssue(pullRequest, []string{"someone"}, gitURL, "fake")
+	require.Error(t, err, "should fail to assign on other git servers")
+
+	o.GitKind = pr.GitKindGitea
+	err = o.AssignUsersToIssue(pullRequest, []string{"someone"}, gitURL, pr.GitKindGitea)
+	require.NoError(t, err, "should only warn when failing to assign on gitea")
+}
+
+// failingAssignService fails to assign users like gitea does for users who are not collaborators
+type failingAssignService struct {
+	scm.PullRequestService
+}
+
+func (s *failingAssignService) AssignIssue(context.Context, string, int, []string) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
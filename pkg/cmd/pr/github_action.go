@@ -0,0 +1,160 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/giturl"
+	"github.com/yargevad/filepathx"
+)
+
+var (
+	// DefaultGitHubActionGlobs the files of a githubAction change if none are specified
+	DefaultGitHubActionGlobs = []string{".github/workflows/*.yml", ".github/workflows/*.yaml"}
+
+	githubActionUsesRegex    = regexp.MustCompile(`^(\s*(?:-\s+)?uses:\s*["']?)([^@\s"']+)@([^\s"'#]+)(["']?)(.*)$`)
+	githubActionCommentRegex = regexp.MustCompile(`^(\s*#\s*)(\S+)(.*)$`)
+	gitSHARegex              = regexp.MustCompile(`^[0-9a-f]{40}$`)
+)
+
+// ApplyGitHubAction replaces the ref of the action in the uses of the GitHub Actions workflows. The commit SHA of the
+// version is only looked up if a workflow pins the action by SHA
+func (o *Options) ApplyGitHubAction(dir, gitURL string, change v1alpha1.Change, action *v1alpha1.GitHubActionChange) error {
+	if action.Name == "" {
+		return fmt.Errorf("no name for githubAction change %#v", change)
+	}
+	ref, err := o.changeValue(gitURL, change, action.Value)
+	if err != nil {
+		return err
+	}
+	sha := ""
+	shaFn := func() (string, error) {
+		if sha != "" {
+			return sha, nil
+		}
+		var err error
+		sha, err = o.GitHubActionSHA(gitURL, action, ref)
+		return sha, err
+	}
+
+	matched := 0
+	for _, g := range GitHubActionGlobs(action) {
+		matches, err := filepathx.Glob(filepath.Join(dir, g))
+		if err != nil {
+			return fmt.Errorf("failed to evaluate glob %s: %w", g, err)
+		}
+		if len(matches) == 0 {
+			_, err = o.handleMissingFile(change, g, gitURL, false)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		for _, f := range matches {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return fmt.Errorf("failed to load file %s: %w", f, err)
+			}
+			text, count, err := o.ReplaceGitHubActionRef(change, string(data), action.Name, ref, shaFn, f)
+			if err != nil {
+				return err
+			}
+			matched += count
+			if text == string(data) {
+				continue
+			}
+			err = os.WriteFile(f, []byte(text), files.DefaultFileWritePermissions)
+			if err != nil {
+				return fmt.Errorf("failed to save file %s: %w", f, err)
+			}
+			o.Logger().Infof("modified file %s with action %s@%s", info(f), action.Name, ref)
+		}
+	}
+	return checkRequireMatch(change, matched, "githubAction", action.Name, GitHubActionGlobs(action), gitURL)
+}
+
+// GitHubActionGlobs returns the files of the githubAction change
+func GitHubActionGlobs(action *v1alpha1.GitHubActionChange) []string {
+	if len(action.Globs) == 0 {
+		return DefaultGitHubActionGlobs
+	}
+	return action.Globs
+}
+
+// GitHubActionSHA returns the commit SHA of the ref of the action from the sha template of the change or by finding
+// the commit of the ref in the repository of the action on the git server of the repository being changed
+func (o *Options) GitHubActionSHA(gitURL string, action *v1alpha1.GitHubActionChange, ref string) (string, error) {
+	if action.SHA != "" {
+		text, err := o.EvaluateVersionTemplate(action.SHA, gitURL)
+		if err != nil {
+			return "", fmt.Errorf("failed to evaluate sha template %s: %w", action.SHA, err)
+		}
+		return strings.TrimSpace(text), nil
+	}
+	gitInfo, err := giturl.ParseGitURL(gitURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse git URL %s: %w", gitURL, err)
+	}
+	parts := strings.SplitN(action.Name, "/", 3)
+	actionURL := gitInfo.HostURLWithoutUser() + "/" + strings.Join(parts[:min(len(parts), 2)], "/")
+	scmClient, repoFullName, err := o.GetScmClient(actionURL, o.GitKind)
+	if err != nil {
+		return "", fmt.Errorf("failed to create ScmClient: %w", err)
+	}
+	commit, _, err := scmClient.Git.FindCommit(context.Background(), repoFullName, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the commit of %s in action repository %s: %w", ref, repoFullName, err)
+	}
+	return commit.Sha, nil
+}
+
+// ReplaceGitHubActionRef replaces the ref of the action in the uses lines of the workflow text returning the new text
+// and the number of uses of the action. Uses pinned by commit SHA are replaced with the SHA returned by the function
+// and their version comment is updated, or added if missing, so the version stays readable
+func (o *Options) ReplaceGitHubActionRef(change v1alpha1.Change, text, name, ref string, sha func() (string, error), path string) (string, int, error) {
+	matched := 0
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		m := githubActionUsesRegex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		prefix, uses, current, quote, rest := m[1], m[2], m[3], m[4], m[5]
+		if uses != name && !strings.HasPrefix(uses, name+"/") {
+			continue
+		}
+		matched++
+		if !gitSHARegex.MatchString(current) {
+			if o.matchesExpectedCurrent(change, current, name+" in "+path) {
+				lines[i] = prefix + uses + "@" + ref + quote + rest
+			}
+			continue
+		}
+
+		cm := githubActionCommentRegex.FindStringSubmatch(rest)
+		currentVersion := ""
+		if cm != nil {
+			currentVersion = cm[2]
+		}
+		if !o.matchesExpectedCurrent(change, currentVersion, name+" in "+path) {
+			continue
+		}
+		s, err := sha()
+		if err != nil {
+			return text, matched, err
+		}
+		if cm != nil {
+			rest = cm[1] + ref + cm[3]
+		} else {
+			rest = " # " + ref + rest
+		}
+		lines[i] = prefix + uses + "@" + s + quote + rest
+	}
+	return strings.Join(lines, "\n"), matched, nil
+}
@@ -0,0 +1,96 @@
+package pr_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyGitHubAction(t *testing.T) {
+	oldSHA := strings.Repeat("a", 40)
+	newSHA := strings.Repeat("b", 40)
+	source := `jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+      - uses: myorg/action@v1.2.3
+        with:
+          name: test
+      - name: setup
+        uses: "myorg/action/setup@v1.2.3"
+      - uses: myorg/action@` + oldSHA + ` # v1.2.3
+      - uses: myorg/action@` + oldSHA + `
+      - uses: myorg/action-other@v1.2.3
+      - uses: ./local-action
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".github", "workflows", "build.yml")
+	writeTestFile(t, path, source)
+
+	scmClient, _ := fake.NewDefault()
+	gitService := &fakeCommitGitService{GitService: scmClient.Git, commits: map[string]string{"myorg/action@v2.0.0": newSHA}}
+	scmClient.Git = gitService
+
+	_, o := pr.NewCmdPullRequest()
+	o.ScmClient = scmClient
+	o.ScmClientFactory.ScmClient = scmClient
+	o.ScmClientFactory.GitServerURL = "https://github.com"
+	o.GitKind = "fake"
+	o.Version = "v2.0.0"
+	o.TemplateData = map[string]interface{}{"Version": "v2.0.0"}
+
+	change := v1alpha1.Change{RequireMatch: true, GitHubAction: &v1alpha1.GitHubActionChange{Name: "myorg/action"}}
+	err := o.ApplyChanges(dir, "https://github.com/myorg/myrepo", change)
+	require.NoError(t, err, "failed to apply change")
+
+	expected := `jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+      - uses: myorg/action@v2.0.0
+        with:
+          name: test
+      - name: setup
+        uses: "myorg/action/setup@v2.0.0"
+      - uses: myorg/action@` + newSHA + ` # v2.0.0
+      - uses: myorg/action@` + newSHA + ` # v2.0.0
+      - uses: myorg/action-other@v1.2.3
+      - uses: ./local-action
+`
+	data, err := os.ReadFile(path)
+	require.NoError(t, err, "failed to read %s", path)
+	assert.Equal(t, expected, string(data), "modified workflow")
+	assert.Equal(t, []string{"myorg/action@v2.0.0"}, gitService.finds, "the commit of the version should be found once")
+
+	change.GitHubAction = &v1alpha1.GitHubActionChange{Name: "myorg/missing"}
+	err = o.ApplyChanges(dir, "https://github.com/myorg/myrepo", change)
+	require.Error(t, err, "should fail as the action is not used")
+}
+
+// fakeCommitGitService finds the commits of refs which the fake driver does not support
+type fakeCommitGitService struct {
+	scm.GitService
+	commits map[string]string
+	date    time.Time
+	finds   []string
+}
+
+func (s *fakeCommitGitService) FindCommit(_ context.Context, repo, ref string) (*scm.Commit, *scm.Response, error) {
+	key := repo + "@" + ref
+	s.finds = append(s.finds, key)
+	sha, ok := s.commits[key]
+	if !ok {
+		return nil, nil, scm.ErrNotFound
+	}
+	return &scm.Commit{Sha: sha, Committer: scm.Signature{Date: s.date}}, nil, nil
+}
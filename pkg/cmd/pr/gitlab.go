@@ -0,0 +1,44 @@
+package pr
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// GitKindGitLab the git kind of GitLab servers
+const GitKindGitLab = "gitlab"
+
+// gitLabMergeRequestRegex matches the reference to the merge request in the message of a GitLab merge commit
+var gitLabMergeRequestRegex = regexp.MustCompile(`See merge request [\w./-]*!(\d+)`)
+
+// IsGitLab returns true if the git kind is GitLab
+func (o *Options) IsGitLab() bool {
+	return o.GitKind == GitKindGitLab || o.ScmClientFactory.GitKind == GitKindGitLab
+}
+
+// findGitLabUsername returns the username of the author of a GitLab commit. GitLab commits only have the name of the
+// author so the name is only used if it is also a username, otherwise an empty string is returned with a warning
+func (o *Options) findGitLabUsername(ctx context.Context, scmClient *scm.Client, sha, name string) (string, error) {
+	if name == "" {
+		o.Logger().Warnf("no author found for commit %s", sha)
+		return "", nil
+	}
+	// usernames cannot contain spaces so there is no need to search for names such as John Smith
+	var user *scm.User
+	var err error
+	if !strings.Contains(name, " ") {
+		user, _, err = scmClient.Users.FindLogin(ctx, name)
+	}
+	if errors.Is(err, scm.ErrNotFound) || (err == nil && user == nil) {
+		o.Logger().Warnf("cannot assign the author %s of commit %s as GitLab commits only have the name of the author which is not a username", name, sha)
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return user.Login, nil
+}
@@ -0,0 +1,114 @@
+package pr_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/gitlab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssignAuthorToGitLabMergeRequest(t *testing.T) {
+	users := map[string]int{"foo": 10, "mr-author": 30}
+	commits := map[string]string{
+		"merge-sha":  "Merge branch 'feature' into 'main'\n\nCloses #7\n\nSee merge request myorg/mysource!3",
+		"direct-sha": "fix: pushed directly",
+	}
+	var assigneeIDs []int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var out interface{}
+		switch path := r.URL.EscapedPath(); {
+		case path == "/api/v4/users":
+			found := []map[string]interface{}{}
+			if id, ok := users[r.URL.Query().Get("search")]; ok {
+				found = append(found, map[string]interface{}{"id": id, "username": r.URL.Query().Get("search")})
+			}
+			out = found
+		case path == "/api/v4/projects/1":
+			out = map[string]interface{}{"id": 1, "path": "myrepo", "path_with_namespace": "myorg/myrepo"}
+		case path == "/api/v4/projects/myorg%2Fmysource/repository/commits/merge-sha" || path == "/api/v4/projects/myorg%2Fmysource/repository/commits/direct-sha":
+			sha := path[len("/api/v4/projects/myorg%2Fmysource/repository/commits/"):]
+			out = map[string]interface{}{"id": sha, "message": commits[sha], "author_name": "Test Author"}
+		case path == "/api/v4/projects/myorg%2Fmysource/merge_requests/3" || path == "/api/v4/projects/myorg%2Fmyrepo/merge_requests/1":
+			if r.Method == http.MethodPut {
+				body := struct {
+					AssigneeIDs []int `json:"assignee_ids"`
+				}{}
+				err := json.NewDecoder(r.Body).Decode(&body)
+				require.NoError(t, err, "failed to decode merge request update")
+				assigneeIDs = body.AssigneeIDs
+			}
+			out = map[string]interface{}{
+				"iid":               1,
+				"source_project_id": 1,
+				"target_project_id": 1,
+				"author":            map[string]interface{}{"id": 30, "username": "mr-author"},
+			}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(out)
+	}))
+	defer server.Close()
+
+	scmClient, err := gitlab.New(server.URL)
+	require.NoError(t, err, "failed to create gitlab client")
+
+	_, o := pr.NewCmdPullRequest()
+	o.ScmClient = scmClient
+	o.ScmClientFactory.ScmClient = scmClient
+	o.ScmClientFactory.GitServerURL = "https://gitlab.com"
+	o.GitKind = pr.GitKindGitLab
+
+	ruleURL := "https://gitlab.com/myorg/myrepo"
+	pipelineURL := "https://gitlab.com/myorg/mysource"
+	pullRequest := &scm.PullRequest{Number: 1}
+	rule := &v1alpha1.Rule{PullRequestAssignees: []string{"foo"}, AssignAuthorToPullRequests: true}
+
+	testCases := []struct {
+		sha      string
+		expected []int
+	}{
+		{sha: "merge-sha", expected: []int{10, 30}},
+		// gitlab commits only have the name of the author so the author is not assigned
+		{sha: "direct-sha", expected: []int{10}},
+	}
+	for _, tc := range testCases {
+		assigneeIDs = nil
+		err = o.AssignUsersToPullRequestIssue(rule, pullRequest, ruleURL, pipelineURL, tc.sha, o.GitKind)
+		require.NoError(t, err, "failed to assign users for commit %s", tc.sha)
+		sort.Ints(assigneeIDs)
+		assert.Equal(t, tc.expected, assigneeIDs, "assignee IDs for commit %s", tc.sha)
+	}
+
+	err = o.AssignUsersToIssue(pullRequest, []string{"ghost"}, ruleURL, o.GitKind)
+	require.Error(t, err, "should fail for a user which is not a GitLab username")
+	assert.Contains(t, err.Error(), "GitLab assigns users by ID")
+
+	err = o.RequestReview(pullRequest, []string{"foo"}, ruleURL, o.GitKind)
+	require.Error(t, err, "should fail to request reviews on GitLab")
+	assert.Contains(t, err.Error(), "pullRequestAssignees")
+}
+
+func TestMergeCommitPullRequestNumber(t *testing.T) {
+	testCases := map[string]string{
+		"Merge pull request #12 from myorg/mybranch": "12",
+		"feat: something (#5)":                       "5",
+		"Merge branch 'feature' into 'main'\n\nCloses #7\n\nSee merge request a/b!42": "42",
+	}
+	for message, expected := range testCases {
+		number, err := pr.MergeCommitPullRequestNumber(&scm.Commit{Message: message})
+		require.NoError(t, err, "failed to find number in %q", message)
+		assert.Equal(t, expected, number, "number in %q", message)
+	}
+}
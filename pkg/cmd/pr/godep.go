@@ -4,17 +4,38 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/options"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
-	"github.com/jenkins-x/jx-logging/v3/pkg/log"
 
 	"github.com/shurcooL/githubv4"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
 	"golang.org/x/oauth2"
 )
 
+const (
+	// GoSumTidy runs go mod tidy after upgrading a package
+	GoSumTidy = "tidy"
+
+	// GoSumDownloadOnly runs go mod download for just the upgraded package so only its go.sum entries are added
+	GoSumDownloadOnly = "download-only"
+)
+
+var (
+	// GoSumModes the valid values of the goSum field of a go change
+	GoSumModes = []string{GoSumTidy, GoSumDownloadOnly}
+
+	commitSHARegex = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+)
+
 // SparseCheckoutPatternsGo return the patterns to check out sparsely
 func (o *Options) SparseCheckoutPatternsGo() []string {
 	return []string{"/go.mod", "/go.sum"}
@@ -23,7 +44,20 @@ func (o *Options) SparseCheckoutPatternsGo() []string {
 // GoFindURLs find the git URLs for the given go dependency change
 func (o *Options) GoFindURLs(rule *v1alpha1.Rule, gc *v1alpha1.GoChange) error {
 	ctx := context.Background()
+	client := o.GitHubGraphQLClient(ctx)
 
+	server := o.GitServer()
+	for _, owner := range gc.Owners {
+		if err := o.queryRepositoriesWithGoMod(ctx, client, rule, gc, server, owner); err != nil {
+			return fmt.Errorf("failed to query repositories: %w", err)
+		}
+	}
+	return nil
+}
+
+// GitHubGraphQLClient returns the GitHub GraphQL client creating it from the git token if it has not been set. The
+// client uses the GraphQL API of the git server so GitHub Enterprise servers are supported
+func (o *Options) GitHubGraphQLClient(ctx context.Context) *githubv4.Client {
 	if o.GraphQLClient == nil {
 		token := o.ScmClientFactory.GitToken
 		if token == "" {
@@ -34,24 +68,72 @@ func (o *Options) GoFindURLs(rule *v1alpha1.Rule, gc *v1alpha1.GoChange) error {
 		}
 		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
 		hc := oauth2.NewClient(ctx, ts)
-		o.GraphQLClient = githubv4.NewClient(hc)
-	}
-
-	for _, owner := range gc.Owners {
-		if err := queryRepositoriesWithGoMod(ctx, o.GraphQLClient, rule, gc, owner); err != nil {
-			return fmt.Errorf("failed to query repositories: %w", err)
-		}
+		o.GraphQLClient = githubv4.NewEnterpriseClient(GitHubGraphQLURL(o.GitServer()), hc)
 	}
-	return nil
+	return o.GraphQLClient
 }
 
 // ApplyGo applies the go change
 func (o *Options) ApplyGo(dir, gitURL string, gc *v1alpha1.GoChange) error {
 	o.CommitTitle = "chore(deps): upgrade go dependencies"
 
-	log.Logger().Infof("finding all the go dependences for repository: %s", gitURL)
+	goSum := gc.GoSum
+	if goSum == "" {
+		goSum = GoSumTidy
+	}
+	if stringhelpers.StringArrayIndex(GoSumModes, goSum) < 0 {
+		return fmt.Errorf("invalid goSum %s for repository %s, should be one of %s", goSum, gitURL, strings.Join(GoSumModes, ", "))
+	}
+
+	runner := o.CommandRunner
+	if runner == nil {
+		runner = cmdrunner.QuietCommandRunner
+	}
+
+	// a sha is only pinned as a pseudo-version of the package so that changes which only upgrade packages still work
+	version := o.Version
+	pseudo := gc.Pseudo || (gc.Package != "" && IsCommitSHA(version))
+	if pseudo {
+		if gc.Package == "" {
+			return fmt.Errorf("no package for go change %#v", gc)
+		}
+		if version == "" {
+			return options.MissingOption("version")
+		}
+		var err error
+		version, err = o.GoPseudoVersion(gc.Package, version)
+		if err != nil {
+			return fmt.Errorf("failed to find the pseudo-version of %s for repository %s: %w", gc.Package, gitURL, err)
+		}
+	}
+
+	if gc.UpdateReplace || gc.IncludeIndirect {
+		if version == "" {
+			return options.MissingOption("version")
+		}
+		modified, err := o.UpdateGoMod(filepath.Join(dir, "go.mod"), gc, version)
+		if err != nil {
+			return fmt.Errorf("failed to update go.mod of repository %s: %w", gitURL, err)
+		}
+		if modified && gc.RunTidy {
+			c := &cmdrunner.Command{
+				Dir:  dir,
+				Name: "go",
+				Args: []string{"mod", "tidy"},
+			}
+			_, err = runner(c)
+			if err != nil {
+				o.Logger().Warnf("failed to run command %s on %s: %s", c.CLI(), gitURL, err.Error())
+			}
+		}
+	}
+
+	if pseudo {
+		return o.PinGoPseudoVersion(dir, gitURL, gc, version, goSum)
+	}
+
+	o.Logger().Infof("finding all the go dependences for repository: %s", gitURL)
 
-	runner := cmdrunner.QuietCommandRunner
 	c := &cmdrunner.Command{
 		Dir:  dir,
 		Name: "go",
@@ -59,7 +141,7 @@ func (o *Options) ApplyGo(dir, gitURL string, gc *v1alpha1.GoChange) error {
 	}
 	text, err := runner(c)
 	if err != nil {
-		log.Logger().Warnf("failed to run command %s on %s", c.CLI(), gitURL)
+		o.Logger().Warnf("failed to run command %s on %s", c.CLI(), gitURL)
 		return nil
 	}
 
@@ -78,23 +160,207 @@ func (o *Options) ApplyGo(dir, gitURL string, gc *v1alpha1.GoChange) error {
 			}
 			_, err = runner(c)
 			if err != nil {
-				log.Logger().Warnf("failed to update %s: %s", line, err.Error())
+				o.Logger().Warnf("failed to update %s: %s", line, err.Error())
 			}
 			c = &cmdrunner.Command{
 				Dir:  dir,
 				Name: "go",
 				Args: []string{"mod", "tidy"},
 			}
+			if goSum == GoSumDownloadOnly {
+				c.Args = []string{"mod", "download", line}
+			}
 			_, err = runner(c)
 			if err != nil {
-				log.Logger().Warnf("failed to update %s: %s", line, err.Error())
+				o.Logger().Warnf("failed to update %s: %s", line, err.Error())
 			}
 		}
 	}
 	return nil
 }
 
-func queryRepositoriesWithGoMod(ctx context.Context, client *githubv4.Client, rule *v1alpha1.Rule, gc *v1alpha1.GoChange, owner string) error {
+// IsCommitSHA returns true if the version looks like an abbreviated or full git commit sha rather than a tag. Versions
+// of only digits such as dates are not treated as a sha
+func IsCommitSHA(version string) bool {
+	return commitSHARegex.MatchString(version) && strings.ContainsAny(version, "abcdef")
+}
+
+// GoPseudoVersion returns the Go pseudo-version of the commit of the module such as v0.0.0-20240102150405-abcdef123456.
+// The commit time is found using the git provider of the repository of the module
+func (o *Options) GoPseudoVersion(modulePath, sha string) (string, error) {
+	prefix, pathMajor, ok := module.SplitPathVersion(modulePath)
+	if !ok {
+		return "", fmt.Errorf("invalid module path %s", modulePath)
+	}
+	parts := strings.Split(prefix, "/")
+	if len(parts) < 3 {
+		return "", fmt.Errorf("module path %s is not a git repository path such as github.com/owner/repo", modulePath)
+	}
+	gitURL := "https://" + strings.Join(parts[:3], "/")
+	scmClient, repoFullName, err := o.GetScmClient(gitURL, o.GitKind)
+	if err != nil {
+		return "", fmt.Errorf("failed to create ScmClient: %w", err)
+	}
+	commit, _, err := scmClient.Git.FindCommit(context.Background(), repoFullName, sha)
+	if err != nil {
+		return "", fmt.Errorf("failed to find commit %s in repository %s: %w", sha, repoFullName, err)
+	}
+	rev := commit.Sha
+	if rev == "" {
+		rev = sha
+	}
+	if len(rev) < 12 {
+		return "", fmt.Errorf("the sha %s of the commit in repository %s is too short for a pseudo-version", rev, repoFullName)
+	}
+	t := commit.Committer.Date
+	if t.IsZero() {
+		t = commit.Author.Date
+	}
+	if t.IsZero() {
+		return "", fmt.Errorf("no date for commit %s in repository %s", rev, repoFullName)
+	}
+	return module.PseudoVersion(module.PathMajorPrefix(pathMajor), "", t, rev[:12]), nil
+}
+
+// PinGoPseudoVersion sets the require of the package in go.mod to the pseudo-version. The go.sum has no entries for the
+// pseudo-version yet so it is updated by running "go mod tidy", or "go mod download" of the package for the
+// download-only goSum mode. If that fails the go.sum is left for the Pull Request checks to report
+func (o *Options) PinGoPseudoVersion(dir, gitURL string, gc *v1alpha1.GoChange, version, goSum string) error {
+	modified, err := o.RequireGoModule(filepath.Join(dir, "go.mod"), gc.Package, version)
+	if err != nil {
+		return fmt.Errorf("failed to update go.mod of repository %s: %w", gitURL, err)
+	}
+	if !modified {
+		return nil
+	}
+	runner := o.CommandRunner
+	if runner == nil {
+		runner = cmdrunner.QuietCommandRunner
+	}
+	c := &cmdrunner.Command{
+		Dir:  dir,
+		Name: "go",
+		Args: []string{"mod", "tidy"},
+	}
+	if goSum == GoSumDownloadOnly {
+		c.Args = []string{"mod", "download", gc.Package}
+	}
+	_, err = runner(c)
+	if err != nil {
+		o.Logger().Warnf("failed to run command %s on %s so go.sum may be missing %s %s: %s", c.CLI(), gitURL, gc.Package, version, err.Error())
+	}
+	return nil
+}
+
+// RequireGoModule sets the version of the requires of the module in the go.mod file using modfile so that its
+// formatting and comments are kept. Returns true if the file was modified
+func (o *Options) RequireGoModule(path, modulePath, version string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to load file %s: %w", path, err)
+	}
+	f, err := modfile.Parse(path, data, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	modified := false
+	for _, r := range f.Require {
+		if r.Mod.Path == modulePath && r.Mod.Version != version {
+			modified = true
+		}
+	}
+	if !modified {
+		return false, nil
+	}
+	err = f.AddRequire(modulePath, version)
+	if err != nil {
+		return false, fmt.Errorf("failed to require %s %s: %w", modulePath, version, err)
+	}
+	f.Cleanup()
+	data, err = f.Format()
+	if err != nil {
+		return false, fmt.Errorf("failed to format %s: %w", path, err)
+	}
+	err = os.WriteFile(path, data, files.DefaultFileWritePermissions)
+	if err != nil {
+		return false, fmt.Errorf("failed to save file %s: %w", path, err)
+	}
+	o.Logger().Infof("modified file %s setting %s to %s", info(path), modulePath, version)
+	return true, nil
+}
+
+// UpdateGoMod sets the version of the package in the replace directives and indirect requires of the go.mod file
+// depending on the UpdateReplace and IncludeIndirect flags of the change. The file is edited with modfile so that its
+// formatting and comments are kept. Returns true if the file was modified
+func (o *Options) UpdateGoMod(path string, gc *v1alpha1.GoChange, version string) (bool, error) {
+	if gc.Package == "" {
+		return false, fmt.Errorf("no package for go change %#v", gc)
+	}
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to load file %s: %w", path, err)
+	}
+	f, err := modfile.Parse(path, data, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	modified := false
+	if gc.IncludeIndirect {
+		var requires []string
+		for _, r := range f.Require {
+			if r.Indirect && r.Mod.Version != version && strings.Contains(r.Mod.Path, gc.Package) {
+				requires = append(requires, r.Mod.Path)
+			}
+		}
+		for _, p := range requires {
+			err = f.AddRequire(p, version)
+			if err != nil {
+				return false, fmt.Errorf("failed to require %s %s: %w", p, version, err)
+			}
+			modified = true
+		}
+	}
+	if gc.UpdateReplace {
+		var replaces []modfile.Replace
+		for _, r := range f.Replace {
+			// replacements with a local directory have no version
+			if r.New.Version == "" || r.New.Version == version {
+				continue
+			}
+			if strings.Contains(r.Old.Path, gc.Package) || strings.Contains(r.New.Path, gc.Package) {
+				replaces = append(replaces, *r)
+			}
+		}
+		for _, r := range replaces {
+			err = f.AddReplace(r.Old.Path, r.Old.Version, r.New.Path, version)
+			if err != nil {
+				return false, fmt.Errorf("failed to replace %s with %s %s: %w", r.Old.Path, r.New.Path, version, err)
+			}
+			modified = true
+		}
+	}
+	if !modified {
+		return false, nil
+	}
+
+	f.Cleanup()
+	data, err = f.Format()
+	if err != nil {
+		return false, fmt.Errorf("failed to format %s: %w", path, err)
+	}
+	err = os.WriteFile(path, data, files.DefaultFileWritePermissions)
+	if err != nil {
+		return false, fmt.Errorf("failed to save file %s: %w", path, err)
+	}
+	o.Logger().Infof("modified file %s setting %s to %s", info(path), gc.Package, version)
+	return true, nil
+}
+
+func (o *Options) queryRepositoriesWithGoMod(ctx context.Context, client *githubv4.Client, rule *v1alpha1.Rule, gc *v1alpha1.GoChange, server, owner string) error {
 	var q struct {
 		Organisation struct {
 			Repositories struct {
@@ -138,14 +404,23 @@ func queryRepositoriesWithGoMod(ctx context.Context, client *githubv4.Client, ru
 				continue
 			}
 			if edge.Node.IsArchived {
-				log.Logger().Infof("ignoring archived repository: %s/%s", owner, name)
+				o.Logger().Infof("ignoring archived repository: %s/%s", owner, name)
 				continue
 			}
 			requirementsText := stripGoModuleLines(text)
 			if strings.Contains(requirementsText, gc.Package) {
-				log.Logger().Infof("about to process %s/%s", owner, name)
+				matches, err := o.MatchesGoModRequire(text, gc)
+				if err != nil {
+					o.Logger().Warnf("ignoring repository %s/%s: %s", owner, name, err.Error())
+					continue
+				}
+				if !matches {
+					o.Logger().Infof("ignoring repository %s/%s as it does not directly require a matching version of %s", owner, name, gc.Package)
+					continue
+				}
+				o.Logger().Infof("about to process %s/%s", owner, name)
 
-				u := fmt.Sprintf("https://github.com/%s/%s", owner, name)
+				u := stringhelpers.UrlJoin(server, owner, name)
 				if stringhelpers.StringArrayIndex(rule.URLs, u) < 0 && stringhelpers.StringArrayIndex(rule.URLs, u+".git") < 0 {
 					rule.URLs = append(rule.URLs, u)
 				}
@@ -160,6 +435,42 @@ func queryRepositoriesWithGoMod(ctx context.Context, client *githubv4.Client, ru
 	return nil
 }
 
+// MatchesGoModRequire returns true if the go.mod text matches the DirectRequire and VersionRange filters of the change
+func (o *Options) MatchesGoModRequire(text string, gc *v1alpha1.GoChange) (bool, error) {
+	if !gc.DirectRequire && gc.VersionRange == "" {
+		return true, nil
+	}
+	var constraint *semver.Constraints
+	if gc.VersionRange != "" {
+		var err error
+		constraint, err = semver.NewConstraint(gc.VersionRange)
+		if err != nil {
+			return false, fmt.Errorf("failed to parse version range %s: %w", gc.VersionRange, err)
+		}
+	}
+	f, err := modfile.ParseLax("go.mod", []byte(text), nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+	for _, r := range f.Require {
+		if r.Indirect || !strings.Contains(r.Mod.Path, gc.Package) {
+			continue
+		}
+		if constraint == nil {
+			return true, nil
+		}
+		v, err := semver.NewVersion(r.Mod.Version)
+		if err != nil {
+			o.Logger().Debugf("ignoring invalid version %s of %s: %s", r.Mod.Version, r.Mod.Path, err.Error())
+			continue
+		}
+		if constraint.Check(v) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func stripGoModuleLines(text string) string {
 	buf := &strings.Builder{}
 	lines := strings.Split(text, "\n")
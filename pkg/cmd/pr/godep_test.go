@@ -0,0 +1,302 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner/fakerunner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesGoModRequire(t *testing.T) {
+	direct := `module github.com/myorg/direct
+
+go 1.24
+
+require github.com/myorg/mylib v1.3.0
+`
+	indirect := `module github.com/myorg/indirect
+
+go 1.24
+
+require github.com/myorg/mylib v1.3.0 // indirect
+`
+	replaced := `module github.com/myorg/replaced
+
+go 1.24
+
+replace github.com/myorg/mylib => ../mylib
+`
+	testCases := []struct {
+		name     string
+		text     string
+		gc       v1alpha1.GoChange
+		expected bool
+		err      bool
+	}{
+		{name: "no-filter-indirect", text: indirect, expected: true},
+		{name: "direct", text: direct, gc: v1alpha1.GoChange{DirectRequire: true}, expected: true},
+		{name: "indirect", text: indirect, gc: v1alpha1.GoChange{DirectRequire: true}},
+		{name: "replaced", text: replaced, gc: v1alpha1.GoChange{DirectRequire: true}},
+		{name: "in-range", text: direct, gc: v1alpha1.GoChange{VersionRange: ">= 1.2.0, < 2.0.0"}, expected: true},
+		{name: "out-of-range", text: direct, gc: v1alpha1.GoChange{VersionRange: "< 1.3.0"}},
+		{name: "bad-range", text: direct, gc: v1alpha1.GoChange{VersionRange: "cheese"}, err: true},
+	}
+
+	o := &pr.Options{}
+	for _, tc := range testCases {
+		gc := tc.gc
+		gc.Package = "github.com/myorg/mylib"
+		actual, err := o.MatchesGoModRequire(tc.text, &gc)
+		if tc.err {
+			require.Error(t, err, "should fail for test %s", tc.name)
+			continue
+		}
+		require.NoError(t, err, "failed for test %s", tc.name)
+		assert.Equal(t, tc.expected, actual, "matches for test %s", tc.name)
+	}
+}
+
+func TestApplyGoSum(t *testing.T) {
+	testCases := []struct {
+		goSum    string
+		expected string
+		err      bool
+	}{
+		{goSum: "", expected: "go mod tidy"},
+		{goSum: pr.GoSumTidy, expected: "go mod tidy"},
+		{goSum: pr.GoSumDownloadOnly, expected: "go mod download github.com/myorg/mylib"},
+		{goSum: "cheese", err: true},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		runner := &fakerunner.FakeRunner{
+			CommandRunner: func(c *cmdrunner.Command) (string, error) {
+				if c.Name == "go" && c.Args[0] == "list" {
+					return "github.com/myorg/myapp\ngithub.com/myorg/mylib\ngithub.com/other/lib\n", nil
+				}
+				return "", nil
+			},
+		}
+		o := &pr.Options{}
+		o.CommandRunner = runner.Run
+		gc := &v1alpha1.GoChange{
+			UpgradePackages: v1alpha1.Pattern{Includes: []string{"github.com/myorg/mylib"}},
+			GoSum:           tc.goSum,
+		}
+		err := o.ApplyGo(dir, "https://github.com/myorg/myapp", gc)
+		if tc.err {
+			require.Error(t, err, "should fail for goSum %s", tc.goSum)
+			continue
+		}
+		require.NoError(t, err, "failed to apply go change for goSum %s", tc.goSum)
+
+		runner.ExpectResults(t,
+			fakerunner.FakeResult{CLI: "go list -m -f {{.Path}} all"},
+			fakerunner.FakeResult{CLI: "go get -u=patch github.com/myorg/mylib"},
+			fakerunner.FakeResult{CLI: tc.expected},
+		)
+	}
+}
+
+func TestUpdateGoMod(t *testing.T) {
+	source := `module github.com/myorg/myapp
+
+go 1.24
+
+require (
+	github.com/myorg/mylib v1.2.0
+	github.com/myorg/mylib/extra v1.2.0 // indirect
+	github.com/other/lib v0.1.0 // indirect
+)
+
+// lets use our fork until the fix is released upstream
+replace github.com/upstream/mylib => github.com/myorg/mylib v1.2.0
+
+replace github.com/myorg/mylib/local => ../local
+`
+	testCases := []struct {
+		name     string
+		gc       v1alpha1.GoChange
+		expected string
+	}{
+		{
+			name: "indirect",
+			gc:   v1alpha1.GoChange{IncludeIndirect: true},
+			expected: strings.Replace(source, "github.com/myorg/mylib/extra v1.2.0 // indirect",
+				"github.com/myorg/mylib/extra v1.3.0 // indirect", 1),
+		},
+		{
+			name: "replace",
+			gc:   v1alpha1.GoChange{UpdateReplace: true},
+			expected: strings.Replace(source, "=> github.com/myorg/mylib v1.2.0",
+				"=> github.com/myorg/mylib v1.3.0", 1),
+		},
+		{
+			name:     "none",
+			expected: source,
+		},
+	}
+
+	o := &pr.Options{}
+	for _, tc := range testCases {
+		path := filepath.Join(t.TempDir(), "go.mod")
+		err := os.WriteFile(path, []byte(source), 0o600)
+		require.NoError(t, err, "failed to write go.mod for %s", tc.name)
+
+		tc.gc.Package = "github.com/myorg/mylib"
+		modified, err := o.UpdateGoMod(path, &tc.gc, "1.3.0")
+		require.NoError(t, err, "failed to update go.mod for %s", tc.name)
+		assert.Equal(t, tc.expected != source, modified, "modified for %s", tc.name)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err, "failed to read go.mod for %s", tc.name)
+		assert.Equal(t, tc.expected, string(data), "go.mod for %s", tc.name)
+	}
+}
+
+func TestApplyGoRunTidy(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/myorg/myapp\n\ngo 1.24\n\nreplace github.com/upstream/mylib => github.com/myorg/mylib v1.2.0\n"), 0o600)
+	require.NoError(t, err, "failed to write go.mod")
+
+	runner := &fakerunner.FakeRunner{}
+	o := &pr.Options{}
+	o.CommandRunner = runner.Run
+	o.Version = "1.3.0"
+	gc := &v1alpha1.GoChange{Package: "github.com/myorg/mylib", UpdateReplace: true, RunTidy: true}
+	err = o.ApplyGo(dir, "https://github.com/myorg/myapp", gc)
+	require.NoError(t, err, "failed to apply go change")
+
+	runner.ExpectResults(t,
+		fakerunner.FakeResult{CLI: "go mod tidy"},
+		fakerunner.FakeResult{CLI: "go list -m -f {{.Path}} all"},
+	)
+}
+
+func TestApplyGoUpgradePackagesWithSHA(t *testing.T) {
+	runner := &fakerunner.FakeRunner{
+		CommandRunner: func(c *cmdrunner.Command) (string, error) {
+			if c.CLI() == "go list -m -f {{.Path}} all" {
+				return "github.com/myorg/myapp\ngithub.com/myorg/mylib\n", nil
+			}
+			return "", nil
+		},
+	}
+	o := &pr.Options{}
+	o.CommandRunner = runner.Run
+	o.Version = "abcdef1234567890abcdef1234567890abcdef12"
+	gc := &v1alpha1.GoChange{UpgradePackages: v1alpha1.Pattern{Includes: []string{"github.com/myorg/mylib"}}}
+	err := o.ApplyGo(t.TempDir(), "https://github.com/myorg/myapp", gc)
+	require.NoError(t, err, "failed to apply go change without a package")
+
+	runner.ExpectResults(t,
+		fakerunner.FakeResult{CLI: "go list -m -f {{.Path}} all"},
+		fakerunner.FakeResult{CLI: "go get -u=patch github.com/myorg/mylib"},
+		fakerunner.FakeResult{CLI: "go mod tidy"},
+	)
+}
+
+func TestIsCommitSHA(t *testing.T) {
+	assert.True(t, pr.IsCommitSHA("abcdef1234567890abcdef1234567890abcdef12"), "full sha")
+	assert.True(t, pr.IsCommitSHA("abc1234"), "abbreviated sha")
+	assert.False(t, pr.IsCommitSHA("1.3.0"), "version")
+	assert.False(t, pr.IsCommitSHA("v1.3.0"), "tag")
+	assert.False(t, pr.IsCommitSHA("20240102"), "date")
+	assert.False(t, pr.IsCommitSHA("abc12"), "too short")
+}
+
+func TestApplyGoPseudoVersion(t *testing.T) {
+	source := `module github.com/myorg/myapp
+
+go 1.24
+
+require (
+	github.com/myorg/mylib v1.2.0
+	github.com/myorg/other/v2 v2.1.0
+)
+`
+	sha := "abcdef1234567890abcdef1234567890abcdef12"
+	testCases := []struct {
+		name     string
+		pkg      string
+		version  string
+		pseudo   bool
+		expected string
+		commands []string
+	}{
+		{
+			name:     "tagged",
+			pkg:      "github.com/myorg/mylib",
+			version:  "1.3.0",
+			expected: source,
+			commands: []string{"go list -m -f {{.Path}} all"},
+		},
+		{
+			name:     "sha",
+			pkg:      "github.com/myorg/mylib",
+			version:  sha,
+			expected: strings.Replace(source, "mylib v1.2.0", "mylib v0.0.0-20240102150405-abcdef123456", 1),
+			commands: []string{"go mod tidy"},
+		},
+		{
+			name:     "major-version",
+			pkg:      "github.com/myorg/other/v2",
+			version:  "abcdef1",
+			pseudo:   true,
+			expected: strings.Replace(source, "other/v2 v2.1.0", "other/v2 v2.0.0-20240102150405-abcdef123456", 1),
+			commands: []string{"go mod tidy"},
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "go.mod")
+		err := os.WriteFile(path, []byte(source), 0o600)
+		require.NoError(t, err, "failed to write go.mod for %s", tc.name)
+
+		scmClient, _ := fake.NewDefault()
+		scmClient.Git = &fakeCommitGitService{
+			GitService: scmClient.Git,
+			commits: map[string]string{
+				"myorg/mylib@" + sha:  sha,
+				"myorg/other@abcdef1": sha,
+			},
+			date: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+		}
+		runner := &fakerunner.FakeRunner{}
+		_, o := pr.NewCmdPullRequest()
+		o.ScmClient = scmClient
+		o.ScmClientFactory.ScmClient = scmClient
+		o.ScmClientFactory.GitServerURL = "https://github.com"
+		o.GitKind = "fake"
+		o.CommandRunner = runner.Run
+		o.Version = tc.version
+		gc := &v1alpha1.GoChange{
+			Package:         tc.pkg,
+			UpgradePackages: v1alpha1.Pattern{Includes: []string{tc.pkg}},
+			Pseudo:          tc.pseudo,
+		}
+		err = o.ApplyGo(dir, "https://github.com/myorg/myapp", gc)
+		require.NoError(t, err, "failed to apply go change for %s", tc.name)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err, "failed to read go.mod for %s", tc.name)
+		assert.Equal(t, tc.expected, string(data), "go.mod for %s", tc.name)
+
+		var results []fakerunner.FakeResult
+		for _, c := range tc.commands {
+			results = append(results, fakerunner.FakeResult{CLI: c})
+		}
+		runner.ExpectResults(t, results...)
+	}
+}
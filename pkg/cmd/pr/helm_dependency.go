@@ -0,0 +1,134 @@
+package pr
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/yargevad/filepathx"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// DefaultHelmDependencyGlobs the files of a helm dependency change if none are specified
+var DefaultHelmDependencyGlobs = []string{"Chart.yaml", "charts/*/Chart.yaml"}
+
+// ApplyHelmDependency sets the version of the dependency in the dependencies of the Chart.yaml files. The files are
+// modified in place so that comments and the other fields of the dependency are kept. It is an error if no file has
+// the dependency unless CreateMissing is enabled
+func (o *Options) ApplyHelmDependency(dir, gitURL string, change v1alpha1.Change, dependency *v1alpha1.HelmDependencyChange) error {
+	if dependency.Name == "" {
+		return fmt.Errorf("no name for helm dependency change %#v", change)
+	}
+	if dependency.CreateMissing && dependency.Repository == "" {
+		return fmt.Errorf("no repository to create the missing helm dependency %s", dependency.Name)
+	}
+	version, err := o.changeValue(gitURL, change, dependency.Version)
+	if err != nil {
+		return err
+	}
+
+	globs := HelmDependencyGlobs(dependency)
+	var paths []string
+	for _, g := range globs {
+		matches, err := filepathx.Glob(filepath.Join(dir, g))
+		if err != nil {
+			return fmt.Errorf("failed to evaluate glob %s: %w", g, err)
+		}
+		paths = append(paths, matches...)
+	}
+	if len(paths) == 0 {
+		_, err = o.handleMissingFile(change, strings.Join(globs, ", "), gitURL, false)
+		return err
+	}
+
+	matched := 0
+	for _, f := range paths {
+		node, err := yaml.ReadFile(f)
+		if err != nil {
+			return fmt.Errorf("failed to load YAML file %s: %w", f, err)
+		}
+		modified, found, err := o.setHelmDependencyVersion(change, node, dependency, version, f)
+		if err != nil {
+			return err
+		}
+		if found {
+			matched++
+		}
+		if !modified {
+			continue
+		}
+		err = yaml.WriteFile(node, f)
+		if err != nil {
+			return fmt.Errorf("failed to save file %s: %w", f, err)
+		}
+		o.Logger().Infof("modified file %s with helm dependency %s version %s", info(f), dependency.Name, version)
+	}
+	if matched == 0 {
+		return fmt.Errorf("the helm dependency %s is not in the files %s of repository %s", dependency.Name, strings.Join(globs, ", "), gitURL)
+	}
+	return nil
+}
+
+// HelmDependencyGlobs returns the files of the helm dependency change
+func HelmDependencyGlobs(dependency *v1alpha1.HelmDependencyChange) []string {
+	if len(dependency.Globs) == 0 {
+		return DefaultHelmDependencyGlobs
+	}
+	return dependency.Globs
+}
+
+// setHelmDependencyVersion sets the version of the dependency in the Chart.yaml node returning whether the node was
+// modified and whether the dependency was found
+func (o *Options) setHelmDependencyVersion(change v1alpha1.Change, node *yaml.RNode, dependency *v1alpha1.HelmDependencyChange, version, path string) (bool, bool, error) {
+	dependencies, err := node.Pipe(yaml.Lookup("dependencies"))
+	if err != nil {
+		return false, false, fmt.Errorf("failed to find dependencies in file %s: %w", path, err)
+	}
+	if dependencies != nil {
+		if dependencies.YNode().Kind != yaml.SequenceNode {
+			return false, false, fmt.Errorf("the dependencies in file %s is not a list", path)
+		}
+		for _, d := range dependencies.YNode().Content {
+			if d.Kind != yaml.MappingNode || yamlMappingValue(d, "name") == nil || yamlMappingValue(d, "name").Value != dependency.Name {
+				continue
+			}
+			if dependency.Repository != "" {
+				repository := yamlMappingValue(d, "repository")
+				if repository == nil || strings.TrimSuffix(repository.Value, "/") != strings.TrimSuffix(dependency.Repository, "/") {
+					continue
+				}
+			}
+			current := yamlMappingValue(d, "version")
+			if current == nil {
+				d.Content = append(d.Content, yamlString("version"), yamlString(version))
+				return true, true, nil
+			}
+			if !o.matchesExpectedCurrent(change, current.Value, dependency.Name+" in "+path) || current.Value == version {
+				return false, true, nil
+			}
+			current.Value = version
+			current.Tag = yaml.NodeTagString
+			return true, true, nil
+		}
+	}
+	if !dependency.CreateMissing {
+		o.Logger().Debugf("the helm dependency %s is not in file %s", dependency.Name, path)
+		return false, false, nil
+	}
+
+	dependencies, err = node.Pipe(yaml.LookupCreate(yaml.SequenceNode, "dependencies"))
+	if err != nil {
+		return false, false, fmt.Errorf("failed to create dependencies in file %s: %w", path, err)
+	}
+	dependencies.YNode().Content = append(dependencies.YNode().Content, &yaml.Node{
+		Kind: yaml.MappingNode,
+		Content: []*yaml.Node{
+			yamlString("name"), yamlString(dependency.Name),
+			yamlString("version"), yamlString(version),
+			yamlString("repository"), yamlString(dependency.Repository),
+		},
+	})
+	o.Logger().Infof("adding the helm dependency %s to file %s", dependency.Name, info(path))
+	return true, true, nil
+}
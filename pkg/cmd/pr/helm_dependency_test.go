@@ -0,0 +1,113 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyHelmDependency(t *testing.T) {
+	source := `apiVersion: v2
+name: myapp
+version: 0.1.0
+dependencies:
+# the shared library chart
+- name: mylib
+  version: 1.2.3
+  repository: https://charts.example.com
+  condition: mylib.enabled
+- name: mylib
+  version: 0.9.0
+  repository: https://other.example.com
+  alias: legacy
+`
+	testCases := []struct {
+		name     string
+		source   string
+		change   v1alpha1.HelmDependencyChange
+		expected string
+		err      bool
+	}{
+		{
+			name:   "update",
+			source: source,
+			change: v1alpha1.HelmDependencyChange{Name: "mylib"},
+			expected: `apiVersion: v2
+name: myapp
+version: 0.1.0
+dependencies:
+# the shared library chart
+- name: mylib
+  version: 2.0.0
+  repository: https://charts.example.com
+  condition: mylib.enabled
+- name: mylib
+  version: 0.9.0
+  repository: https://other.example.com
+  alias: legacy
+`,
+		},
+		{
+			name:   "repository",
+			source: source,
+			change: v1alpha1.HelmDependencyChange{Name: "mylib", Repository: "https://other.example.com/", Version: "{{ .Version }}-legacy"},
+			expected: `apiVersion: v2
+name: myapp
+version: 0.1.0
+dependencies:
+# the shared library chart
+- name: mylib
+  version: 1.2.3
+  repository: https://charts.example.com
+  condition: mylib.enabled
+- name: mylib
+  version: 2.0.0-legacy
+  repository: https://other.example.com
+  alias: legacy
+`,
+		},
+		{
+			name:   "missing",
+			source: source,
+			change: v1alpha1.HelmDependencyChange{Name: "other"},
+			err:    true,
+		},
+		{
+			name:   "create-missing",
+			source: "apiVersion: v2\nname: myapp\nversion: 0.1.0\n",
+			change: v1alpha1.HelmDependencyChange{Name: "mylib", Repository: "https://charts.example.com", CreateMissing: true},
+			expected: `apiVersion: v2
+name: myapp
+version: 0.1.0
+dependencies:
+- name: mylib
+  version: 2.0.0
+  repository: https://charts.example.com
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "charts", "myapp", "Chart.yaml")
+		writeTestFile(t, path, tc.source)
+
+		o := &pr.Options{Version: "2.0.0", TemplateData: map[string]interface{}{"Version": "2.0.0"}}
+		change := v1alpha1.Change{HelmDependency: &tc.change}
+		err := o.ApplyChanges(dir, "https://github.com/myorg/myrepo", change)
+		if tc.err {
+			require.Error(t, err, "should fail for %s", tc.name)
+			continue
+		}
+		require.NoError(t, err, "failed to apply change for %s", tc.name)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err, "failed to read %s", path)
+		assert.Equal(t, tc.expected, string(data), "Chart.yaml for %s", tc.name)
+	}
+}
@@ -0,0 +1,61 @@
+package pr
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/options"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+)
+
+const (
+	// IfMissingError fails the change if the file to change does not exist
+	IfMissingError = "error"
+
+	// IfMissingSkip skips the change with a warning if the file to change does not exist
+	IfMissingSkip = "skip"
+
+	// IfMissingCreate creates a minimal file containing the version if the file to change does not exist
+	IfMissingCreate = "create"
+)
+
+// IfMissingValues the valid values of the ifMissing field of a change
+var IfMissingValues = []string{IfMissingError, IfMissingSkip, IfMissingCreate}
+
+// ValidateIfMissing verifies the ifMissing field of each change of the rule so that an invalid value fails the rule
+// before any repository is changed rather than only when a file is missing
+func ValidateIfMissing(rule *v1alpha1.Rule) error {
+	for i := range rule.Changes {
+		ifMissing := rule.Changes[i].IfMissing
+		if ifMissing != "" && stringhelpers.StringArrayIndex(IfMissingValues, ifMissing) < 0 {
+			return fmt.Errorf("invalid change #%d: %w", i, options.InvalidOption("ifMissing", ifMissing, IfMissingValues))
+		}
+	}
+	return nil
+}
+
+// handleMissingFile decides what to do when the file of the change does not exist in the repository.
+// Returns true if the file should be created, false if the change should be skipped or an error if the change
+// should fail. Creating files is only possible if canCreate is true
+func (o *Options) handleMissingFile(change v1alpha1.Change, path, gitURL string, canCreate bool) (bool, error) {
+	ifMissing := change.IfMissing
+	if ifMissing == "" {
+		ifMissing = IfMissingSkip
+	}
+	if stringhelpers.StringArrayIndex(IfMissingValues, ifMissing) < 0 {
+		return false, options.InvalidOption("ifMissing", ifMissing, IfMissingValues)
+	}
+	switch ifMissing {
+	case IfMissingError:
+		return false, fmt.Errorf("file %s does not exist in repository %s", path, gitURL)
+	case IfMissingCreate:
+		if !canCreate {
+			return false, fmt.Errorf("cannot create missing file %s in repository %s as the change does not support ifMissing %s", path, gitURL, IfMissingCreate)
+		}
+		o.Logger().Infof("creating missing file %s in repository %s", path, gitURL)
+		return true, nil
+	default:
+		o.Logger().Warnf("skipping change as file %s does not exist in repository %s", path, gitURL)
+		return false, nil
+	}
+}
@@ -0,0 +1,129 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/versionstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIfMissing(t *testing.T) {
+	gitURL := "https://github.com/myorg/myrepo"
+	testCases := []struct {
+		name    string
+		change  v1alpha1.Change
+		created string
+		err     bool
+	}{
+		{
+			name:   "regex-default",
+			change: v1alpha1.Change{Regex: &v1alpha1.Regex{Pattern: `(.*)`, Globs: []string{"VERSION"}}},
+		},
+		{
+			name:   "regex-skip",
+			change: v1alpha1.Change{IfMissing: pr.IfMissingSkip, Regex: &v1alpha1.Regex{Pattern: `(.*)`, Globs: []string{"VERSION"}}},
+		},
+		{
+			name:   "regex-error",
+			change: v1alpha1.Change{IfMissing: pr.IfMissingError, Regex: &v1alpha1.Regex{Pattern: `(.*)`, Globs: []string{"VERSION"}}},
+			err:    true,
+		},
+		{
+			name:    "regex-create",
+			change:  v1alpha1.Change{IfMissing: pr.IfMissingCreate, Regex: &v1alpha1.Regex{Pattern: `(.*)`, Globs: []string{"deploy/VERSION"}}},
+			created: "deploy/VERSION",
+		},
+		{
+			name:   "regex-create-glob",
+			change: v1alpha1.Change{IfMissing: pr.IfMissingCreate, Regex: &v1alpha1.Regex{Pattern: `(.*)`, Globs: []string{"**/VERSION"}}},
+			err:    true,
+		},
+		{
+			name:   "invalid",
+			change: v1alpha1.Change{IfMissing: "cheese", Regex: &v1alpha1.Regex{Pattern: `(.*)`, Globs: []string{"VERSION"}}},
+			err:    true,
+		},
+		{
+			name: "checksum-create",
+			change: v1alpha1.Change{
+				IfMissing: pr.IfMissingCreate,
+				Checksum: &v1alpha1.Checksum{
+					VersionPattern:  `version: (.*)`,
+					ChecksumPattern: `sha256: (.*)`,
+					Globs:           []string{"tool.yaml"},
+					Checksums:       map[string]string{"1.2.3": "abc"},
+				},
+			},
+			err: true,
+		},
+		{
+			name:   "move-error",
+			change: v1alpha1.Change{IfMissing: pr.IfMissingError, Move: &v1alpha1.Move{From: "a.yaml", To: "b.yaml"}},
+			err:    true,
+		},
+		{
+			name:    "version-stream-create",
+			change:  v1alpha1.Change{IfMissing: pr.IfMissingCreate, VersionStream: &v1alpha1.VersionStreamChange{Pattern: v1alpha1.Pattern{Name: "jxgh/jx-preview"}, Kind: "charts"}},
+			created: "charts/jxgh/jx-preview/defaults.yaml",
+		},
+		{
+			name:   "version-stream-error",
+			change: v1alpha1.Change{IfMissing: pr.IfMissingError, VersionStream: &v1alpha1.VersionStreamChange{Pattern: v1alpha1.Pattern{Name: "jxgh/jx-preview"}, Kind: "charts"}},
+			err:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		initGitRepository(t, dir)
+		o := &pr.Options{Version: "1.2.3"}
+		if tc.change.VersionStream != nil {
+			tc.change.VersionStream.Version = o.Version
+		}
+
+		err := o.ApplyChanges(dir, gitURL, tc.change)
+		if tc.err {
+			require.Error(t, err, "should fail for test %s", tc.name)
+			continue
+		}
+		require.NoError(t, err, "failed to apply change for test %s", tc.name)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err, "failed to read dir for test %s", tc.name)
+		if tc.created == "" {
+			assert.Len(t, entries, 1, "should only contain the .git dir for test %s", tc.name)
+			continue
+		}
+		path := filepath.Join(dir, tc.created)
+		if tc.change.VersionStream != nil {
+			sv, err := versionstream.LoadStableVersionFile(path)
+			require.NoError(t, err, "failed to load %s for test %s", path, tc.name)
+			assert.Equal(t, "1.2.3", sv.Version, "created version for test %s", tc.name)
+			continue
+		}
+		data, err := os.ReadFile(path)
+		require.NoError(t, err, "failed to read %s for test %s", path, tc.name)
+		assert.Equal(t, "1.2.3\n", string(data), "created file for test %s", tc.name)
+	}
+}
+
+func TestProcessRuleInvalidIfMissing(t *testing.T) {
+	o := &pr.Options{Version: "1.2.3"}
+	rule := &v1alpha1.Rule{
+		URLs: []string{"https://github.com/myorg/myrepo"},
+		Changes: []v1alpha1.Change{
+			{IfMissing: pr.IfMissingCreate, Regex: &v1alpha1.Regex{Pattern: `(.*)`, Globs: []string{"VERSION"}}},
+			{IfMissing: "cheese", Regex: &v1alpha1.Regex{Pattern: `(.*)`, Globs: []string{"VERSION"}}},
+		},
+	}
+	err := o.ProcessRule(rule, 2)
+	require.Error(t, err, "should fail for an invalid ifMissing")
+	assert.Contains(t, err.Error(), "rule #2")
+	assert.Contains(t, err.Error(), "change #1")
+	assert.Contains(t, err.Error(), "cheese")
+}
@@ -0,0 +1,140 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/yargevad/filepathx"
+)
+
+var digestRegex = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// ApplyImageDigest replaces the digest of the references to the image pinned by digest with the digest of the
+// version tag. References which also have a tag, such as myapp:1.0.0@sha256:abc..., have the tag replaced with the version
+func (o *Options) ApplyImageDigest(dir, gitURL string, change v1alpha1.Change, imageDigest *v1alpha1.ImageDigest) error {
+	image := imageDigest.Image
+	if image == "" {
+		return fmt.Errorf("no image for imageDigest change %#v", change)
+	}
+	r, err := imageDigestRegex(image)
+	if err != nil {
+		return err
+	}
+
+	version := o.Version
+	if change.VersionTemplate != "" {
+		version, err = o.EvaluateVersionTemplate(change.VersionTemplate, gitURL)
+		if err != nil {
+			return fmt.Errorf("failed to valuate version template %s: %w", change.VersionTemplate, err)
+		}
+	}
+
+	// lets resolve the digest before modifying any files so a failure leaves the repository unchanged
+	digest, err := o.ResolveImageDigest(context.Background(), image, version, imageDigest.PlainHTTP)
+	if err != nil {
+		return fmt.Errorf("failed to find digest of version %s: %w", version, err)
+	}
+
+	matched := 0
+	for _, g := range imageDigest.Globs {
+		path := filepath.Join(dir, g)
+		matches, err := filepathx.Glob(path)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate glob %s: %w", path, err)
+		}
+		if len(matches) == 0 {
+			_, err = o.handleMissingFile(change, g, gitURL, false)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		for _, f := range matches {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return fmt.Errorf("failed to load file %s: %w", f, err)
+			}
+			text := string(data)
+			matched += len(r.FindAllStringIndex(text, -1))
+			text2 := ReplaceImageDigest(r, text, image, version, digest)
+			if text2 != text {
+				err = os.WriteFile(f, []byte(text2), files.DefaultFileWritePermissions)
+				if err != nil {
+					return fmt.Errorf("failed to save file %s: %w", f, err)
+				}
+				o.Logger().Infof("modified file %s with image %s@%s", info(f), image, digest)
+			}
+		}
+	}
+	return checkRequireMatch(change, matched, "imageDigest", image, imageDigest.Globs, gitURL)
+}
+
+// IsImageDigest returns true if the text is a sha256 digest such as sha256:abc...
+func IsImageDigest(text string) bool {
+	return digestRegex.MatchString(text)
+}
+
+// ChangeDigest returns the digest of the tag of the image for a change with the digest option. The --digest is used if
+// specified, otherwise the tag is resolved to its digest in the registry of the image of the option, defaulting to the
+// image of the change
+func (o *Options) ChangeDigest(option *v1alpha1.DigestOption, image, tag string) (string, error) {
+	if o.Digest != "" {
+		return o.Digest, nil
+	}
+	if option.Image != "" {
+		image = option.Image
+	}
+	if image == "" {
+		return "", fmt.Errorf("no image to resolve the digest of tag %s, specify the image of the digest or --digest", tag)
+	}
+	digest, err := o.ResolveImageDigest(context.Background(), image, tag, option.PlainHTTP)
+	if err != nil {
+		return "", fmt.Errorf("failed to find digest of version %s: %w", tag, err)
+	}
+	return digest, nil
+}
+
+// ReplaceReferenceDigest returns the image reference, such as myorg/myapp:1.0.0@sha256:abc..., with the digest and
+// the tag if the reference has a tag. A digest on its own is replaced by the digest and other values by the tag
+func ReplaceReferenceDigest(ref, tag, digest string) string {
+	if IsImageDigest(ref) {
+		return digest
+	}
+	if !strings.Contains(ref, "@") {
+		return tag
+	}
+	name, currentTag, _ := splitImageReference(ref)
+	if currentTag != "" {
+		name += ":" + tag
+	}
+	return name + "@" + digest
+}
+
+// imageDigestRegex returns the regex matching the image pinned by a sha256 digest with an optional tag
+func imageDigestRegex(image string) (*regexp.Regexp, error) {
+	pattern := `(^|[^\w./-])` + regexp.QuoteMeta(image) + `(:[\w][\w.-]*)?@sha256:[a-f0-9]{64}`
+	r, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse image digest regex %s: %w", pattern, err)
+	}
+	return r, nil
+}
+
+// ReplaceImageDigest replaces the matches of the image digest regex with the image and digest, keeping the version
+// as the tag if the reference has a tag
+func ReplaceImageDigest(r *regexp.Regexp, text, image, version, digest string) string {
+	return r.ReplaceAllStringFunc(text, func(s string) string {
+		groups := r.FindStringSubmatch(s)
+		ref := groups[1] + image
+		if groups[2] != "" {
+			ref += ":" + version
+		}
+		return ref + "@" + digest
+	})
+}
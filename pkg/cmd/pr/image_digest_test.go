@@ -0,0 +1,133 @@
+package pr_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyImageDigest(t *testing.T) {
+	host, digest := newTestRegistry(t, "myorg/myapp", "1.2.3")
+	image := host + "/myorg/myapp"
+
+	oldDigest := "sha256:" + strings.Repeat("a", 64)
+	source := fmt.Sprintf(`image: %[1]s@%[2]s
+tagged: %[1]s:1.0.0@%[2]s
+other: %[1]s-sidecar@%[2]s
+prefixed: mirror.example.com/%[1]s@%[2]s
+unpinned: %[1]s:1.0.0
+`, image, oldDigest)
+	expected := fmt.Sprintf(`image: %[1]s@%[3]s
+tagged: %[1]s:1.2.3@%[3]s
+other: %[1]s-sidecar@%[2]s
+prefixed: mirror.example.com/%[1]s@%[2]s
+unpinned: %[1]s:1.0.0
+`, image, oldDigest, digest)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "values.yaml")
+	err := os.WriteFile(path, []byte(source), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to write %s", path)
+
+	o := &pr.Options{Version: "1.2.3"}
+	change := v1alpha1.Change{
+		ImageDigest: &v1alpha1.ImageDigest{Image: image, Globs: []string{"*.yaml"}, PlainHTTP: true},
+	}
+	err = o.ApplyChanges(dir, "https://github.com/myorg/myrepo", change)
+	require.NoError(t, err, "failed to apply image digest change")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err, "failed to read %s", path)
+	assert.Equal(t, expected, string(data), "values.yaml")
+
+	o.Version = "9.9.9"
+	err = o.ApplyChanges(dir, "https://github.com/myorg/myrepo", change)
+	require.Error(t, err, "should fail for a missing tag")
+}
+
+func TestDigestOption(t *testing.T) {
+	host, digest := newTestRegistry(t, "myorg/myapp", "1.2.3")
+	image := host + "/myorg/myapp"
+	literal := "sha256:" + strings.Repeat("b", 64)
+	oldDigest := "sha256:" + strings.Repeat("a", 64)
+	gitURL := "https://github.com/myorg/myrepo"
+
+	dir := t.TempDir()
+	dockerfile := filepath.Join(dir, "Dockerfile")
+	writeTestFile(t, dockerfile, fmt.Sprintf("FROM %[1]s:1.0.0@%[2]s AS build\nFROM %[1]s@%[2]s\nFROM %[1]s:1.0.0\n", image, oldDigest))
+	values := filepath.Join(dir, "values.yaml")
+	writeTestFile(t, values, fmt.Sprintf("image:\n  tag: 1.0.0\n  digest: %[2]s\nsidecar: %[1]s:1.0.0@%[2]s\n", image, oldDigest))
+	kustomization := filepath.Join(dir, "kustomization.yaml")
+	writeTestFile(t, kustomization, fmt.Sprintf("images:\n- name: %[1]s\n  newTag: 1.0.0\n  digest: %[2]s\n", image, oldDigest))
+
+	// the literal --digest is used without querying the registry
+	o := &pr.Options{Version: "1.2.3", Digest: literal}
+	changes := []v1alpha1.Change{
+		{Dockerfile: &v1alpha1.DockerfileChange{Image: image, Digest: &v1alpha1.DigestOption{}}},
+		{YAML: &v1alpha1.YAMLChange{Path: "image.digest", Globs: []string{"values.yaml"}, Digest: &v1alpha1.DigestOption{}}},
+		{YAML: &v1alpha1.YAMLChange{Path: "sidecar", Globs: []string{"values.yaml"}, Digest: &v1alpha1.DigestOption{}}},
+	}
+	for _, change := range changes {
+		err := o.ApplyChanges(dir, gitURL, change)
+		require.NoError(t, err, "failed to apply change with literal digest")
+	}
+	assertFileText(t, dockerfile, fmt.Sprintf("FROM %[1]s:1.2.3@%[2]s AS build\nFROM %[1]s@%[2]s\nFROM %[1]s:1.2.3\n", image, literal))
+	assertFileText(t, values, fmt.Sprintf("image:\n  tag: 1.0.0\n  digest: %[2]s\nsidecar: %[1]s:1.2.3@%[2]s\n", image, literal))
+
+	// the digest of the tag is resolved from the registry
+	o = &pr.Options{Version: "1.2.3"}
+	change := v1alpha1.Change{Kustomize: &v1alpha1.KustomizeChange{Name: image, Digest: &v1alpha1.DigestOption{PlainHTTP: true}}}
+	err := o.ApplyChanges(dir, gitURL, change)
+	require.NoError(t, err, "failed to apply change resolving the digest")
+	assertFileText(t, kustomization, fmt.Sprintf("images:\n- name: %[1]s\n  newTag: 1.2.3\n  digest: %[2]s\n", image, digest))
+
+	change = v1alpha1.Change{YAML: &v1alpha1.YAMLChange{Path: "image.digest", Globs: []string{"values.yaml"}, Digest: &v1alpha1.DigestOption{Image: image, PlainHTTP: true}}}
+	err = o.ApplyChanges(dir, gitURL, change)
+	require.NoError(t, err, "failed to apply yaml change resolving the digest")
+	assertFileText(t, values, fmt.Sprintf("image:\n  tag: 1.0.0\n  digest: %[2]s\nsidecar: %[1]s:1.2.3@%[3]s\n", image, digest, literal))
+
+	change.YAML.Digest = &v1alpha1.DigestOption{}
+	err = o.ApplyChanges(dir, gitURL, change)
+	require.Error(t, err, "should fail without an image to resolve the digest of")
+}
+
+// newTestRegistry starts a registry serving a manifest for the tag of the repository returning its host and the
+// digest of the manifest
+func newTestRegistry(t *testing.T, repository, tag string) (string, string) {
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"mediaType":"application/vnd.oci.image.config.v1+json","digest":"sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a","size":2},"layers":[]}`)
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(manifest))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/"+repository+"/manifests/"+tag {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		w.Header().Set("Content-Length", strconv.Itoa(len(manifest)))
+		w.Header().Set("Docker-Content-Digest", digest)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(manifest)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://"), digest
+}
+
+func assertFileText(t *testing.T, path, expected string) {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err, "failed to read %s", path)
+	assert.Equal(t, expected, string(data), "contents of %s", path)
+}
@@ -0,0 +1,176 @@
+package pr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/tidwall/gjson"
+	"github.com/yargevad/filepathx"
+)
+
+// ApplyJSON sets the value at the JSONPath in the JSON files. Only the bytes of the current value are replaced so the
+// formatting of the files is kept
+func (o *Options) ApplyJSON(dir, gitURL string, change v1alpha1.Change, jsonChange *v1alpha1.JSONChange) error {
+	if jsonChange.Path == "" {
+		return fmt.Errorf("no path for json change %#v", change)
+	}
+	path, err := JSONPathToGJSON(jsonChange.Path)
+	if err != nil {
+		return err
+	}
+	value, err := o.changeValue(gitURL, change, jsonChange.Value)
+	if err != nil {
+		return err
+	}
+
+	matched := 0
+	for _, g := range jsonChange.Globs {
+		matches, err := filepathx.Glob(filepath.Join(dir, g))
+		if err != nil {
+			return fmt.Errorf("failed to evaluate glob %s: %w", g, err)
+		}
+		if len(matches) == 0 {
+			_, err = o.handleMissingFile(change, g, gitURL, false)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		for _, f := range matches {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return fmt.Errorf("failed to load file %s: %w", f, err)
+			}
+			if !gjson.ValidBytes(data) {
+				return fmt.Errorf("file %s is not valid JSON", f)
+			}
+			current := gjson.GetBytes(data, path)
+			if !current.Exists() {
+				return fmt.Errorf("the JSONPath %s does not exist in file %s", jsonChange.Path, f)
+			}
+			if current.Index <= 0 {
+				return fmt.Errorf("failed to find the position of the JSONPath %s in file %s", jsonChange.Path, f)
+			}
+			matched++
+			if !o.matchesExpectedCurrent(change, current.String(), jsonChange.Path+" in "+f) {
+				continue
+			}
+			raw, err := jsonValue(current, value)
+			if err != nil {
+				return fmt.Errorf("failed to encode value %s: %w", value, err)
+			}
+			if raw == current.Raw {
+				continue
+			}
+			data2 := append(append(append([]byte{}, data[:current.Index]...), raw...), data[current.Index+len(current.Raw):]...)
+			err = os.WriteFile(f, data2, files.DefaultFileWritePermissions)
+			if err != nil {
+				return fmt.Errorf("failed to save file %s: %w", f, err)
+			}
+			o.Logger().Infof("modified file %s setting %s to %s", info(f), jsonChange.Path, value)
+		}
+	}
+	return checkRequireMatch(change, matched, "json", jsonChange.Path, jsonChange.Globs, gitURL)
+}
+
+// changeValue returns the evaluated value template of a change or the version if there is no value template
+func (o *Options) changeValue(gitURL string, change v1alpha1.Change, valueTemplate string) (string, error) {
+	if valueTemplate != "" {
+		value, err := o.EvaluateVersionTemplate(valueTemplate, gitURL)
+		if err != nil {
+			return "", fmt.Errorf("failed to evaluate value template %s: %w", valueTemplate, err)
+		}
+		return value, nil
+	}
+	if change.VersionTemplate != "" {
+		version, err := o.EvaluateVersionTemplate(change.VersionTemplate, gitURL)
+		if err != nil {
+			return "", fmt.Errorf("failed to valuate version template %s: %w", change.VersionTemplate, err)
+		}
+		return version, nil
+	}
+	return o.Version, nil
+}
+
+// jsonValue returns the raw JSON of the value. Numbers and booleans are only kept unquoted if the current value is
+// of the same type so that a version string is never written as a number
+func jsonValue(current gjson.Result, value string) (string, error) {
+	switch current.Type {
+	case gjson.Number:
+		if _, err := strconv.ParseFloat(value, 64); err == nil && gjson.Valid(value) {
+			return value, nil
+		}
+	case gjson.True, gjson.False:
+		if value == "true" || value == "false" {
+			return value, nil
+		}
+	}
+	return jsonString(value)
+}
+
+// jsonString returns the value as a quoted JSON string without escaping HTML characters
+func jsonString(value string) (string, error) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(value)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// JSONPathToGJSON converts a JSONPath such as $.dependencies['@myorg/lib'] or $.items[0].version to the path syntax
+// of gjson. Only child names and array indexes are supported
+func JSONPathToGJSON(path string) (string, error) {
+	text := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	var segments []string
+	for len(text) > 0 {
+		switch text[0] {
+		case '.':
+			text = text[1:]
+			end := strings.IndexAny(text, ".[")
+			if end < 0 {
+				end = len(text)
+			}
+			name := text[:end]
+			if name == "" || name == "*" {
+				return "", fmt.Errorf("unsupported JSONPath %s: expected a child name after '.'", path)
+			}
+			segments = append(segments, gjson.Escape(name))
+			text = text[end:]
+		case '[':
+			end := strings.Index(text, "]")
+			if end < 0 {
+				return "", fmt.Errorf("invalid JSONPath %s: missing ']'", path)
+			}
+			key := text[1:end]
+			text = text[end+1:]
+			if len(key) >= 2 && (key[0] == '\'' || key[0] == '"') && key[len(key)-1] == key[0] {
+				segments = append(segments, gjson.Escape(key[1:len(key)-1]))
+				continue
+			}
+			if _, err := strconv.Atoi(key); err != nil {
+				return "", fmt.Errorf("unsupported JSONPath %s: expected a quoted name or array index in brackets", path)
+			}
+			segments = append(segments, key)
+		default:
+			if len(segments) > 0 {
+				return "", fmt.Errorf("invalid JSONPath %s", path)
+			}
+			// lets allow paths without the leading $. such as dependencies.myLib
+			text = "." + text
+		}
+	}
+	if len(segments) == 0 {
+		return "", fmt.Errorf("invalid JSONPath %s: no child names", path)
+	}
+	return strings.Join(segments, "."), nil
+}
@@ -0,0 +1,115 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const packageJSON = `{
+    "name": "myapp",
+    "version": "0.0.1",
+    "dependencies": {
+        "@myorg/my.lib": "^1.0.0",
+        "myLib": "1.0.0"
+    },
+    "config": {"replicas": 1, "enabled": false},
+    "images": [
+        {"name": "myapp", "tag": "1.0.0"}
+    ]
+}
+`
+
+func TestApplyJSON(t *testing.T) {
+	testCases := []struct {
+		name     string
+		change   v1alpha1.JSONChange
+		expected string
+		err      bool
+	}{
+		{
+			name:     "version",
+			change:   v1alpha1.JSONChange{Path: "$.dependencies.myLib"},
+			expected: `        "myLib": "2.0.0"`,
+		},
+		{
+			name:     "no-dollar",
+			change:   v1alpha1.JSONChange{Path: "dependencies.myLib"},
+			expected: `        "myLib": "2.0.0"`,
+		},
+		{
+			name:     "bracket-key",
+			change:   v1alpha1.JSONChange{Path: "$.dependencies['@myorg/my.lib']", Value: "^{{ .Version }}"},
+			expected: `        "@myorg/my.lib": "^2.0.0",`,
+		},
+		{
+			name:     "array-index",
+			change:   v1alpha1.JSONChange{Path: "$.images[0].tag"},
+			expected: `        {"name": "myapp", "tag": "2.0.0"}`,
+		},
+		{
+			name:     "number",
+			change:   v1alpha1.JSONChange{Path: "$.config.replicas", Value: "3"},
+			expected: `    "config": {"replicas": 3, "enabled": false},`,
+		},
+		{
+			name:     "boolean",
+			change:   v1alpha1.JSONChange{Path: "$.config.enabled", Value: "true"},
+			expected: `    "config": {"replicas": 1, "enabled": true},`,
+		},
+		{
+			name:     "number-to-string",
+			change:   v1alpha1.JSONChange{Path: "$.config.replicas", Value: "many"},
+			expected: `    "config": {"replicas": "many", "enabled": false},`,
+		},
+		{
+			name:   "missing-path",
+			change: v1alpha1.JSONChange{Path: "$.dependencies.other"},
+			err:    true,
+		},
+		{
+			name:   "unsupported-path",
+			change: v1alpha1.JSONChange{Path: "$.dependencies[*]"},
+			err:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "package.json")
+		err := os.WriteFile(path, []byte(packageJSON), 0o600)
+		require.NoError(t, err, "failed to write %s", path)
+
+		tc.change.Globs = []string{"*.json"}
+		o := &pr.Options{Version: "2.0.0", TemplateData: map[string]interface{}{"Version": "2.0.0"}}
+		err = o.ApplyChanges(dir, "https://github.com/myorg/myrepo", v1alpha1.Change{JSON: &tc.change})
+		if tc.err {
+			require.Error(t, err, "should fail for test %s", tc.name)
+			t.Logf("test %s got expected error: %s", tc.name, err.Error())
+			continue
+		}
+		require.NoError(t, err, "failed to apply change for test %s", tc.name)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err, "failed to read %s", path)
+		assert.Contains(t, string(data), tc.expected+"\n", "modified file for test %s", tc.name)
+	}
+}
+
+func TestJSONPathToGJSON(t *testing.T) {
+	testCases := map[string]string{
+		"$.dependencies.myLib":            "dependencies.myLib",
+		"$.dependencies['@myorg/my.lib']": `dependencies.\@myorg\/my\.lib`,
+		`$["a b"][2].c`:                   "a b.2.c",
+	}
+	for path, expected := range testCases {
+		actual, err := pr.JSONPathToGJSON(path)
+		require.NoError(t, err, "failed to convert %s", path)
+		assert.Equal(t, expected, actual, "converted path %s", path)
+	}
+}
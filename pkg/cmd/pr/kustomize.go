@@ -0,0 +1,157 @@
+package pr
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/yargevad/filepathx"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// DefaultKustomizeGlobs the files of a kustomize change if none are specified
+var DefaultKustomizeGlobs = []string{"**/kustomization.yaml", "**/kustomization.yml", "**/Kustomization"}
+
+// ApplyKustomize sets the newTag of the image in the images list of the kustomization files. The files are modified in
+// place so that comments and the other fields of the image are kept
+func (o *Options) ApplyKustomize(dir, gitURL string, change v1alpha1.Change, kustomize *v1alpha1.KustomizeChange) error {
+	if kustomize.Name == "" {
+		return fmt.Errorf("no name for kustomize change %#v", change)
+	}
+	tag, err := o.changeValue(gitURL, change, kustomize.NewTag)
+	if err != nil {
+		return err
+	}
+	digest := ""
+	if kustomize.Digest != nil {
+		digest, err = o.ChangeDigest(kustomize.Digest, kustomize.Name, tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	globs := KustomizeGlobs(kustomize)
+	var paths []string
+	for _, g := range globs {
+		matches, err := filepathx.Glob(filepath.Join(dir, g))
+		if err != nil {
+			return fmt.Errorf("failed to evaluate glob %s: %w", g, err)
+		}
+		paths = append(paths, matches...)
+	}
+	// a repository only needs one of the kustomization file names so only report missing files if none match
+	if len(paths) == 0 {
+		_, err = o.handleMissingFile(change, strings.Join(globs, ", "), gitURL, false)
+		return err
+	}
+
+	matched := 0
+	for _, f := range paths {
+		node, err := yaml.ReadFile(f)
+		if err != nil {
+			return fmt.Errorf("failed to load YAML file %s: %w", f, err)
+		}
+		modified, found, err := o.setKustomizeImageTag(change, node, kustomize, tag, digest, f)
+		if err != nil {
+			return err
+		}
+		if found {
+			matched++
+		}
+		if !modified {
+			continue
+		}
+		err = yaml.WriteFile(node, f)
+		if err != nil {
+			return fmt.Errorf("failed to save file %s: %w", f, err)
+		}
+		o.Logger().Infof("modified file %s with image %s:%s", info(f), kustomize.Name, tag)
+	}
+	return checkRequireMatch(change, matched, "kustomize", kustomize.Name, globs, gitURL)
+}
+
+// KustomizeGlobs returns the files of the kustomize change
+func KustomizeGlobs(kustomize *v1alpha1.KustomizeChange) []string {
+	if len(kustomize.Globs) == 0 {
+		return DefaultKustomizeGlobs
+	}
+	return kustomize.Globs
+}
+
+// setKustomizeImageTag sets the newTag of the image in the kustomization node, along with its digest if it has one and
+// the digest is specified, returning whether the node was modified and whether the image was found
+func (o *Options) setKustomizeImageTag(change v1alpha1.Change, node *yaml.RNode, kustomize *v1alpha1.KustomizeChange, tag, digest, path string) (bool, bool, error) {
+	images, err := node.Pipe(yaml.Lookup("images"))
+	if err != nil {
+		return false, false, fmt.Errorf("failed to find images in file %s: %w", path, err)
+	}
+	if images != nil {
+		if images.YNode().Kind != yaml.SequenceNode {
+			return false, false, fmt.Errorf("the images in file %s is not a list", path)
+		}
+		for _, image := range images.YNode().Content {
+			if image.Kind != yaml.MappingNode || yamlMappingValue(image, "name") == nil || yamlMappingValue(image, "name").Value != kustomize.Name {
+				continue
+			}
+			currentDigest := yamlMappingValue(image, "digest")
+			if currentDigest != nil && digest == "" {
+				o.Logger().Warnf("the image %s in %s has a digest which takes precedence over the newTag", kustomize.Name, path)
+			}
+			newTag := yamlMappingValue(image, "newTag")
+			if newTag != nil && !o.matchesExpectedCurrent(change, newTag.Value, kustomize.Name+" in "+path) {
+				return false, true, nil
+			}
+			modified := false
+			if currentDigest != nil && digest != "" && currentDigest.Value != digest {
+				currentDigest.Value = digest
+				modified = true
+			}
+			if newTag == nil {
+				// an image pinned only by digest does not need a tag
+				if currentDigest != nil && digest != "" {
+					return modified, true, nil
+				}
+				image.Content = append(image.Content, yamlString("newTag"), yamlString(tag))
+				return true, true, nil
+			}
+			if newTag.Value == tag {
+				return modified, true, nil
+			}
+			// kustomize requires the tag to be a string so lets make sure a version such as 1.2 is quoted
+			newTag.Value = tag
+			newTag.Tag = yaml.NodeTagString
+			return true, true, nil
+		}
+	}
+	if !kustomize.CreateMissing {
+		o.Logger().Debugf("the image %s is not in file %s", kustomize.Name, path)
+		return false, false, nil
+	}
+
+	images, err = node.Pipe(yaml.LookupCreate(yaml.SequenceNode, "images"))
+	if err != nil {
+		return false, false, fmt.Errorf("failed to create images in file %s: %w", path, err)
+	}
+	content := []*yaml.Node{yamlString("name"), yamlString(kustomize.Name), yamlString("newTag"), yamlString(tag)}
+	if digest != "" {
+		content = append(content, yamlString("digest"), yamlString(digest))
+	}
+	images.YNode().Content = append(images.YNode().Content, &yaml.Node{Kind: yaml.MappingNode, Content: content})
+	o.Logger().Infof("adding the image %s to file %s", kustomize.Name, info(path))
+	return true, true, nil
+}
+
+// yamlMappingValue returns the value of the key in the mapping node or nil if there is no key
+func yamlMappingValue(n *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func yamlString(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: yaml.NodeTagString, Value: value}
+}
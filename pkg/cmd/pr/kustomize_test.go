@@ -0,0 +1,87 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyKustomize(t *testing.T) {
+	source := `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment.yaml
+images:
+# the application image
+- name: myorg/myapp
+  newName: myreg/myorg/myapp
+  newTag: 1.2.3
+- name: myorg/other
+  newTag: 0.1.0
+`
+	testCases := []struct {
+		name     string
+		source   string
+		change   v1alpha1.KustomizeChange
+		expected string
+	}{
+		{
+			name:   "update",
+			source: source,
+			change: v1alpha1.KustomizeChange{Name: "myorg/myapp"},
+			expected: `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment.yaml
+images:
+# the application image
+- name: myorg/myapp
+  newName: myreg/myorg/myapp
+  newTag: "2.0"
+- name: myorg/other
+  newTag: 0.1.0
+`,
+		},
+		{
+			name:     "missing",
+			source:   source,
+			change:   v1alpha1.KustomizeChange{Name: "myorg/new"},
+			expected: source,
+		},
+		{
+			name:   "create-missing",
+			source: "resources:\n- deployment.yaml\n",
+			change: v1alpha1.KustomizeChange{Name: "myorg/new", CreateMissing: true},
+			expected: `resources:
+- deployment.yaml
+images:
+- name: myorg/new
+  newTag: "2.0"
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "overlays", "prod", "kustomization.yaml")
+		err := os.MkdirAll(filepath.Dir(path), files.DefaultDirWritePermissions)
+		require.NoError(t, err, "failed to create dir for %s", tc.name)
+		err = os.WriteFile(path, []byte(tc.source), files.DefaultFileWritePermissions)
+		require.NoError(t, err, "failed to write %s", path)
+
+		o := &pr.Options{Version: "2.0", TemplateData: map[string]interface{}{"Version": "2.0"}}
+		change := v1alpha1.Change{Kustomize: &tc.change}
+		err = o.ApplyChanges(dir, "https://github.com/myorg/myrepo", change)
+		require.NoError(t, err, "failed to apply change for %s", tc.name)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err, "failed to read %s", path)
+		assert.Equal(t, tc.expected, string(data), "kustomization for %s", tc.name)
+	}
+}
@@ -0,0 +1,55 @@
+package pr
+
+import (
+	"github.com/jenkins-x/jx-helpers/v3/pkg/options"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// LogFormatText logs human readable messages
+	LogFormatText = "text"
+
+	// LogFormatJSON logs a JSON object per line for ingestion into a log pipeline
+	LogFormatJSON = "json"
+)
+
+// LogFormats the valid values of the --log-format option
+var LogFormats = []string{LogFormatText, LogFormatJSON}
+
+// configureLogFormat switches the logger to JSON lines for --log-format json. The text format is left as is so that
+// it is still configured by the JX_LOG_FORMAT environment variable
+func (o *Options) configureLogFormat() error {
+	if o.LogFormat == "" {
+		o.LogFormat = LogFormatText
+	}
+	if stringhelpers.StringArrayIndex(LogFormats, o.LogFormat) < 0 {
+		return options.InvalidOption("log-format", o.LogFormat, LogFormats)
+	}
+	if o.LogFormat == LogFormatJSON {
+		log.Logger().Logger.SetFormatter(&logrus.JSONFormatter{})
+	}
+	return nil
+}
+
+// Logger returns the logger with the rule, repo and branch fields of the rule and repository being processed
+func (o *Options) Logger() *logrus.Entry {
+	if len(o.logFields) == 0 {
+		return log.Logger()
+	}
+	return log.Logger().WithFields(o.logFields)
+}
+
+// addLogFields adds the fields to a copy of the log fields so that the copies of the options used to process
+// repositories concurrently do not share their fields
+func (o *Options) addLogFields(fields logrus.Fields) {
+	m := make(logrus.Fields, len(o.logFields)+len(fields))
+	for k, v := range o.logFields {
+		m[k] = v
+	}
+	for k, v := range fields {
+		m[k] = v
+	}
+	o.logFields = m
+}
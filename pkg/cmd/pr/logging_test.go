@@ -0,0 +1,116 @@
+package pr_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureOutputLogFormatJSON(t *testing.T) {
+	logger := log.Logger().Logger
+	formatter := logger.Formatter
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+	defer func() {
+		logger.SetFormatter(formatter)
+		log.SetOutput(os.Stdout)
+	}()
+
+	o := &pr.Options{LogFormat: pr.LogFormatJSON}
+	err := o.ConfigureOutput()
+	require.NoError(t, err, "failed to configure output")
+
+	o.Logger().Infof("hello %s", "world")
+	entry := map[string]interface{}{}
+	err = json.Unmarshal(buf.Bytes(), &entry)
+	require.NoError(t, err, "failed to parse log line %s", buf.String())
+	assert.Equal(t, "hello world", entry["msg"], "log message")
+	assert.Equal(t, "info", entry["level"], "log level")
+}
+
+func TestConfigureOutputInvalidLogFormat(t *testing.T) {
+	o := &pr.Options{LogFormat: "xml"}
+	err := o.ConfigureOutput()
+	require.Error(t, err, "should fail for an invalid --log-format")
+}
+
+func TestRunLogsRuleAndRepoFields(t *testing.T) {
+	logger := log.Logger().Logger
+	formatter := logger.Formatter
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+	defer func() {
+		logger.SetFormatter(formatter)
+		log.SetOutput(os.Stdout)
+	}()
+
+	// lets create a local repository to clone
+	repo := t.TempDir()
+	initGitRepository(t, repo)
+	writeTestFile(t, filepath.Join(repo, "values.yaml"), "version: 1.0.0\n")
+	for _, args := range [][]string{
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", "initial"},
+	} {
+		_, err := cmdrunner.QuietCommandRunner(&cmdrunner.Command{Dir: repo, Name: "git", Args: args})
+		require.NoError(t, err, "failed to run git %v", args)
+	}
+
+	// the change is skipped as the current version is not the expected version which is logged by the change
+	dir := t.TempDir()
+	config := `apiVersion: updatebot.jenkins-x.io/v1alpha1
+kind: UpdateConfig
+spec:
+  rules:
+  - urls:
+    - ` + repo + `
+    changes:
+    - expectedCurrent: 0.9.0
+      regex:
+        pattern: "version: (.*)"
+        files:
+        - values.yaml
+`
+	initGitRepository(t, dir)
+	err := os.MkdirAll(filepath.Join(dir, ".jx"), files.DefaultDirWritePermissions)
+	require.NoError(t, err, "failed to create .jx dir")
+	err = os.WriteFile(filepath.Join(dir, ".jx", "updatebot.yaml"), []byte(config), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to write config")
+
+	_, o := pr.NewCmdPullRequest()
+	o.Dir = dir
+	o.CommandRunner = cmdrunner.QuietCommandRunner
+	o.ScmClientFactory.NoWriteGitCredentialsFile = true
+	o.Version = "2.0.0"
+	o.Application = "myapp"
+	o.DryRun = true
+	o.LogFormat = pr.LogFormatJSON
+	o.EnvironmentPullRequestOptions.ScmClientFactory.GitServerURL = "https://github.com"
+	o.EnvironmentPullRequestOptions.ScmClientFactory.GitToken = "dummytoken"
+
+	err = o.Run()
+	require.NoError(t, err, "failed to run")
+
+	var entry map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		m := map[string]interface{}{}
+		err = json.Unmarshal([]byte(line), &m)
+		require.NoError(t, err, "failed to parse log line %s", line)
+		if msg, _ := m["msg"].(string); strings.HasPrefix(msg, "skipping change of") {
+			entry = m
+		}
+	}
+	require.NotNil(t, entry, "should log the skipped change in %s", buf.String())
+	assert.Equal(t, float64(0), entry["rule"], "rule field")
+	assert.Equal(t, repo, entry["repo"], "repo field")
+}
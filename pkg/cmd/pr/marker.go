@@ -0,0 +1,79 @@
+package pr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/yamls"
+)
+
+// MarkerFile the file in the downstream repository which records the changes applied with a marker
+const MarkerFile = ".jx/updatebot-markers.yaml"
+
+// Markers the content hashes of the changes applied to a repository and the version they were applied for
+type Markers struct {
+	Markers map[string]string `json:"markers,omitempty"`
+}
+
+// ApplyChangeWithMarker applies the change unless the marker file records it has already been applied for the
+// version. After applying the change the marker file is updated
+func (o *Options) ApplyChangeWithMarker(dir, gitURL string, change v1alpha1.Change) error {
+	change.Marker = false
+	key, err := ChangeHash(&change)
+	if err != nil {
+		return err
+	}
+	version := o.Version
+	if change.VersionTemplate != "" {
+		version, err = o.EvaluateVersionTemplate(change.VersionTemplate, gitURL)
+		if err != nil {
+			return fmt.Errorf("failed to valuate version template %s: %w", change.VersionTemplate, err)
+		}
+	}
+
+	path := filepath.Join(dir, MarkerFile)
+	markers := &Markers{}
+	err = yamls.LoadFile(path, markers)
+	if err != nil {
+		return fmt.Errorf("failed to load marker file %s: %w", path, err)
+	}
+	if markers.Markers[key] == version {
+		o.Logger().Infof("skipping change %s as the marker shows it is already applied for version %s", key, version)
+		return nil
+	}
+
+	err = o.ApplyChanges(dir, gitURL, change)
+	if err != nil {
+		return err
+	}
+
+	if markers.Markers == nil {
+		markers.Markers = map[string]string{}
+	}
+	markers.Markers[key] = version
+	err = os.MkdirAll(filepath.Dir(path), files.DefaultDirWritePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to create dir for marker file %s: %w", path, err)
+	}
+	err = yamls.SaveFile(markers, path)
+	if err != nil {
+		return fmt.Errorf("failed to save marker file %s: %w", path, err)
+	}
+	return nil
+}
+
+// ChangeHash returns the content hash of the change which identifies it in the marker file
+func ChangeHash(change *v1alpha1.Change) (string, error) {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal change: %w", err)
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
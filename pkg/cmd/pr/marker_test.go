@@ -0,0 +1,46 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyChangeWithMarker(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "version.txt")
+	err := os.WriteFile(path, []byte("version: 0.0.1\n"), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to write %s", path)
+
+	change := v1alpha1.Change{
+		Regex: &v1alpha1.Regex{
+			Pattern: `version: (.*)`,
+			Globs:   []string{"version.txt"},
+		},
+		Marker: true,
+	}
+	apply := func(version string) string {
+		o := &pr.Options{Version: version}
+		err := o.ApplyChanges(dir, "https://github.com/myorg/myrepo", change)
+		require.NoError(t, err, "failed to apply change for version %s", version)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err, "failed to read %s", path)
+		return string(data)
+	}
+
+	assert.Equal(t, "version: 1.0.0\n", apply("1.0.0"), "should apply the change")
+	assert.FileExists(t, filepath.Join(dir, pr.MarkerFile), "should write the marker file")
+
+	// lets modify the file so we can see the change is skipped when the marker matches
+	err = os.WriteFile(path, []byte("version: edited\n"), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to write %s", path)
+	assert.Equal(t, "version: edited\n", apply("1.0.0"), "should skip the change for the same version")
+
+	assert.Equal(t, "version: 1.1.0\n", apply("1.1.0"), "should apply the change for a new version")
+}
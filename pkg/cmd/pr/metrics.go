@@ -0,0 +1,91 @@
+package pr
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+)
+
+// Metrics the counters of a run which are logged once it completes and written to the --metrics-file
+type Metrics struct {
+	// Rules the number of rules run
+	Rules int `json:"rules"`
+	// Repositories the number of repositories processed
+	Repositories int `json:"repositories"`
+	// Created the number of Pull Requests created
+	Created int `json:"created"`
+	// Reused the number of existing Pull Requests which were updated
+	Reused int `json:"reused"`
+	// Skipped the number of repositories which were not changed as they are already up to date
+	Skipped int `json:"skipped"`
+	// BlockedRules the number of rules which were skipped as a rule they depend on failed
+	BlockedRules int `json:"blockedRules"`
+	// Failures the number of repositories or rules which failed
+	Failures int `json:"failures"`
+}
+
+// StartMetrics resets the counters of the run. Pull Requests created before the run started are counted as reused
+func (o *Options) StartMetrics() {
+	o.metrics = &Metrics{}
+	o.metricsStart = time.Now()
+}
+
+// countMetrics updates the counters of the run if they have been started. The counters are shared by the copies of
+// the options used to process repositories concurrently so they are updated with the lock
+func (o *Options) countMetrics(fn func(m *Metrics)) {
+	if o.metrics == nil {
+		return
+	}
+	o.withLock(func() {
+		fn(o.metrics)
+	})
+}
+
+// CountPullRequest counts the Pull Request as created, or as reused if it was created before the run started
+func (o *Options) CountPullRequest(pr *scm.PullRequest) {
+	o.countMetrics(func(m *Metrics) {
+		if pr.Created.IsZero() || !pr.Created.Before(o.metricsStart) {
+			m.Created++
+			return
+		}
+		m.Reused++
+	})
+}
+
+// RunMetrics returns the counters of the run. The error of the run is counted as a failure if no repository failures
+// were recorded
+func (o *Options) RunMetrics(runErr error) Metrics {
+	m := Metrics{}
+	if o.metrics != nil {
+		m = *o.metrics
+	}
+	m.Failures = len(o.failures)
+	if runErr != nil && m.Failures == 0 {
+		m.Failures = 1
+	}
+	return m
+}
+
+// ReportMetrics logs the summary of the run and writes the counters as JSON to the --metrics-file if specified
+func (o *Options) ReportMetrics(runErr error) error {
+	m := o.RunMetrics(runErr)
+	o.Logger().Infof("summary: %d rules, %d repositories, %d Pull Requests created, %d reused, %d repositories skipped as up to date, %d rules skipped as a dependency failed, %d failures",
+		m.Rules, m.Repositories, m.Created, m.Reused, m.Skipped, m.BlockedRules, m.Failures)
+	if o.MetricsFile == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metrics: %w", err)
+	}
+	data = append(data, '\n')
+	err = os.WriteFile(o.MetricsFile, data, files.DefaultFileWritePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to save metrics file %s: %w", o.MetricsFile, err)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package pr_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportMetrics(t *testing.T) {
+	_, o := pr.NewCmdPullRequest()
+	o.MetricsFile = filepath.Join(t.TempDir(), "metrics.json")
+
+	// counters are not recorded until the run starts
+	o.CountPullRequest(&scm.PullRequest{Number: 1})
+	assert.Equal(t, pr.Metrics{}, o.RunMetrics(nil), "metrics before the run starts")
+
+	o.StartMetrics()
+	o.CountPullRequest(&scm.PullRequest{Number: 2, Created: time.Now()})
+	o.CountPullRequest(&scm.PullRequest{Number: 3})
+	o.CountPullRequest(&scm.PullRequest{Number: 4, Created: time.Now().Add(-time.Hour)})
+
+	err := o.ReportMetrics(errors.New("rule #1 failed"))
+	require.NoError(t, err, "failed to report metrics")
+
+	data, err := os.ReadFile(o.MetricsFile)
+	require.NoError(t, err, "failed to read %s", o.MetricsFile)
+	assert.JSONEq(t, `{"rules":0,"repositories":0,"created":2,"reused":1,"skipped":0,"blockedRules":0,"failures":1}`, string(data), "metrics file")
+}
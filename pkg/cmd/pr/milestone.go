@@ -0,0 +1,79 @@
+package pr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// milestonePageSize the number of milestones listed per page
+const milestonePageSize = 100
+
+// PullRequestMilestone returns the title of the milestone of the Pull Requests of the rule defaulting to --pr-milestone
+func (o *Options) PullRequestMilestone(rule *v1alpha1.Rule) string {
+	if rule.Milestone != "" {
+		return rule.Milestone
+	}
+	return o.PRMilestone
+}
+
+// SetPullRequestMilestone adds the PR to the milestone of the rule. A missing milestone is created with
+// --create-milestone, otherwise the PR is left without a milestone with a warning
+func (o *Options) SetPullRequestMilestone(rule *v1alpha1.Rule, pullRequest *scm.PullRequest, gitURL, gitKind string) error {
+	title := o.PullRequestMilestone(rule)
+	if title == "" {
+		return nil
+	}
+	ctx := context.Background()
+	scmClient, repoFullName, err := o.GetScmClient(gitURL, gitKind)
+	if err != nil {
+		return fmt.Errorf("failed to create ScmClient: %w", err)
+	}
+	milestone, err := FindMilestone(ctx, scmClient, repoFullName, title)
+	if errors.Is(err, scm.ErrNotSupported) {
+		o.Logger().Warnf("cannot add PR %d in repo %s to milestone %s as the git provider does not support milestones", pullRequest.Number, repoFullName, title)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if milestone == nil {
+		if !o.CreateMilestone {
+			o.Logger().Warnf("not adding PR %d in repo %s to milestone %s as it does not exist, use --create-milestone to create it", pullRequest.Number, repoFullName, title)
+			return nil
+		}
+		milestone, _, err = scmClient.Milestones.Create(ctx, repoFullName, &scm.MilestoneInput{Title: title, State: "open"})
+		if err != nil {
+			return fmt.Errorf("failed to create milestone %s in repo %s: %w", title, repoFullName, err)
+		}
+		o.Logger().Infof("created milestone %s in repo %s", info(title), repoFullName)
+	}
+	_, err = scmClient.PullRequests.SetMilestone(ctx, repoFullName, pullRequest.Number, milestone.Number)
+	if err != nil {
+		return fmt.Errorf("failed to add PR %d in repo %s to milestone %s: %w", pullRequest.Number, repoFullName, title, err)
+	}
+	o.Logger().Infof("added PR %d in repo %s to milestone %s", pullRequest.Number, repoFullName, info(title))
+	return nil
+}
+
+// FindMilestone pages through the open and closed milestones of the repository to find the milestone with the title.
+// Returns nil if there is no such milestone
+func FindMilestone(ctx context.Context, scmClient *scm.Client, repoFullName, title string) (*scm.Milestone, error) {
+	for page := 1; ; page++ {
+		milestones, _, err := scmClient.Milestones.List(ctx, repoFullName, scm.MilestoneListOptions{Page: page, Size: milestonePageSize, Open: true, Closed: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list milestones of repo %s: %w", repoFullName, err)
+		}
+		for _, m := range milestones {
+			if m != nil && m.Title == title {
+				return m, nil
+			}
+		}
+		if len(milestones) < milestonePageSize {
+			return nil, nil
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package pr_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetPullRequestMilestone(t *testing.T) {
+	gitURL := "https://github.com/myorg/myrepo"
+	pullRequest := &scm.PullRequest{Number: 5}
+
+	testCases := []struct {
+		name     string
+		rule     *v1alpha1.Rule
+		create   bool
+		expected map[string]int
+		created  []string
+	}{
+		{
+			name:     "flag",
+			rule:     &v1alpha1.Rule{},
+			expected: map[string]int{"myorg/myrepo#5": 2},
+		},
+		{
+			name:     "rule",
+			rule:     &v1alpha1.Rule{Milestone: "v1.0"},
+			expected: map[string]int{"myorg/myrepo#5": 1},
+		},
+		{
+			name: "missing",
+			rule: &v1alpha1.Rule{Milestone: "v3.0"},
+		},
+		{
+			name:     "create",
+			rule:     &v1alpha1.Rule{Milestone: "v3.0"},
+			create:   true,
+			expected: map[string]int{"myorg/myrepo#5": 3},
+			created:  []string{"v3.0"},
+		},
+	}
+	for _, tc := range testCases {
+		scmClient, _ := fake.NewDefault()
+		milestones := &fakeMilestoneService{
+			milestones: []*scm.Milestone{{Number: 1, Title: "v1.0", State: "closed"}, {Number: 2, Title: "v2.0", State: "open"}},
+		}
+		pullRequests := &fakeMilestonePullRequestService{PullRequestService: scmClient.PullRequests}
+		scmClient.Milestones = milestones
+		scmClient.PullRequests = pullRequests
+
+		_, o := pr.NewCmdPullRequest()
+		o.ScmClient = scmClient
+		o.ScmClientFactory.ScmClient = scmClient
+		o.ScmClientFactory.GitServerURL = "https://github.com"
+		o.GitKind = "fake"
+		o.PRMilestone = "v2.0"
+		o.CreateMilestone = tc.create
+
+		err := o.SetPullRequestMilestone(tc.rule, pullRequest, gitURL, o.GitKind)
+		require.NoError(t, err, "failed to set milestone for %s", tc.name)
+		assert.Equal(t, tc.expected, pullRequests.milestones, "milestones of PRs for %s", tc.name)
+		assert.Equal(t, tc.created, milestones.created, "created milestones for %s", tc.name)
+	}
+}
+
+// fakeMilestoneService lists the milestones and records the milestones which are created
+type fakeMilestoneService struct {
+	scm.MilestoneService
+	milestones []*scm.Milestone
+	created    []string
+}
+
+func (s *fakeMilestoneService) List(_ context.Context, _ string, opts scm.MilestoneListOptions) ([]*scm.Milestone, *scm.Response, error) {
+	if opts.Page > 1 {
+		return nil, nil, nil
+	}
+	return s.milestones, nil, nil
+}
+
+func (s *fakeMilestoneService) Create(_ context.Context, _ string, input *scm.MilestoneInput) (*scm.Milestone, *scm.Response, error) {
+	s.created = append(s.created, input.Title)
+	m := &scm.Milestone{Number: len(s.milestones) + 1, Title: input.Title, State: input.State}
+	s.milestones = append(s.milestones, m)
+	return m, nil, nil
+}
+
+// fakeMilestonePullRequestService records the milestones the PRs are added to
+type fakeMilestonePullRequestService struct {
+	scm.PullRequestService
+	milestones map[string]int
+}
+
+func (s *fakeMilestonePullRequestService) SetMilestone(_ context.Context, repo string, prID, number int) (*scm.Response, error) {
+	if s.milestones == nil {
+		s.milestones = map[string]int{}
+	}
+	s.milestones[fmt.Sprintf("%s#%d", repo, prID)] = number
+	return nil, nil
+}
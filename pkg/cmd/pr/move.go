@@ -0,0 +1,93 @@
+package pr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/templater"
+)
+
+// ApplyMove moves a file in the repository with git mv then applies the optional regex change to the moved file
+func (o *Options) ApplyMove(dir, gitURL string, change v1alpha1.Change, move *v1alpha1.Move) error {
+	if move.From == "" {
+		return fmt.Errorf("no from for move change %#v", change)
+	}
+	if move.To == "" {
+		return fmt.Errorf("no to for move change %#v", change)
+	}
+
+	version := o.Version
+	var err error
+	if change.VersionTemplate != "" {
+		version, err = o.EvaluateVersionTemplate(change.VersionTemplate, gitURL)
+		if err != nil {
+			return fmt.Errorf("failed to valuate version template %s: %w", change.VersionTemplate, err)
+		}
+	}
+	templateData := map[string]interface{}{
+		"Version": version,
+	}
+	from, err := evaluateMoveTemplate(move.From, templateData)
+	if err != nil {
+		return err
+	}
+	to, err := evaluateMoveTemplate(move.To, templateData)
+	if err != nil {
+		return err
+	}
+
+	fromPath := filepath.Join(dir, from)
+	toPath := filepath.Join(dir, to)
+	exists, err := files.FileExists(fromPath)
+	if err != nil {
+		return fmt.Errorf("failed to check for file %s: %w", fromPath, err)
+	}
+	if !exists {
+		moved, err := files.FileExists(toPath)
+		if err != nil {
+			return fmt.Errorf("failed to check for file %s: %w", toPath, err)
+		}
+		if moved {
+			o.Logger().Infof("file %s has already been moved to %s in repository %s", from, to, gitURL)
+			return nil
+		}
+		_, err = o.handleMissingFile(change, from, gitURL, false)
+		return err
+	}
+	if from != to {
+		err = os.MkdirAll(filepath.Dir(toPath), files.DefaultDirWritePermissions)
+		if err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", toPath, err)
+		}
+		_, err = o.Git().Command(dir, "mv", from, to)
+		if err != nil {
+			return fmt.Errorf("failed to move %s to %s: %w", from, to, err)
+		}
+		o.Logger().Infof("moved %s to %s in repository %s", from, to, gitURL)
+	}
+
+	if move.Regex == nil {
+		return nil
+	}
+	regex := *move.Regex
+	if len(regex.Globs) == 0 {
+		regex.Globs = []string{to}
+	}
+	return o.ApplyRegex(dir, gitURL, change, &regex)
+}
+
+func evaluateMoveTemplate(templateText string, templateData map[string]interface{}) (string, error) {
+	if !strings.Contains(templateText, "{{") {
+		return templateText, nil
+	}
+	answer, err := templater.Evaluate(sprig.TxtFuncMap(), templateData, templateText, "move.gotmpl", "move template")
+	if err != nil {
+		return "", fmt.Errorf("failed to evaluate template %s: %w", templateText, err)
+	}
+	return answer, nil
+}
@@ -0,0 +1,53 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyMove(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepository(t, dir)
+	err := os.MkdirAll(filepath.Join(dir, "config"), files.DefaultDirWritePermissions)
+	require.NoError(t, err, "failed to create config dir")
+	err = os.WriteFile(filepath.Join(dir, "config", "config-v1.yaml"), []byte("version: 1.0.0\n"), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to write config file")
+	_, err = cmdrunner.QuietCommandRunner(&cmdrunner.Command{Dir: dir, Name: "git", Args: []string{"add", "."}})
+	require.NoError(t, err, "failed to add config file")
+
+	o := &pr.Options{Version: "2.0.0"}
+	move := &v1alpha1.Move{
+		From: "config/config-v1.yaml",
+		To:   `config/v{{ .Version | splitList "." | first }}/config-v{{ .Version | splitList "." | first }}.yaml`,
+		Regex: &v1alpha1.Regex{
+			Pattern: `version: (.*)`,
+		},
+	}
+	gitURL := "https://github.com/myorg/myrepo"
+	err = o.ApplyMove(dir, gitURL, v1alpha1.Change{Move: move}, move)
+	require.NoError(t, err, "failed to apply move")
+
+	assert.NoFileExists(t, filepath.Join(dir, "config", "config-v1.yaml"), "should have moved the file")
+	data, err := os.ReadFile(filepath.Join(dir, "config", "v2", "config-v2.yaml"))
+	require.NoError(t, err, "failed to read the moved file")
+	assert.Equal(t, "version: 2.0.0\n", string(data), "moved file contents")
+
+	status, err := cmdrunner.QuietCommandRunner(&cmdrunner.Command{Dir: dir, Name: "git", Args: []string{"status", "--porcelain"}})
+	require.NoError(t, err, "failed to get git status")
+	assert.Contains(t, status, "config/v2/config-v2.yaml", "git status should include the moved file")
+
+	// applying again does nothing as the file has already been moved
+	err = o.ApplyMove(dir, gitURL, v1alpha1.Change{Move: move}, move)
+	require.NoError(t, err, "failed to apply move again")
+
+	err = o.ApplyMove(dir, gitURL, v1alpha1.Change{}, &v1alpha1.Move{From: "a.yaml"})
+	require.Error(t, err, "should fail without a to path")
+}
@@ -0,0 +1,187 @@
+package pr
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/options"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// OutputFormatJSON writes the --output-file as JSON
+	OutputFormatJSON = "json"
+
+	// OutputFormatYAML writes the --output-file as YAML
+	OutputFormatYAML = "yaml"
+)
+
+// OutputFormats the valid values of the --output-format option
+var OutputFormats = []string{OutputFormatJSON, OutputFormatYAML}
+
+// Output the summary of a run written to the --output-file
+type Output struct {
+	// PullRequests the Pull Requests created or reused
+	PullRequests []OutputPullRequest `json:"pullRequests"`
+	// Errors the repositories or rules which failed
+	Errors []OutputError `json:"errors,omitempty"`
+}
+
+// OutputPullRequest a Pull Request created or reused by a run
+type OutputPullRequest struct {
+	// Repo the git URL of the repository
+	Repo string `json:"repo"`
+	// Number the Pull Request number
+	Number int `json:"number"`
+	// URL the URL of the Pull Request
+	URL string `json:"url"`
+	// Title the title of the Pull Request
+	Title string `json:"title"`
+	// Branch the branch of the Pull Request
+	Branch string `json:"branch"`
+	// Merged true if the Pull Request has been merged
+	Merged bool `json:"merged"`
+}
+
+// OutputError a failure of a run
+type OutputError struct {
+	// Repo the git URL of the repository which failed if known
+	Repo string `json:"repo,omitempty"`
+	// Error the reason for the failure
+	Error string `json:"error"`
+}
+
+// ConfigureOutput configures the --log-format, only logs warnings and errors for --quiet and logs to stderr for
+// --porcelain so that stdout only contains the Pull Requests
+func (o *Options) ConfigureOutput() error {
+	err := o.configureLogFormat()
+	if err != nil {
+		return err
+	}
+	if o.Quiet {
+		err = log.SetLevel("warn")
+		if err != nil {
+			return fmt.Errorf("failed to set log level: %w", err)
+		}
+	}
+	if o.Porcelain {
+		log.SetOutput(os.Stderr)
+	}
+	return nil
+}
+
+// PrintPullRequest prints a line for the Pull Request if --porcelain is enabled. The line contains the git URL of
+// the repository, the Pull Request number and its URL separated by tabs
+func (o *Options) PrintPullRequest(gitURL string, pr *scm.PullRequest) {
+	if !o.Porcelain || pr == nil {
+		return
+	}
+	out := o.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	_, _ = fmt.Fprintf(out, "%s\t%d\t%s\n", gitURL, pr.Number, pr.Link)
+}
+
+// StartOutput resets the Pull Requests recorded by the run. The records are shared by the copies of the options used
+// to process repositories concurrently so they are added with the lock
+func (o *Options) StartOutput() {
+	if o.mu == nil {
+		o.mu = &sync.Mutex{}
+	}
+	o.outputPullRequests = &[]OutputPullRequest{}
+}
+
+// RecordPullRequest adds the Pull Request created or reused on the repository to the --output-file and the
+// --slack-notify-success summary
+func (o *Options) RecordPullRequest(gitURL string, pr *scm.PullRequest) {
+	if (o.OutputFile == "" && !o.SlackNotifySuccess) || pr == nil {
+		return
+	}
+	title := pr.Title
+	if title == "" {
+		title = o.CommitTitle
+	}
+	branch := pr.Head.Ref
+	if branch == "" {
+		branch = pr.Source
+	}
+	o.withLock(func() {
+		if o.outputPullRequests == nil {
+			o.outputPullRequests = &[]OutputPullRequest{}
+		}
+		*o.outputPullRequests = append(*o.outputPullRequests, OutputPullRequest{
+			Repo:   gitURL,
+			Number: pr.Number,
+			URL:    pr.Link,
+			Title:  title,
+			Branch: branch,
+			Merged: pr.Merged,
+		})
+	})
+}
+
+// OutputPullRequests returns the Pull Requests recorded by the run
+func (o *Options) OutputPullRequests() []OutputPullRequest {
+	var answer []OutputPullRequest
+	o.withLock(func() {
+		if o.outputPullRequests != nil {
+			answer = append(answer, *o.outputPullRequests...)
+		}
+	})
+	return answer
+}
+
+// WriteOutputFile writes the Pull Requests and failures of the run to the --output-file. The error of the run is
+// included if no repository failures were recorded so that the file always explains a failed run
+func (o *Options) WriteOutputFile(runErr error) error {
+	if o.OutputFile == "" {
+		return nil
+	}
+	output := Output{PullRequests: o.OutputPullRequests()}
+	if output.PullRequests == nil {
+		output.PullRequests = []OutputPullRequest{}
+	}
+	for _, f := range o.failures {
+		output.Errors = append(output.Errors, OutputError{Repo: f.GitURL, Error: f.Error.Error()})
+	}
+	if runErr != nil && len(output.Errors) == 0 {
+		output.Errors = append(output.Errors, OutputError{Error: runErr.Error()})
+	}
+
+	var data []byte
+	var err error
+	switch o.OutputFormat {
+	case OutputFormatYAML:
+		data, err = yaml.Marshal(output)
+	default:
+		data, err = json.MarshalIndent(output, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+	err = os.WriteFile(o.OutputFile, data, files.DefaultFileWritePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to save output file %s: %w", o.OutputFile, err)
+	}
+	o.Logger().Infof("saved the Pull Requests to %s", info(o.OutputFile))
+	return nil
+}
+
+// validateOutputFormat checks the --output-format option
+func (o *Options) validateOutputFormat() error {
+	if o.OutputFormat == "" {
+		o.OutputFormat = OutputFormatJSON
+	}
+	if stringhelpers.StringArrayIndex(OutputFormats, o.OutputFormat) < 0 {
+		return options.InvalidOption("output-format", o.OutputFormat, OutputFormats)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package pr_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintPullRequest(t *testing.T) {
+	pullRequest := &scm.PullRequest{
+		Number: 7,
+		Link:   "https://github.com/myorg/myrepo/pull/7",
+	}
+
+	buf := &bytes.Buffer{}
+	o := &pr.Options{Out: buf}
+	o.PrintPullRequest("https://github.com/myorg/myrepo", pullRequest)
+	assert.Empty(t, buf.String(), "should not print without --porcelain")
+
+	o.Porcelain = true
+	o.PrintPullRequest("https://github.com/myorg/myrepo", pullRequest)
+	o.PrintPullRequest("https://github.com/myorg/another", nil)
+	assert.Equal(t, "https://github.com/myorg/myrepo\t7\thttps://github.com/myorg/myrepo/pull/7\n", buf.String())
+}
+
+func TestConfigureOutputQuiet(t *testing.T) {
+	level := log.GetLevel()
+	defer func() {
+		_ = log.SetLevel(level)
+	}()
+
+	o := &pr.Options{Quiet: true}
+	err := o.ConfigureOutput()
+	require.NoError(t, err, "failed to configure output")
+	assert.Equal(t, "warning", log.GetLevel())
+}
+
+func TestWriteOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	pullRequest := &scm.PullRequest{
+		Number: 7,
+		Title:  "chore: upgrade myapp to version 1.2.3",
+		Link:   "https://github.com/myorg/myrepo/pull/7",
+		Head:   scm.PullRequestBranch{Ref: "updatebot-123"},
+	}
+
+	o := &pr.Options{OutputFile: filepath.Join(dir, "output.json"), OutputFormat: pr.OutputFormatJSON}
+	o.RecordPullRequest("https://github.com/myorg/myrepo", pullRequest)
+	o.RecordPullRequest("https://github.com/myorg/another", nil)
+	err := o.WriteOutputFile(errors.New("failed to create Pull Requests for rule #1"))
+	require.NoError(t, err, "failed to write output file")
+
+	data, err := os.ReadFile(o.OutputFile)
+	require.NoError(t, err, "failed to read output file")
+	output := pr.Output{}
+	err = json.Unmarshal(data, &output)
+	require.NoError(t, err, "failed to parse output file")
+	assert.Equal(t, []pr.OutputPullRequest{
+		{
+			Repo:   "https://github.com/myorg/myrepo",
+			Number: 7,
+			URL:    "https://github.com/myorg/myrepo/pull/7",
+			Title:  "chore: upgrade myapp to version 1.2.3",
+			Branch: "updatebot-123",
+		},
+	}, output.PullRequests)
+	assert.Equal(t, []pr.OutputError{{Error: "failed to create Pull Requests for rule #1"}}, output.Errors)
+
+	o = &pr.Options{OutputFile: filepath.Join(dir, "output.yaml"), OutputFormat: pr.OutputFormatYAML}
+	err = o.WriteOutputFile(nil)
+	require.NoError(t, err, "failed to write output file")
+	data, err = os.ReadFile(o.OutputFile)
+	require.NoError(t, err, "failed to read output file")
+	assert.Equal(t, "pullRequests: []\n", string(data))
+}
+
+func TestWriteOutputFileConcurrently(t *testing.T) {
+	o := &pr.Options{OutputFile: filepath.Join(t.TempDir(), "output.json"), OutputFormat: pr.OutputFormatJSON}
+	o.StartOutput()
+
+	// each repository is processed with its own copy of the options when --concurrency is greater than 1
+	const repositories = 5
+	wg := sync.WaitGroup{}
+	for i := 1; i <= repositories; i++ {
+		ro := *o
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ro.RecordPullRequest(fmt.Sprintf("https://github.com/myorg/repo%d", i), &scm.PullRequest{Number: i})
+		}()
+	}
+	wg.Wait()
+
+	err := o.WriteOutputFile(nil)
+	require.NoError(t, err, "failed to write output file")
+	data, err := os.ReadFile(o.OutputFile)
+	require.NoError(t, err, "failed to read output file")
+	output := pr.Output{}
+	err = json.Unmarshal(data, &output)
+	require.NoError(t, err, "failed to parse output file")
+
+	var numbers []int
+	for _, pullRequest := range output.PullRequests {
+		numbers = append(numbers, pullRequest.Number)
+	}
+	assert.ElementsMatch(t, []int{1, 2, 3, 4, 5}, numbers, "should record the Pull Requests of every repository")
+}
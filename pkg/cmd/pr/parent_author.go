@@ -0,0 +1,157 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+const (
+	// DefaultAuthorCommitScanLimit the default maximum number of commits listed to find the parent of a commit which is
+	// not in the local clone
+	DefaultAuthorCommitScanLimit = 500
+
+	authorCommitPageSize = 50
+)
+
+// FindParentCommitAuthor finds the author of the parent of the commit in the local clone in --dir. The parents are read
+// from git rather than assumed from the commit order so merge commits with several parents and shallow clones are
+// handled. Parents on the baseRef are preferred, then the first parent which is not itself a merge commit is used.
+// If the local clone does not have the parents the commits of the baseRef are listed instead.
+// Returns an empty author with a warning if no parent can be found
+func (o *Options) FindParentCommitAuthor(ctx context.Context, scmClient *scm.Client, repoFullName, sha, baseRef string) (string, error) {
+	parents, err := o.CommitParents(sha)
+	if err != nil || len(parents) == 0 {
+		if err != nil {
+			o.Logger().Warnf("cannot find the parents of commit %s in the local clone: %s", sha, err.Error())
+		}
+		return o.FindListedParentCommitAuthor(ctx, scmClient, repoFullName, sha, baseRef)
+	}
+	if len(parents) > 1 && baseRef != "" {
+		parents = o.preferParentsOnRef(parents, baseRef)
+	}
+
+	parent := ""
+	for _, p := range parents {
+		grandParents, err := o.CommitParents(p)
+		if err == nil && len(grandParents) > 1 {
+			o.Logger().Debugf("ignoring parent %s of commit %s as it is a merge commit", p, sha)
+			continue
+		}
+		parent = p
+		break
+	}
+	if parent == "" {
+		o.Logger().Warnf("cannot find a parent of commit %s which is not a merge commit", sha)
+		return "", nil
+	}
+
+	commit, _, err := scmClient.Git.FindCommit(ctx, repoFullName, parent)
+	if err != nil {
+		return "", fmt.Errorf("failed to find commit %s: %w", parent, err)
+	}
+	if commit == nil || commit.Author.Login == "" {
+		o.Logger().Warnf("no author found for parent commit %s of commit %s", parent, sha)
+		return "", nil
+	}
+	o.Logger().Infof("found author %s of parent commit %s of commit %s", commit.Author.Login, parent, sha)
+	return commit.Author.Login, nil
+}
+
+// CommitParents returns the SHAs of the parents of the commit in the git repository in --dir. A shallow clone
+// truncated at the commit returns no parents
+func (o *Options) CommitParents(sha string) ([]string, error) {
+	text, err := o.Git().Command(o.Dir, "log", "-n", "1", "--pretty=%P", sha)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the parents of commit %s: %w", sha, err)
+	}
+	return strings.Fields(text), nil
+}
+
+// preferParentsOnRef returns the parents with those which are ancestors of the ref first
+func (o *Options) preferParentsOnRef(parents []string, ref string) []string {
+	var onRef, others []string
+	for _, p := range parents {
+		_, err := o.Git().Command(o.Dir, "merge-base", "--is-ancestor", p, ref)
+		if err == nil {
+			onRef = append(onRef, p)
+		} else {
+			others = append(others, p)
+		}
+	}
+	return append(onRef, others...)
+}
+
+// FindListedParentCommitAuthor pages through the commits of the ref using the git provider until it finds the commit
+// then returns the author of the commit listed after it. Paging stops after --author-commit-scan-limit commits or
+// once the commits are older than --author-since. As assigning the author is best effort an empty author is returned
+// with a warning if the commits cannot be listed or the commit is not found
+func (o *Options) FindListedParentCommitAuthor(ctx context.Context, scmClient *scm.Client, repoFullName, sha, ref string) (string, error) {
+	limit := o.AuthorScanLimit
+	if limit <= 0 {
+		limit = DefaultAuthorCommitScanLimit
+	}
+	since, err := o.AuthorSinceTime()
+	if err != nil {
+		return "", err
+	}
+
+	scanned := 0
+	found := false
+	for page := 1; scanned < limit || found; page++ {
+		commits, _, err := scmClient.Git.ListCommits(ctx, repoFullName, scm.CommitListOptions{Ref: o.CommitListRef(ref), Page: page, Size: authorCommitPageSize})
+		if err != nil {
+			o.Logger().Warnf("failed to list commits of repository %s to find the parent of commit %s: %s", repoFullName, sha, err.Error())
+			return "", nil
+		}
+		for _, c := range commits {
+			if scanned >= limit && !found {
+				break
+			}
+			scanned++
+			if found {
+				if c.Author.Login == "" {
+					o.Logger().Warnf("no author found for parent commit %s of commit %s", c.Sha, sha)
+				} else {
+					o.Logger().Infof("found author %s of parent commit %s of commit %s", c.Author.Login, c.Sha, sha)
+				}
+				return c.Author.Login, nil
+			}
+			if c.Sha == sha {
+				found = true
+				continue
+			}
+			if !since.IsZero() && !c.Committer.Date.IsZero() && c.Committer.Date.Before(since) {
+				o.Logger().Warnf("cannot find the parent of commit %s in the %d commits of repository %s since %s", sha, scanned, repoFullName, since.Format(time.RFC3339))
+				return "", nil
+			}
+		}
+		if len(commits) < authorCommitPageSize {
+			break
+		}
+	}
+	o.Logger().Warnf("cannot find the parent of commit %s in the %d commits scanned of repository %s", sha, scanned, repoFullName)
+	return "", nil
+}
+
+// AuthorSinceTime returns the time of the --author-since option which can be a date such as 2024-01-02, an RFC3339
+// time or a duration before now such as 72h. Returns the zero time if it is not specified
+func (o *Options) AuthorSinceTime() (time.Time, error) {
+	if o.AuthorSince == "" {
+		return time.Time{}, nil
+	}
+	d, err := time.ParseDuration(o.AuthorSince)
+	if err == nil {
+		return time.Now().Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		t, err := time.Parse(layout, o.AuthorSince)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --author-since %s: expected a date, an RFC3339 time or a duration", o.AuthorSince)
+}
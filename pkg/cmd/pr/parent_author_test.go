@@ -0,0 +1,133 @@
+package pr_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/go-scm/scm/driver/github"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner/fakerunner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindParentCommitAuthor(t *testing.T) {
+	// merge has the parents main-parent on the base branch and branch-merge which is itself a merge of feature
+	parents := map[string]string{
+		"merge":        "branch-merge main-parent",
+		"branch-merge": "feature other",
+		"main-parent":  "root",
+		"feature":      "root",
+		"single":       "feature",
+		"shallow":      "",
+	}
+	onMain := map[string]bool{"main-parent": true, "root": true}
+
+	testCases := []struct {
+		sha      string
+		baseRef  string
+		expected string
+	}{
+		{sha: "merge", baseRef: "main", expected: "main-author"},
+		{sha: "merge", baseRef: "", expected: "main-author"},
+		{sha: "single", expected: "feature-author"},
+		{sha: "shallow", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		runner := &fakerunner.FakeRunner{
+			CommandRunner: func(c *cmdrunner.Command) (string, error) {
+				args := c.Args
+				switch {
+				case len(args) == 5 && args[0] == "log" && args[3] == "--pretty=%P":
+					p, ok := parents[args[4]]
+					if !ok {
+						return "", fmt.Errorf("unknown commit %s", args[4])
+					}
+					return p, nil
+				case len(args) == 4 && args[0] == "merge-base" && args[1] == "--is-ancestor":
+					if onMain[args[2]] && args[3] == "main" {
+						return "", nil
+					}
+					return "", fmt.Errorf("not an ancestor")
+				}
+				return "", fmt.Errorf("unexpected command %s", c.CLI())
+			},
+		}
+		scmClient, fakeData := fake.NewDefault()
+		fakeData.Commits["main-parent"] = &scm.Commit{Sha: "main-parent", Author: scm.Signature{Login: "main-author"}}
+		fakeData.Commits["feature"] = &scm.Commit{Sha: "feature", Author: scm.Signature{Login: "feature-author"}}
+
+		o := &pr.Options{Dir: t.TempDir()}
+		o.CommandRunner = runner.Run
+
+		author, err := o.FindParentCommitAuthor(context.Background(), scmClient, "myorg/myrepo", tc.sha, tc.baseRef)
+		require.NoError(t, err, "failed to find parent author of %s", tc.sha)
+		assert.Equal(t, tc.expected, author, "parent author of %s with base ref %s", tc.sha, tc.baseRef)
+	}
+}
+
+func TestFindListedParentCommitAuthor(t *testing.T) {
+	// the commits are listed newest first one hour apart
+	now := time.Now()
+	var commits []map[string]interface{}
+	for i := 0; i < 120; i++ {
+		date := now.Add(-time.Duration(i) * time.Hour).UTC().Format(time.RFC3339)
+		commits = append(commits, map[string]interface{}{
+			"sha":       fmt.Sprintf("c%d", i),
+			"commit":    map[string]interface{}{"message": "a commit", "committer": map[string]interface{}{"name": "test", "date": date}},
+			"author":    map[string]interface{}{"login": fmt.Sprintf("author-%d", i)},
+			"committer": map[string]interface{}{"login": "test"},
+		})
+	}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/myorg/myrepo/commits" {
+			http.NotFound(w, r)
+			return
+		}
+		requests++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		start := min((page-1)*size, len(commits))
+		end := min(start+size, len(commits))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(commits[start:end])
+	}))
+	defer server.Close()
+
+	scmClient, err := github.New(server.URL)
+	require.NoError(t, err, "failed to create github client")
+
+	testCases := []struct {
+		name     string
+		sha      string
+		limit    int
+		since    string
+		expected string
+		requests int
+	}{
+		{name: "first-page", sha: "c3", expected: "author-4", requests: 1},
+		{name: "later-page", sha: "c70", expected: "author-71", requests: 2},
+		{name: "limit", sha: "c70", limit: 60, requests: 2},
+		{name: "since", sha: "c70", since: "24h", requests: 1},
+		{name: "missing", sha: "unknown", requests: 3},
+	}
+	for _, tc := range testCases {
+		requests = 0
+		o := &pr.Options{AuthorScanLimit: tc.limit, AuthorSince: tc.since}
+		author, err := o.FindListedParentCommitAuthor(context.Background(), scmClient, "myorg/myrepo", tc.sha, "main")
+		require.NoError(t, err, "failed to find parent author for %s", tc.name)
+		assert.Equal(t, tc.expected, author, "parent author for %s", tc.name)
+		assert.Equal(t, tc.requests, requests, "requests for %s", tc.name)
+	}
+}
@@ -0,0 +1,45 @@
+package pr
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+)
+
+// RunPostPullRequestCommand runs the --post-pr-command for the Pull Request with a shell. The details of the Pull
+// Request are passed as the PULL_REQUEST_NUMBER, PULL_REQUEST_URL, REPOSITORY_URL, VERSION and APPLICATION
+// environment variables. A failure is only logged unless --post-pr-command-fail is specified
+func (o *Options) RunPostPullRequestCommand(gitURL string, pr *scm.PullRequest) error {
+	if o.PostPRCommand == "" || pr == nil {
+		return nil
+	}
+	runner := o.CommandRunner
+	if runner == nil {
+		runner = cmdrunner.DefaultCommandRunner
+	}
+	c := &cmdrunner.Command{
+		Dir:  o.Dir,
+		Name: "sh",
+		Args: []string{"-c", o.PostPRCommand},
+		Env: map[string]string{
+			"PULL_REQUEST_NUMBER": strconv.Itoa(pr.Number),
+			"PULL_REQUEST_URL":    pr.Link,
+			"REPOSITORY_URL":      gitURL,
+			"VERSION":             o.Version,
+			"APPLICATION":         o.Application,
+		},
+		Out: os.Stdout,
+		Err: os.Stderr,
+	}
+	_, err := runner(c)
+	if err != nil {
+		if o.PostPRCommandFail {
+			return fmt.Errorf("failed to run post pull request command for %s: %w", pr.Link, err)
+		}
+		o.Logger().Warnf("failed to run post pull request command for %s: %s", pr.Link, err.Error())
+	}
+	return nil
+}
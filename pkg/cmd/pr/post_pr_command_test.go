@@ -0,0 +1,69 @@
+package pr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner/fakerunner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPostPullRequestCommand(t *testing.T) {
+	gitURL := "https://github.com/myorg/myrepo"
+	pullRequest := &scm.PullRequest{Number: 12, Link: "https://github.com/myorg/myrepo/pull/12"}
+
+	testCases := []struct {
+		name    string
+		command string
+		fail    bool
+		exitErr error
+		runs    int
+		err     bool
+	}{
+		{name: "disabled"},
+		{name: "success", command: "./ticket.sh", runs: 1},
+		{name: "failure-logged", command: "./ticket.sh", exitErr: errors.New("exit status 1"), runs: 1},
+		{name: "failure", command: "./ticket.sh", fail: true, exitErr: errors.New("exit status 1"), runs: 1, err: true},
+	}
+
+	for _, tc := range testCases {
+		runner := &fakerunner.FakeRunner{
+			CommandRunner: func(_ *cmdrunner.Command) (string, error) {
+				return "", tc.exitErr
+			},
+		}
+		o := &pr.Options{
+			Dir:               "/workspace",
+			Version:           "1.2.3",
+			PostPRCommand:     tc.command,
+			PostPRCommandFail: tc.fail,
+		}
+		o.Application = "myorg/myapp"
+		o.CommandRunner = runner.Run
+
+		err := o.RunPostPullRequestCommand(gitURL, pullRequest)
+		if tc.err {
+			require.Error(t, err, "should fail for test %s", tc.name)
+		} else {
+			require.NoError(t, err, "should not fail for test %s", tc.name)
+		}
+		require.Len(t, runner.OrderedCommands, tc.runs, "commands for test %s", tc.name)
+		if tc.runs == 0 {
+			continue
+		}
+		c := runner.OrderedCommands[0]
+		assert.Equal(t, "sh -c ./ticket.sh", c.CLI(), "command for test %s", tc.name)
+		assert.Equal(t, "/workspace", c.Dir, "dir for test %s", tc.name)
+		assert.Equal(t, map[string]string{
+			"PULL_REQUEST_NUMBER": "12",
+			"PULL_REQUEST_URL":    "https://github.com/myorg/myrepo/pull/12",
+			"REPOSITORY_URL":      gitURL,
+			"VERSION":             "1.2.3",
+			"APPLICATION":         "myorg/myapp",
+		}, c.Env, "environment for test %s", tc.name)
+	}
+}
@@ -2,14 +2,17 @@ package pr
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/jenkins-x-plugins/jx-gitops/pkg/cmd/git/setup"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/helmer"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/scmhelpers"
@@ -27,8 +30,11 @@ import (
 	"github.com/jenkins-x/jx-helpers/v3/pkg/termcolor"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/yamls"
 	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/sirupsen/logrus"
 
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -45,23 +51,120 @@ type Options struct {
 
 	Dir                string
 	ConfigFile         string
+	ConfigOverlay      string
+	ConfigURL          string
+	ConfigURLAuthEnv   string
+	VersionTrimPrefix  string
+	VersionTrimSuffix  string
+	BaseDir            string
 	Version            string
+	PreviousVersion    string
 	VersionFile        string
+	VersionsFile       string
+	VersionRegistry    string
+	Digest             string
+	TagPattern         string
+	VersionFormat      string
+	ChartVersionField  string
 	AddChangelog       string
+	ChangelogTemplate  string
 	GitCommitUsername  string
 	GitCommitUserEmail string
+	CommitAuthorName   string
+	CommitAuthorEmail  string
 	PipelineCommitSha  string
 	PipelineRepoURL    string
+	Sanitize           string
+	GitAPIServerURL    string
+	BodyTemplate       string
+	PostPRCommand      string
+	AssigneesFile      string
+	RepoFile           string
+	RepoFileTag        string
+	OutputFile         string
+	MetricsFile        string
+	PRMilestone        string
+	OutputFormat       string
+	LogFormat          string
+	SlackWebhook       string
+	SlackChannel       string
+	AuthorSince        string
+	GPGKeyID           string
+	BranchNameTemplate string
+	NoAutoMergeWindow  string
+	Timezone           string
 	AutoMerge          bool
 	NoVersion          bool
 	GitCredentials     bool
+	SkipGitUserSetup   bool
+	AutoRebase         bool
+	ConventionalCommit bool
+	Quiet              bool
+	Porcelain          bool
+	TriggerLabels      bool
+	ContinueOnError    bool
+	ContinueValidation bool
+	FailFast           bool
+	FailureReport      bool
+	VerifyCI           bool
+	AutoTrackingIssue  bool
+	BatchRepositories  bool
+	PostPRCommandFail  bool
+	SearchVersionFile  bool
+	PrintConfig        bool
+	DryRun             bool
+	SkipEmpty          bool
+	SlackNotifySuccess bool
+	SignCommits        bool
+	CloseSuperseded    bool
+	Draft              bool
+	CreateMilestone    bool
+	StripV             bool
+	VersionFromTag     bool
+	GenerateChangelog  bool
+	ChangesTable       bool
+	TrackingIssue      int
+	Concurrency        int
+	AuthorScanLimit    int
+	MaxPrune           int
+	CloneConcurrency   int
+	PRConcurrency      int
 	PRAssignees        []string
+	PRReviewers        []string
 	Labels             []string
+	ExcludeRepos       []string
 	TemplateData       map[string]interface{}
 	PullRequestSHAs    map[string]string
+	Versions           map[string]string
 	Helmer             helmer.Helmer
 	GraphQLClient      *githubv4.Client
+	Now                func() time.Time
 	UpdateConfig       v1alpha1.UpdateConfig
+	Out                io.Writer
+
+	gitAPIServerClient *scm.Client
+	ctx                context.Context
+	triggerLabels      []string
+	failures           []RepositoryFailure
+	trackingIssue      *scm.Issue
+	repoAssignees      map[string][]string
+	repoFileURLs       []string
+	scmClients         map[scmClientKey]*cachedScmClient
+	prSlots            chan struct{}
+	releaseClone       func()
+	mu                 *sync.Mutex
+	dryRunUpdates      map[string]bool
+	outputPullRequests *[]OutputPullRequest
+	upToDate           bool
+	resolvedURLs       bool
+	validationErr      error
+	downloadedConfig   string
+	ruleIndex          int
+	logFields          logrus.Fields
+	defaultBranches    map[string]string
+	forkUsername       *string
+	metrics            *Metrics
+	metricsStart       time.Time
 }
 
 // NewCmdPullRequest creates a command object for the command
@@ -79,38 +182,150 @@ func NewCmdPullRequest() (*cobra.Command, *Options) {
 	}
 	cmd.Flags().StringVarP(&o.Dir, "dir", "d", ".", "the directory look for the VERSION file")
 	cmd.Flags().StringVarP(&o.ConfigFile, "config-file", "c", "", "the updatebot config file. If none specified defaults to .jx/updatebot.yaml")
+	cmd.Flags().StringVarP(&o.ConfigURL, "config-url", "", "", "the HTTP(S) URL of the updatebot config to download if no --config-file is specified")
+	cmd.Flags().StringVarP(&o.ConfigURLAuthEnv, "config-url-auth-env", "", DefaultConfigURLAuthEnv, "the environment variable containing the Authorization header used to download the --config-url such as 'Bearer mytoken'")
+	cmd.Flags().StringVarP(&o.BaseDir, "base-dir", "", "", "the directory relative paths in the config file, such as pullRequestBodyTemplateFile and the dir of version stream rules, are resolved against. Defaults to the directory of the config file")
+	cmd.Flags().StringVarP(&o.ConfigOverlay, "config-overlay", "", "", "a YAML file or inline YAML merged over the updatebot config. Maps are merged and lists are replaced unless their elements have a name field")
 	cmd.Flags().StringVarP(&o.Version, "version", "", "", "the version number to promote. If not specified uses $VERSION or the version file")
+	cmd.Flags().StringVarP(&o.VersionTrimPrefix, "version-trim-prefix", "", "", "a prefix removed from the version once it is resolved such as refs/tags/ when the version comes from a git tag ref")
+	cmd.Flags().StringVarP(&o.VersionTrimSuffix, "version-trim-suffix", "", "", "a suffix removed from the version once it is resolved")
+	cmd.Flags().BoolVarP(&o.StripV, "strip-v", "", false, "removes a leading v from the version once it is resolved and any --version-trim-prefix is removed such as v1.2.3 to 1.2.3")
+	cmd.Flags().StringVarP(&o.PreviousVersion, "previous-version", "", os.Getenv("PREVIOUS_VERSION"), "the version being upgraded from used by --conventional-commit. If not specified uses $PREVIOUS_VERSION or the latest git tag before the current commit")
+	cmd.Flags().BoolVarP(&o.ConventionalCommit, "conventional-commit", "", false, "uses fix(deps), feat(deps) or feat(deps)! as the type of the generated commit title depending on whether the upgrade is a patch, minor or major release")
+	cmd.Flags().StringVarP(&o.VersionRegistry, "version-from-registry", "", "", "an image reference, such as ghcr.io/myorg/myapp, whose newest semantic version tag in the container registry is used as the version if not specified directly or via $VERSION. Uses the docker config file for authentication")
+	cmd.Flags().StringVarP(&o.Digest, "digest", "", "", "the digest, such as sha256:abc..., of the version used by changes with the digest option to update images pinned by digest. Defaults to resolving the digest of the tag in the registry of the image")
 	cmd.Flags().StringVarP(&o.VersionFile, "version-file", "", "", "the file to load the version from if not specified directly or via a $VERSION environment variable. Defaults to VERSION in the current dir")
+	cmd.Flags().StringVarP(&o.VersionFormat, "version-format", "", "", "the format of the version file: plain or chart. chart reads the version from a helm Chart.yaml. Defaults to chart if the version file is called Chart.yaml otherwise plain")
+	cmd.Flags().StringVarP(&o.ChartVersionField, "chart-version-field", "", "version", "the field of the Chart.yaml version file to read the version from: version or appVersion")
+	cmd.Flags().StringToStringVarP(&o.Versions, "versions", "", nil, "the named versions used by changes with a versionKey such as --versions myapp=1.2.3. Can be specified multiple times")
+	cmd.Flags().StringVarP(&o.VersionsFile, "versions-file", "", "", "the file of name=version lines to load the named versions used by changes with a versionKey from. Defaults to VERSIONS in the current dir if it exists")
+	cmd.Flags().BoolVarP(&o.SearchVersionFile, "search-version-file", "", false, "searches the parent directories of --dir up to the root of the git repository for the VERSION file if it is not in --dir and no --version-file is specified")
 	cmd.Flags().StringVarP(&o.Application, "app", "a", "", "the Application to promote. Used for informational purposes")
 	cmd.Flags().StringVarP(&o.AddChangelog, "add-changelog", "", "", "a file to take a changelog from to add to the pull request body. Typically a file generated by jx changelog.")
+	cmd.Flags().BoolVarP(&o.ChangesTable, "pr-body-changes-table", "", false, "appends a collapsible table of the lines changed in each file, taken from the git diff of the repository, to the pull request body")
+	cmd.Flags().BoolVarP(&o.GenerateChangelog, "generate-changelog", "", false, "generates the changelog added to the pull request body from the commits in the --dir between the previous and latest git tags. Ignored if --add-changelog is specified")
+	cmd.Flags().StringVarP(&o.ChangelogTemplate, "changelog-template", "", DefaultChangelogTemplate, "the go template of each commit of the --generate-changelog which can use the .SHA, .ShortSHA, .Subject and .Author of the commit")
 	cmd.Flags().StringVarP(&o.ChangelogSeparator, "changelog-separator", "", os.Getenv("CHANGELOG_SEPARATOR"), "the separator to use between commit message and changelog in the pull request body. Default to ----- or if set the CHANGELOG_SEPARATOR environment variable")
-	cmd.Flags().StringVar(&o.CommitTitle, "pull-request-title", "", "the PR title")
-	cmd.Flags().StringVar(&o.CommitMessage, "pull-request-body", "", "the PR body")
+	cmd.Flags().StringVar(&o.CommitTitle, "pull-request-title", "", "the PR title. If not specified uses $PR_TITLE")
+	cmd.Flags().StringVar(&o.CommitMessage, "pull-request-body", "", "the PR body. If not specified uses $PR_BODY")
+	cmd.Flags().StringVarP(&o.BodyTemplate, "pull-request-body-template", "", "", "a go template file used to render the PR body. Rules can override it with pullRequestBodyTemplate or pullRequestBodyTemplateFile")
 	cmd.Flags().StringVarP(&o.GitCommitUsername, "git-user-name", "", "", "the user name to git commit")
 	cmd.Flags().StringVarP(&o.GitCommitUserEmail, "git-user-email", "", "", "the user email to git commit")
+	cmd.Flags().StringVarP(&o.CommitAuthorName, "commit-author-name", "", "", "the name of the author of the commits if it differs from the --git-user-name committer. Defaults to the author of the pipeline commit for rules which assign the author to Pull Requests")
+	cmd.Flags().StringVarP(&o.CommitAuthorEmail, "commit-author-email", "", "", "the email of the author of the commits if it differs from the --git-user-email committer")
 	cmd.Flags().StringVarP(&o.PipelineCommitSha, "pipeline-commit-sha", "", os.Getenv("PULL_BASE_SHA"), "the git SHA of the commit that triggered the pipeline")
 	cmd.Flags().StringVarP(&o.PipelineRepoURL, "pipeline-repo-url", "", os.Getenv("REPO_URL"), "the git URL of the repository that triggered the pipeline")
 	cmd.Flags().StringSliceVar(&o.Labels, "labels", []string{}, "a list of labels to apply to the PR")
 	cmd.Flags().StringSliceVar(&o.PRAssignees, "pull-request-assign", []string{}, "Assignees of created PRs")
+	cmd.Flags().StringSliceVar(&o.PRReviewers, "pull-request-reviewer", []string{}, "the users requested to review created PRs")
+	cmd.Flags().StringVarP(&o.PRMilestone, "pr-milestone", "", "", "the title of the milestone to add created PRs to if the rule does not specify a milestone")
+	cmd.Flags().BoolVarP(&o.CreateMilestone, "create-milestone", "", false, "creates the milestone of the PRs if it does not exist in the repository rather than warning")
+	cmd.Flags().StringVarP(&o.AssigneesFile, "assignees-file", "", "", "a YAML file mapping repository names, such as myorg/myrepo, or git URLs to the users assigned to their Pull Requests along with the assignees of the rule")
+	cmd.Flags().IntVarP(&o.AuthorScanLimit, "author-commit-scan-limit", "", DefaultAuthorCommitScanLimit, "the maximum number of commits listed to find the parent of a merge commit whose author is assigned when the parent is not in the local clone")
+	cmd.Flags().StringVarP(&o.AuthorSince, "author-since", "", "", "only lists commits since this date, RFC3339 time or duration before now, such as 720h, to find the parent of a merge commit whose author is assigned")
 	cmd.Flags().BoolVarP(&o.AutoMerge, "auto-merge", "", true, "should we automatically merge if the PR pipeline is green")
+	cmd.Flags().StringVarP(&o.BranchNameTemplate, "branch-name-template", "", "", "a template of the name of the Pull Request branch such as updatebot/{{.App}}-{{.Version}} so repeated runs for the same version force push to the same branch and update its Pull Request rather than creating a new branch. Defaults to a generated branch name")
+	cmd.Flags().StringVarP(&o.NoAutoMergeWindow, "no-automerge-window", "", "", "a window of quiet hours such as 22:00-06:00 during which Pull Requests are created without auto merge regardless of the rules. They still have the updatebot label so a later run reusing them can merge them")
+	cmd.Flags().StringVarP(&o.Timezone, "timezone", "", "", "the timezone of the --no-automerge-window such as Europe/London. Defaults to the local timezone")
+	cmd.Flags().BoolVarP(&o.SignCommits, "sign-commits", "", false, "signs the commits pushed to the Pull Request branches with GPG. Requires a --gpg-key-id or user.signingkey in the git config")
+	cmd.Flags().StringVarP(&o.GPGKeyID, "gpg-key-id", "", os.Getenv("GPG_KEY_ID"), "the ID of the GPG key used to sign commits with --sign-commits. Defaults to $GPG_KEY_ID or the user.signingkey of the git config")
+	cmd.Flags().BoolVarP(&o.VersionFromTag, "version-from-tag", "", false, "uses the latest git tag in the --dir, with any v prefix removed, as the version if not specified directly or via --version-file. Falls back to the VERSION file or $VERSION if there is no tag")
+	cmd.Flags().StringVarP(&o.TagPattern, "tag-pattern", "", "", "a glob of the git tags used by --version-from-tag such as v*")
 	cmd.Flags().BoolVarP(&o.NoVersion, "no-version", "", false, "disables validation on requiring a '--version' option or environment variable to be required")
 	cmd.Flags().BoolVarP(&o.GitCredentials, "git-credentials", "", false, "ensures the git credentials are setup so we can push to git")
+	cmd.Flags().BoolVarP(&o.AutoRebase, "auto-rebase", "", false, "rebases reused pull requests which conflict with their base branch rather than only labelling them "+LabelNeedsRebase)
+	cmd.Flags().BoolVarP(&o.SkipGitUserSetup, "skip-git-user-setup", "", false, "skips setting up the git user name and email as the environment already has a git identity configured")
+	cmd.Flags().BoolVarP(&o.TriggerLabels, "trigger-labels", "", false, "only runs rules whose triggerLabels are all on the pull request that created the --pipeline-commit-sha commit")
+	cmd.Flags().BoolVarP(&o.ContinueOnError, "continue-on-error", "", false, "continues with the remaining repositories if a repository fails to be updated. The command still fails once all repositories are processed")
+	cmd.Flags().BoolVarP(&o.FailFast, "fail-fast", "", true, "fails on the first rule or repository which fails. If disabled all the rules and repositories are processed and the error summarizes each failure with its rule index and repository")
+	cmd.Flags().BoolVarP(&o.ContinueValidation, "continue-on-validation-failure", "", false, "continues with the remaining repositories if the validate commands of a rule fail on a repository. The command still fails once all repositories are processed")
+	cmd.Flags().BoolVarP(&o.FailureReport, "failure-report", "", false, "opens an issue in the --pipeline-repo-url repository listing the repositories which failed with --continue-on-error, or comments on the issue if it is already open")
+	cmd.Flags().BoolVarP(&o.VerifyCI, "verify-ci", "", false, "only enables auto merge on repositories which have CI configured, such as GitHub workflows or a .lighthouse directory, so pull requests are not merged without any checks")
+	cmd.Flags().IntVarP(&o.TrackingIssue, "tracking-issue", "", 0, "the number of an issue in the --pipeline-repo-url repository which is referenced by each pull request and lists the pull requests as a checklist")
+	cmd.Flags().BoolVarP(&o.AutoTrackingIssue, "create-tracking-issue", "", false, "creates a tracking issue in the --pipeline-repo-url repository if no --tracking-issue is specified")
+	cmd.Flags().BoolVarP(&o.DryRun, "dry-run", "", false, "clones the repositories and applies the changes then logs the diff, title, body, labels, assignees and reviewers of each Pull Request without pushing any branches or creating any Pull Requests")
+	cmd.Flags().BoolVarP(&o.SkipEmpty, "skip-empty", "", true, "skips creating a Pull Request on repositories which are not changed by the changes as they are already up to date. Stale Pull Requests reused by reusePullRequest rules are closed")
+	cmd.Flags().BoolVarP(&o.Draft, "draft", "", false, "creates the Pull Requests as drafts which are not auto merged. Git providers which do not support drafts create a normal Pull Request with a warning")
+	cmd.Flags().BoolVarP(&o.CloseSuperseded, "close-superseded", "", true, "closes the older open Pull Requests matching the labels of a reusePullRequest rule with a comment so only the newest is reused rather than leaving duplicates")
+	cmd.Flags().IntVarP(&o.MaxPrune, "max-prune", "", DefaultMaxPrune, "the maximum number of resources a version stream change with prune enabled can remove from a repository. The repository fails if more would be removed. 0 disables the limit")
+	cmd.Flags().BoolVarP(&o.PrintConfig, "print-config", "", false, "prints the effective config as YAML after applying the config overlay and generating the version stream rules then exits without creating any Pull Requests")
+	cmd.Flags().StringVarP(&o.PostPRCommand, "post-pr-command", "", "", "a shell command run after each Pull Request is created or updated. The PULL_REQUEST_NUMBER, PULL_REQUEST_URL, REPOSITORY_URL, VERSION and APPLICATION environment variables describe the Pull Request")
+	cmd.Flags().BoolVarP(&o.PostPRCommandFail, "post-pr-command-fail", "", false, "fails the repository if the --post-pr-command fails rather than only logging a warning")
+	cmd.Flags().BoolVarP(&o.BatchRepositories, "batch-by-repository", "", false, "creates a single Pull Request for each repository with the changes of all the rules which update it. The Pull Request body lists each application and version")
+	cmd.Flags().IntVarP(&o.Concurrency, "concurrency", "", 1, "the maximum number of repositories processed in parallel. Raises the --clone-concurrency and --pr-concurrency limits to this value. If a repository fails the others are still processed and the error lists all the repositories which failed")
+	cmd.Flags().IntVarP(&o.CloneConcurrency, "clone-concurrency", "", 1, "the maximum number of repositories which are cloned and changed at the same time")
+	cmd.Flags().IntVarP(&o.PRConcurrency, "pr-concurrency", "", 1, "the maximum number of repositories which are pushed and have their Pull Request created at the same time to limit the load on the git provider API")
+	cmd.Flags().StringVarP(&o.RepoFile, "repo-file", "", "", "a file of the git URLs of repositories, one per line or separated by commas, which are added to the URLs of every rule. Blank lines and lines starting with # are ignored")
+	cmd.Flags().StringVarP(&o.RepoFileTag, "repo-file-tag", "", "", "only adds the repositories of the --repo-file to the rules with this tag")
+	cmd.Flags().StringArrayVarP(&o.ExcludeRepos, "exclude-repo", "", nil, "a glob of the git URLs or owner/name of repositories which are never updated by any rule such as myorg/legacy-*. Can be specified multiple times")
+	cmd.Flags().BoolVarP(&o.Quiet, "quiet", "q", false, "only logs warnings and errors")
+	cmd.Flags().StringVarP(&o.LogFormat, "log-format", "", LogFormatText, "the format of the logs: text or json. The json format logs a JSON object per line with the rule, repo and branch fields of the repository being processed")
+	cmd.Flags().StringVarP(&o.OutputFile, "output-file", "", "", "a file to write the Pull Requests created or reused to, along with any failures, once the command completes")
+	cmd.Flags().StringVarP(&o.MetricsFile, "metrics-file", "", "", "a file to write the counts of the rules, repositories, Pull Requests created and reused, repositories skipped and failures of the run to as JSON once the command completes")
+	cmd.Flags().StringVarP(&o.OutputFormat, "output-format", "", OutputFormatJSON, "the format of the --output-file: json or yaml")
+	cmd.Flags().StringVarP(&o.SlackWebhook, "slack-webhook", "", os.Getenv("SLACK_WEBHOOK_URL"), "the URL of a Slack incoming webhook which is posted a message for each repository which fails. Defaults to $SLACK_WEBHOOK_URL")
+	cmd.Flags().StringVarP(&o.SlackChannel, "slack-channel", "", "", "the Slack channel to post to if it differs from the default channel of the --slack-webhook")
+	cmd.Flags().BoolVarP(&o.SlackNotifySuccess, "slack-notify-success", "", false, "also posts a summary of the Pull Requests created or reused to the --slack-webhook once the command completes")
+	cmd.Flags().BoolVarP(&o.Porcelain, "porcelain", "", false, "prints a line for each pull request to stdout with the repository git URL, pull request number and URL separated by tabs. Logs are written to stderr")
+	cmd.Flags().StringVarP(&o.Sanitize, "sanitize", "", SanitizeControl, fmt.Sprintf("how to sanitize the pull request title and body before creating it. Possible values: %s", strings.Join(SanitizeLevels, ", ")))
+	cmd.Flags().StringVarP(&o.GitAPIServerURL, "git-api-server", "", os.Getenv("GIT_API_SERVER"), "the URL of the git API server used to create pull requests if it differs from the git server repositories are pushed to, such as an internal mirror. Defaults to $GIT_API_SERVER")
 	o.EnvironmentPullRequestOptions.ScmClientFactory.AddFlags(cmd)
 
 	cmd.Flags().StringVarP(&o.CommitTitle, "commit-title", "", "", "the commit title")
 	cmd.Flags().StringVarP(&o.CommitMessage, "commit-message", "", "", "the commit message")
-	cmd.Flags().StringVarP(&o.BaseBranchName, "base-branch-name", "b", "", "the base branch name to use for new pull requests")
+	cmd.Flags().StringVarP(&o.BaseBranchName, "base-branch-name", "b", "", "the base branch name to use for new pull requests. Can be a template using the version such as release/{{.VersionMajorMinor}}. Defaults to the default branch of each repository")
 
 	return cmd, o
 }
 
 // Run implements the command
-func (o *Options) Run() error {
-	err := o.Validate()
+func (o *Options) Run() (err error) {
+	err = o.ConfigureOutput()
+	if err != nil {
+		return fmt.Errorf("failed to configure output: %w", err)
+	}
+	defer o.RemoveDownloadedConfig()
+	if o.PrintConfig {
+		return o.PrintEffectiveConfig()
+	}
+	if !o.FailFast {
+		// the failures of every repository are collected as well as those of every rule
+		o.ContinueOnError = true
+	}
+
+	shutdownTracing, err := StartTracing(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to start tracing: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			o.Logger().Warnf("failed to shutdown tracing: %s", err.Error())
+		}
+	}()
+	runCtx, span := o.startSpan("updatebot.run")
+	o.ctx = runCtx
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	err = o.Validate()
 	if err != nil {
 		return fmt.Errorf("failed to validate: %w", err)
 	}
 
+	// lets write the output file and metrics even if some rules fail so that the Pull Requests which were created are
+	// recorded
+	o.StartMetrics()
+	o.StartOutput()
+	defer func() {
+		if outputErr := o.WriteOutputFile(err); outputErr != nil {
+			o.Logger().Warnf("%s", outputErr.Error())
+		}
+		if metricsErr := o.ReportMetrics(err); metricsErr != nil {
+			o.Logger().Warnf("%s", metricsErr.Error())
+		}
+	}()
+
 	// Auto-discover git URL and commit details if not provided
 	err = o.SetCommitDetails(o.Dir)
 	if err != nil {
@@ -123,18 +338,129 @@ func (o *Options) Run() error {
 		return fmt.Errorf("failed to set changelog: %w", err)
 	}
 
+	err = o.GenerateVersionStreamRules()
+	if err != nil {
+		return fmt.Errorf("failed to generate version stream rules: %w", err)
+	}
+
+	if o.TriggerLabels {
+		o.triggerLabels, err = o.FindTriggerLabels(o.PipelineRepoURL, o.PipelineCommitSha, o.GitKind)
+		if err != nil {
+			return fmt.Errorf("failed to find trigger labels: %w", err)
+		}
+	}
+
+	if o.DryRun {
+		o.dryRunUpdates = map[string]bool{}
+	} else {
+		err = o.EnsureTrackingIssue()
+		if err != nil {
+			return fmt.Errorf("failed to find tracking issue: %w", err)
+		}
+	}
+
 	BaseBranchName := o.BaseBranchName
 
+	var rules []v1alpha1.Rule
+	// the indexes of the rules in the config so that messages and failures refer to the rules as configured
+	var indexes []int
 	for i, rule := range o.UpdateConfig.Spec.Rules {
-		err = o.ProcessRule(&rule, i)
+		if o.TriggerLabels && !MatchesTriggerLabels(&rule, o.triggerLabels) {
+			o.Logger().Infof("skipping rule #%d as the trigger labels %v are not all on the triggering pull request", i, rule.TriggerLabels)
+			continue
+		}
+		matches, err := o.MatchesVersionConstraint(&rule, i)
+		if err != nil {
+			return err
+		}
+		if !matches {
+			continue
+		}
+		rules = append(rules, rule)
+		indexes = append(indexes, i)
+	}
+	rules, positions := OrderRulesByDependencies(rules)
+	indexes = rulePositionIndexes(indexes, positions)
+	if o.BatchRepositories {
+		rules, indexes, err = o.ResolveRuleURLs(rules, indexes)
+		if err != nil {
+			return err
+		}
+		// a batch is reported as the first rule it contains
+		rules, positions = BatchRulesByRepository(rules)
+		indexes = rulePositionIndexes(indexes, positions)
+	}
+	o.metrics.Rules = len(rules)
+
+	// the IDs of the rules which failed, or were skipped, so that the rules depending on them are skipped
+	failedRules := map[string]bool{}
+	for i, rule := range rules {
+		index := indexes[i]
+		if dep := FailedDependency(&rule, failedRules); dep != "" {
+			o.Logger().Warnf("skipping rule %s as the rule %s it depends on failed", ruleID(&rule, index), dep)
+			failedRules[rule.ID] = true
+			o.metrics.BlockedRules++
+			continue
+		}
+		o.ctx = runCtx
+		failures := len(o.failures)
+		err = o.runRule(&rule, index, BaseBranchName)
 		if err != nil {
-			return fmt.Errorf("failed to process rule #%d: %w", i, err)
+			if o.FailFast {
+				return err
+			}
+			o.Logger().Warnf("%s, continuing with the remaining rules", err.Error())
+			o.failures = append(o.failures, RepositoryFailure{Rule: index, Error: err})
+		}
+		if len(o.failures) > failures {
+			failedRules[rule.ID] = true
 		}
+	}
+	if o.DryRun {
+		o.Logger().Infof("dry run: %d repositories would be updated", len(o.DryRunRepositories()))
+	}
+	o.NotifySlackSuccess()
+	return o.reportFailures()
+}
 
-		if err := o.ProcessAndCreatePullRequests(&rule, BaseBranchName, o.Labels, o.AutoMerge); err != nil {
-			return fmt.Errorf("failed to create Pull Requests for rule #%d: %w", i, err)
+// reportFailures creates the failure report if enabled and returns an error if any repositories failed
+func (o *Options) reportFailures() error {
+	if len(o.failures) == 0 {
+		return nil
+	}
+	if o.FailureReport && !o.DryRun {
+		_, err := o.CreateFailureReport(o.failures)
+		if err != nil {
+			o.Logger().Warnf("failed to create failure report: %s", err.Error())
 		}
 	}
+	if !o.FailFast {
+		return FailuresError(o.failures)
+	}
+	return fmt.Errorf("failed to create Pull Requests on %d repositories", len(o.failures))
+}
+
+// runRule processes the rule and creates its Pull Requests within a trace span for the rule
+func (o *Options) runRule(rule *v1alpha1.Rule, index int, baseBranch string) (err error) {
+	var span trace.Span
+	o.ctx, span = o.startSpan("updatebot.rule", attribute.Int("rule.index", index))
+	defer func() {
+		endSpan(span, err)
+	}()
+	o.ruleIndex = index
+	o.logFields = logrus.Fields{"rule": index}
+	defer func() {
+		o.logFields = nil
+	}()
+
+	err = o.ProcessRule(rule, index)
+	if err != nil {
+		return fmt.Errorf("failed to process rule #%d: %w", index, err)
+	}
+
+	if err := o.ProcessAndCreatePullRequests(rule, baseBranch, o.Labels, o.AutoMerge); err != nil {
+		return fmt.Errorf("failed to create Pull Requests for rule #%d: %w", index, err)
+	}
 	return nil
 }
 
@@ -142,54 +468,119 @@ func (o *Options) Validate() error {
 	if o.TemplateData == nil {
 		o.TemplateData = map[string]interface{}{}
 	}
+	o.AddCommitTemplateData()
 	if o.PullRequestSHAs == nil {
 		o.PullRequestSHAs = map[string]string{}
 	}
+	if o.Sanitize != "" && stringhelpers.StringArrayIndex(SanitizeLevels, o.Sanitize) < 0 {
+		return options.InvalidOption("sanitize", o.Sanitize, SanitizeLevels)
+	}
+	err := o.validateOutputFormat()
+	if err != nil {
+		return err
+	}
+	_, err = o.AuthorSinceTime()
+	if err != nil {
+		return err
+	}
+	_, err = o.QuietHours()
+	if err != nil {
+		return err
+	}
+	if o.Digest != "" && !IsImageDigest(o.Digest) {
+		return fmt.Errorf("invalid --digest %s, should be sha256: followed by 64 hex characters", o.Digest)
+	}
+	if o.Version == "" && o.VersionRegistry != "" {
+		var err error
+		o.Version, err = o.FindLatestImageTag(context.Background(), o.VersionRegistry, false)
+		if err != nil {
+			return fmt.Errorf("failed to find version from registry: %w", err)
+		}
+	}
+	if o.Version == "" && o.VersionFromTag && o.VersionFile == "" {
+		o.Version = o.VersionFromGitTag()
+	}
 	if o.Version == "" {
 		if o.VersionFile == "" {
 			o.VersionFile = filepath.Join(o.Dir, "VERSION")
+			if o.SearchVersionFile {
+				path, err := FindVersionFile(o.Dir, "VERSION")
+				if err != nil {
+					return fmt.Errorf("failed to search for the version file: %w", err)
+				}
+				if path != "" {
+					o.Logger().Infof("found version file %s", path)
+					o.VersionFile = path
+				}
+			}
 		}
 		exists, err := files.FileExists(o.VersionFile)
 		if err != nil {
 			return fmt.Errorf("failed to check for file %s: %w", o.VersionFile, err)
 		}
 		if exists {
-			data, err := os.ReadFile(o.VersionFile)
+			o.Version, err = o.ReadVersionFile(o.VersionFile)
 			if err != nil {
-				return fmt.Errorf("failed to read version file %s: %w", o.VersionFile, err)
+				return err
 			}
-			o.Version = strings.TrimSpace(string(data))
 		} else {
-			log.Logger().Infof("version file %s does not exist", o.VersionFile)
+			o.Logger().Infof("version file %s does not exist", o.VersionFile)
 		}
 	}
+	if o.CommitTitle == "" {
+		o.CommitTitle = os.Getenv("PR_TITLE")
+	}
+	if o.CommitMessage == "" {
+		o.CommitMessage = os.Getenv("PR_BODY")
+	}
+	err = o.LoadVersions()
+	if err != nil {
+		return err
+	}
 	if o.Version == "" {
 		o.Version = os.Getenv("VERSION")
-		if o.Version == "" && !o.NoVersion {
+		if o.Version == "" && len(o.Versions) == 0 && !o.NoVersion {
 			return options.MissingOption("version")
 		}
 	}
+	o.Version = o.TrimVersion(o.Version)
 
-	// lets default the config file
-	if o.ConfigFile == "" {
-		o.ConfigFile = filepath.Join(o.Dir, ".jx", "updatebot.yaml")
-	}
-	exists, err := files.FileExists(o.ConfigFile)
+	o.AddVersionTemplateData()
+
+	err = o.LoadConfig()
 	if err != nil {
-		return fmt.Errorf("failed to check for file %s: %w", o.ConfigFile, err)
+		return fmt.Errorf("failed to load config: %w", err)
 	}
-	if exists {
-		err = yamls.LoadFile(o.ConfigFile, &o.UpdateConfig)
-		if err != nil {
-			return fmt.Errorf("failed to load config file %s: %w", o.ConfigFile, err)
-		}
-	} else {
-		log.Logger().Warnf("file %s does not exist so cannot create any updatebot Pull Requests", o.ConfigFile)
+	err = o.ValidateVersionConstraints()
+	if err != nil {
+		return err
 	}
 
 	if len(o.Labels) == 0 {
 		o.Labels = o.UpdateConfig.Spec.PullRequestLabels
 	}
+	err = o.ValidatePullRequestLabels(o.Labels)
+	if err != nil {
+		return err
+	}
+	for i := range o.UpdateConfig.Spec.Rules {
+		err = o.ValidatePullRequestLabels(o.UpdateConfig.Spec.Rules[i].PullRequestLabels)
+		if err != nil {
+			return fmt.Errorf("invalid rule #%d: %w", i, err)
+		}
+		_, err = SparseCheckoutPathPatterns(o.UpdateConfig.Spec.Rules[i].SparseCheckoutPaths)
+		if err != nil {
+			return fmt.Errorf("invalid rule #%d: %w", i, err)
+		}
+	}
+	err = o.LoadAssignees()
+	if err != nil {
+		return err
+	}
+	err = o.LoadRepoFile()
+	if err != nil {
+		return err
+	}
 
 	if o.Helmer == nil {
 		o.Helmer = helmer.NewHelmCLIWithRunner(o.CommandRunner, "helm", o.Dir, false)
@@ -198,9 +589,13 @@ func (o *Options) Validate() error {
 	// lazy create the git client
 	g := o.EnvironmentPullRequestOptions.Git()
 
-	_, _, err = gitclient.EnsureUserAndEmailSetup(g, o.Dir, o.GitCommitUsername, o.GitCommitUserEmail)
-	if err != nil {
-		return fmt.Errorf("failed to setup git user and email: %w", err)
+	if o.SkipGitUserSetup {
+		o.Logger().Debugf("skipping git user and email setup")
+	} else {
+		_, _, err = gitclient.EnsureUserAndEmailSetup(g, o.Dir, o.GitCommitUsername, o.GitCommitUserEmail)
+		if err != nil {
+			return fmt.Errorf("failed to setup git user and email: %w", err)
+		}
 	}
 
 	// lets try default the git user/token
@@ -242,18 +637,16 @@ func (o *Options) Validate() error {
 		if o.ScmClientFactory.GitToken == "" {
 			return fmt.Errorf("missing git token environment variable. Try setting GIT_TOKEN or GITHUB_TOKEN")
 		}
-		_, gc := setup.NewCmdGitSetup()
-		gc.Dir = o.Dir
-		gc.DisableInClusterTest = true
-		gc.UserEmail = o.GitCommitUserEmail
-		gc.UserName = o.GitCommitUsername
-		gc.Password = o.ScmClientFactory.GitToken
-		gc.GitProviderURL = "https://github.com"
+		gc := o.GitCredentialsSetup()
 		err = gc.Run()
 		if err != nil {
 			return fmt.Errorf("failed to setup git credentials file: %w", err)
 		}
-		log.Logger().Infof("setup git credentials file for user %s and email %s", gc.UserName, gc.UserEmail)
+		o.Logger().Infof("setup git credentials file for user %s and email %s on %s", gc.UserName, gc.UserEmail, gc.GitProviderURL)
+	}
+	err = o.validateCommitSigning()
+	if err != nil {
+		return err
 	}
 	if o.ChangelogSeparator == "" {
 		o.ChangelogSeparator = "-----"
@@ -261,27 +654,155 @@ func (o *Options) Validate() error {
 	return nil
 }
 
+// LoadConfig loads the config file, defaulting to .jx/updatebot.yaml in the directory, and applies the config overlay
+func (o *Options) LoadConfig() error {
+	if o.ConfigURL != "" && o.downloadedConfig == "" {
+		if o.ConfigFile != "" {
+			o.Logger().Infof("using the config file %s rather than the config URL %s", o.ConfigFile, o.ConfigURL)
+		} else {
+			err := o.DownloadConfig()
+			if err != nil {
+				return err
+			}
+			// relative paths in a downloaded config are resolved against the dir rather than the temporary file
+			if o.BaseDir == "" {
+				o.BaseDir = o.Dir
+			}
+		}
+	}
+	if o.ConfigFile == "" {
+		o.ConfigFile = filepath.Join(o.Dir, ".jx", "updatebot.yaml")
+	}
+	if o.BaseDir == "" {
+		o.BaseDir = filepath.Dir(o.ConfigFile)
+	}
+	exists, err := files.FileExists(o.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to check for file %s: %w", o.ConfigFile, err)
+	}
+	if exists {
+		err = yamls.LoadFile(o.ConfigFile, &o.UpdateConfig)
+		if err != nil {
+			return fmt.Errorf("failed to load config file %s: %w", o.ConfigFile, err)
+		}
+	} else {
+		o.Logger().Warnf("file %s does not exist so cannot create any updatebot Pull Requests", o.ConfigFile)
+	}
+	err = o.ApplyConfigOverlay()
+	if err != nil {
+		return fmt.Errorf("failed to apply config overlay: %w", err)
+	}
+	err = ValidateRuleDependencies(ConfigRules(&o.UpdateConfig))
+	if err != nil {
+		return fmt.Errorf("invalid rule dependencies: %w", err)
+	}
+	return o.ValidateTransforms()
+}
+
 func (o *Options) GetSparseCheckoutPatterns(rule *v1alpha1.Rule) ([]string, error) {
+	if len(rule.SparseCheckoutPaths) > 0 {
+		return SparseCheckoutPathPatterns(rule.SparseCheckoutPaths)
+	}
 	patterns := make([]string, len(rule.Changes))
 	for _, change := range rule.Changes {
 		if change.Command != nil {
 			return nil, fmt.Errorf("sparse checkout not supported for command change")
 		}
+		if change.Marker {
+			patterns = stringhelpers.EnsureStringArrayContains(patterns, "/"+MarkerFile)
+		}
 		if change.VersionStream != nil {
 			return nil, fmt.Errorf("sparse checkout not supported for VersionStream change")
 		}
+		if change.Move != nil {
+			return nil, fmt.Errorf("sparse checkout not supported for move change")
+		}
+		if len(change.AfterChange) > 0 {
+			return nil, fmt.Errorf("sparse checkout not supported for afterChange commands")
+		}
+		var changePatterns []string
 		if change.Go != nil {
-			patterns = append(patterns, o.SparseCheckoutPatternsGo()...)
+			changePatterns = append(changePatterns, o.SparseCheckoutPatternsGo()...)
 		}
 		if change.Regex != nil {
-			patterns = append(patterns, o.SparseCheckoutPatternsRegex(change.Regex)...)
+			changePatterns = append(changePatterns, o.SparseCheckoutPatternsRegex(change.Regex)...)
+		}
+		if change.Checksum != nil {
+			changePatterns = append(changePatterns, o.SparseCheckoutPatternsRegex(&v1alpha1.Regex{Globs: change.Checksum.Globs})...)
+		}
+		if change.Set != nil {
+			changePatterns = append(changePatterns, o.SparseCheckoutPatternsRegex(&v1alpha1.Regex{Globs: change.Set.Globs})...)
+		}
+		if change.ImageDigest != nil {
+			changePatterns = append(changePatterns, o.SparseCheckoutPatternsRegex(&v1alpha1.Regex{Globs: change.ImageDigest.Globs})...)
+		}
+		if change.JSON != nil {
+			changePatterns = append(changePatterns, o.SparseCheckoutPatternsRegex(&v1alpha1.Regex{Globs: change.JSON.Globs})...)
+		}
+		if change.TOML != nil {
+			changePatterns = append(changePatterns, o.SparseCheckoutPatternsRegex(&v1alpha1.Regex{Globs: change.TOML.Globs})...)
+		}
+		if change.Properties != nil {
+			changePatterns = append(changePatterns, o.SparseCheckoutPatternsRegex(&v1alpha1.Regex{Globs: change.Properties.Globs})...)
+		}
+		if change.YAML != nil {
+			changePatterns = append(changePatterns, o.SparseCheckoutPatternsRegex(&v1alpha1.Regex{Globs: change.YAML.Globs})...)
+		}
+		if change.Dockerfile != nil {
+			changePatterns = append(changePatterns, o.SparseCheckoutPatternsRegex(&v1alpha1.Regex{Globs: DockerfileGlobs(change.Dockerfile)})...)
+		}
+		if change.Kustomize != nil {
+			changePatterns = append(changePatterns, o.SparseCheckoutPatternsRegex(&v1alpha1.Regex{Globs: KustomizeGlobs(change.Kustomize)})...)
 		}
+		if change.HelmDependency != nil {
+			changePatterns = append(changePatterns, o.SparseCheckoutPatternsRegex(&v1alpha1.Regex{Globs: HelmDependencyGlobs(change.HelmDependency)})...)
+		}
+		if change.XML != nil {
+			changePatterns = append(changePatterns, o.SparseCheckoutPatternsRegex(&v1alpha1.Regex{Globs: XMLGlobs(change.XML)})...)
+		}
+		if change.GitHubAction != nil {
+			changePatterns = append(changePatterns, o.SparseCheckoutPatternsRegex(&v1alpha1.Regex{Globs: GitHubActionGlobs(change.GitHubAction)})...)
+		}
+		patterns = append(patterns, WorkingDirPatterns(change, changePatterns)...)
 	}
 	return patterns, nil
 }
 
 // ApplyChanges applies the changes to the given dir
 func (o *Options) ApplyChanges(dir, gitURL string, change v1alpha1.Change) error {
+	restoreVersion, err := o.useChangeVersion(change)
+	if err != nil {
+		return err
+	}
+	defer restoreVersion()
+
+	if change.Marker {
+		return o.ApplyChangeWithMarker(dir, gitURL, change)
+	}
+	if change.WorkingDir != "" {
+		workingDir, err := ChangeWorkingDir(dir, change)
+		if err != nil {
+			return err
+		}
+		exists, err := files.DirExists(workingDir)
+		if err != nil {
+			return fmt.Errorf("failed to check for directory %s: %w", workingDir, err)
+		}
+		if !exists {
+			_, err = o.handleMissingFile(change, change.WorkingDir, gitURL, false)
+			return err
+		}
+		dir = workingDir
+	}
+	err = o.applyChange(dir, gitURL, change)
+	if err != nil {
+		return err
+	}
+	return o.RunAfterChange(dir, change.AfterChange)
+}
+
+// applyChange applies the change to the dir according to its type
+func (o *Options) applyChange(dir, gitURL string, change v1alpha1.Change) error {
 	if change.Command != nil {
 		return o.ApplyCommand(dir, change.Command)
 	}
@@ -291,14 +812,59 @@ func (o *Options) ApplyChanges(dir, gitURL string, change v1alpha1.Change) error
 	if change.Regex != nil {
 		return o.ApplyRegex(dir, gitURL, change, change.Regex)
 	}
+	if change.Checksum != nil {
+		return o.ApplyChecksum(dir, gitURL, change, change.Checksum)
+	}
+	if change.Move != nil {
+		return o.ApplyMove(dir, gitURL, change, change.Move)
+	}
+	if change.Set != nil {
+		return o.ApplySet(dir, gitURL, change, change.Set)
+	}
+	if change.ImageDigest != nil {
+		return o.ApplyImageDigest(dir, gitURL, change, change.ImageDigest)
+	}
+	if change.JSON != nil {
+		return o.ApplyJSON(dir, gitURL, change, change.JSON)
+	}
+	if change.TOML != nil {
+		return o.ApplyTOML(dir, gitURL, change, change.TOML)
+	}
+	if change.Properties != nil {
+		return o.ApplyProperties(dir, gitURL, change, change.Properties)
+	}
+	if change.YAML != nil {
+		return o.ApplyYAML(dir, gitURL, change, change.YAML)
+	}
+	if change.Dockerfile != nil {
+		return o.ApplyDockerfile(dir, gitURL, change, change.Dockerfile)
+	}
+	if change.Kustomize != nil {
+		return o.ApplyKustomize(dir, gitURL, change, change.Kustomize)
+	}
+	if change.HelmDependency != nil {
+		return o.ApplyHelmDependency(dir, gitURL, change, change.HelmDependency)
+	}
+	if change.XML != nil {
+		return o.ApplyXML(dir, gitURL, change, change.XML)
+	}
+	if change.GitHubAction != nil {
+		return o.ApplyGitHubAction(dir, gitURL, change, change.GitHubAction)
+	}
 	if change.VersionStream != nil {
-		return o.ApplyVersionStream(dir, change.VersionStream)
+		return o.ApplyVersionStream(dir, gitURL, change, change.VersionStream)
 	}
-	log.Logger().Infof("ignoring unknown change %#v", change)
+	o.Logger().Infof("ignoring unknown change %#v", change)
 	return nil
 }
 
 func (o *Options) FindURLs(rule *v1alpha1.Rule) error {
+	if rule.RepoQuery != nil {
+		err := o.RepoQueryFindURLs(rule, rule.RepoQuery)
+		if err != nil {
+			return fmt.Errorf("failed to find repositories of the repoQuery: %w", err)
+		}
+	}
 	for _, change := range rule.Changes {
 		if change.Go != nil {
 			err := o.GoFindURLs(rule, change.Go)
@@ -318,6 +884,14 @@ func (o *Options) SetChangeLog(addChangeLog string) error {
 			return fmt.Errorf("failed to read changelog file %s: %w", addChangeLog, err)
 		}
 		o.EnvironmentPullRequestOptions.CommitChangelog = string(changelog)
+		return nil
+	}
+	if o.GenerateChangelog {
+		changelog, err := o.ChangelogFromCommits(o.Dir)
+		if err != nil {
+			return fmt.Errorf("failed to generate changelog: %w", err)
+		}
+		o.EnvironmentPullRequestOptions.CommitChangelog = changelog
 	}
 	return nil
 }
@@ -328,7 +902,7 @@ func (o *Options) SetCommitDetails(dir string) error {
 		if o.Application == "" || o.CommitMessage == "" {
 			gitURL, err := gitdiscovery.FindGitURLFromDir(dir, true)
 			if err != nil {
-				log.Logger().Warnf("failed to find git URL %s", err.Error())
+				o.Logger().Warnf("failed to find git URL %s", err.Error())
 			} else if gitURL != "" {
 				if o.Application == "" {
 					gitURLPart := strings.Split(gitURL, "/")
@@ -342,10 +916,11 @@ func (o *Options) SetCommitDetails(dir string) error {
 		}
 
 		if o.CommitTitle == "" {
+			commitType := o.CommitType(dir)
 			if o.Application == "" {
-				o.CommitTitle = fmt.Sprintf("chore(deps): upgrade to version %s", o.Version)
+				o.CommitTitle = fmt.Sprintf("%s: upgrade to version %s", commitType, o.Version)
 			} else {
-				o.CommitTitle = fmt.Sprintf("chore(deps): upgrade %s to version %s", o.Application, o.Version)
+				o.CommitTitle = fmt.Sprintf("%s: upgrade %s to version %s", commitType, o.Application, o.Version)
 			}
 		}
 	}
@@ -353,15 +928,33 @@ func (o *Options) SetCommitDetails(dir string) error {
 }
 
 // ProcessRule sets the Fork and SparseCheckoutPatterns for the given rule
-func (o *Options) ProcessRule(rule *v1alpha1.Rule, index int) error {
-	err := o.FindURLs(rule)
+func (o *Options) ProcessRule(rule *v1alpha1.Rule, index int) (err error) {
+	_, span := o.startSpan("updatebot.process-rule", attribute.Int("rule.index", index))
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	err = o.ValidatePullRequestTemplates(rule)
 	if err != nil {
-		return fmt.Errorf("failed to find URLs: %w", err)
+		return fmt.Errorf("invalid Pull Request templates for rule #%d: %w", index, err)
+	}
+	err = ValidateIfMissing(rule)
+	if err != nil {
+		return fmt.Errorf("invalid changes for rule #%d: %w", index, err)
 	}
 
-	o.Fork = rule.Fork
+	// the repositories of batched rules are found before the rules are batched
+	if !o.resolvedURLs {
+		err = o.resolveURLs(rule, index)
+		if err != nil {
+			return err
+		}
+	}
+	span.SetAttributes(attribute.Int("rule.urls", len(rule.URLs)))
+
+	o.Fork = rule.Fork || rule.ForkOrg != ""
 	if len(rule.URLs) == 0 {
-		log.Logger().Warnf("no URLs found for rule #%d, skipping...\n", index)
+		o.Logger().Warnf("no URLs found for rule #%d, skipping...\n", index)
 		return nil
 	}
 
@@ -375,60 +968,302 @@ func (o *Options) ProcessRule(rule *v1alpha1.Rule, index int) error {
 	return nil
 }
 
+// resolveURLs adds the repositories found by the repoQuery and go changes of the rule and the --repo-file to the URLs
+// of the rule then removes the excluded repositories
+func (o *Options) resolveURLs(rule *v1alpha1.Rule, index int) error {
+	err := o.FindURLs(rule)
+	if err != nil {
+		return fmt.Errorf("failed to find URLs: %w", err)
+	}
+	o.AddRepoFileURLs(rule)
+	err = o.ExcludeURLs(rule)
+	if err != nil {
+		return fmt.Errorf("failed to exclude URLs of rule #%d: %w", index, err)
+	}
+	return nil
+}
+
 // ProcessAndCreatePullRequests handles the URL loop, sets the closure, and creates/reuses PRs.
 func (o *Options) ProcessAndCreatePullRequests(rule *v1alpha1.Rule, baseBranch string, labels []string, automerge bool) error {
+	if o.Concurrency > 1 || o.CloneConcurrency > 1 || o.PRConcurrency > 1 {
+		return o.processRuleURLsConcurrently(rule, baseBranch, labels, automerge)
+	}
+	ruleCtx := o.ctx
+	defer func() {
+		o.ctx = ruleCtx
+	}()
 	for _, ruleURL := range rule.URLs {
 		if ruleURL == "" {
-			log.Logger().Warnf("skipping empty git URL")
+			o.Logger().Warnf("skipping empty git URL")
 			continue
 		}
-		o.BranchName = ""
-		o.BaseBranchName = baseBranch
-
-		o.Function = func() error {
-			dir := o.OutDir
-			for _, ch := range rule.Changes {
-				if err := o.ApplyChanges(dir, ruleURL, ch); err != nil {
-					return fmt.Errorf("failed to apply change: %w", err)
-				}
+		o.ctx = ruleCtx
+		err := o.processRuleURL(rule, ruleURL, baseBranch, labels, automerge)
+		if err != nil {
+			if !o.continueAfterFailure(err) {
+				return err
 			}
-			return nil
+			o.Logger().Warnf("%s, continuing with the remaining repositories", err.Error())
+			o.failures = append(o.failures, RepositoryFailure{GitURL: ruleURL, Rule: o.ruleIndex, Error: err})
 		}
+	}
+	return nil
+}
 
-		if rule.ReusePullRequest {
-			if len(o.Labels) == 0 {
-				return fmt.Errorf("to be able to reuse pull request you need to supply pullRequestLabels in config file or --labels")
+// processRuleURL applies the changes of the rule to the repository and creates or reuses its Pull Request.
+// The clone, apply and push phases are traced as child spans of the repository span
+func (o *Options) processRuleURL(rule *v1alpha1.Rule, ruleURL, baseBranch string, labels []string, automerge bool) (err error) {
+	var span trace.Span
+	o.ctx, span = o.startSpan("updatebot.repository", attribute.String("git.url", ruleURL))
+	defer func() {
+		endSpan(span, err)
+		o.NotifySlackFailure(ruleURL, o.ruleIndex, err)
+	}()
+	ruleLogFields := o.logFields
+	defer func() {
+		o.logFields = ruleLogFields
+	}()
+	o.addLogFields(logrus.Fields{"repo": ruleURL})
+	o.countMetrics(func(m *Metrics) {
+		m.Repositories++
+	})
+
+	o.upToDate = false
+	o.validationErr = nil
+	o.BranchName, err = o.EvaluateBranchName(ruleURL)
+	if err != nil {
+		return err
+	}
+	o.BaseBranchName, err = o.EvaluateBaseBranch(baseBranch, ruleURL)
+	if err != nil {
+		return err
+	}
+	if o.BaseBranchName != "" {
+		o.addLogFields(logrus.Fields{"branch": o.BaseBranchName})
+	}
+
+	// the title and body templates are rendered into the commit title and message so restore them for the next repository
+	commitTitle := o.CommitTitle
+	commitMessage := o.CommitMessage
+	defer func() {
+		o.CommitTitle = commitTitle
+		o.CommitMessage = commitMessage
+	}()
+
+	// with --pr-concurrency the push and Pull Request phase waits for a slot once the repository is cloned and changed
+	defer o.releaseCloneSlot()
+	releasePR := func() {}
+	defer func() {
+		releasePR()
+	}()
+
+	// the clone, commit and push are performed by Create so the phase spans are switched within the change function
+	_, phase := o.startSpan("updatebot.clone")
+	o.Function = func() (err error) {
+		endSpan(phase, nil)
+		_, phase = o.startSpan("updatebot.apply")
+		defer func() {
+			endSpan(phase, err)
+			o.releaseCloneSlot()
+			releasePR = acquireSlot(o.prSlots)
+			_, phase = o.startSpan("updatebot.push")
+		}()
+
+		dir := o.OutDir
+		err = o.ConfigureCommitSigning(dir)
+		if err != nil {
+			return err
+		}
+		err = o.ConfigureCommitAuthor(dir, rule)
+		if err != nil {
+			return err
+		}
+		// commands may commit their changes so the commit before the changes is compared to find if there are changes
+		var sha string
+		if o.SkipEmpty {
+			sha, err = gitclient.GetLatestCommitSha(o.Git(), dir)
+			if err != nil {
+				return fmt.Errorf("failed to get the latest commit SHA in dir %s: %w", dir, err)
 			}
-			o.PullRequestFilter = &environments.PullRequestFilter{Labels: []string{}}
-			for _, label := range o.Labels {
-				o.PullRequestFilter.Labels = stringhelpers.EnsureStringArrayContains(o.PullRequestFilter.Labels, label)
+		}
+		for _, ch := range rule.Changes {
+			if err := o.ApplyChanges(dir, ruleURL, ch); err != nil {
+				return fmt.Errorf("failed to apply change: %w", err)
 			}
-			if o.AutoMerge {
-				o.PullRequestFilter.Labels = stringhelpers.EnsureStringArrayContains(o.PullRequestFilter.Labels, environments.LabelUpdatebot)
+		}
+		if o.SkipEmpty {
+			changed, err := o.HasChanges(dir, sha)
+			if err != nil {
+				return err
+			}
+			if !changed {
+				o.upToDate = true
+				return errNoChanges
 			}
 		}
-
-		pr, err := o.EnvironmentPullRequestOptions.Create(ruleURL, "", labels, automerge)
+		err = o.RunValidateCommands(rule, dir, ruleURL)
+		if err != nil {
+			o.validationErr = err
+			return err
+		}
+		err = o.VerifyChangedFiles(rule, dir, ruleURL)
 		if err != nil {
-			return fmt.Errorf("failed to create Pull Request on repository %s: %w", ruleURL, err)
+			return err
 		}
-		if pr != nil {
-			o.AddPullRequest(pr)
-			err = o.AssignUsersToPullRequestIssue(rule, pr, ruleURL, o.PipelineRepoURL, o.PipelineCommitSha, o.GitKind)
+		title, err := o.EvaluatePullRequestTitle(rule, ruleURL)
+		if err != nil {
+			return fmt.Errorf("failed to render pull request title of rule #%d: %w", o.ruleIndex, err)
+		}
+		o.CommitMessage, err = o.EvaluatePullRequestBody(rule, ruleURL)
+		if err != nil {
+			return fmt.Errorf("failed to render pull request body of rule #%d: %w", o.ruleIndex, err)
+		}
+		o.CommitTitle = o.DraftTitle(rule, title)
+		if o.BatchRepositories {
+			batchBody, err := o.BatchPullRequestBody(rule, ruleURL)
+			if err != nil {
+				return fmt.Errorf("failed to render batched pull request body of rule #%d: %w", o.ruleIndex, err)
+			}
+			o.CommitMessage += batchBody
+		}
+		if o.ChangesTable {
+			table, err := o.PullRequestChangesTable(dir)
 			if err != nil {
-				return fmt.Errorf("failed to assign users to PR: %w", err)
+				return fmt.Errorf("failed to create the changes table of the pull request body: %w", err)
 			}
+			o.CommitMessage += table
+		}
+		o.CommitMessage += o.TrackingIssueReference()
+		o.sanitizePullRequestText()
+		return nil
+	}
+
+	labels, err = o.EvaluatePullRequestLabels(rule, ruleURL, labels)
+	if err != nil {
+		endSpan(phase, err)
+		return err
+	}
+
+	if rule.ReusePullRequest {
+		if len(labels) == 0 {
+			endSpan(phase, nil)
+			return fmt.Errorf("to be able to reuse pull request you need to supply pullRequestLabels in config file or --labels")
+		}
+		o.PullRequestFilter = &environments.PullRequestFilter{Labels: []string{}}
+		for _, label := range labels {
+			o.PullRequestFilter.Labels = stringhelpers.EnsureStringArrayContains(o.PullRequestFilter.Labels, label)
+		}
+		if o.AutoMerge {
+			o.PullRequestFilter.Labels = stringhelpers.EnsureStringArrayContains(o.PullRequestFilter.Labels, environments.LabelUpdatebot)
+		}
+	}
+
+	if o.DryRun {
+		err = o.DryRunPullRequest(rule, ruleURL, labels, automerge)
+		endSpan(phase, err)
+		return err
+	}
+
+	// lets reuse the cached ScmClient of the git server when creating the Pull Request
+	scmClient, repoFullName, err := o.GetScmClient(ruleURL, o.GitKind)
+	if err != nil {
+		endSpan(phase, err)
+		return fmt.Errorf("failed to create ScmClient for repository %s: %w", ruleURL, err)
+	}
+	o.UseForkOrg(scmClient, rule.ForkOrg)
+	o.CreateDraftPullRequests(scmClient, repoFullName, rule)
+	o.ReuseBranchPullRequests(scmClient, repoFullName)
+
+	if rule.ReusePullRequest && o.CloseSuperseded {
+		_, err = o.CloseSupersededPullRequests(ruleURL)
+		if err != nil {
+			endSpan(phase, err)
+			return fmt.Errorf("failed to close superseded Pull Requests on repository %s: %w", ruleURL, err)
 		}
 	}
+
+	if automerge {
+		automerge, labels = o.QuietHoursAutoMerge(ruleURL, labels)
+	}
+	if automerge && o.IsDraft(rule) {
+		o.Logger().Infof("not auto merging the draft Pull Request on repository %s", info(ruleURL))
+		automerge = false
+	}
+	if automerge && !o.CheckAutoMergeConditions(rule, ruleURL, labels) {
+		automerge = false
+	}
+	if automerge && o.VerifyCI {
+		automerge = o.VerifyAutoMerge(ruleURL)
+	}
+
+	pr, err := o.EnvironmentPullRequestOptions.Create(ruleURL, "", labels, automerge)
+	if o.validationErr != nil {
+		endSpan(phase, o.validationErr)
+		return o.validationErr
+	}
+	if o.upToDate {
+		endSpan(phase, nil)
+		o.Logger().Infof("repository %s is already up to date so not creating a Pull Request", info(ruleURL))
+		o.countMetrics(func(m *Metrics) {
+			m.Skipped++
+		})
+		if rule.ReusePullRequest {
+			return o.CloseStalePullRequest(ruleURL)
+		}
+		return nil
+	}
+	endSpan(phase, err)
+	if err != nil {
+		return fmt.Errorf("failed to create Pull Request on repository %s: %w", ruleURL, err)
+	}
+	if rule.ReusePullRequest {
+		err = o.CheckPullRequestConflicts(ruleURL, pr)
+		if err != nil {
+			return fmt.Errorf("failed to check Pull Request conflicts on repository %s: %w", ruleURL, err)
+		}
+	}
+	if pr != nil {
+		o.MarkPullRequestDraft(rule, ruleURL, pr)
+		span.SetAttributes(attribute.Int("pull_request.number", pr.Number), attribute.String("pull_request.url", pr.Link))
+		o.AddPullRequest(pr)
+		o.CountPullRequest(pr)
+		o.PrintPullRequest(ruleURL, pr)
+		o.RecordPullRequest(ruleURL, pr)
+		if automerge && o.IsGitea() {
+			o.ScheduleGiteaAutoMerge(ruleURL, pr)
+		}
+		err = o.AddToTrackingIssue(pr)
+		if err != nil {
+			return fmt.Errorf("failed to add PR to tracking issue: %w", err)
+		}
+		err = o.AssignUsersToPullRequestIssue(rule, pr, ruleURL, o.PipelineRepoURL, o.PipelineCommitSha, o.GitKind)
+		if err != nil {
+			return fmt.Errorf("failed to assign users to PR: %w", err)
+		}
+		err = o.RequestReviewersOnPullRequest(rule, pr, ruleURL, o.GitKind)
+		if err != nil {
+			return fmt.Errorf("failed to request reviewers on PR: %w", err)
+		}
+		err = o.SetPullRequestMilestone(rule, pr, ruleURL, o.GitKind)
+		if err != nil {
+			return fmt.Errorf("failed to set milestone of PR: %w", err)
+		}
+		err = o.RunPostPullRequestCommand(ruleURL, pr)
+		if err != nil {
+			return err
+		}
+	} else {
+		o.countMetrics(func(m *Metrics) {
+			m.Skipped++
+		})
+	}
 	return nil
 }
 
-// AssignUsersToPullRequestIssue assigns user to a downstream PR issue
+// AssignUsersToPullRequestIssue assigns user to a downstream PR issue. The assignees of the rule are combined with the
+// --pull-request-assign users and the users of the repository in the --assignees-file
 func (o *Options) AssignUsersToPullRequestIssue(rule *v1alpha1.Rule, pullRequest *scm.PullRequest, ruleURL, pipelineURL, pipelineSHA, gitKind string) error {
-	var assignees []string
-	for _, pullRequestAssignee := range rule.PullRequestAssignees {
-		assignees = stringhelpers.EnsureStringArrayContains(assignees, pullRequestAssignee)
-	}
+	assignees := o.configuredAssignees(rule, ruleURL)
 	if rule.AssignAuthorToPullRequests {
 		author, err := o.FindCommitAuthor(pipelineURL, pipelineSHA, gitKind)
 		if err != nil {
@@ -447,20 +1282,36 @@ func (o *Options) AssignUsersToPullRequestIssue(rule *v1alpha1.Rule, pullRequest
 	return nil
 }
 
+// configuredAssignees returns the assignees of the rule combined with the --pull-request-assign users and the users
+// of the repository in the --assignees-file
+func (o *Options) configuredAssignees(rule *v1alpha1.Rule, ruleURL string) []string {
+	var assignees []string
+	for _, pullRequestAssignee := range rule.PullRequestAssignees {
+		assignees = stringhelpers.EnsureStringArrayContains(assignees, pullRequestAssignee)
+	}
+	for _, pullRequestAssignee := range o.PRAssignees {
+		assignees = stringhelpers.EnsureStringArrayContains(assignees, pullRequestAssignee)
+	}
+	for _, pullRequestAssignee := range o.RepositoryAssignees(ruleURL) {
+		assignees = stringhelpers.EnsureStringArrayContains(assignees, pullRequestAssignee)
+	}
+	return assignees
+}
+
 // FindCommitAuthor finds the author of the commit, or the author of the PR if the commit is a merge commit
 func (o *Options) FindCommitAuthor(gitURL, sha, gitKind string) (string, error) {
 	if gitURL == "" || sha == "" {
-		log.Logger().Warnf("cannot find commit author with empty gitURL or sha")
+		o.Logger().Warnf("cannot find commit author with empty gitURL or sha")
 		return "", nil
 	}
 
 	ctx := context.Background()
 	scmClient, repoFullName, err := o.GetScmClient(gitURL, gitKind)
-	log.Logger().Infof("Repository URL: %s, extracted repo name: %s", gitURL, repoFullName)
+	o.Logger().Infof("Repository URL: %s, extracted repo name: %s", gitURL, repoFullName)
 	if err != nil {
 		return "", fmt.Errorf("failed to create ScmClient: %w", err)
 	}
-	log.Logger().Infof("Looking for commit %s in repo %s", sha, repoFullName)
+	o.Logger().Infof("Looking for commit %s in repo %s", sha, repoFullName)
 	commit, _, err := scmClient.Git.FindCommit(ctx, repoFullName, sha)
 	if err != nil {
 		return "", fmt.Errorf("failed to find commit %s: %w", sha, err)
@@ -476,18 +1327,22 @@ func (o *Options) FindCommitAuthor(gitURL, sha, gitKind string) (string, error)
 	}
 
 	if !isMergeCommit {
-		log.Logger().Infof("commit %s is not a merge commit - using current author", sha)
+		o.Logger().Infof("commit %s is not a merge commit - using current author", sha)
 		commitAuthor := commit.Author.Login
+		if o.IsGitLab() {
+			return o.findGitLabUsername(ctx, scmClient, sha, commitAuthor)
+		}
 		if commitAuthor == "" {
-			log.Logger().Warnf("no author found for commit %s", sha)
+			o.Logger().Warnf("no author found for commit %s", sha)
 		}
 		return commitAuthor, nil
 	}
 
-	log.Logger().Infof("commit %s is a merge commit - finding PR author", sha)
+	o.Logger().Infof("commit %s is a merge commit - finding PR author", sha)
 	prNumber, err := MergeCommitPullRequestNumber(commit)
 	if err != nil {
-		return "", fmt.Errorf("failed to get PR number from merge commit: %w", err)
+		o.Logger().Warnf("failed to get PR number from merge commit so using the author of its parent: %s", err.Error())
+		return o.FindParentCommitAuthor(ctx, scmClient, repoFullName, sha, os.Getenv("PULL_BASE_REF"))
 	}
 	prAuthor, err := FindPullRequestAuthor(ctx, scmClient, repoFullName, prNumber)
 	if err != nil {
@@ -503,8 +1358,22 @@ func (o *Options) AssignUsersToIssue(pullRequest *scm.PullRequest, users []strin
 	if err != nil {
 		return fmt.Errorf("failed to create ScmClient: %w", err)
 	}
-	log.Logger().Infof("Assigning users %v to PR %d in repo %s", users, pullRequest.Number, repoFullName)
+	if o.IsBitbucketServer() {
+		// bitbucket server pull requests have reviewers rather than assignees
+		o.Logger().Infof("adding the assignees %v as reviewers of PR %d in repo %s as Bitbucket Server does not support assignees", users, pullRequest.Number, repoFullName)
+		return o.RequestReview(pullRequest, users, gitURL, gitKind)
+	}
+	o.Logger().Infof("Assigning users %v to PR %d in repo %s", users, pullRequest.Number, repoFullName)
 	_, err = scmClient.PullRequests.AssignIssue(ctx, repoFullName, pullRequest.Number, users)
+	if errors.Is(err, scm.ErrNotFound) && o.IsGitLab() {
+		// gitlab assigns merge requests by user ID so every login is looked up as a username first
+		return fmt.Errorf("failed to assign users %v to merge request !%d in repo %s as GitLab assigns users by ID and not all of them are GitLab usernames: %w", users, pullRequest.Number, repoFullName, err)
+	}
+	if err != nil && o.IsGitea() {
+		// gitea only allows collaborators of the repository to be assigned
+		o.Logger().Warnf("failed to assign users %v to PR %d in repo %s: %s", users, pullRequest.Number, repoFullName, err.Error())
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to assign user to PR %d: %w", pullRequest.Number, err)
 	}
@@ -515,7 +1384,7 @@ func checkMergeCommit(commit *scm.Commit) (bool, error) {
 	if commit == nil {
 		return false, fmt.Errorf("commit is nil")
 	}
-	if strings.HasPrefix(commit.Message, "Merge pull request") {
+	if strings.HasPrefix(commit.Message, "Merge pull request") || gitLabMergeRequestRegex.MatchString(commit.Message) {
 		log.Logger().Infof("commit %s is a merge commit", commit.Sha)
 		return true, nil
 	}
@@ -524,6 +1393,11 @@ func checkMergeCommit(commit *scm.Commit) (bool, error) {
 }
 
 func MergeCommitPullRequestNumber(commit *scm.Commit) (string, error) {
+	// gitlab merge commits refer to the merge request as group/project!123 and may also mention issues as #123
+	if match := gitLabMergeRequestRegex.FindStringSubmatch(commit.Message); match != nil {
+		log.Logger().Infof("found MR number %s in commit message %s", match[1], commit.Message)
+		return match[1], nil
+	}
 	match := regexp.MustCompile(`\B#(\d+)\b`).FindStringSubmatch(commit.Message)
 	if match == nil {
 		return "", fmt.Errorf("no pull rquest number found: %s", commit.Message)
@@ -0,0 +1,50 @@
+package pr
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/templater"
+)
+
+// EvaluatePullRequestLabels returns the labels of the Pull Request on the repository which are the labels along with
+// the labels of the rule. Labels containing {{ are go templates rendered with the same data as the title template, such
+// as release/{{ .VersionMajorMinor }}. A template rendering an empty string adds no label
+func (o *Options) EvaluatePullRequestLabels(rule *v1alpha1.Rule, gitURL string, labels []string) ([]string, error) {
+	var answer []string
+	for _, label := range append(append([]string{}, labels...), rule.PullRequestLabels...) {
+		if isInlineTemplate(label) {
+			text, err := templater.Evaluate(o.pullRequestFuncMap(), o.pullRequestTemplateData(rule, gitURL), label, "pullRequestLabel", "pull request label for "+gitURL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to render pull request label %s: %w", label, err)
+			}
+			label = strings.TrimSpace(text)
+		}
+		if label != "" {
+			answer = stringhelpers.EnsureStringArrayContains(answer, label)
+		}
+	}
+	return answer, nil
+}
+
+// ValidatePullRequestLabels parses the templated labels so that a mistake fails when the config is loaded
+func (o *Options) ValidatePullRequestLabels(labels []string) error {
+	for _, label := range labels {
+		if !isInlineTemplate(label) {
+			continue
+		}
+		_, err := template.New("pullRequestLabel").Funcs(o.pullRequestFuncMap()).Parse(label)
+		if err != nil {
+			return fmt.Errorf("invalid pull request label %s: %w", label, err)
+		}
+	}
+	return nil
+}
+
+// isInlineTemplate returns true if the label is a go template rather than a literal label
+func isInlineTemplate(text string) bool {
+	return strings.Contains(text, "{{")
+}
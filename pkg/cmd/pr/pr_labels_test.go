@@ -0,0 +1,32 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluatePullRequestLabels(t *testing.T) {
+	o := &pr.Options{Version: "1.3.0", TemplateData: map[string]interface{}{"Team": "payments"}}
+	o.Application = "myorg/myapp"
+	o.AddVersionTemplateData()
+
+	rule := &v1alpha1.Rule{
+		PullRequestLabels: []string{
+			"team/{{ .Team }}",
+			"release/{{ if eq .VersionPatch 0 }}minor{{ else }}patch{{ end }}",
+			`{{ if hasPrefix "0." .Version }}unstable{{ end }}`,
+			"updatebot",
+		},
+	}
+	labels, err := o.EvaluatePullRequestLabels(rule, "https://github.com/myorg/myrepo", []string{"updatebot", "app/{{ base .Application }}"})
+	require.NoError(t, err, "failed to evaluate labels")
+	assert.Equal(t, []string{"updatebot", "app/myapp", "team/payments", "release/minor"}, labels, "labels")
+
+	err = o.ValidatePullRequestLabels([]string{"dependencies", "team/{{ .Team"})
+	require.Error(t, err, "should fail to validate an invalid template")
+	assert.Contains(t, err.Error(), "team/{{ .Team", "error should name the label")
+}
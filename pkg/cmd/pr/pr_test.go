@@ -228,3 +228,57 @@ func TestAssignMergeCommitAuthor(t *testing.T) {
 		t.Logf("PR created successfully with assignees: %v\n", actualAssignees)
 	}
 }
+
+func TestValidateSkipGitUserSetup(t *testing.T) {
+	for _, skip := range []bool{false, true} {
+		runner := &fakerunner.FakeRunner{}
+
+		_, o := pr.NewCmdPullRequest()
+		o.Dir = t.TempDir()
+		o.CommandRunner = runner.Run
+		o.NoVersion = true
+		o.SkipGitUserSetup = skip
+		o.ScmClientFactory.GitServerURL = "https://github.com"
+		o.ScmClientFactory.GitToken = "dummytoken"
+
+		err := o.Validate()
+		require.NoError(t, err, "failed to validate with skip %v", skip)
+
+		gitConfig := false
+		for _, c := range runner.OrderedCommands {
+			if c.Name == "git" && len(c.Args) > 0 && c.Args[0] == "config" {
+				gitConfig = true
+			}
+		}
+		assert.Equal(t, !skip, gitConfig, "should run git config when skip is %v", skip)
+	}
+}
+
+func TestValidatePullRequestTitleAndBodyFromEnv(t *testing.T) {
+	t.Setenv("PR_TITLE", "chore: env title")
+	t.Setenv("PR_BODY", "env body")
+
+	for _, flags := range []bool{false, true} {
+		_, o := pr.NewCmdPullRequest()
+		o.Dir = t.TempDir()
+		o.CommandRunner = (&fakerunner.FakeRunner{}).Run
+		o.NoVersion = true
+		o.ScmClientFactory.GitServerURL = "https://github.com"
+		o.ScmClientFactory.GitToken = "dummytoken"
+		if flags {
+			o.CommitTitle = "chore: flag title"
+			o.CommitMessage = "flag body"
+		}
+
+		err := o.Validate()
+		require.NoError(t, err, "failed to validate")
+
+		if flags {
+			assert.Equal(t, "chore: flag title", o.CommitTitle, "the flag should take precedence")
+			assert.Equal(t, "flag body", o.CommitMessage, "the flag should take precedence")
+		} else {
+			assert.Equal(t, "chore: env title", o.CommitTitle, "should default from $PR_TITLE")
+			assert.Equal(t, "env body", o.CommitMessage, "should default from $PR_BODY")
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package pr
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+// PrintEffectiveConfig prints the config which would be used by Run as YAML. The config file is loaded, the config
+// overlay applied and the version stream rules are generated but no git or git provider setup is performed
+func (o *Options) PrintEffectiveConfig() error {
+	err := o.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	err = o.GenerateVersionStreamRules()
+	if err != nil {
+		return fmt.Errorf("failed to generate version stream rules: %w", err)
+	}
+	data, err := yaml.Marshal(&o.UpdateConfig)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	out := o.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	_, err = out.Write(data)
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
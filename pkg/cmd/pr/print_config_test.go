@@ -0,0 +1,60 @@
+package pr_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func TestPrintConfig(t *testing.T) {
+	dir := t.TempDir()
+	configDir := filepath.Join(dir, ".jx")
+	err := os.MkdirAll(configDir, files.DefaultDirWritePermissions)
+	require.NoError(t, err, "failed to create %s", configDir)
+	config := `apiVersion: updatebot.jenkins-x.io/v1alpha1
+kind: UpdateConfig
+spec:
+  pullRequestLabels:
+  - from-file
+  rules:
+  - urls:
+    - https://github.com/myorg/myrepo
+    changes:
+    - regex:
+        pattern: "version: (.*)"
+        files:
+        - values.yaml
+`
+	err = os.WriteFile(filepath.Join(configDir, "updatebot.yaml"), []byte(config), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to write config")
+
+	out := &bytes.Buffer{}
+	_, o := pr.NewCmdPullRequest()
+	o.Dir = dir
+	o.PrintConfig = true
+	o.ConfigOverlay = "spec:\n  pullRequestLabels:\n  - from-overlay\n"
+	o.Out = out
+
+	err = o.Run()
+	require.NoError(t, err, "failed to run")
+
+	actual := v1alpha1.UpdateConfig{}
+	err = yaml.UnmarshalStrict(out.Bytes(), &actual)
+	require.NoError(t, err, "failed to parse output:\n%s", out.String())
+
+	assert.Equal(t, "UpdateConfig", actual.Kind)
+	assert.Equal(t, []string{"from-overlay"}, actual.Spec.PullRequestLabels)
+	require.Len(t, actual.Spec.Rules, 1)
+	assert.Equal(t, []string{"https://github.com/myorg/myrepo"}, actual.Spec.Rules[0].URLs)
+	require.Len(t, actual.Spec.Rules[0].Changes, 1)
+	require.NotNil(t, actual.Spec.Rules[0].Changes[0].Regex)
+	assert.Equal(t, []string{"values.yaml"}, actual.Spec.Rules[0].Changes[0].Regex.Globs)
+}
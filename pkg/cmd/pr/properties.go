@@ -0,0 +1,129 @@
+package pr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/yargevad/filepathx"
+)
+
+// ApplyProperties sets the value of the key in the properties or .env files. Only the current value is replaced so
+// comments and the order of the keys are kept
+func (o *Options) ApplyProperties(dir, gitURL string, change v1alpha1.Change, properties *v1alpha1.PropertiesChange) error {
+	if properties.Key == "" {
+		return fmt.Errorf("no key for properties change %#v", change)
+	}
+	value, err := o.changeValue(gitURL, change, properties.Value)
+	if err != nil {
+		return err
+	}
+
+	matched := 0
+	for _, g := range properties.Globs {
+		matches, err := filepathx.Glob(filepath.Join(dir, g))
+		if err != nil {
+			return fmt.Errorf("failed to evaluate glob %s: %w", g, err)
+		}
+		if len(matches) == 0 {
+			_, err = o.handleMissingFile(change, g, gitURL, false)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		for _, f := range matches {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return fmt.Errorf("failed to load file %s: %w", f, err)
+			}
+			text := string(data)
+			text2, ok, err := o.SetPropertyValue(change, text, properties.Key, value, properties.CreateMissing, f)
+			if err != nil {
+				return err
+			}
+			if ok {
+				matched++
+			}
+			if text2 != text {
+				err = os.WriteFile(f, []byte(text2), files.DefaultFileWritePermissions)
+				if err != nil {
+					return fmt.Errorf("failed to save file %s: %w", f, err)
+				}
+				o.Logger().Infof("modified file %s setting %s to %s", info(f), properties.Key, value)
+			}
+		}
+	}
+	return checkRequireMatch(change, matched, "properties", properties.Key, properties.Globs, gitURL)
+}
+
+// SetPropertyValue sets the value of the key in the properties or .env text returning the new text and true if the
+// key was found or created. Quotes around the current value and the spaces around the separator are kept. A missing
+// key is appended using the separator of the file if createMissing is enabled, otherwise it is an error
+func (o *Options) SetPropertyValue(change v1alpha1.Change, text, key, value string, createMissing bool, path string) (string, bool, error) {
+	separator := "="
+	lines := strings.Split(text, "\n")
+	detected := false
+	for i, line := range lines {
+		name, sep, start, ok := parsePropertyLine(line)
+		if !ok {
+			continue
+		}
+		if !detected {
+			separator = sep
+			detected = true
+		}
+		if name != key {
+			continue
+		}
+		current := strings.TrimRight(line[start:], " \t\r")
+		suffix := line[start+len(current):]
+		quote := ""
+		if len(current) >= 2 && (current[0] == '"' || current[0] == '\'') && current[len(current)-1] == current[0] {
+			quote = current[:1]
+			current = current[1 : len(current)-1]
+		}
+		if !o.matchesExpectedCurrent(change, current, key+" in "+path) {
+			return text, true, nil
+		}
+		lines[i] = line[:start] + quote + value + quote + suffix
+		return strings.Join(lines, "\n"), true, nil
+	}
+	if !createMissing {
+		return "", false, fmt.Errorf("the key %s does not exist in file %s", key, path)
+	}
+	line := key + separator + value
+	if separator == ":" {
+		line = key + ": " + value
+	}
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return text + line + "\n", true, nil
+}
+
+// parsePropertyLine returns the key and separator of a key value line along with the position of its value. Comments,
+// blank lines and lines without a separator are skipped. The export prefix of .env files is ignored
+func parsePropertyLine(line string) (string, string, int, bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "!") {
+		return "", "", 0, false
+	}
+	i := strings.IndexAny(line, "=:")
+	if i < 0 {
+		return "", "", 0, false
+	}
+	name := strings.TrimSpace(line[:i])
+	name = strings.TrimSpace(strings.TrimPrefix(name, "export "))
+	if name == "" {
+		return "", "", 0, false
+	}
+	start := i + 1
+	for start < len(line) && (line[start] == ' ' || line[start] == '\t') {
+		start++
+	}
+	return name, line[i : i+1], start, true
+}
@@ -0,0 +1,65 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyProperties(t *testing.T) {
+	dir := t.TempDir()
+	gitURL := "https://github.com/myorg/myrepo"
+	envFile := filepath.Join(dir, ".env")
+	propertiesFile := filepath.Join(dir, "config", "app.properties")
+	writeTestFile(t, envFile, `# the image of the app
+IMAGE_TAG=1.2.3
+export IMAGE_NAME="myorg/myapp"
+DEBUG=false
+`)
+	writeTestFile(t, propertiesFile, `! generated
+app.name: myapp
+image.tag : '1.2.3'
+`)
+
+	o := &pr.Options{Version: "2.0.0", TemplateData: map[string]interface{}{"Version": "2.0.0"}}
+	change := v1alpha1.Change{RequireMatch: true, Properties: &v1alpha1.PropertiesChange{Key: "IMAGE_TAG", Globs: []string{".env"}}}
+	err := o.ApplyChanges(dir, gitURL, change)
+	require.NoError(t, err, "failed to apply change")
+
+	change.Properties = &v1alpha1.PropertiesChange{Key: "IMAGE_NAME", Value: "myorg/other", Globs: []string{".env"}}
+	err = o.ApplyChanges(dir, gitURL, change)
+	require.NoError(t, err, "failed to apply change to an exported key")
+
+	change.Properties = &v1alpha1.PropertiesChange{Key: "image.tag", Globs: []string{"config/*.properties"}}
+	err = o.ApplyChanges(dir, gitURL, change)
+	require.NoError(t, err, "failed to apply change to properties")
+
+	change.Properties = &v1alpha1.PropertiesChange{Key: "image.digest", Value: "sha256:abc", Globs: []string{"config/*.properties"}}
+	err = o.ApplyChanges(dir, gitURL, change)
+	require.Error(t, err, "should fail for a missing key")
+
+	change.Properties.CreateMissing = true
+	err = o.ApplyChanges(dir, gitURL, change)
+	require.NoError(t, err, "failed to create missing key")
+
+	data, err := os.ReadFile(envFile)
+	require.NoError(t, err, "failed to read %s", envFile)
+	assert.Equal(t, `# the image of the app
+IMAGE_TAG=2.0.0
+export IMAGE_NAME="myorg/other"
+DEBUG=false
+`, string(data), "modified .env")
+
+	data, err = os.ReadFile(propertiesFile)
+	require.NoError(t, err, "failed to read %s", propertiesFile)
+	assert.Equal(t, `! generated
+app.name: myapp
+image.tag : '2.0.0'
+image.digest: sha256:abc
+`, string(data), "modified properties")
+}
@@ -0,0 +1,78 @@
+package pr
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x-plugins/jx-promote/pkg/environments"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+)
+
+// QuietHours returns true if the current time in the --timezone is within the --no-automerge-window so Pull Requests
+// are created without auto merge
+func (o *Options) QuietHours() (bool, error) {
+	if o.NoAutoMergeWindow == "" {
+		return false, nil
+	}
+	start, end, err := ParseTimeWindow(o.NoAutoMergeWindow)
+	if err != nil {
+		return false, fmt.Errorf("invalid --no-automerge-window: %w", err)
+	}
+	loc := time.Local
+	if o.Timezone != "" {
+		loc, err = time.LoadLocation(o.Timezone)
+		if err != nil {
+			return false, fmt.Errorf("invalid --timezone %s: %w", o.Timezone, err)
+		}
+	}
+	now := time.Now
+	if o.Now != nil {
+		now = o.Now
+	}
+	t := now().In(loc)
+	return InTimeWindow(t.Hour()*60+t.Minute(), start, end), nil
+}
+
+// QuietHoursAutoMerge returns false if auto merge of the Pull Request on the repository is suppressed as it is
+// quiet hours. The labels then include the updatebot label which auto merge would add so a later run can find the
+// Pull Request to reuse and merge it
+func (o *Options) QuietHoursAutoMerge(gitURL string, labels []string) (bool, []string) {
+	quiet, err := o.QuietHours()
+	if err != nil {
+		o.Logger().Warnf("failed to check the quiet hours: %s", err.Error())
+		return true, labels
+	}
+	if !quiet {
+		return true, labels
+	}
+	o.Logger().Infof("suppressing auto merge of the Pull Request on repository %s due to the quiet hours %s", info(gitURL), o.NoAutoMergeWindow)
+	labels = stringhelpers.EnsureStringArrayContains(append([]string{}, labels...), environments.LabelUpdatebot)
+	return false, labels
+}
+
+// ParseTimeWindow parses a window of the day such as 22:00-06:00 returning the minutes of the day of its start and end
+func ParseTimeWindow(window string) (int, int, error) {
+	from, to, ok := strings.Cut(window, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("the window %s should be a start and end time such as 22:00-06:00", window)
+	}
+	start, err := time.Parse("15:04", strings.TrimSpace(from))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse start time %s of window %s: %w", from, window, err)
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(to))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse end time %s of window %s: %w", to, window, err)
+	}
+	return start.Hour()*60 + start.Minute(), end.Hour()*60 + end.Minute(), nil
+}
+
+// InTimeWindow returns true if the minute of the day is within the window including its start but not its end. A
+// window whose end is before its start wraps past midnight
+func InTimeWindow(minute, start, end int) bool {
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
@@ -0,0 +1,61 @@
+package pr_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuietHours(t *testing.T) {
+	testCases := []struct {
+		window   string
+		timezone string
+		now      time.Time
+		expected bool
+		err      bool
+	}{
+		{window: "", now: time.Date(2024, 1, 2, 23, 0, 0, 0, time.UTC)},
+		{window: "22:00-06:00", timezone: "UTC", now: time.Date(2024, 1, 2, 23, 0, 0, 0, time.UTC), expected: true},
+		{window: "22:00-06:00", timezone: "UTC", now: time.Date(2024, 1, 2, 5, 59, 0, 0, time.UTC), expected: true},
+		{window: "22:00-06:00", timezone: "UTC", now: time.Date(2024, 1, 2, 6, 0, 0, 0, time.UTC)},
+		{window: "22:00-06:00", timezone: "UTC", now: time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)},
+		{window: "12:00-14:00", timezone: "UTC", now: time.Date(2024, 1, 2, 13, 0, 0, 0, time.UTC), expected: true},
+		{window: "22:00-06:00", timezone: "America/New_York", now: time.Date(2024, 1, 2, 23, 0, 0, 0, time.UTC)},
+		{window: "22:00-06:00", timezone: "America/New_York", now: time.Date(2024, 1, 2, 4, 0, 0, 0, time.UTC), expected: true},
+		{window: "22:00", timezone: "UTC", err: true},
+		{window: "22:00-25:00", timezone: "UTC", err: true},
+		{window: "22:00-06:00", timezone: "Nowhere/Cheese", err: true},
+	}
+
+	for _, tc := range testCases {
+		now := tc.now
+		o := &pr.Options{NoAutoMergeWindow: tc.window, Timezone: tc.timezone, Now: func() time.Time { return now }}
+		actual, err := o.QuietHours()
+		if tc.err {
+			require.Error(t, err, "should fail for window %s in timezone %s", tc.window, tc.timezone)
+			continue
+		}
+		require.NoError(t, err, "failed for window %s in timezone %s", tc.window, tc.timezone)
+		assert.Equal(t, tc.expected, actual, "quiet hours for window %s in timezone %s at %s", tc.window, tc.timezone, tc.now)
+	}
+}
+
+func TestQuietHoursAutoMerge(t *testing.T) {
+	gitURL := "https://github.com/myorg/myrepo"
+	labels := []string{"dependencies"}
+	o := &pr.Options{NoAutoMergeWindow: "22:00-06:00", Timezone: "UTC"}
+
+	o.Now = func() time.Time { return time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC) }
+	automerge, actual := o.QuietHoursAutoMerge(gitURL, labels)
+	assert.True(t, automerge, "auto merge outside the quiet hours")
+	assert.Equal(t, []string{"dependencies"}, actual, "labels outside the quiet hours")
+
+	o.Now = func() time.Time { return time.Date(2024, 1, 2, 23, 30, 0, 0, time.UTC) }
+	automerge, actual = o.QuietHoursAutoMerge(gitURL, labels)
+	assert.False(t, automerge, "auto merge during the quiet hours")
+	assert.Equal(t, []string{"dependencies", "updatebot"}, actual, "labels during the quiet hours")
+	assert.Equal(t, []string{"dependencies"}, labels, "the labels of the rule are not modified")
+}
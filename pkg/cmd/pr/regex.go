@@ -1,20 +1,22 @@
 package pr
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 
 	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
-	"github.com/jenkins-x/jx-logging/v3/pkg/log"
 
 	"github.com/yargevad/filepathx"
 )
 
-// SparseCheckoutPatternsRegex return the patterns to check out sparsely
+// SparseCheckoutPatternsRegex return the patterns to check out sparsely. The excluded files are not removed from the
+// patterns so the checkout is a superset of the files the regex changes
 func (o *Options) SparseCheckoutPatternsRegex(regex *v1alpha1.Regex) []string {
 	res := make([]string, len(regex.Globs))
 	for _, p := range regex.Globs {
@@ -48,19 +50,50 @@ func (o *Options) ApplyRegex(dir, gitURL string, change v1alpha1.Change, regex *
 		}
 	}
 
+	excluded, err := ExcludedFiles(dir, regex.ExcludeFiles)
+	if err != nil {
+		return err
+	}
+
+	matched := 0
 	for _, g := range regex.Globs {
 		path := filepath.Join(dir, g)
 		matches, err := filepathx.Glob(path)
 		if err != nil {
 			return fmt.Errorf("failed to evaluate glob %s: %w", path, err)
 		}
+		if len(matches) == 0 {
+			create, err := o.handleMissingFile(change, g, gitURL, !strings.ContainsAny(g, "*?[{"))
+			if err != nil {
+				return err
+			}
+			if create {
+				err = o.createVersionFile(path, gitURL, change)
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
 		for _, f := range matches {
-			log.Logger().Infof("found file %s", f)
+			if excluded[f] {
+				o.Logger().Debugf("ignoring file %s as it matches the excludeFiles %v", f, regex.ExcludeFiles)
+				continue
+			}
+			if !MatchesExtensions(f, regex.Extensions) {
+				o.Logger().Debugf("ignoring file %s as its extension is not one of %v", f, regex.Extensions)
+				continue
+			}
+			o.Logger().Infof("found file %s", f)
 
 			data, err := os.ReadFile(f)
 			if err != nil {
 				return fmt.Errorf("failed to load file %s: %w", f, err)
 			}
+			if IsBinary(data) {
+				o.Logger().Warnf("ignoring binary file %s", f)
+				continue
+			}
 
 			text := string(data)
 			version := o.Version
@@ -70,20 +103,22 @@ func (o *Options) ApplyRegex(dir, gitURL string, change v1alpha1.Change, regex *
 					return fmt.Errorf("failed to valuate version template %s: %w", change.VersionTemplate, err)
 				}
 			}
+			version, err = TransformVersion(regex.Transform, version)
+			if err != nil {
+				return err
+			}
 
 			oldVersions := make([]string, 0)
 
 			text2 := stringhelpers.ReplaceAllStringSubmatchFunc(r, text, func(groups []stringhelpers.Group) []string {
+				matched++
 				answer := make([]string, 0)
 				for i, group := range groups {
-					if namedCapture {
+					if namedCapture && !namedCaptures[i] {
 						// If we are using named capture, then replace only the named captures that have the right name
-						if namedCaptures[i] {
-							oldVersions = append(oldVersions, group.Value)
-							answer = append(answer, version)
-						} else {
-							answer = append(answer, group.Value)
-						}
+						answer = append(answer, group.Value)
+					} else if !o.matchesExpectedCurrent(change, group.Value, f) {
+						answer = append(answer, group.Value)
 					} else {
 						oldVersions = append(oldVersions, group.Value)
 						answer = append(answer, version)
@@ -97,9 +132,76 @@ func (o *Options) ApplyRegex(dir, gitURL string, change v1alpha1.Change, regex *
 				if err != nil {
 					return fmt.Errorf("failed to save file %s: %w", f, err)
 				}
-				log.Logger().Infof("modified file %s", info(f))
+				o.Logger().Infof("modified file %s", info(f))
 			}
 		}
 	}
+	return checkRequireMatch(change, matched, "regex", pattern, regex.Globs, gitURL)
+}
+
+// createVersionFile creates a missing file containing just the version
+func (o *Options) createVersionFile(path, gitURL string, change v1alpha1.Change) error {
+	version := o.Version
+	if change.VersionTemplate != "" {
+		var err error
+		version, err = o.EvaluateVersionTemplate(change.VersionTemplate, gitURL)
+		if err != nil {
+			return fmt.Errorf("failed to valuate version template %s: %w", change.VersionTemplate, err)
+		}
+	}
+	if change.Regex != nil {
+		var err error
+		version, err = TransformVersion(change.Regex.Transform, version)
+		if err != nil {
+			return err
+		}
+	}
+	err := os.MkdirAll(filepath.Dir(path), files.DefaultDirWritePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	err = os.WriteFile(path, []byte(version+"\n"), files.DefaultFileWritePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to save file %s: %w", path, err)
+	}
+	o.Logger().Infof("created file %s", info(path))
 	return nil
 }
+
+// ExcludedFiles returns the set of files in the dir matching the exclude globs
+func ExcludedFiles(dir string, excludeGlobs []string) (map[string]bool, error) {
+	answer := map[string]bool{}
+	for _, g := range excludeGlobs {
+		matches, err := filepathx.Glob(filepath.Join(dir, g))
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate exclude glob %s: %w", g, err)
+		}
+		for _, f := range matches {
+			answer[f] = true
+		}
+	}
+	return answer, nil
+}
+
+// MatchesExtensions returns true if there are no extensions or the file has one of the extensions.
+// Extensions can be specified with or without the leading dot
+func MatchesExtensions(path string, extensions []string) bool {
+	if len(extensions) == 0 {
+		return true
+	}
+	ext := filepath.Ext(path)
+	for _, e := range extensions {
+		if e != "" && strings.EqualFold(ext, "."+strings.TrimPrefix(e, ".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBinary returns true if the data looks like a binary file as it contains a NUL byte near the start like git checks
+func IsBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
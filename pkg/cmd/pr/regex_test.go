@@ -0,0 +1,134 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyRegexExtensions(t *testing.T) {
+	sources := map[string]string{
+		"values.yaml": "version: 1.0.0\n",
+		"Makefile":    "version: 1.0.0\n",
+		"notes.md":    "version: 1.0.0\n",
+		"image.bin":   "version: 1.0.0\x00\x01\x02",
+		"config.YAML": "version: 1.0.0\n",
+	}
+	testCases := []struct {
+		name       string
+		extensions []string
+		expected   []string
+	}{
+		{
+			name:     "all-text-files",
+			expected: []string{"values.yaml", "Makefile", "notes.md", "config.YAML"},
+		},
+		{
+			name:       "yaml",
+			extensions: []string{".yaml", "yml"},
+			expected:   []string{"values.yaml", "config.YAML"},
+		},
+		{
+			name:       "binary-allowed-extension",
+			extensions: []string{"bin", "md"},
+			expected:   []string{"notes.md"},
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		for name, text := range sources {
+			err := os.WriteFile(filepath.Join(dir, name), []byte(text), files.DefaultFileWritePermissions)
+			require.NoError(t, err, "failed to write %s", name)
+		}
+
+		o := &pr.Options{Version: "2.0.0"}
+		regex := &v1alpha1.Regex{
+			Pattern:    `version: (\d+\.\d+\.\d+)`,
+			Globs:      []string{"*"},
+			Extensions: tc.extensions,
+		}
+		err := o.ApplyRegex(dir, "https://github.com/myorg/myrepo", v1alpha1.Change{Regex: regex}, regex)
+		require.NoError(t, err, "failed to apply regex for test %s", tc.name)
+
+		for name, text := range sources {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			require.NoError(t, err, "failed to read %s for test %s", name, tc.name)
+			expected := text
+			for _, e := range tc.expected {
+				if e == name {
+					expected = "version: 2.0.0\n"
+				}
+			}
+			assert.Equal(t, expected, string(data), "file %s for test %s", name, tc.name)
+		}
+	}
+}
+
+func TestApplyRegexExcludeFiles(t *testing.T) {
+	sources := map[string]string{
+		"values.yaml":                    "version: 1.0.0\n",
+		"env/dev/values.yaml":            "version: 1.0.0\n",
+		"charts/myapp/values.yaml":       "version: 1.0.0\n",
+		"charts/myapp/templates/cm.yaml": "version: 1.0.0\n",
+	}
+	testCases := []struct {
+		name     string
+		globs    []string
+		exclude  []string
+		expected []string
+	}{
+		{
+			name:     "exclude-charts",
+			globs:    []string{"**/*.yaml"},
+			exclude:  []string{"charts/**"},
+			expected: []string{"values.yaml", "env/dev/values.yaml"},
+		},
+		{
+			name:     "only-charts",
+			globs:    []string{"charts/**/*.yaml"},
+			exclude:  []string{"charts/*/templates/*.yaml"},
+			expected: []string{"charts/myapp/values.yaml"},
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		for name, text := range sources {
+			writeTestFile(t, filepath.Join(dir, name), text)
+		}
+
+		o := &pr.Options{Version: "2.0.0"}
+		regex := &v1alpha1.Regex{
+			Pattern:      `version: (\d+\.\d+\.\d+)`,
+			Globs:        tc.globs,
+			ExcludeFiles: tc.exclude,
+		}
+		err := o.ApplyRegex(dir, "https://github.com/myorg/myrepo", v1alpha1.Change{Regex: regex}, regex)
+		require.NoError(t, err, "failed to apply regex for test %s", tc.name)
+
+		for name, text := range sources {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			require.NoError(t, err, "failed to read %s for test %s", name, tc.name)
+			expected := text
+			for _, e := range tc.expected {
+				if e == name {
+					expected = "version: 2.0.0\n"
+				}
+			}
+			assert.Equal(t, expected, string(data), "file %s for test %s", name, tc.name)
+		}
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	assert.False(t, pr.IsBinary([]byte("version: 1.0.0\n")), "text")
+	assert.False(t, pr.IsBinary(nil), "empty")
+	assert.True(t, pr.IsBinary([]byte{0x89, 'P', 'N', 'G', 0x00}), "binary")
+}
@@ -0,0 +1,82 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+	"oras.land/oras-go/v2/registry/remote/retry"
+)
+
+// FindLatestImageTag returns the newest semantic version tag of the image in the container registry.
+// Pre-release tags and tags which are not semantic versions are ignored.
+// The registry credentials are loaded from the docker config file
+func (o *Options) FindLatestImageTag(ctx context.Context, image string, plainHTTP bool) (string, error) {
+	repo, err := newRegistryRepository(image, plainHTTP)
+	if err != nil {
+		return "", err
+	}
+
+	var latest *semver.Version
+	latestTag := ""
+	err = repo.Tags(ctx, "", func(tags []string) error {
+		for _, tag := range tags {
+			v, err := semver.NewVersion(tag)
+			if err != nil || v.Prerelease() != "" {
+				continue
+			}
+			if latest == nil || v.GreaterThan(latest) {
+				latest = v
+				latestTag = tag
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list tags of image %s: %w", image, err)
+	}
+	if latestTag == "" {
+		return "", fmt.Errorf("no semantic version tags found for image %s", image)
+	}
+	o.Logger().Infof("found latest tag %s of image %s", latestTag, image)
+	return latestTag, nil
+}
+
+// ResolveImageDigest returns the digest, such as sha256:abc..., of the image with the tag in the container registry.
+// The registry credentials are loaded from the docker config file
+func (o *Options) ResolveImageDigest(ctx context.Context, image, tag string, plainHTTP bool) (string, error) {
+	repo, err := newRegistryRepository(image, plainHTTP)
+	if err != nil {
+		return "", err
+	}
+	desc, err := repo.Resolve(ctx, tag)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve tag %s of image %s: %w", tag, image, err)
+	}
+	digest := desc.Digest.String()
+	o.Logger().Infof("resolved tag %s of image %s to digest %s", tag, image, digest)
+	return digest, nil
+}
+
+// newRegistryRepository creates the client of the image repository using the docker config file for authentication
+func newRegistryRepository(image string, plainHTTP bool) (*remote.Repository, error) {
+	repo, err := remote.NewRepository(image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse image %s: %w", image, err)
+	}
+	repo.PlainHTTP = plainHTTP
+
+	store, err := credentials.NewStoreFromDocker(credentials.StoreOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load docker credentials: %w", err)
+	}
+	repo.Client = &auth.Client{
+		Client:     retry.DefaultClient,
+		Cache:      auth.NewCache(),
+		Credential: credentials.Credential(store),
+	}
+	return repo, nil
+}
@@ -0,0 +1,58 @@
+package pr_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindLatestImageTag(t *testing.T) {
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+
+	tags := map[string][]string{
+		"myorg/myapp":   {"latest", "1.2.0", "1.10.0", "1.9.3", "2.0.0-rc.1", "sha-abc123"},
+		"myorg/notags":  {"latest"},
+		"myorg/vprefix": {"v0.1.0", "v0.2.0"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v2/"), "/tags/list")
+		list, ok := tags[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(map[string]interface{}{"name": name, "tags": list})
+		require.NoError(t, err, "failed to write tags")
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	testCases := []struct {
+		image    string
+		expected string
+	}{
+		{image: "myorg/myapp", expected: "1.10.0"},
+		{image: "myorg/myapp:latest", expected: "1.10.0"},
+		{image: "myorg/vprefix", expected: "v0.2.0"},
+		{image: "myorg/notags"},
+		{image: "myorg/missing"},
+	}
+	o := &pr.Options{}
+	for _, tc := range testCases {
+		tag, err := o.FindLatestImageTag(context.Background(), host+"/"+tc.image, true)
+		if tc.expected == "" {
+			require.Error(t, err, "should fail for image %s", tc.image)
+			continue
+		}
+		require.NoError(t, err, "failed to find latest tag for image %s", tc.image)
+		assert.Equal(t, tc.expected, tag, "latest tag for image %s", tc.image)
+	}
+}
@@ -0,0 +1,69 @@
+package pr
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+)
+
+// LoadRepoFile loads the git URLs of the --repo-file so that the repositories updated can be computed by another tool
+func (o *Options) LoadRepoFile() error {
+	if o.RepoFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(o.RepoFile)
+	if err != nil {
+		return fmt.Errorf("failed to load repo file %s: %w", o.RepoFile, err)
+	}
+	o.repoFileURLs = ParseRepoFile(string(data))
+	o.Logger().Infof("loaded %d repositories from file %s", len(o.repoFileURLs), info(o.RepoFile))
+	return nil
+}
+
+// ParseRepoFile returns the git URLs of the repo file text. The URLs are one per line or separated by commas. Blank
+// lines and comments starting with # are ignored
+func ParseRepoFile(text string) []string {
+	var urls []string
+	for _, line := range strings.Split(text, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		for _, u := range strings.Split(line, ",") {
+			u = strings.TrimSpace(u)
+			if u != "" && stringhelpers.StringArrayIndex(urls, u) < 0 {
+				urls = append(urls, u)
+			}
+		}
+	}
+	return urls
+}
+
+// AddRepoFileURLs adds the git URLs of the --repo-file to the URLs of the rule if it has the --repo-file-tag. URLs
+// which the rule already has, ignoring any .git suffix, are not added again
+func (o *Options) AddRepoFileURLs(rule *v1alpha1.Rule) {
+	if len(o.repoFileURLs) == 0 {
+		return
+	}
+	if o.RepoFileTag != "" && stringhelpers.StringArrayIndex(rule.Tags, o.RepoFileTag) < 0 {
+		return
+	}
+	existing := map[string]bool{}
+	for _, u := range rule.URLs {
+		existing[normalizeRepoURL(u)] = true
+	}
+	for _, u := range o.repoFileURLs {
+		key := normalizeRepoURL(u)
+		if existing[key] {
+			continue
+		}
+		existing[key] = true
+		rule.URLs = append(rule.URLs, u)
+	}
+}
+
+func normalizeRepoURL(gitURL string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(gitURL, "/"), ".git")
+}
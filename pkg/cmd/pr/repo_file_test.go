@@ -0,0 +1,47 @@
+package pr_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repos.txt")
+	writeTestFile(t, path, `# generated by the service catalog
+https://github.com/myorg/app1
+
+https://github.com/myorg/app2.git, https://github.com/myorg/app3
+https://github.com/myorg/app1 # duplicate
+`)
+
+	_, o := pr.NewCmdPullRequest()
+	o.RepoFile = path
+	err := o.LoadRepoFile()
+	require.NoError(t, err, "failed to load repo file")
+
+	rule := &v1alpha1.Rule{URLs: []string{"https://github.com/myorg/app2"}}
+	o.AddRepoFileURLs(rule)
+	assert.Equal(t, []string{
+		"https://github.com/myorg/app2",
+		"https://github.com/myorg/app1",
+		"https://github.com/myorg/app3",
+	}, rule.URLs, "URLs of the rule")
+
+	o.RepoFileTag = "services"
+	rule = &v1alpha1.Rule{URLs: []string{"https://github.com/myorg/other"}}
+	o.AddRepoFileURLs(rule)
+	assert.Equal(t, []string{"https://github.com/myorg/other"}, rule.URLs, "URLs of a rule without the tag")
+
+	rule = &v1alpha1.Rule{Tags: []string{"services"}}
+	o.AddRepoFileURLs(rule)
+	assert.Len(t, rule.URLs, 3, "URLs of a rule with the tag")
+
+	o.RepoFile = filepath.Join(t.TempDir(), "missing.txt")
+	err = o.LoadRepoFile()
+	require.Error(t, err, "should fail for a missing file")
+}
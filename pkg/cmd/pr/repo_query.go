@@ -0,0 +1,145 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+	"github.com/shurcooL/githubv4"
+)
+
+const (
+	// DefaultRepoQueryServer the git server of a repository query if neither it nor the git server is specified
+	DefaultRepoQueryServer = DefaultGitServerURL
+
+	// repoQueryPageSize the number of repositories listed per page
+	repoQueryPageSize = 100
+)
+
+// RepoQueryFindURLs adds the git URLs of the repositories matching the repository query of the rule to its URLs
+func (o *Options) RepoQueryFindURLs(rule *v1alpha1.Rule, query *v1alpha1.RepoQuery) error {
+	if query.Org == "" {
+		return fmt.Errorf("no org specified for the repoQuery of the rule")
+	}
+	var nameRegex *regexp.Regexp
+	if query.NameRegex != "" {
+		var err error
+		nameRegex, err = regexp.Compile(query.NameRegex)
+		if err != nil {
+			return fmt.Errorf("failed to parse repoQuery nameRegex %s: %w", query.NameRegex, err)
+		}
+	}
+	server := strings.TrimSuffix(query.Server, "/")
+	if server == "" {
+		server = o.GitServer()
+	}
+
+	ctx := context.Background()
+	var repos []*scm.Repository
+	var err error
+	if query.Topic != "" {
+		repos, err = o.queryRepositoriesWithTopic(ctx, query)
+	} else {
+		repos, err = o.listOrganisationRepositories(ctx, server, query.Org)
+	}
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for _, repo := range repos {
+		fullName := repo.FullName
+		if fullName == "" {
+			fullName = scm.Join(query.Org, repo.Name)
+		}
+		if nameRegex != nil && !nameRegex.MatchString(repo.Name) {
+			continue
+		}
+		if repo.Archived && !query.IncludeArchived {
+			o.Logger().Debugf("ignoring archived repository: %s", fullName)
+			continue
+		}
+		u := stringhelpers.UrlJoin(server, fullName)
+		if stringhelpers.StringArrayIndex(rule.URLs, u) < 0 && stringhelpers.StringArrayIndex(rule.URLs, u+".git") < 0 {
+			rule.URLs = append(rule.URLs, u)
+			count++
+		}
+	}
+	o.Logger().Infof("found %d repositories in %s matching the repoQuery", count, info(stringhelpers.UrlJoin(server, query.Org)))
+	return nil
+}
+
+// listOrganisationRepositories pages through the repositories of the organisation on the git server
+func (o *Options) listOrganisationRepositories(ctx context.Context, server, org string) ([]*scm.Repository, error) {
+	// the ScmClient is per git server so the URL of any repository of the organisation can be used
+	scmClient, _, err := o.GetScmClient(stringhelpers.UrlJoin(server, org, ".github"), o.GitKind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ScmClient for %s: %w", server, err)
+	}
+	var answer []*scm.Repository
+	for page := 1; ; page++ {
+		repos, _, err := scmClient.Repositories.ListOrganisation(ctx, org, &scm.ListOptions{Page: page, Size: repoQueryPageSize})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories of organisation %s: %w", org, err)
+		}
+		answer = append(answer, repos...)
+		if len(repos) < repoQueryPageSize {
+			return answer, nil
+		}
+	}
+}
+
+// queryRepositoriesWithTopic pages through the GitHub search results for the repositories of the organisation with
+// the topic of the query
+func (o *Options) queryRepositoriesWithTopic(ctx context.Context, query *v1alpha1.RepoQuery) ([]*scm.Repository, error) {
+	var q struct {
+		Search struct {
+			Nodes []struct {
+				Repository struct {
+					Name       string
+					IsArchived bool
+				} `graphql:"... on Repository"`
+			}
+			PageInfo struct {
+				EndCursor   githubv4.String
+				HasNextPage bool
+			}
+		} `graphql:"search(query: $query, type: REPOSITORY, first: 100, after: $cursor)"`
+	}
+	search := fmt.Sprintf("org:%s topic:%s", query.Org, query.Topic)
+	if !query.IncludeArchived {
+		search += " archived:false"
+	}
+	v := map[string]interface{}{
+		"query":  githubv4.String(search),
+		"cursor": (*githubv4.String)(nil),
+	}
+
+	client := o.GitHubGraphQLClient(ctx)
+	var answer []*scm.Repository
+	for {
+		err := client.Query(ctx, &q, v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search for repositories with %s: %w", search, err)
+		}
+		for _, n := range q.Search.Nodes {
+			if n.Repository.Name == "" {
+				continue
+			}
+			answer = append(answer, &scm.Repository{
+				Namespace: query.Org,
+				Name:      n.Repository.Name,
+				FullName:  scm.Join(query.Org, n.Repository.Name),
+				Archived:  n.Repository.IsArchived,
+			})
+		}
+		if !q.Search.PageInfo.HasNextPage {
+			return answer, nil
+		}
+		v["cursor"] = githubv4.NewString(q.Search.PageInfo.EndCursor)
+	}
+}
@@ -0,0 +1,106 @@
+package pr_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoQueryFindURLs(t *testing.T) {
+	var repos []*scm.Repository
+	for i := 1; i <= 120; i++ {
+		repos = append(repos, &scm.Repository{Namespace: "myorg", Name: fmt.Sprintf("service-%d", i), FullName: fmt.Sprintf("myorg/service-%d", i)})
+	}
+	repos = append(repos,
+		&scm.Repository{Namespace: "myorg", Name: "service-old", FullName: "myorg/service-old", Archived: true},
+		&scm.Repository{Namespace: "myorg", Name: "docs", FullName: "myorg/docs"},
+	)
+
+	testCases := []struct {
+		name     string
+		query    v1alpha1.RepoQuery
+		expected []string
+	}{
+		{
+			name:  "name-regex",
+			query: v1alpha1.RepoQuery{Org: "myorg", NameRegex: `^service-(1|old)$`},
+			expected: []string{
+				"https://github.com/myorg/existing",
+				"https://github.com/myorg/service-1",
+			},
+		},
+		{
+			name:  "include-archived",
+			query: v1alpha1.RepoQuery{Org: "myorg", NameRegex: `^service-(1|old)$`, IncludeArchived: true},
+			expected: []string{
+				"https://github.com/myorg/existing",
+				"https://github.com/myorg/service-1",
+				"https://github.com/myorg/service-old",
+			},
+		},
+		{
+			name:  "paging",
+			query: v1alpha1.RepoQuery{Org: "myorg", NameRegex: `^service-1[12]0$`},
+			expected: []string{
+				"https://github.com/myorg/existing",
+				"https://github.com/myorg/service-110",
+				"https://github.com/myorg/service-120",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		scmClient, _ := fake.NewDefault()
+		repoService := &fakeRepositoryService{RepositoryService: scmClient.Repositories, repos: repos}
+		scmClient.Repositories = repoService
+
+		_, o := pr.NewCmdPullRequest()
+		o.ScmClient = scmClient
+		o.ScmClientFactory.ScmClient = scmClient
+		o.ScmClientFactory.GitServerURL = "https://github.com"
+		o.GitKind = "fake"
+
+		rule := &v1alpha1.Rule{
+			URLs:      []string{"https://github.com/myorg/existing"},
+			RepoQuery: &tc.query,
+			Changes:   []v1alpha1.Change{{Regex: &v1alpha1.Regex{Pattern: `version: (.*)`, Globs: []string{"values.yaml"}}}},
+		}
+		err := o.ProcessRule(rule, 0)
+		require.NoError(t, err, "failed to process rule for %s", tc.name)
+		assert.Equal(t, tc.expected, rule.URLs, "discovered URLs for %s", tc.name)
+		assert.Equal(t, []string{"myorg", "myorg"}, repoService.orgs, "pages listed for %s", tc.name)
+	}
+}
+
+// fakeRepositoryService implements paging through the repositories of an organisation
+type fakeRepositoryService struct {
+	scm.RepositoryService
+	repos []*scm.Repository
+	orgs  []string
+}
+
+func (s *fakeRepositoryService) ListOrganisation(_ context.Context, org string, opts *scm.ListOptions) ([]*scm.Repository, *scm.Response, error) {
+	s.orgs = append(s.orgs, org)
+	var answer []*scm.Repository
+	for _, r := range s.repos {
+		if r.Namespace == org {
+			answer = append(answer, r)
+		}
+	}
+	start := (opts.Page - 1) * opts.Size
+	if start >= len(answer) {
+		return nil, &scm.Response{}, nil
+	}
+	end := start + opts.Size
+	if end > len(answer) {
+		end = len(answer)
+	}
+	return answer[start:end], &scm.Response{}, nil
+}
@@ -0,0 +1,15 @@
+package pr
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+)
+
+// checkRequireMatch returns an error if the change requires a match but the pattern matched nothing in the files
+func checkRequireMatch(change v1alpha1.Change, matches int, kind, pattern string, globs []string, gitURL string) error {
+	if !change.RequireMatch || matches > 0 {
+		return nil
+	}
+	return fmt.Errorf("the %s change with pattern %s matched nothing in the files %v of repository %s", kind, pattern, globs, gitURL)
+}
@@ -0,0 +1,73 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireMatch(t *testing.T) {
+	testCases := []struct {
+		name   string
+		change v1alpha1.Change
+		err    bool
+	}{
+		{
+			name:   "regex-matches",
+			change: v1alpha1.Change{RequireMatch: true, Regex: &v1alpha1.Regex{Pattern: `version: (.*)`, Globs: []string{"*.yaml"}}},
+		},
+		{
+			name:   "regex-typo-tolerated",
+			change: v1alpha1.Change{Regex: &v1alpha1.Regex{Pattern: `verison: (.*)`, Globs: []string{"*.yaml"}}},
+		},
+		{
+			name:   "regex-typo",
+			change: v1alpha1.Change{RequireMatch: true, Regex: &v1alpha1.Regex{Pattern: `verison: (.*)`, Globs: []string{"*.yaml"}}},
+			err:    true,
+		},
+		{
+			name:   "regex-no-files",
+			change: v1alpha1.Change{RequireMatch: true, Regex: &v1alpha1.Regex{Pattern: `version: (.*)`, Globs: []string{"*.yml"}}},
+			err:    true,
+		},
+		{
+			name:   "set-matches",
+			change: v1alpha1.Change{RequireMatch: true, Set: &v1alpha1.SetValue{Path: "enabled", Value: "true", Globs: []string{"*.yaml"}}},
+		},
+		{
+			name:   "set-no-files",
+			change: v1alpha1.Change{RequireMatch: true, Set: &v1alpha1.SetValue{Path: "enabled", Value: "true", Globs: []string{"missing.yaml"}}},
+			err:    true,
+		},
+		{
+			name: "checksum-typo",
+			change: v1alpha1.Change{RequireMatch: true, Checksum: &v1alpha1.Checksum{
+				VersionPattern:  `tool_version: (.*)`,
+				ChecksumPattern: `sha256: (.*)`,
+				Globs:           []string{"*.yaml"},
+				Checksums:       map[string]string{"2.0.0": "abc"},
+			}},
+			err: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		err := os.WriteFile(filepath.Join(dir, "values.yaml"), []byte("version: 1.0.0\nsha256: def\n"), files.DefaultFileWritePermissions)
+		require.NoError(t, err, "failed to write values.yaml")
+
+		o := &pr.Options{Version: "2.0.0"}
+		err = o.ApplyChanges(dir, "https://github.com/myorg/myrepo", tc.change)
+		if tc.err {
+			require.Error(t, err, "should fail for test %s", tc.name)
+			t.Logf("test %s got expected error: %s", tc.name, err.Error())
+			continue
+		}
+		require.NoError(t, err, "should not fail for test %s", tc.name)
+	}
+}
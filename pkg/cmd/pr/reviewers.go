@@ -0,0 +1,55 @@
+package pr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+)
+
+// RequestReviewersOnPullRequest requests reviews of a downstream PR from the reviewers of the rule combined with the
+// --pull-request-reviewer users
+func (o *Options) RequestReviewersOnPullRequest(rule *v1alpha1.Rule, pullRequest *scm.PullRequest, ruleURL, gitKind string) error {
+	reviewers := o.configuredReviewers(rule)
+	if len(reviewers) == 0 {
+		return nil
+	}
+	return o.RequestReview(pullRequest, reviewers, ruleURL, gitKind)
+}
+
+// configuredReviewers returns the reviewers of the rule combined with the --pull-request-reviewer users
+func (o *Options) configuredReviewers(rule *v1alpha1.Rule) []string {
+	var reviewers []string
+	for _, reviewer := range rule.PullRequestReviewers {
+		reviewers = stringhelpers.EnsureStringArrayContains(reviewers, reviewer)
+	}
+	for _, reviewer := range o.PRReviewers {
+		reviewers = stringhelpers.EnsureStringArrayContains(reviewers, reviewer)
+	}
+	return reviewers
+}
+
+// RequestReview requests reviews of the PR from the users. Fails if the git provider does not support review requests,
+// including GitLab where the client would assign the users to the merge request instead
+func (o *Options) RequestReview(pullRequest *scm.PullRequest, users []string, gitURL, gitKind string) error {
+	ctx := context.Background()
+	scmClient, repoFullName, err := o.GetScmClient(gitURL, gitKind)
+	if err != nil {
+		return fmt.Errorf("failed to create ScmClient: %w", err)
+	}
+	if o.IsGitLab() {
+		return fmt.Errorf("cannot request reviews from users %v on merge request !%d in repo %s as reviewers are not supported for GitLab, use pullRequestAssignees to assign them instead", users, pullRequest.Number, repoFullName)
+	}
+	o.Logger().Infof("Requesting reviews from users %v on PR %d in repo %s", users, pullRequest.Number, repoFullName)
+	_, err = scmClient.PullRequests.RequestReview(ctx, repoFullName, pullRequest.Number, users)
+	if errors.Is(err, scm.ErrNotSupported) {
+		return fmt.Errorf("cannot request reviews from users %v on PR %d in repo %s as the git provider does not support review requests", users, pullRequest.Number, repoFullName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to request reviews on PR %d: %w", pullRequest.Number, err)
+	}
+	return nil
+}
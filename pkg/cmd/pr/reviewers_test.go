@@ -0,0 +1,58 @@
+package pr_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestReviewersOnPullRequest(t *testing.T) {
+	gitURL := "https://github.com/myorg/myrepo"
+	rule := &v1alpha1.Rule{PullRequestReviewers: []string{"alice", "bob"}}
+	pullRequest := &scm.PullRequest{Number: 5}
+
+	for _, supported := range []bool{true, false} {
+		scmClient, _ := fake.NewDefault()
+		pullRequests := &fakeReviewPullRequestService{PullRequestService: scmClient.PullRequests}
+		if supported {
+			scmClient.PullRequests = pullRequests
+		}
+
+		_, o := pr.NewCmdPullRequest()
+		o.ScmClient = scmClient
+		o.ScmClientFactory.ScmClient = scmClient
+		o.ScmClientFactory.GitServerURL = "https://github.com"
+		o.GitKind = "fake"
+		o.PRReviewers = []string{"bob", "carol"}
+
+		err := o.RequestReviewersOnPullRequest(rule, pullRequest, gitURL, o.GitKind)
+		if !supported {
+			require.Error(t, err, "should fail when review requests are not supported")
+			continue
+		}
+		require.NoError(t, err, "failed to request reviewers")
+		assert.Equal(t, map[string][]string{"myorg/myrepo#5": {"alice", "bob", "carol"}}, pullRequests.reviewers, "requested reviewers")
+	}
+}
+
+// fakeReviewPullRequestService records the review requests which the fake driver does not support
+type fakeReviewPullRequestService struct {
+	scm.PullRequestService
+	reviewers map[string][]string
+}
+
+func (s *fakeReviewPullRequestService) RequestReview(_ context.Context, repo string, number int, logins []string) (*scm.Response, error) {
+	if s.reviewers == nil {
+		s.reviewers = map[string][]string{}
+	}
+	key := fmt.Sprintf("%s#%d", repo, number)
+	s.reviewers[key] = append(s.reviewers[key], logins...)
+	return nil, nil
+}
@@ -0,0 +1,136 @@
+package pr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+)
+
+// ValidateRuleDependencies checks the IDs of the rules are unique and that their dependencies are the IDs of rules
+// which do not form a cycle so that an invalid config fails before any repository is changed
+func ValidateRuleDependencies(rules []v1alpha1.Rule) error {
+	ids := map[string]int{}
+	for i := range rules {
+		id := rules[i].ID
+		if id == "" {
+			continue
+		}
+		if j, ok := ids[id]; ok {
+			return fmt.Errorf("rules #%d and #%d have the same id %s", j, i, id)
+		}
+		ids[id] = i
+	}
+	for i := range rules {
+		for _, dep := range rules[i].DependsOn {
+			if _, ok := ids[dep]; !ok {
+				return fmt.Errorf("rule #%d depends on the rule %s which does not exist", i, dep)
+			}
+		}
+	}
+
+	// lets walk the dependencies of each rule keeping the path so a cycle can be reported
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	states := make([]int, len(rules))
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		path = append(path, ruleID(&rules[i], i))
+		switch states[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("rules have a dependency cycle: %s", strings.Join(path, " -> "))
+		}
+		states[i] = visiting
+		for _, dep := range rules[i].DependsOn {
+			err := visit(ids[dep], path)
+			if err != nil {
+				return err
+			}
+		}
+		states[i] = visited
+		return nil
+	}
+	for i := range rules {
+		err := visit(i, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OrderRulesByDependencies returns the rules ordered so that each rule comes after the rules it depends on along with
+// the position of each rule in the given rules. Otherwise the order of the rules is kept. Dependencies on rules which
+// are not in the list, as they are not run, are ignored
+func OrderRulesByDependencies(rules []v1alpha1.Rule) ([]v1alpha1.Rule, []int) {
+	ids := map[string]int{}
+	for i := range rules {
+		if rules[i].ID != "" {
+			ids[rules[i].ID] = i
+		}
+	}
+	answer := make([]v1alpha1.Rule, 0, len(rules))
+	positions := make([]int, 0, len(rules))
+	added := make([]bool, len(rules))
+	var add func(i int)
+	add = func(i int) {
+		if added[i] {
+			return
+		}
+		// mark the rule first so that a cycle cannot recurse forever
+		added[i] = true
+		for _, dep := range rules[i].DependsOn {
+			if j, ok := ids[dep]; ok {
+				add(j)
+			}
+		}
+		answer = append(answer, rules[i])
+		positions = append(positions, i)
+	}
+	for i := range rules {
+		add(i)
+	}
+	return answer, positions
+}
+
+// rulePositionIndexes returns the config indexes of the rules at the positions returned when the rules were ordered or
+// batched
+func rulePositionIndexes(indexes, positions []int) []int {
+	answer := make([]int, 0, len(positions))
+	for _, p := range positions {
+		answer = append(answer, indexes[p])
+	}
+	return answer
+}
+
+// FailedDependency returns the first dependency of the rule which failed, or was skipped, so that the rule is skipped
+func FailedDependency(rule *v1alpha1.Rule, failed map[string]bool) string {
+	for _, dep := range rule.DependsOn {
+		if failed[dep] {
+			return dep
+		}
+	}
+	return ""
+}
+
+// ruleID returns the ID of the rule for messages falling back to its index
+func ruleID(rule *v1alpha1.Rule, index int) string {
+	if rule.ID != "" {
+		return rule.ID
+	}
+	return fmt.Sprintf("#%d", index)
+}
+
+// ConfigRules returns the rules of the config followed by the rules of its version stream rules in the order the rules
+// are run so that rules can depend on version stream rules
+func ConfigRules(config *v1alpha1.UpdateConfig) []v1alpha1.Rule {
+	rules := append([]v1alpha1.Rule{}, config.Spec.Rules...)
+	for i := range config.Spec.VersionStreamRules {
+		rules = append(rules, config.Spec.VersionStreamRules[i].Rule)
+	}
+	return rules
+}
@@ -0,0 +1,76 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRuleDependencies(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rules    []v1alpha1.Rule
+		expected string
+	}{
+		{
+			name: "valid",
+			rules: []v1alpha1.Rule{
+				{ID: "apps", DependsOn: []string{"lib"}},
+				{ID: "lib"},
+				{DependsOn: []string{"lib", "apps"}},
+			},
+		},
+		{
+			name:     "duplicate id",
+			rules:    []v1alpha1.Rule{{ID: "lib"}, {ID: "lib"}},
+			expected: "rules #0 and #1 have the same id lib",
+		},
+		{
+			name:     "missing dependency",
+			rules:    []v1alpha1.Rule{{ID: "apps", DependsOn: []string{"lib"}}},
+			expected: "rule #0 depends on the rule lib which does not exist",
+		},
+		{
+			name: "cycle",
+			rules: []v1alpha1.Rule{
+				{ID: "a", DependsOn: []string{"b"}},
+				{ID: "b", DependsOn: []string{"c"}},
+				{ID: "c", DependsOn: []string{"a"}},
+			},
+			expected: "rules have a dependency cycle: a -> b -> c -> a",
+		},
+	}
+	for _, tc := range testCases {
+		err := pr.ValidateRuleDependencies(tc.rules)
+		if tc.expected == "" {
+			assert.NoError(t, err, "for %s", tc.name)
+			continue
+		}
+		require.Error(t, err, "for %s", tc.name)
+		assert.Equal(t, tc.expected, err.Error(), "for %s", tc.name)
+	}
+}
+
+func TestOrderRulesByDependencies(t *testing.T) {
+	rules := []v1alpha1.Rule{
+		{ID: "apps", DependsOn: []string{"lib", "charts"}},
+		{ID: "docs"},
+		{ID: "lib", DependsOn: []string{"base"}},
+		{ID: "base"},
+		{ID: "other", DependsOn: []string{"filtered"}},
+	}
+	ordered, positions := pr.OrderRulesByDependencies(rules)
+	var ids []string
+	for _, rule := range ordered {
+		ids = append(ids, rule.ID)
+	}
+	assert.Equal(t, []string{"base", "lib", "apps", "docs", "other"}, ids, "ordered rules")
+	assert.Equal(t, []int{3, 2, 0, 1, 4}, positions, "positions of the ordered rules")
+
+	failed := map[string]bool{"lib": true}
+	assert.Equal(t, "lib", pr.FailedDependency(&rules[0], failed), "failed dependency of apps")
+	assert.Empty(t, pr.FailedDependency(&rules[1], failed), "failed dependency of docs")
+}
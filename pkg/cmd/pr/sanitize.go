@@ -0,0 +1,51 @@
+package pr
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// SanitizeNone leaves the Pull Request title and body unchanged
+	SanitizeNone = "none"
+
+	// SanitizeControl removes control characters and replaces invalid UTF-8
+	SanitizeControl = "control"
+
+	// SanitizeASCII removes control characters and any non ASCII characters
+	SanitizeASCII = "ascii"
+)
+
+// SanitizeLevels the valid values of the --sanitize option
+var SanitizeLevels = []string{SanitizeNone, SanitizeControl, SanitizeASCII}
+
+// SanitizeText removes characters from the text which can cause SCM APIs to reject a Pull Request.
+// New lines and tabs are always kept.
+func SanitizeText(text, level string) string {
+	if level == "" || level == SanitizeNone {
+		return text
+	}
+	if !utf8.ValidString(text) {
+		text = strings.ToValidUTF8(text, string(utf8.RuneError))
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if unicode.IsControl(r) {
+			return -1
+		}
+		if level == SanitizeASCII && (r > unicode.MaxASCII || r == utf8.RuneError) {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// sanitizePullRequestText sanitizes the title, body and changelog used to create the Pull Request
+func (o *Options) sanitizePullRequestText() {
+	o.CommitTitle = SanitizeText(o.CommitTitle, o.Sanitize)
+	o.CommitMessage = SanitizeText(o.CommitMessage, o.Sanitize)
+	o.CommitChangelog = SanitizeText(o.CommitChangelog, o.Sanitize)
+}
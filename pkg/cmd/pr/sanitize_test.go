@@ -0,0 +1,47 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeText(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		level    string
+		expected string
+	}{
+		{
+			name:     "none",
+			text:     "chore: bump \x1b[1mfoo\x1b[0m ✨",
+			level:    pr.SanitizeNone,
+			expected: "chore: bump \x1b[1mfoo\x1b[0m ✨",
+		},
+		{
+			name:     "control",
+			text:     "chore: bump \x1b[1mfoo\x1b[0m ✨\n\tdetails\r",
+			level:    pr.SanitizeControl,
+			expected: "chore: bump [1mfoo[0m ✨\n\tdetails",
+		},
+		{
+			name:     "control-invalid-utf8",
+			text:     "bad \xff byte",
+			level:    pr.SanitizeControl,
+			expected: "bad � byte",
+		},
+		{
+			name:     "ascii",
+			text:     "chore: bump foo ✨ to 1.0.0\nbad \xff byte\x00",
+			level:    pr.SanitizeASCII,
+			expected: "chore: bump foo  to 1.0.0\nbad  byte",
+		},
+	}
+
+	for _, tc := range testCases {
+		actual := pr.SanitizeText(tc.text, tc.level)
+		assert.Equal(t, tc.expected, actual, "sanitized text for test %s", tc.name)
+	}
+}
@@ -0,0 +1,63 @@
+package pr
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/giturl"
+)
+
+// scmClientKey the git server and kind an ScmClient is cached for
+type scmClientKey struct {
+	server string
+	kind   string
+}
+
+// cachedScmClient an authenticated ScmClient along with the token and git kind it was created with
+type cachedScmClient struct {
+	client *scm.Client
+	token  string
+	kind   string
+}
+
+// newScmClientKey returns the cache key for the git URL and kind
+func newScmClientKey(gitURL, kind string) (scmClientKey, error) {
+	gitInfo, err := giturl.ParseGitURL(gitURL)
+	if err != nil {
+		return scmClientKey{}, fmt.Errorf("failed to parse git URL %s: %w", gitURL, err)
+	}
+	return scmClientKey{server: gitInfo.HostURLWithoutUser(), kind: kind}, nil
+}
+
+// useCachedScmClient makes the ScmClientFactory reuse the cached client for the key so that a new client is not
+// created when a run switches between git servers. Returns the git kind of the cached client
+func (o *Options) useCachedScmClient(key scmClientKey) string {
+	var c *cachedScmClient
+	o.withLock(func() {
+		c = o.scmClients[key]
+	})
+	if c == nil {
+		return key.kind
+	}
+	f := &o.ScmClientFactory
+	f.ScmClient = c.client
+	f.GitServerURL = key.server
+	f.GitToken = c.token
+	f.GitKind = c.kind
+	return c.kind
+}
+
+// cacheScmClient caches the client for the key along with the token and git kind of the ScmClientFactory
+func (o *Options) cacheScmClient(key scmClientKey, client *scm.Client) {
+	f := &o.ScmClientFactory
+	o.withLock(func() {
+		if o.scmClients == nil {
+			o.scmClients = map[scmClientKey]*cachedScmClient{}
+		}
+		o.scmClients[key] = &cachedScmClient{
+			client: client,
+			token:  f.GitToken,
+			kind:   f.GitKind,
+		}
+	})
+}
@@ -0,0 +1,32 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetScmClientCachesPerServer(t *testing.T) {
+	_, o := pr.NewCmdPullRequest()
+	o.ScmClientFactory.GitToken = "dummytoken"
+
+	github1, repo, err := o.GetScmClient("https://github.com/myorg/repo1", "fake")
+	require.NoError(t, err, "failed to create client for github")
+	assert.Equal(t, "myorg/repo1", repo, "repository name")
+
+	gitlab, _, err := o.GetScmClient("https://gitlab.com/myorg/repo2", "fake")
+	require.NoError(t, err, "failed to create client for gitlab")
+	assert.NotSame(t, github1, gitlab, "should use a different client for each server")
+
+	github2, repo, err := o.GetScmClient("https://github.com/myorg/repo3", "fake")
+	require.NoError(t, err, "failed to get client for github")
+	assert.Equal(t, "myorg/repo3", repo, "repository name")
+	assert.Same(t, github1, github2, "should reuse the github client")
+	assert.Equal(t, "https://github.com", o.ScmClientFactory.GitServerURL, "git server of the factory")
+
+	gitlab2, _, err := o.GetScmClient("https://gitlab.com/myorg/repo4", "fake")
+	require.NoError(t, err, "failed to get client for gitlab")
+	assert.Same(t, gitlab, gitlab2, "should reuse the gitlab client")
+}
@@ -0,0 +1,112 @@
+package pr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/yargevad/filepathx"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ApplySet sets the value at the path in the YAML files to the literal value of the change
+func (o *Options) ApplySet(dir, gitURL string, change v1alpha1.Change, set *v1alpha1.SetValue) error {
+	if set.Path == "" {
+		return fmt.Errorf("no path for set change %#v", change)
+	}
+	fields := strings.Split(set.Path, ".")
+	value, err := yaml.Parse(set.Value)
+	if err != nil {
+		return fmt.Errorf("failed to parse value %s of set change: %w", set.Value, err)
+	}
+	if value.YNode().Kind != yaml.ScalarNode {
+		return fmt.Errorf("the value %s of set change must be a scalar", set.Value)
+	}
+
+	matched := 0
+	for _, g := range set.Globs {
+		path := filepath.Join(dir, g)
+		matches, err := filepathx.Glob(path)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate glob %s: %w", path, err)
+		}
+		if len(matches) == 0 {
+			create, err := o.handleMissingFile(change, g, gitURL, !strings.ContainsAny(g, "*?[{"))
+			if err != nil {
+				return err
+			}
+			if create {
+				err = os.MkdirAll(filepath.Dir(path), files.DefaultDirWritePermissions)
+				if err != nil {
+					return fmt.Errorf("failed to create directory for %s: %w", path, err)
+				}
+				err = o.setYAMLValue(path, yaml.NewMapRNode(nil), fields, value)
+				if err != nil {
+					return err
+				}
+				matched++
+			}
+			continue
+		}
+		matched += len(matches)
+		for _, f := range matches {
+			node, err := yaml.ReadFile(f)
+			if err != nil {
+				return fmt.Errorf("failed to load YAML file %s: %w", f, err)
+			}
+			if change.ExpectedCurrent != "" {
+				current, err := node.Pipe(yaml.Lookup(fields...))
+				if err != nil {
+					return fmt.Errorf("failed to find %s in file %s: %w", set.Path, f, err)
+				}
+				value := ""
+				if current != nil {
+					value = current.YNode().Value
+				}
+				if !o.matchesExpectedCurrent(change, value, set.Path+" in "+f) {
+					continue
+				}
+			}
+			err = o.setYAMLValue(f, node, fields, value)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return checkRequireMatch(change, matched, "set", set.Path, set.Globs, gitURL)
+}
+
+// setYAMLValue sets the field path of the node to the value and saves the file if it has changed
+func (o *Options) setYAMLValue(path string, node *yaml.RNode, fields []string, value *yaml.RNode) error {
+	parent, err := node.Pipe(yaml.LookupCreate(yaml.MappingNode, fields[:len(fields)-1]...))
+	if err != nil {
+		return fmt.Errorf("failed to find %s in file %s: %w", strings.Join(fields, "."), path, err)
+	}
+	name := fields[len(fields)-1]
+	current := parent.Field(name)
+	if current != nil && current.Value.YNode().Kind == yaml.ScalarNode {
+		// lets update the existing scalar so that any comments are kept
+		n := current.Value.YNode()
+		v := value.YNode()
+		if n.Value == v.Value && n.Tag == v.Tag && n.Style == v.Style {
+			return nil
+		}
+		n.Value = v.Value
+		n.Tag = v.Tag
+		n.Style = v.Style
+	} else {
+		err = parent.PipeE(yaml.SetField(name, value.Copy()))
+		if err != nil {
+			return fmt.Errorf("failed to set %s in file %s: %w", strings.Join(fields, "."), path, err)
+		}
+	}
+	err = yaml.WriteFile(node, path)
+	if err != nil {
+		return fmt.Errorf("failed to save file %s: %w", path, err)
+	}
+	o.Logger().Infof("modified file %s setting %s to %s", info(path), strings.Join(fields, "."), value.YNode().Value)
+	return nil
+}
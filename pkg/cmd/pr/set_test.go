@@ -0,0 +1,109 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplySet(t *testing.T) {
+	source := `# the app config
+app:
+  version: 1.0.0
+  features:
+    newUI: false # enabled in 2.0.0
+`
+	testCases := []struct {
+		name     string
+		set      v1alpha1.SetValue
+		expected string
+		err      bool
+	}{
+		{
+			name: "bool",
+			set:  v1alpha1.SetValue{Path: "app.features.newUI", Value: "true"},
+			expected: `# the app config
+app:
+  version: 1.0.0
+  features:
+    newUI: true # enabled in 2.0.0
+`,
+		},
+		{
+			name: "string",
+			set:  v1alpha1.SetValue{Path: "app.features.newUI", Value: `"true"`},
+			expected: `# the app config
+app:
+  version: 1.0.0
+  features:
+    newUI: "true" # enabled in 2.0.0
+`,
+		},
+		{
+			name: "create-fields",
+			set:  v1alpha1.SetValue{Path: "app.features.darkMode", Value: "on"},
+			expected: `# the app config
+app:
+  version: 1.0.0
+  features:
+    newUI: false # enabled in 2.0.0
+    darkMode: "on"
+`,
+		},
+		{
+			name:     "unchanged",
+			set:      v1alpha1.SetValue{Path: "app.features.newUI", Value: "false"},
+			expected: source,
+		},
+		{
+			name: "no-path",
+			set:  v1alpha1.SetValue{Value: "true"},
+			err:  true,
+		},
+		{
+			name: "not-scalar",
+			set:  v1alpha1.SetValue{Path: "app.features", Value: "a: b"},
+			err:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.yaml")
+		err := os.WriteFile(path, []byte(source), files.DefaultFileWritePermissions)
+		require.NoError(t, err, "failed to write config for test %s", tc.name)
+
+		o := &pr.Options{Version: "2.0.0"}
+		tc.set.Globs = []string{"*.yaml"}
+		change := v1alpha1.Change{Set: &tc.set}
+		err = o.ApplyChanges(dir, "https://github.com/myorg/myrepo", change)
+		if tc.err {
+			require.Error(t, err, "should fail for test %s", tc.name)
+			continue
+		}
+		require.NoError(t, err, "failed to apply set change for test %s", tc.name)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err, "failed to read config for test %s", tc.name)
+		assert.Equal(t, tc.expected, string(data), "config for test %s", tc.name)
+	}
+
+	// a missing file can be created
+	dir := t.TempDir()
+	o := &pr.Options{}
+	change := v1alpha1.Change{
+		IfMissing: pr.IfMissingCreate,
+		Set:       &v1alpha1.SetValue{Path: "features.newUI", Value: "true", Globs: []string{"config/flags.yaml"}},
+	}
+	err := o.ApplyChanges(dir, "https://github.com/myorg/myrepo", change)
+	require.NoError(t, err, "failed to create missing file")
+	data, err := os.ReadFile(filepath.Join(dir, "config", "flags.yaml"))
+	require.NoError(t, err, "failed to read created file")
+	assert.Equal(t, "features:\n  newUI: true\n", string(data), "created file")
+}
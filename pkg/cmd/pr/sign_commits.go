@@ -0,0 +1,41 @@
+package pr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// validateCommitSigning checks a GPG key is configured for --sign-commits. If no --gpg-key-id is specified the
+// user.signingkey of the git config is used
+func (o *Options) validateCommitSigning() error {
+	if !o.SignCommits || o.GPGKeyID != "" {
+		return nil
+	}
+	key, err := o.Git().Command(o.Dir, "config", "--get", "user.signingkey")
+	if err == nil {
+		o.GPGKeyID = strings.TrimSpace(key)
+	}
+	if o.GPGKeyID == "" {
+		return fmt.Errorf("cannot sign commits with --sign-commits as no GPG key is configured. Try specifying --gpg-key-id or setting user.signingkey in the git config")
+	}
+	return nil
+}
+
+// ConfigureCommitSigning configures the git repository in the dir to sign its commits with the GPG key if
+// --sign-commits is enabled. The local config of the repository is changed so the global git config is not modified
+func (o *Options) ConfigureCommitSigning(dir string) error {
+	if !o.SignCommits {
+		return nil
+	}
+	g := o.Git()
+	for _, args := range [][]string{
+		{"config", "--local", "commit.gpgsign", "true"},
+		{"config", "--local", "user.signingkey", o.GPGKeyID},
+	} {
+		_, err := g.Command(dir, args...)
+		if err != nil {
+			return fmt.Errorf("failed to configure commit signing in dir %s: %w", dir, err)
+		}
+	}
+	return nil
+}
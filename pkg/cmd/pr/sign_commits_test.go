@@ -0,0 +1,31 @@
+package pr_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureCommitSigning(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepository(t, dir)
+
+	o := &pr.Options{}
+	err := o.ConfigureCommitSigning(dir)
+	require.NoError(t, err, "failed to configure commit signing when disabled")
+	_, err = o.Git().Command(dir, "config", "--local", "--get", "commit.gpgsign")
+	require.Error(t, err, "should not configure signing when disabled")
+
+	o = &pr.Options{SignCommits: true, GPGKeyID: "ABCDEF1234567890"}
+	err = o.ConfigureCommitSigning(dir)
+	require.NoError(t, err, "failed to configure commit signing")
+
+	for key, expected := range map[string]string{"commit.gpgsign": "true", "user.signingkey": "ABCDEF1234567890"} {
+		value, err := o.Git().Command(dir, "config", "--local", "--get", key)
+		require.NoError(t, err, "failed to get git config %s", key)
+		assert.Equal(t, expected, strings.TrimSpace(value), "git config %s", key)
+	}
+}
@@ -0,0 +1,67 @@
+package pr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient"
+)
+
+// errNoChanges is returned by the change function when the changes leave the repository unchanged so that no Pull
+// Request is created
+var errNoChanges = errors.New("no changes to the repository")
+
+// HasChanges returns true if the working tree of the git repository in the dir has any changes, including new files,
+// or if HEAD is no longer the commit SHA from before the changes were applied as a command may commit its changes
+func (o *Options) HasChanges(dir, sha string) (bool, error) {
+	status, err := o.Git().Command(dir, "status", "--porcelain")
+	if err != nil {
+		return false, fmt.Errorf("failed to get git status in dir %s: %w", dir, err)
+	}
+	if strings.TrimSpace(status) != "" {
+		return true, nil
+	}
+	if sha == "" {
+		return false, nil
+	}
+	head, err := gitclient.GetLatestCommitSha(o.Git(), dir)
+	if err != nil {
+		return false, fmt.Errorf("failed to get the latest commit SHA in dir %s: %w", dir, err)
+	}
+	return strings.TrimSpace(head) != strings.TrimSpace(sha), nil
+}
+
+// CloseStalePullRequest closes the Pull Request reused on the repository as the base branch already has the changes
+// so the Pull Request is stale. Does nothing if there is no open Pull Request
+func (o *Options) CloseStalePullRequest(gitURL string) error {
+	scmClient, repoFullName, err := o.GetScmClient(gitURL, o.GitKind)
+	if err != nil {
+		return fmt.Errorf("failed to create ScmClient: %w", err)
+	}
+	if scmClient == nil {
+		return nil
+	}
+	pr, err := o.FindExistingPullRequest(scmClient, repoFullName)
+	if err != nil {
+		return fmt.Errorf("failed to find existing Pull Request: %w", err)
+	}
+	if pr == nil || pr.Closed || pr.Merged {
+		return nil
+	}
+
+	ctx := context.Background()
+	body := fmt.Sprintf("closing as the base branch is already up to date with version %s", o.Version)
+	_, _, err = scmClient.PullRequests.CreateComment(ctx, repoFullName, pr.Number, &scm.CommentInput{Body: body})
+	if err != nil {
+		o.Logger().Warnf("failed to comment on stale Pull Request %s: %s", pr.Link, err.Error())
+	}
+	_, err = scmClient.PullRequests.Close(ctx, repoFullName, pr.Number)
+	if err != nil {
+		return fmt.Errorf("failed to close stale Pull Request %d: %w", pr.Number, err)
+	}
+	o.Logger().Infof("closed stale Pull Request %s as repository %s is already up to date", info(pr.Link), gitURL)
+	return nil
+}
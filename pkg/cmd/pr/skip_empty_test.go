@@ -0,0 +1,72 @@
+package pr_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasChanges(t *testing.T) {
+	testCases := []struct {
+		name     string
+		version  string
+		commit   bool
+		expected bool
+	}{
+		{
+			name:     "changed",
+			version:  "2.0.0",
+			expected: true,
+		},
+		{
+			name:    "up-to-date",
+			version: "1.0.0",
+		},
+		{
+			name:     "committed",
+			version:  "1.0.0",
+			commit:   true,
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		initGitRepository(t, dir)
+		writeTestFile(t, filepath.Join(dir, "values.yaml"), "version: 1.0.0\n")
+		o := &pr.Options{Version: tc.version, TemplateData: map[string]interface{}{}}
+		for _, args := range [][]string{
+			{"add", "."},
+			{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", "initial"},
+		} {
+			_, err := o.Git().Command(dir, args...)
+			require.NoError(t, err, "failed to run git %v for %s", args, tc.name)
+		}
+		sha, err := o.Git().Command(dir, "rev-parse", "HEAD")
+		require.NoError(t, err, "failed to get the commit SHA for %s", tc.name)
+
+		change := v1alpha1.Change{Regex: &v1alpha1.Regex{Pattern: `version: (.*)`, Globs: []string{"values.yaml"}}}
+		err = o.ApplyChanges(dir, "https://github.com/myorg/myrepo", change)
+		require.NoError(t, err, "failed to apply change for %s", tc.name)
+
+		// a command may commit its own changes leaving the working tree clean
+		if tc.commit {
+			writeTestFile(t, filepath.Join(dir, "go.sum"), "checksums\n")
+			for _, args := range [][]string{
+				{"add", "."},
+				{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", "regenerate"},
+			} {
+				_, err = o.Git().Command(dir, args...)
+				require.NoError(t, err, "failed to run git %v for %s", args, tc.name)
+			}
+		}
+
+		changed, err := o.HasChanges(dir, sha)
+		require.NoError(t, err, "failed to check for changes for %s", tc.name)
+		assert.Equal(t, tc.expected, changed, "changes for %s", tc.name)
+	}
+}
@@ -0,0 +1,70 @@
+package pr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jenkins-x/jx-helpers/v3/pkg/httphelpers"
+)
+
+// slackMessage the payload posted to the Slack incoming webhook
+type slackMessage struct {
+	Channel string `json:"channel,omitempty"`
+	Text    string `json:"text"`
+}
+
+// NotifySlackFailure posts a message to the --slack-webhook describing the repository of the rule which failed.
+// Failing to post the message only logs a warning so that the run is not aborted
+func (o *Options) NotifySlackFailure(gitURL string, ruleIndex int, failure error) {
+	if o.SlackWebhook == "" || failure == nil {
+		return
+	}
+	text := fmt.Sprintf(":x: updatebot failed to create a Pull Request on %s for rule #%d upgrading %s to version %s:\n```%s```",
+		gitURL, ruleIndex, o.applicationName(), o.Version, failure.Error())
+	o.postSlackMessage(text)
+}
+
+// NotifySlackSuccess posts a summary of the Pull Requests created or reused by the run to the --slack-webhook if
+// --slack-notify-success is enabled. Failing to post the message only logs a warning
+func (o *Options) NotifySlackSuccess() {
+	if o.SlackWebhook == "" || !o.SlackNotifySuccess || o.DryRun {
+		return
+	}
+	pullRequests := o.OutputPullRequests()
+	if len(pullRequests) == 0 {
+		return
+	}
+	lines := []string{fmt.Sprintf(":white_check_mark: updatebot upgraded %s to version %s:", o.applicationName(), o.Version)}
+	for _, pr := range pullRequests {
+		lines = append(lines, fmt.Sprintf("* %s: <%s|#%d>", pr.Repo, pr.URL, pr.Number))
+	}
+	o.postSlackMessage(strings.Join(lines, "\n"))
+}
+
+func (o *Options) applicationName() string {
+	if o.Application == "" {
+		return "the application"
+	}
+	return o.Application
+}
+
+// postSlackMessage posts the text to the --slack-webhook logging a warning if it fails
+func (o *Options) postSlackMessage(text string) {
+	data, err := json.Marshal(&slackMessage{Channel: o.SlackChannel, Text: text})
+	if err != nil {
+		o.Logger().Warnf("failed to marshal Slack message: %s", err.Error())
+		return
+	}
+	resp, err := httphelpers.GetClient().Post(o.SlackWebhook, "application/json", bytes.NewReader(data))
+	if err != nil {
+		o.Logger().Warnf("failed to post Slack message: %s", err.Error())
+		return
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		o.Logger().Warnf("failed to post Slack message: status %s", resp.Status)
+	}
+}
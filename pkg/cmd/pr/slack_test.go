@@ -0,0 +1,65 @@
+package pr_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifySlack(t *testing.T) {
+	var messages []map[string]string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m := map[string]string{}
+		err := json.NewDecoder(r.Body).Decode(&m)
+		require.NoError(t, err, "failed to decode Slack message")
+		messages = append(messages, m)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	o := &pr.Options{
+		Version:            "1.2.3",
+		SlackWebhook:       server.URL,
+		SlackChannel:       "#releases",
+		SlackNotifySuccess: true,
+	}
+	o.Application = "myorg/myapp"
+
+	o.NotifySlackFailure("https://github.com/myorg/myrepo", 2, errors.New("failed to push"))
+	require.Len(t, messages, 1, "failure messages")
+	assert.Equal(t, "#releases", messages[0]["channel"], "channel")
+	assert.Contains(t, messages[0]["text"], "https://github.com/myorg/myrepo for rule #2", "failure message")
+	assert.Contains(t, messages[0]["text"], "failed to push", "failure message")
+
+	// the Pull Requests are recorded on the copies of the options used to process repositories concurrently
+	o.StartOutput()
+	wg := sync.WaitGroup{}
+	for i := 5; i <= 6; i++ {
+		ro := *o
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ro.RecordPullRequest("https://github.com/myorg/other", &scm.PullRequest{Number: i, Link: fmt.Sprintf("https://github.com/myorg/other/pull/%d", i)})
+		}()
+	}
+	wg.Wait()
+	o.NotifySlackSuccess()
+	require.Len(t, messages, 2, "success messages")
+	assert.Contains(t, messages[1]["text"], "<https://github.com/myorg/other/pull/5|#5>", "success message")
+	assert.Contains(t, messages[1]["text"], "<https://github.com/myorg/other/pull/6|#6>", "success message")
+
+	// a failure to post is only logged
+	status = http.StatusInternalServerError
+	o.NotifySlackFailure("https://github.com/myorg/myrepo", 0, errors.New("failed to push"))
+	assert.Len(t, messages, 3, "messages")
+}
@@ -0,0 +1,20 @@
+package pr
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+)
+
+// SparseCheckoutPathPatterns returns the sparse checkout patterns of the sparseCheckoutPaths of a rule. The paths must
+// be relative paths within the repository and are anchored to its root like the patterns derived from the changes
+func SparseCheckoutPathPatterns(paths []string) ([]string, error) {
+	answer := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if !filepath.IsLocal(p) {
+			return nil, fmt.Errorf("the sparseCheckoutPaths %s must be a relative path within the repository", p)
+		}
+		answer = append(answer, path.Join("/", filepath.ToSlash(p)))
+	}
+	return answer, nil
+}
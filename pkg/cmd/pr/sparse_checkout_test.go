@@ -0,0 +1,37 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSparseCheckoutPaths(t *testing.T) {
+	o := &pr.Options{Version: "1.2.3"}
+	rule := &v1alpha1.Rule{
+		URLs:                []string{"https://github.com/myorg/monorepo"},
+		SparseCheckout:      true,
+		SparseCheckoutPaths: []string{"services/app/", "charts/app/Chart.yaml"},
+		Changes: []v1alpha1.Change{
+			{Regex: &v1alpha1.Regex{Pattern: `version: (.*)`, Globs: []string{"values.yaml"}}},
+			// commands do not support sparse checkout unless the paths are specified
+			{WorkingDir: "services/app", Command: &v1alpha1.Command{Name: "make"}},
+		},
+	}
+	err := o.ProcessRule(rule, 0)
+	require.NoError(t, err, "failed to process rule")
+	assert.Equal(t, []string{"/services/app", "/charts/app/Chart.yaml"}, o.SparseCheckoutPatterns, "only the paths of the rule are checked out")
+
+	rule.SparseCheckout = false
+	err = o.ProcessRule(rule, 0)
+	require.NoError(t, err, "failed to process rule")
+	assert.Empty(t, o.SparseCheckoutPatterns, "no sparse checkout unless enabled")
+
+	for _, p := range []string{"/etc", "../other", ""} {
+		_, err = pr.SparseCheckoutPathPatterns([]string{p})
+		require.Error(t, err, "should fail for path %q", p)
+	}
+}
@@ -0,0 +1,71 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/scmhelpers"
+)
+
+// CloseSupersededPullRequests finds the open Pull Requests on the repository matching the labels of the Pull Request
+// filter used to reuse Pull Requests. The newest is kept so that it is reused and the older duplicates are closed with
+// a comment explaining they were superseded. Returns the Pull Request which is kept, if any
+func (o *Options) CloseSupersededPullRequests(gitURL string) (*scm.PullRequest, error) {
+	if o.PullRequestFilter == nil || len(o.PullRequestFilter.Labels) == 0 {
+		return nil, nil
+	}
+	scmClient, repoFullName, err := o.GetScmClient(gitURL, o.GitKind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ScmClient: %w", err)
+	}
+	if scmClient == nil {
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	opts := &scm.PullRequestListOptions{Open: true, Labels: o.PullRequestFilter.Labels, Size: 100}
+	prs, _, err := scmClient.PullRequests.List(ctx, repoFullName, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open Pull Requests of repository %s: %w", repoFullName, err)
+	}
+	var matching []*scm.PullRequest
+	for _, pr := range prs {
+		if pr.Closed || pr.Merged || !hasAllLabels(pr.Labels, o.PullRequestFilter.Labels) {
+			continue
+		}
+		matching = append(matching, pr)
+	}
+	if len(matching) == 0 {
+		return nil, nil
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].Number > matching[j].Number
+	})
+
+	newest := matching[0]
+	for _, pr := range matching[1:] {
+		body := fmt.Sprintf("closing as superseded by #%d which upgrades %s to version %s", newest.Number, o.applicationName(), o.Version)
+		_, _, err = scmClient.PullRequests.CreateComment(ctx, repoFullName, pr.Number, &scm.CommentInput{Body: body})
+		if err != nil {
+			o.Logger().Warnf("failed to comment on superseded Pull Request %s: %s", pr.Link, err.Error())
+		}
+		_, err = scmClient.PullRequests.Close(ctx, repoFullName, pr.Number)
+		if err != nil {
+			return nil, fmt.Errorf("failed to close superseded Pull Request %d: %w", pr.Number, err)
+		}
+		o.Logger().Infof("closed Pull Request %s as it is superseded by %s", info(pr.Link), info(newest.Link))
+	}
+	return newest, nil
+}
+
+// hasAllLabels returns true if the labels contain all the names
+func hasAllLabels(labels []*scm.Label, names []string) bool {
+	for _, name := range names {
+		if !scmhelpers.ContainsLabel(labels, name) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package pr_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-promote/pkg/environments"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloseSupersededPullRequests(t *testing.T) {
+	gitURL := "https://github.com/myorg/myrepo"
+	labels := []*scm.Label{{Name: "updatebot"}, {Name: "myapp"}}
+
+	scmClient, _ := fake.NewDefault()
+	pullRequests := &fakeSupersededPullRequestService{
+		PullRequestService: scmClient.PullRequests,
+		prs: []*scm.PullRequest{
+			{Number: 3, Link: "https://github.com/myorg/myrepo/pull/3", Labels: labels},
+			{Number: 7, Link: "https://github.com/myorg/myrepo/pull/7", Labels: labels},
+			{Number: 5, Link: "https://github.com/myorg/myrepo/pull/5", Labels: []*scm.Label{{Name: "updatebot"}}},
+		},
+		comments: map[int]string{},
+	}
+	scmClient.PullRequests = pullRequests
+
+	_, o := pr.NewCmdPullRequest()
+	o.ScmClient = scmClient
+	o.ScmClientFactory.ScmClient = scmClient
+	o.ScmClientFactory.GitServerURL = "https://github.com"
+	o.GitKind = "fake"
+	o.Version = "1.2.3"
+	o.Application = "myorg/myapp"
+	o.PullRequestFilter = &environments.PullRequestFilter{Labels: []string{"updatebot", "myapp"}}
+
+	kept, err := o.CloseSupersededPullRequests(gitURL)
+	require.NoError(t, err, "failed to close superseded Pull Requests")
+	require.NotNil(t, kept, "should keep the newest Pull Request")
+	assert.Equal(t, 7, kept.Number, "kept Pull Request")
+	assert.Equal(t, []int{3}, pullRequests.closed, "closed Pull Requests")
+	assert.Contains(t, pullRequests.comments[3], "superseded by #7", "comment on the closed Pull Request")
+}
+
+// fakeSupersededPullRequestService lists the open Pull Requests and records those closed
+type fakeSupersededPullRequestService struct {
+	scm.PullRequestService
+	prs      []*scm.PullRequest
+	closed   []int
+	comments map[int]string
+}
+
+func (s *fakeSupersededPullRequestService) List(_ context.Context, _ string, _ *scm.PullRequestListOptions) ([]*scm.PullRequest, *scm.Response, error) {
+	return s.prs, nil, nil
+}
+
+func (s *fakeSupersededPullRequestService) Close(_ context.Context, _ string, number int) (*scm.Response, error) {
+	s.closed = append(s.closed, number)
+	return nil, nil
+}
+
+func (s *fakeSupersededPullRequestService) CreateComment(_ context.Context, _ string, number int, input *scm.CommentInput) (*scm.Comment, *scm.Response, error) {
+	s.comments[number] = input.Body
+	return &scm.Comment{Body: input.Body}, nil, nil
+}
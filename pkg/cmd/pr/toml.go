@@ -0,0 +1,476 @@
+package pr
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/yargevad/filepathx"
+)
+
+var (
+	tomlBareKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	tomlNumberRegex  = regexp.MustCompile(`^[+-]?\d[\d_]*(\.\d[\d_]*)?([eE][+-]?\d+)?$`)
+)
+
+// ApplyTOML sets the value of the dotted key in the TOML files. Only the bytes of the current value are replaced so
+// comments and the order of the keys are kept
+func (o *Options) ApplyTOML(dir, gitURL string, change v1alpha1.Change, tomlChange *v1alpha1.TOMLChange) error {
+	if tomlChange.Key == "" {
+		return fmt.Errorf("no key for toml change %#v", change)
+	}
+	key, rest, err := parseTOMLKey(tomlChange.Key, 0)
+	if err != nil || strings.TrimSpace(tomlChange.Key[rest:]) != "" {
+		return fmt.Errorf("invalid key %s of toml change", tomlChange.Key)
+	}
+	value, err := o.changeValue(gitURL, change, tomlChange.Value)
+	if err != nil {
+		return err
+	}
+
+	matched := 0
+	for _, g := range tomlChange.Globs {
+		matches, err := filepathx.Glob(filepath.Join(dir, g))
+		if err != nil {
+			return fmt.Errorf("failed to evaluate glob %s: %w", g, err)
+		}
+		if len(matches) == 0 {
+			_, err = o.handleMissingFile(change, g, gitURL, false)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		for _, f := range matches {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return fmt.Errorf("failed to load file %s: %w", f, err)
+			}
+			text := string(data)
+			text2, ok, err := o.setTOMLValue(text, key, value, change, tomlChange.CreateMissing, f)
+			if err != nil {
+				return err
+			}
+			if ok {
+				matched++
+			}
+			if text2 != text {
+				err = os.WriteFile(f, []byte(text2), files.DefaultFileWritePermissions)
+				if err != nil {
+					return fmt.Errorf("failed to save file %s: %w", f, err)
+				}
+				o.Logger().Infof("modified file %s setting %s to %s", info(f), tomlChange.Key, value)
+			}
+		}
+	}
+	return checkRequireMatch(change, matched, "toml", tomlChange.Key, tomlChange.Globs, gitURL)
+}
+
+// tomlDocument the positions of the values and tables in a TOML file
+type tomlDocument struct {
+	// values the start and end of the values of the keys
+	values map[string][2]int
+	// tables the position after the header or last key of the tables
+	tables map[string]int
+	// defined the keys of values including those in inline tables
+	defined []string
+	// rootEnd the position to insert keys in the root table
+	rootEnd int
+}
+
+// setTOMLValue returns the text with the value of the key replaced and true if the key was found or created
+func (o *Options) setTOMLValue(text string, key []string, value string, change v1alpha1.Change, createMissing bool, path string) (string, bool, error) {
+	doc, err := parseTOMLDocument(text)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to parse TOML file %s: %w", path, err)
+	}
+	name := tomlKeyString(key)
+	if _, ok := doc.tables[name]; ok {
+		return "", false, fmt.Errorf("the key %s in file %s is a table rather than a value", name, path)
+	}
+	span, ok := doc.values[name]
+	if !ok {
+		if !createMissing {
+			return "", false, fmt.Errorf("the key %s does not exist in file %s", name, path)
+		}
+		text, err = insertTOMLValue(text, doc, key, value)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to create key %s in file %s: %w", name, path, err)
+		}
+		return text, true, nil
+	}
+	current := text[span[0]:span[1]]
+	if strings.HasPrefix(current, "{") || strings.HasPrefix(current, "[") {
+		return "", false, fmt.Errorf("the value of key %s in file %s is not a scalar", name, path)
+	}
+	if !o.matchesExpectedCurrent(change, tomlString(current), name+" in "+path) {
+		return text, true, nil
+	}
+	return text[:span[0]] + tomlValue(current, value) + text[span[1]:], true, nil
+}
+
+// insertTOMLValue adds the key to its table, appending the table to the end of the text if it does not exist
+func insertTOMLValue(text string, doc *tomlDocument, key []string, value string) (string, error) {
+	parent := tomlKeyString(key[:len(key)-1])
+	line := tomlKeyString(key[len(key)-1:]) + " = " + tomlValue(`""`, value) + "\n"
+	pos, ok := doc.tables[parent]
+	if parent == "" {
+		pos, ok = doc.rootEnd, true
+	}
+	if ok {
+		if pos > 0 && text[pos-1] != '\n' {
+			line = "\n" + line
+		}
+		return text[:pos] + line + text[pos:], nil
+	}
+	for _, d := range doc.defined {
+		if d == parent || strings.HasPrefix(d, parent+".") {
+			return "", fmt.Errorf("the table %s is defined by a dotted key or inline table", parent)
+		}
+	}
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	if text != "" {
+		text += "\n"
+	}
+	return text + "[" + parent + "]\n" + line, nil
+}
+
+// parseTOMLDocument finds the positions of the keys, values and tables in the TOML text
+func parseTOMLDocument(text string) (*tomlDocument, error) {
+	doc := &tomlDocument{
+		values: map[string][2]int{},
+		tables: map[string]int{},
+	}
+	var table []string
+	arrayTable := false
+	firstHeader := -1
+	pos := 0
+	for {
+		pos = skipTOMLSpace(text, pos, true)
+		if pos >= len(text) {
+			break
+		}
+		if text[pos] == '[' {
+			if firstHeader < 0 {
+				firstHeader = pos
+			}
+			arrayTable = strings.HasPrefix(text[pos:], "[[")
+			start := pos + 1
+			if arrayTable {
+				start++
+			}
+			key, end, err := parseTOMLKey(text, start)
+			if err != nil {
+				return nil, err
+			}
+			end = skipTOMLSpace(text, end, false)
+			closing := "]"
+			if arrayTable {
+				closing = "]]"
+			}
+			if !strings.HasPrefix(text[end:], closing) {
+				return nil, fmt.Errorf("missing %s at position %d", closing, end)
+			}
+			table = key
+			pos = endOfTOMLLine(text, end+len(closing))
+			if !arrayTable {
+				doc.tables[tomlKeyString(table)] = pos
+			}
+			continue
+		}
+		key, end, err := parseTOMLKey(text, pos)
+		if err != nil {
+			return nil, err
+		}
+		end = skipTOMLSpace(text, end, false)
+		if end >= len(text) || text[end] != '=' {
+			return nil, fmt.Errorf("missing = after key at position %d", pos)
+		}
+		start := skipTOMLSpace(text, end+1, false)
+		valueEnd, err := scanTOMLValue(text, start)
+		if err != nil {
+			return nil, err
+		}
+		pos = endOfTOMLLine(text, valueEnd)
+		if arrayTable {
+			// the keys of arrays of tables are not addressable by a dotted key
+			continue
+		}
+		full := append(append([]string{}, table...), key...)
+		err = doc.addValue(text, full, start, valueEnd)
+		if err != nil {
+			return nil, err
+		}
+		if len(table) == 0 && firstHeader < 0 {
+			doc.rootEnd = pos
+		} else if len(table) > 0 {
+			doc.tables[tomlKeyString(table)] = pos
+		}
+	}
+	if doc.rootEnd == 0 {
+		doc.rootEnd = firstHeader
+		if firstHeader < 0 {
+			doc.rootEnd = len(text)
+		}
+	}
+	return doc, nil
+}
+
+// addValue records the position of the value of the key along with the values of an inline table
+func (d *tomlDocument) addValue(text string, key []string, start, end int) error {
+	name := tomlKeyString(key)
+	d.values[name] = [2]int{start, end}
+	d.defined = append(d.defined, name)
+	if text[start] != '{' {
+		return nil
+	}
+	pos := start + 1
+	for {
+		pos = skipTOMLSpace(text, pos, false)
+		if pos < end && text[pos] == '}' {
+			return nil
+		}
+		child, keyEnd, err := parseTOMLKey(text, pos)
+		if err != nil {
+			return err
+		}
+		keyEnd = skipTOMLSpace(text, keyEnd, false)
+		if keyEnd >= end || text[keyEnd] != '=' {
+			return fmt.Errorf("missing = in inline table at position %d", pos)
+		}
+		valueStart := skipTOMLSpace(text, keyEnd+1, false)
+		valueEnd, err := scanTOMLValue(text, valueStart)
+		if err != nil {
+			return err
+		}
+		err = d.addValue(text, append(append([]string{}, key...), child...), valueStart, valueEnd)
+		if err != nil {
+			return err
+		}
+		pos = skipTOMLSpace(text, valueEnd, false)
+		if pos < end && text[pos] == ',' {
+			pos++
+		}
+	}
+}
+
+// parseTOMLKey parses the dotted key, which may contain quoted parts, at the position and returns the parts and the
+// position after the key
+func parseTOMLKey(text string, pos int) ([]string, int, error) {
+	var key []string
+	for {
+		pos = skipTOMLSpace(text, pos, false)
+		if pos >= len(text) {
+			return nil, pos, fmt.Errorf("missing key at end of file")
+		}
+		switch text[pos] {
+		case '"':
+			end, err := scanTOMLValue(text, pos)
+			if err != nil {
+				return nil, pos, err
+			}
+			key = append(key, tomlString(text[pos:end]))
+			pos = end
+		case '\'':
+			end := strings.IndexByte(text[pos+1:], '\'')
+			if end < 0 {
+				return nil, pos, fmt.Errorf("unterminated key at position %d", pos)
+			}
+			key = append(key, text[pos+1:pos+1+end])
+			pos += end + 2
+		default:
+			end := pos
+			for end < len(text) && (tomlBareKeyRegex.MatchString(text[end : end+1])) {
+				end++
+			}
+			if end == pos {
+				return nil, pos, fmt.Errorf("invalid key at position %d", pos)
+			}
+			key = append(key, text[pos:end])
+			pos = end
+		}
+		next := skipTOMLSpace(text, pos, false)
+		if next >= len(text) || text[next] != '.' {
+			return key, pos, nil
+		}
+		pos = next + 1
+	}
+}
+
+// scanTOMLValue returns the position after the value which starts at the position
+func scanTOMLValue(text string, pos int) (int, error) {
+	if pos >= len(text) {
+		return pos, fmt.Errorf("missing value at end of file")
+	}
+	for _, delim := range []string{`"""`, `'''`} {
+		if strings.HasPrefix(text[pos:], delim) {
+			i := pos + 3
+			for i < len(text) {
+				if delim[0] == '"' && text[i] == '\\' {
+					i += 2
+					continue
+				}
+				if strings.HasPrefix(text[i:], delim) {
+					// up to two quotes are allowed before the closing delimiter
+					end := i + 3
+					for n := 0; n < 2 && end < len(text) && text[end] == delim[0]; n++ {
+						end++
+					}
+					return end, nil
+				}
+				i++
+			}
+			return pos, fmt.Errorf("unterminated multi-line string at position %d", pos)
+		}
+	}
+	switch text[pos] {
+	case '"':
+		for i := pos + 1; i < len(text) && text[i] != '\n'; i++ {
+			if text[i] == '\\' {
+				i++
+				continue
+			}
+			if text[i] == '"' {
+				return i + 1, nil
+			}
+		}
+		return pos, fmt.Errorf("unterminated string at position %d", pos)
+	case '\'':
+		end := strings.IndexAny(text[pos+1:], "'\n")
+		if end < 0 || text[pos+1+end] != '\'' {
+			return pos, fmt.Errorf("unterminated string at position %d", pos)
+		}
+		return pos + end + 2, nil
+	case '[', '{':
+		depth := 0
+		for i := pos; i < len(text); i++ {
+			switch text[i] {
+			case '"', '\'':
+				end, err := scanTOMLValue(text, i)
+				if err != nil {
+					return pos, err
+				}
+				i = end - 1
+			case '#':
+				end := strings.IndexByte(text[i:], '\n')
+				if end < 0 {
+					return pos, fmt.Errorf("unterminated array or inline table at position %d", pos)
+				}
+				i += end
+			case '[', '{':
+				depth++
+			case ']', '}':
+				depth--
+				if depth == 0 {
+					return i + 1, nil
+				}
+			}
+		}
+		return pos, fmt.Errorf("unterminated array or inline table at position %d", pos)
+	}
+	end := pos
+	for end < len(text) && !strings.ContainsRune(",]}#\n", rune(text[end])) {
+		end++
+	}
+	value := strings.TrimRight(text[pos:end], " \t\r")
+	if value == "" {
+		return pos, fmt.Errorf("missing value at position %d", pos)
+	}
+	return pos + len(value), nil
+}
+
+// skipTOMLSpace returns the position of the next character which is not whitespace. Newlines and comments are also
+// skipped if lines is true
+func skipTOMLSpace(text string, pos int, lines bool) int {
+	for pos < len(text) {
+		switch text[pos] {
+		case ' ', '\t':
+			pos++
+		case '\r', '\n':
+			if !lines {
+				return pos
+			}
+			pos++
+		case '#':
+			if !lines {
+				return pos
+			}
+			end := strings.IndexByte(text[pos:], '\n')
+			if end < 0 {
+				return len(text)
+			}
+			pos += end
+		default:
+			return pos
+		}
+	}
+	return pos
+}
+
+// endOfTOMLLine returns the position after the newline which ends the line containing the position
+func endOfTOMLLine(text string, pos int) int {
+	end := strings.IndexByte(text[pos:], '\n')
+	if end < 0 {
+		return len(text)
+	}
+	return pos + end + 1
+}
+
+// tomlKeyString returns the dotted key quoting any parts which are not bare keys
+func tomlKeyString(key []string) string {
+	parts := make([]string, 0, len(key))
+	for _, k := range key {
+		if !tomlBareKeyRegex.MatchString(k) {
+			k = tomlBasicString(k)
+		}
+		parts = append(parts, k)
+	}
+	return strings.Join(parts, ".")
+}
+
+// tomlString returns the string of a TOML value with any quotes removed
+func tomlString(raw string) string {
+	switch {
+	case strings.HasPrefix(raw, `"""`) || strings.HasPrefix(raw, `'''`):
+		return strings.TrimPrefix(raw[3:len(raw)-3], "\n")
+	case strings.HasPrefix(raw, `"`):
+		var s string
+		if err := json.Unmarshal([]byte(raw), &s); err == nil {
+			return s
+		}
+		return raw[1 : len(raw)-1]
+	case strings.HasPrefix(raw, `'`):
+		return raw[1 : len(raw)-1]
+	}
+	return raw
+}
+
+// tomlValue returns the raw TOML of the value keeping the quoting of the current value. Numbers and booleans are
+// only kept unquoted if the current value is of the same type so that a version string is never written as a number
+func tomlValue(current, value string) string {
+	switch {
+	case strings.HasPrefix(current, "'") && !strings.HasPrefix(current, "'''") && !strings.ContainsAny(value, "'\n"):
+		return "'" + value + "'"
+	case (current == "true" || current == "false") && (value == "true" || value == "false"):
+		return value
+	case tomlNumberRegex.MatchString(current) && tomlNumberRegex.MatchString(value):
+		return value
+	}
+	return tomlBasicString(value)
+}
+
+// tomlBasicString returns the value as a quoted TOML basic string which uses the same escapes as JSON
+func tomlBasicString(value string) string {
+	s, err := jsonString(value)
+	if err != nil {
+		return `"` + value + `"`
+	}
+	return s
+}
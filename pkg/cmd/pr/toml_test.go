@@ -0,0 +1,123 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const cargoTOML = `# the package
+[package]
+name = "myapp"
+version = "0.1.0" # the app version
+description = """
+multi-line [description] with = signs
+"""
+
+[dependencies]
+# serialisation
+serde = { version = "1.0", features = ["derive"] }
+mylib = '1.0.0'
+tokio = "1"
+
+[dev-dependencies.mockall]
+version = "0.11"
+
+[[bin]]
+name = "mybin"
+version = "0.0.1"
+`
+
+func TestApplyTOML(t *testing.T) {
+	testCases := []struct {
+		name     string
+		change   v1alpha1.TOMLChange
+		expected []string
+		err      bool
+	}{
+		{
+			name:     "string",
+			change:   v1alpha1.TOMLChange{Key: "dependencies.tokio"},
+			expected: []string{`tokio = "2.0.0"` + "\n"},
+		},
+		{
+			name:     "literal-string",
+			change:   v1alpha1.TOMLChange{Key: "dependencies.mylib"},
+			expected: []string{`mylib = '2.0.0'` + "\n"},
+		},
+		{
+			name:     "inline-table",
+			change:   v1alpha1.TOMLChange{Key: "dependencies.serde.version", Value: "~{{ .Version }}"},
+			expected: []string{`serde = { version = "~2.0.0", features = ["derive"] }` + "\n", "# serialisation\n"},
+		},
+		{
+			name:     "keeps-comment",
+			change:   v1alpha1.TOMLChange{Key: "package.version"},
+			expected: []string{`version = "2.0.0" # the app version` + "\n", "# the package\n"},
+		},
+		{
+			name:     "sub-table",
+			change:   v1alpha1.TOMLChange{Key: "dev-dependencies.mockall.version"},
+			expected: []string{"[dev-dependencies.mockall]\nversion = \"2.0.0\"\n"},
+		},
+		{
+			name:     "create-key",
+			change:   v1alpha1.TOMLChange{Key: "dependencies.\"my.lib\"", CreateMissing: true},
+			expected: []string{"tokio = \"1\"\n\"my.lib\" = \"2.0.0\"\n\n[dev-dependencies.mockall]"},
+		},
+		{
+			name:     "create-table",
+			change:   v1alpha1.TOMLChange{Key: "workspace.package.version", CreateMissing: true},
+			expected: []string{"version = \"0.0.1\"\n\n[workspace.package]\nversion = \"2.0.0\"\n"},
+		},
+		{
+			name:   "missing-key",
+			change: v1alpha1.TOMLChange{Key: "dependencies.other"},
+			err:    true,
+		},
+		{
+			name:   "table",
+			change: v1alpha1.TOMLChange{Key: "dev-dependencies.mockall"},
+			err:    true,
+		},
+		{
+			name:   "inline-table-value",
+			change: v1alpha1.TOMLChange{Key: "dependencies.serde"},
+			err:    true,
+		},
+		{
+			name:   "create-in-inline-table",
+			change: v1alpha1.TOMLChange{Key: "dependencies.serde.path", CreateMissing: true},
+			err:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "Cargo.toml")
+		err := os.WriteFile(path, []byte(cargoTOML), 0o600)
+		require.NoError(t, err, "failed to write %s", path)
+
+		tc.change.Globs = []string{"*.toml"}
+		o := &pr.Options{Version: "2.0.0", TemplateData: map[string]interface{}{"Version": "2.0.0"}}
+		err = o.ApplyChanges(dir, "https://github.com/myorg/myrepo", v1alpha1.Change{TOML: &tc.change})
+		if tc.err {
+			require.Error(t, err, "should fail for test %s", tc.name)
+			t.Logf("test %s got expected error: %s", tc.name, err.Error())
+			continue
+		}
+		require.NoError(t, err, "failed to apply change for test %s", tc.name)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err, "failed to read %s", path)
+		for _, expected := range tc.expected {
+			assert.Contains(t, string(data), expected, "modified file for test %s", tc.name)
+		}
+		assert.Contains(t, string(data), "[[bin]]\nname = \"mybin\"\nversion = \"0.0.1\"\n", "array of tables unchanged for test %s", tc.name)
+	}
+}
@@ -0,0 +1,75 @@
+package pr_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStartTracingWithoutEndpoint(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	shutdown, err := pr.StartTracing(context.Background())
+	require.NoError(t, err, "failed to start tracing")
+	assert.NoError(t, shutdown(context.Background()), "failed to shutdown tracing")
+}
+
+func TestRunTracesRules(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	dir := t.TempDir()
+	config := `apiVersion: updatebot.jenkins-x.io/v1alpha1
+kind: UpdateConfig
+spec:
+  rules:
+  - urls: []
+    changes:
+    - command:
+        name: echo
+`
+	initGitRepository(t, dir)
+	err := os.MkdirAll(filepath.Join(dir, ".jx"), files.DefaultDirWritePermissions)
+	require.NoError(t, err, "failed to create .jx dir")
+	err = os.WriteFile(filepath.Join(dir, ".jx", "updatebot.yaml"), []byte(config), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to write config")
+
+	_, o := pr.NewCmdPullRequest()
+	o.Dir = dir
+	o.CommandRunner = cmdrunner.QuietCommandRunner
+	o.ScmClientFactory.NoWriteGitCredentialsFile = true
+	o.Version = "1.2.3"
+	o.Application = "myapp"
+	o.EnvironmentPullRequestOptions.ScmClientFactory.GitServerURL = "https://github.com"
+	o.EnvironmentPullRequestOptions.ScmClientFactory.GitToken = "dummytoken"
+	o.EnvironmentPullRequestOptions.ScmClientFactory.GitUsername = "dummyuser"
+
+	err = o.Run()
+	require.NoError(t, err, "failed to run")
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	require.Contains(t, spans, "updatebot.run")
+	require.Contains(t, spans, "updatebot.rule")
+	require.Contains(t, spans, "updatebot.process-rule")
+
+	run := spans["updatebot.run"].SpanContext()
+	rule := spans["updatebot.rule"]
+	assert.Equal(t, run.SpanID(), rule.Parent().SpanID(), "rule span should be a child of the run span")
+	assert.Equal(t, rule.SpanContext().SpanID(), spans["updatebot.process-rule"].Parent().SpanID(), "process-rule span should be a child of the rule span")
+}
@@ -0,0 +1,94 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// EnsureTrackingIssue finds the --tracking-issue in the pipeline repository or creates one if
+// --create-tracking-issue is enabled. Does nothing if neither option is specified
+func (o *Options) EnsureTrackingIssue() error {
+	if o.TrackingIssue <= 0 && !o.AutoTrackingIssue {
+		return nil
+	}
+	if o.PipelineRepoURL == "" {
+		return fmt.Errorf("cannot use a tracking issue without a --pipeline-repo-url")
+	}
+	ctx := context.Background()
+	scmClient, repoFullName, err := o.GetScmClient(o.PipelineRepoURL, o.GitKind)
+	if err != nil {
+		return fmt.Errorf("failed to create ScmClient: %w", err)
+	}
+
+	if o.TrackingIssue > 0 {
+		issue, _, err := scmClient.Issues.Find(ctx, repoFullName, o.TrackingIssue)
+		if err != nil {
+			return fmt.Errorf("failed to find tracking issue %d in repo %s: %w", o.TrackingIssue, repoFullName, err)
+		}
+		if issue == nil {
+			return fmt.Errorf("no tracking issue %d in repo %s", o.TrackingIssue, repoFullName)
+		}
+		o.trackingIssue = issue
+		return nil
+	}
+
+	title := fmt.Sprintf("upgrade %s to version %s", o.Application, o.Version)
+	if o.Application == "" {
+		title = fmt.Sprintf("upgrade to version %s", o.Version)
+	}
+	issue, _, err := scmClient.Issues.Create(ctx, repoFullName, &scm.IssueInput{
+		Title: title,
+		Body:  "Tracks the pull requests created on the downstream repositories:\n",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create tracking issue in repo %s: %w", repoFullName, err)
+	}
+	o.Logger().Infof("created tracking issue %s", issue.Link)
+	o.TrackingIssue = issue.Number
+	o.trackingIssue = issue
+	return nil
+}
+
+// TrackingIssueReference returns the text added to the Pull Request body to reference the tracking issue
+func (o *Options) TrackingIssueReference() string {
+	if o.trackingIssue == nil {
+		return ""
+	}
+	link := o.trackingIssue.Link
+	if link == "" {
+		link = fmt.Sprintf("%s#%d", strings.TrimSuffix(o.PipelineRepoURL, ".git"), o.trackingIssue.Number)
+	}
+	return fmt.Sprintf("\n\nTracked by %s\n", link)
+}
+
+// AddToTrackingIssue adds a checklist item for the Pull Request to the tracking issue as a comment.
+// Pull Requests which are already listed on the tracking issue are skipped so reused Pull Requests are only added once
+func (o *Options) AddToTrackingIssue(pr *scm.PullRequest) error {
+	if o.trackingIssue == nil || pr == nil || pr.Link == "" {
+		return nil
+	}
+	ctx := context.Background()
+	scmClient, repoFullName, err := o.GetScmClient(o.PipelineRepoURL, o.GitKind)
+	if err != nil {
+		return fmt.Errorf("failed to create ScmClient: %w", err)
+	}
+	number := o.trackingIssue.Number
+	comments, _, err := scmClient.Issues.ListComments(ctx, repoFullName, number, &scm.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list comments on tracking issue %d in repo %s: %w", number, repoFullName, err)
+	}
+	for _, c := range comments {
+		if c != nil && strings.Contains(c.Body, pr.Link) {
+			return nil
+		}
+	}
+	body := fmt.Sprintf("- [ ] %s", pr.Link)
+	_, _, err = scmClient.Issues.CreateComment(ctx, repoFullName, number, &scm.CommentInput{Body: body})
+	if err != nil {
+		return fmt.Errorf("failed to comment on tracking issue %d in repo %s: %w", number, repoFullName, err)
+	}
+	return nil
+}
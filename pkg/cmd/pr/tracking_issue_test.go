@@ -0,0 +1,61 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackingIssue(t *testing.T) {
+	for _, create := range []bool{false, true} {
+		scmClient, fakeData := fake.NewDefault()
+		issues := &fakeIssueService{IssueService: scmClient.Issues}
+		scmClient.Issues = issues
+
+		_, o := pr.NewCmdPullRequest()
+		o.ScmClient = scmClient
+		o.ScmClientFactory.ScmClient = scmClient
+		o.ScmClientFactory.GitServerURL = "https://github.com"
+		o.GitKind = "fake"
+		o.PipelineRepoURL = "https://github.com/myorg/mysource"
+		o.Application = "myapp"
+		o.Version = "1.2.3"
+		if create {
+			o.AutoTrackingIssue = true
+		} else {
+			fakeData.Issues[7] = []*scm.Issue{{Number: 7, Title: "release 1.2.3", Link: "https://github.com/myorg/mysource/issues/7"}}
+			o.TrackingIssue = 7
+		}
+
+		err := o.EnsureTrackingIssue()
+		require.NoError(t, err, "failed to ensure tracking issue when create is %v", create)
+
+		expectedRef := "myorg/mysource#7"
+		if create {
+			expectedRef = "myorg/mysource#1"
+			require.Len(t, issues.created, 1, "issues created")
+			assert.Equal(t, "upgrade myapp to version 1.2.3", issues.created[0].Title, "tracking issue title")
+			assert.Equal(t, "\n\nTracked by https://github.com/myorg/mysource#1\n", o.TrackingIssueReference(), "tracking issue reference")
+		} else {
+			assert.Empty(t, issues.created, "should not create an issue")
+			assert.Equal(t, "\n\nTracked by https://github.com/myorg/mysource/issues/7\n", o.TrackingIssueReference(), "tracking issue reference")
+		}
+
+		pullRequest := &scm.PullRequest{Number: 1, Link: "https://github.com/myorg/myrepo/pull/1"}
+		for i := 0; i < 2; i++ {
+			err = o.AddToTrackingIssue(pullRequest)
+			require.NoError(t, err, "failed to add PR to tracking issue")
+		}
+		require.Len(t, fakeData.IssueCommentsAdded, 1, "should only add the PR once")
+		assert.Equal(t, expectedRef+":- [ ] https://github.com/myorg/myrepo/pull/1", fakeData.IssueCommentsAdded[0], "checklist comment")
+	}
+
+	// no tracking issue by default
+	_, o := pr.NewCmdPullRequest()
+	require.NoError(t, o.EnsureTrackingIssue(), "should not need a tracking issue")
+	assert.Empty(t, o.TrackingIssueReference(), "should not reference a tracking issue")
+}
@@ -0,0 +1,83 @@
+package pr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/options"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+)
+
+const (
+	// TransformStripVPrefix removes a leading v such as v1.2.3 to 1.2.3
+	TransformStripVPrefix = "stripVPrefix"
+
+	// TransformAddVPrefix adds a leading v if there is not one such as 1.2.3 to v1.2.3
+	TransformAddVPrefix = "addVPrefix"
+
+	// TransformMajor uses the major version such as 1.2.3 to 1
+	TransformMajor = "major"
+
+	// TransformMajorMinor uses the major and minor versions such as 1.2.3 to 1.2
+	TransformMajorMinor = "majorMinor"
+
+	// TransformUnderscores replaces dots with underscores such as 1.2.3 to 1_2_3
+	TransformUnderscores = "underscores"
+
+	// TransformUpper converts the version to upper case
+	TransformUpper = "upper"
+
+	// TransformLower converts the version to lower case
+	TransformLower = "lower"
+)
+
+// VersionTransforms the valid values of the transform field of a regex change
+var VersionTransforms = []string{TransformStripVPrefix, TransformAddVPrefix, TransformMajor, TransformMajorMinor, TransformUnderscores, TransformUpper, TransformLower}
+
+// TransformVersion applies the transform to the version. Returns the version unchanged if there is no transform
+func TransformVersion(transform, version string) (string, error) {
+	switch transform {
+	case "":
+		return version, nil
+	case TransformStripVPrefix:
+		return strings.TrimPrefix(version, "v"), nil
+	case TransformAddVPrefix:
+		if strings.HasPrefix(version, "v") {
+			return version, nil
+		}
+		return "v" + version, nil
+	case TransformMajor, TransformMajorMinor:
+		v, err := semver.NewVersion(version)
+		if err != nil {
+			return "", fmt.Errorf("cannot apply transform %s to version %s as it is not a semantic version: %w", transform, version, err)
+		}
+		if transform == TransformMajor {
+			return fmt.Sprintf("%d", v.Major()), nil
+		}
+		return fmt.Sprintf("%d.%d", v.Major(), v.Minor()), nil
+	case TransformUnderscores:
+		return strings.ReplaceAll(version, ".", "_"), nil
+	case TransformUpper:
+		return strings.ToUpper(version), nil
+	case TransformLower:
+		return strings.ToLower(version), nil
+	}
+	return "", options.InvalidOption("transform", transform, VersionTransforms)
+}
+
+// ValidateTransforms checks the transforms of the regex changes of the rules so that a mistake fails when the config
+// is loaded rather than part way through updating the repositories
+func (o *Options) ValidateTransforms() error {
+	for i := range o.UpdateConfig.Spec.Rules {
+		for _, change := range o.UpdateConfig.Spec.Rules[i].Changes {
+			if change.Regex == nil || change.Regex.Transform == "" {
+				continue
+			}
+			if stringhelpers.StringArrayIndex(VersionTransforms, change.Regex.Transform) < 0 {
+				return fmt.Errorf("invalid regex change of rule #%d: %w", i, options.InvalidOption("transform", change.Regex.Transform, VersionTransforms))
+			}
+		}
+	}
+	return nil
+}
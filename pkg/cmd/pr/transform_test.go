@@ -0,0 +1,69 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyRegexTransform(t *testing.T) {
+	source := "# release MYAPP_1_0_0\nversion: 1.0.0\n"
+	testCases := []struct {
+		transform string
+		version   string
+		pattern   string
+		expected  string
+		err       bool
+	}{
+		{version: "v2.3.4", pattern: `version: (.+)`, expected: "version: v2.3.4"},
+		{transform: pr.TransformStripVPrefix, version: "v2.3.4", pattern: `version: (.+)`, expected: "version: 2.3.4"},
+		{transform: pr.TransformAddVPrefix, version: "2.3.4", pattern: `version: (.+)`, expected: "version: v2.3.4"},
+		{transform: pr.TransformMajor, version: "v2.3.4", pattern: `version: (.+)`, expected: "version: 2"},
+		{transform: pr.TransformMajorMinor, version: "v2.3.4", pattern: `version: (.+)`, expected: "version: 2.3"},
+		{transform: pr.TransformUnderscores, version: "2.3.4", pattern: `MYAPP_(\w+)`, expected: "# release MYAPP_2_3_4"},
+		{transform: pr.TransformUpper, version: "2.3.4-rc.1", pattern: `version: (.+)`, expected: "version: 2.3.4-RC.1"},
+		{transform: pr.TransformLower, version: "2.3.4-RC.1", pattern: `version: (.+)`, expected: "version: 2.3.4-rc.1"},
+		{transform: pr.TransformMajorMinor, version: "3f2a1bc", pattern: `version: (.+)`, err: true},
+		{transform: "reverse", version: "2.3.4", pattern: `version: (.+)`, err: true},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "release.yaml")
+		err := os.WriteFile(path, []byte(source), files.DefaultFileWritePermissions)
+		require.NoError(t, err, "failed to write %s", path)
+
+		o := &pr.Options{Version: tc.version}
+		change := v1alpha1.Change{Regex: &v1alpha1.Regex{Pattern: tc.pattern, Globs: []string{"*.yaml"}, Transform: tc.transform}}
+		err = o.ApplyChanges(dir, "https://github.com/myorg/myrepo", change)
+		if tc.err {
+			require.Error(t, err, "should fail for transform %s of %s", tc.transform, tc.version)
+			continue
+		}
+		require.NoError(t, err, "failed to apply transform %s", tc.transform)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err, "failed to read %s", path)
+		assert.Contains(t, string(data), tc.expected+"\n", "transform %s of %s", tc.transform, tc.version)
+	}
+}
+
+func TestValidateTransforms(t *testing.T) {
+	o := &pr.Options{}
+	o.UpdateConfig.Spec.Rules = []v1alpha1.Rule{
+		{Changes: []v1alpha1.Change{{Regex: &v1alpha1.Regex{Transform: pr.TransformMajorMinor}}}},
+		{Changes: []v1alpha1.Change{{Regex: &v1alpha1.Regex{}}, {Regex: &v1alpha1.Regex{Transform: "reverse"}}}},
+	}
+	err := o.ValidateTransforms()
+	require.Error(t, err, "should fail for an unknown transform")
+	assert.Contains(t, err.Error(), "rule #1")
+
+	o.UpdateConfig.Spec.Rules = o.UpdateConfig.Spec.Rules[:1]
+	assert.NoError(t, o.ValidateTransforms(), "should accept known transforms")
+}
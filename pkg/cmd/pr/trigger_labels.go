@@ -0,0 +1,69 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+)
+
+// FindTriggerLabels finds the labels of the Pull Request which was merged to create the commit that triggered the
+// pipeline. The Pull Request number is taken from the merge or squash commit message.
+// Returns no labels if the commit was not created from a Pull Request
+func (o *Options) FindTriggerLabels(gitURL, sha, gitKind string) ([]string, error) {
+	if gitURL == "" || sha == "" {
+		o.Logger().Warnf("cannot find trigger labels with empty gitURL or sha")
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	scmClient, repoFullName, err := o.GetScmClient(gitURL, gitKind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ScmClient: %w", err)
+	}
+	commit, _, err := scmClient.Git.FindCommit(ctx, repoFullName, sha)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find commit %s: %w", sha, err)
+	}
+	if commit == nil {
+		return nil, fmt.Errorf("no commit found for SHA %s", sha)
+	}
+
+	prNumberStr, err := MergeCommitPullRequestNumber(commit)
+	if err != nil {
+		o.Logger().Infof("commit %s was not created from a pull request so there are no trigger labels", sha)
+		return nil, nil
+	}
+	prNumber, err := strconv.Atoi(prNumberStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pull request number %q: %w", prNumberStr, err)
+	}
+	pr, _, err := scmClient.PullRequests.Find(ctx, repoFullName, prNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find PR %d: %w", prNumber, err)
+	}
+	if pr == nil {
+		return nil, fmt.Errorf("no PR found for number %d", prNumber)
+	}
+
+	var labels []string
+	for _, l := range pr.Labels {
+		if l != nil {
+			labels = append(labels, l.Name)
+		}
+	}
+	o.Logger().Infof("found trigger labels %v on PR %d in repo %s", labels, prNumber, repoFullName)
+	return labels, nil
+}
+
+// MatchesTriggerLabels returns true if the rule has no trigger labels or all of its trigger labels are in the labels
+func MatchesTriggerLabels(rule *v1alpha1.Rule, labels []string) bool {
+	for _, label := range rule.TriggerLabels {
+		if stringhelpers.StringArrayIndex(labels, label) < 0 {
+			return false
+		}
+	}
+	return true
+}
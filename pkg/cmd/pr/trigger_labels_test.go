@@ -0,0 +1,60 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindTriggerLabels(t *testing.T) {
+	scmClient, fakeData := fake.NewDefault()
+	fakeData.Commits["merge"] = &scm.Commit{Sha: "merge", Message: "Merge pull request #3 from myorg/mybranch"}
+	fakeData.Commits["squash"] = &scm.Commit{Sha: "squash", Message: "feat: something (#3)"}
+	fakeData.Commits["direct"] = &scm.Commit{Sha: "direct", Message: "fix: pushed directly"}
+	fakeData.PullRequests[3] = &scm.PullRequest{
+		Number: 3,
+		Labels: []*scm.Label{{Name: "area/backend"}, {Name: "risk/high"}},
+	}
+
+	_, o := pr.NewCmdPullRequest()
+	o.ScmClient = scmClient
+	o.ScmClientFactory.ScmClient = scmClient
+	o.ScmClientFactory.GitServerURL = "https://github.com"
+	o.GitKind = "fake"
+
+	gitURL := "https://github.com/myorg/mysource"
+	for _, sha := range []string{"merge", "squash"} {
+		labels, err := o.FindTriggerLabels(gitURL, sha, o.GitKind)
+		require.NoError(t, err, "failed to find trigger labels for %s", sha)
+		assert.Equal(t, []string{"area/backend", "risk/high"}, labels, "trigger labels for %s", sha)
+	}
+
+	labels, err := o.FindTriggerLabels(gitURL, "direct", o.GitKind)
+	require.NoError(t, err, "failed to find trigger labels for a direct commit")
+	assert.Empty(t, labels, "trigger labels for a direct commit")
+}
+
+func TestMatchesTriggerLabels(t *testing.T) {
+	labels := []string{"area/backend", "risk/high"}
+	testCases := []struct {
+		name     string
+		rule     v1alpha1.Rule
+		expected bool
+	}{
+		{name: "no-trigger-labels", expected: true},
+		{name: "match", rule: v1alpha1.Rule{TriggerLabels: []string{"area/backend"}}, expected: true},
+		{name: "match-all", rule: v1alpha1.Rule{TriggerLabels: []string{"area/backend", "risk/high"}}, expected: true},
+		{name: "missing", rule: v1alpha1.Rule{TriggerLabels: []string{"area/frontend"}}},
+		{name: "partial", rule: v1alpha1.Rule{TriggerLabels: []string{"area/backend", "risk/low"}}},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, pr.MatchesTriggerLabels(&tc.rule, labels), "matches for test %s", tc.name)
+	}
+	assert.False(t, pr.MatchesTriggerLabels(&v1alpha1.Rule{TriggerLabels: []string{"area/backend"}}, nil), "should not match without labels")
+}
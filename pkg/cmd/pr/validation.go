@@ -0,0 +1,69 @@
+package pr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+)
+
+// ValidationError a validate command of a rule which failed on a repository so no Pull Request is created
+type ValidationError struct {
+	// GitURL the git URL of the repository
+	GitURL string
+	// Command the command line which failed
+	Command string
+	// Output the output of the command
+	Output string
+	// Err the error of the command
+	Err error
+}
+
+// Error returns the failure along with the output of the command
+func (e *ValidationError) Error() string {
+	msg := fmt.Sprintf("validation command %s failed on repository %s so not creating a Pull Request: %s", e.Command, e.GitURL, e.Err.Error())
+	if e.Output != "" {
+		msg += "\n" + e.Output
+	}
+	return msg
+}
+
+// Unwrap returns the error of the command
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// RunValidateCommands runs the validate commands of the rule in the root of the repository once the changes are applied.
+// A ValidationError with the output of the first command which fails is returned
+func (o *Options) RunValidateCommands(rule *v1alpha1.Rule, dir, gitURL string) error {
+	for i := range rule.Validate {
+		command := &rule.Validate[i]
+		out := &bytes.Buffer{}
+		c := o.newCommand(dir, command, nil)
+		c.Out = out
+		c.Err = out
+		text, err := o.CommandRunner(c)
+		output := strings.TrimSpace(out.String())
+		if output == "" {
+			output = strings.TrimSpace(text)
+		}
+		if err != nil {
+			o.Logger().Warnf("validation command %s failed on repository %s", c.CLI(), gitURL)
+			return &ValidationError{GitURL: gitURL, Command: c.CLI(), Output: output, Err: err}
+		}
+		o.Logger().Infof("validation command %s succeeded on repository %s", info(c.CLI()), gitURL)
+	}
+	return nil
+}
+
+// continueAfterFailure returns true if the remaining repositories are processed after the repository failed with the
+// error due to --continue-on-error or --continue-on-validation-failure for a validation failure
+func (o *Options) continueAfterFailure(err error) bool {
+	if o.ContinueOnError {
+		return true
+	}
+	var validationErr *ValidationError
+	return o.ContinueValidation && errors.As(err, &validationErr)
+}
@@ -0,0 +1,52 @@
+package pr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunValidateCommands(t *testing.T) {
+	dir := t.TempDir()
+	gitURL := "https://github.com/myorg/myrepo"
+
+	var ran []string
+	o := &pr.Options{Version: "1.2.3"}
+	o.CommandRunner = func(c *cmdrunner.Command) (string, error) {
+		ran = append(ran, c.CLI())
+		assert.Equal(t, dir, c.Dir, "dir of command %s", c.CLI())
+		if c.Name == "helm" {
+			_, _ = c.Out.Write([]byte("[ERROR] Chart.yaml: version is required\n"))
+			return "", errors.New("exit status 1")
+		}
+		return "", nil
+	}
+
+	rule := &v1alpha1.Rule{
+		Validate: []v1alpha1.Command{
+			{Name: "go", Args: []string{"build", "./..."}},
+		},
+	}
+	err := o.RunValidateCommands(rule, dir, gitURL)
+	require.NoError(t, err, "validation should succeed")
+
+	rule.Validate = append(rule.Validate,
+		v1alpha1.Command{Name: "helm", Args: []string{"lint", "charts/myapp"}},
+		v1alpha1.Command{Name: "go", Args: []string{"vet", "./..."}},
+	)
+	ran = nil
+	err = o.RunValidateCommands(rule, dir, gitURL)
+	require.Error(t, err, "validation should fail")
+
+	var validationErr *pr.ValidationError
+	require.True(t, errors.As(err, &validationErr), "error should be a ValidationError: %s", err.Error())
+	assert.Equal(t, gitURL, validationErr.GitURL, "git URL of the failure")
+	assert.Equal(t, "[ERROR] Chart.yaml: version is required", validationErr.Output, "output of the failure")
+	assert.Contains(t, err.Error(), "validation command helm lint charts/myapp failed on repository "+gitURL, "error")
+	assert.Equal(t, []string{"go build ./...", "helm lint charts/myapp"}, ran, "commands run until the failure")
+}
@@ -0,0 +1,67 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+)
+
+// CIConfigFiles the files and directories in the root of a repository which configure a CI pipeline
+var CIConfigFiles = []string{".lighthouse", ".tekton", ".circleci", ".gitlab-ci.yml", "Jenkinsfile", "azure-pipelines.yml", "bitbucket-pipelines.yml"}
+
+// HasCIConfig returns true if the repository has CI configured on the branch so that Pull Requests are checked before
+// they are merged. GitHub Actions are detected by workflow files in the .github/workflows directory
+func (o *Options) HasCIConfig(gitURL, branch string) (bool, error) {
+	ctx := context.Background()
+	scmClient, repoFullName, err := o.GetScmClient(gitURL, o.GitKind)
+	if err != nil {
+		return false, fmt.Errorf("failed to create ScmClient: %w", err)
+	}
+
+	entries, _, err := scmClient.Contents.List(ctx, repoFullName, "", branch, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to list files in repo %s: %w", repoFullName, err)
+	}
+	hasGitHubDir := false
+	for _, e := range entries {
+		if stringhelpers.StringArrayIndex(CIConfigFiles, e.Name) >= 0 {
+			o.Logger().Debugf("found CI configuration %s in repo %s", e.Name, repoFullName)
+			return true, nil
+		}
+		if e.Name == ".github" && e.Type == "dir" {
+			hasGitHubDir = true
+		}
+	}
+	if !hasGitHubDir {
+		return false, nil
+	}
+
+	workflows, _, err := scmClient.Contents.List(ctx, repoFullName, ".github/workflows", branch, nil)
+	if err != nil {
+		o.Logger().Debugf("failed to list workflows in repo %s: %s", repoFullName, err.Error())
+		return false, nil
+	}
+	for _, e := range workflows {
+		if strings.HasSuffix(e.Name, ".yml") || strings.HasSuffix(e.Name, ".yaml") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// VerifyAutoMerge returns true if the Pull Request on the repository can be auto merged as CI is configured.
+// Otherwise auto merge is disabled so the Pull Request is not merged without any checks
+func (o *Options) VerifyAutoMerge(gitURL string) bool {
+	ok, err := o.HasCIConfig(gitURL, o.BaseBranchName)
+	if err != nil {
+		o.Logger().Warnf("disabling auto merge for repository %s as failed to verify its CI configuration: %s", gitURL, err.Error())
+		return false
+	}
+	if !ok {
+		o.Logger().Warnf("disabling auto merge for repository %s as it has no CI configuration", gitURL)
+		return false
+	}
+	return true
+}
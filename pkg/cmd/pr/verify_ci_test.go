@@ -0,0 +1,54 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyAutoMerge(t *testing.T) {
+	contentDir := t.TempDir()
+	repoFiles := map[string][]string{
+		"myorg/workflows":       {".github/workflows/ci.yaml", "README.md"},
+		"myorg/lighthouse":      {".lighthouse/jenkins-x/triggers.yaml"},
+		"myorg/jenkinsfile":     {"Jenkinsfile"},
+		"myorg/no-ci":           {"README.md"},
+		"myorg/github-no-flows": {".github/CODEOWNERS"},
+	}
+	for repo, paths := range repoFiles {
+		for _, p := range paths {
+			path := filepath.Join(contentDir, repo, p)
+			err := os.MkdirAll(filepath.Dir(path), files.DefaultDirWritePermissions)
+			require.NoError(t, err, "failed to create dir for %s", path)
+			err = os.WriteFile(path, []byte("test"), files.DefaultFileWritePermissions)
+			require.NoError(t, err, "failed to write %s", path)
+		}
+	}
+
+	testCases := map[string]bool{
+		"myorg/workflows":       true,
+		"myorg/lighthouse":      true,
+		"myorg/jenkinsfile":     true,
+		"myorg/no-ci":           false,
+		"myorg/github-no-flows": false,
+		"myorg/missing":         false,
+	}
+	for repo, expected := range testCases {
+		scmClient, fakeData := fake.NewDefault()
+		fakeData.ContentDir = contentDir
+
+		_, o := pr.NewCmdPullRequest()
+		o.ScmClient = scmClient
+		o.ScmClientFactory.ScmClient = scmClient
+		o.ScmClientFactory.GitServerURL = "https://github.com"
+		o.GitKind = "fake"
+
+		assert.Equal(t, expected, o.VerifyAutoMerge("https://github.com/"+repo), "auto merge for repo %s", repo)
+	}
+}
@@ -0,0 +1,46 @@
+package pr
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+)
+
+// ValidateVersionConstraints parses the version constraints of the rules so that an invalid constraint fails before
+// any repository is changed
+func (o *Options) ValidateVersionConstraints() error {
+	for i := range o.UpdateConfig.Spec.Rules {
+		rule := &o.UpdateConfig.Spec.Rules[i]
+		if rule.VersionConstraint == "" {
+			continue
+		}
+		_, err := semver.NewConstraint(rule.VersionConstraint)
+		if err != nil {
+			return fmt.Errorf("invalid versionConstraint %s of rule #%d: %w", rule.VersionConstraint, i, err)
+		}
+	}
+	return nil
+}
+
+// MatchesVersionConstraint returns true if the version satisfies the version constraint of the rule. Rules without a
+// constraint always match as do versions which are not semantic versions
+func (o *Options) MatchesVersionConstraint(rule *v1alpha1.Rule, index int) (bool, error) {
+	if rule.VersionConstraint == "" {
+		return true, nil
+	}
+	constraint, err := semver.NewConstraint(rule.VersionConstraint)
+	if err != nil {
+		return false, fmt.Errorf("invalid versionConstraint %s of rule #%d: %w", rule.VersionConstraint, index, err)
+	}
+	version, err := semver.NewVersion(o.Version)
+	if err != nil {
+		o.Logger().Warnf("ignoring the version constraint %s of rule #%d as the version %s is not a semantic version", rule.VersionConstraint, index, o.Version)
+		return true, nil
+	}
+	if !constraint.Check(version) {
+		o.Logger().Infof("skipping rule #%d as the version %s does not satisfy the version constraint %s", index, o.Version, rule.VersionConstraint)
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,48 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesVersionConstraint(t *testing.T) {
+	testCases := []struct {
+		version    string
+		constraint string
+		expected   bool
+	}{
+		{version: "1.2.3", expected: true},
+		{version: "1.2.3", constraint: ">=1.0.0 <2.0.0", expected: true},
+		{version: "2.0.0", constraint: ">=1.0.0 <2.0.0", expected: false},
+		{version: "v1.4.0", constraint: "~1.4", expected: true},
+		{version: "1.5.0", constraint: "~1.4", expected: false},
+		{version: "3f2a1bc", constraint: "<2.0.0", expected: true},
+	}
+
+	for _, tc := range testCases {
+		o := &pr.Options{Version: tc.version}
+		matches, err := o.MatchesVersionConstraint(&v1alpha1.Rule{VersionConstraint: tc.constraint}, 0)
+		require.NoError(t, err, "failed to check version %s against %s", tc.version, tc.constraint)
+		assert.Equal(t, tc.expected, matches, "version %s with constraint %s", tc.version, tc.constraint)
+	}
+}
+
+func TestValidateVersionConstraints(t *testing.T) {
+	o := &pr.Options{}
+	o.UpdateConfig.Spec.Rules = []v1alpha1.Rule{
+		{VersionConstraint: ">=1.0.0 <2.0.0"},
+		{},
+		{VersionConstraint: ">=1.0.0 <<2"},
+	}
+	err := o.ValidateVersionConstraints()
+	require.Error(t, err, "should fail for an invalid constraint")
+	assert.Contains(t, err.Error(), "rule #2")
+
+	o.UpdateConfig.Spec.Rules = o.UpdateConfig.Spec.Rules[:2]
+	err = o.ValidateVersionConstraints()
+	assert.NoError(t, err, "should accept valid constraints")
+}
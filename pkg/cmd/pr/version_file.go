@@ -0,0 +1,109 @@
+package pr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/options"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// VersionFormatPlain the version file only contains the version
+	VersionFormatPlain = "plain"
+
+	// VersionFormatChart the version file is a helm Chart.yaml and the version is read from a field of the chart
+	VersionFormatChart = "chart"
+)
+
+var (
+	// VersionFormats the valid values of the --version-format option
+	VersionFormats = []string{VersionFormatPlain, VersionFormatChart}
+
+	// ChartVersionFields the valid values of the --chart-version-field option
+	ChartVersionFields = []string{"version", "appVersion"}
+)
+
+// FindVersionFile searches for the file with the name in the dir and then its parent directories, stopping at the root
+// of the git repository which contains the dir. Returns an empty string if the file is not found
+func FindVersionFile(dir, name string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to find absolute path of %s: %w", dir, err)
+	}
+	for {
+		path := filepath.Join(dir, name)
+		exists, err := files.FileExists(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to check for file %s: %w", path, err)
+		}
+		if exists {
+			return path, nil
+		}
+		gitDir := filepath.Join(dir, ".git")
+		isGitRoot, err := files.FileExists(gitDir)
+		if err != nil {
+			return "", fmt.Errorf("failed to check for %s: %w", gitDir, err)
+		}
+		if !isGitRoot {
+			isGitRoot, err = files.DirExists(gitDir)
+			if err != nil {
+				return "", fmt.Errorf("failed to check for %s: %w", gitDir, err)
+			}
+		}
+		parent := filepath.Dir(dir)
+		if isGitRoot || parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// ReadVersionFile reads the version from the version file. A helm Chart.yaml, detected by the file name or the
+// --version-format chart option, is parsed and the version is read from the --chart-version-field field. Otherwise
+// the whole file is the version
+func (o *Options) ReadVersionFile(path string) (string, error) {
+	format := o.VersionFormat
+	if format == "" {
+		format = VersionFormatPlain
+		if filepath.Base(path) == "Chart.yaml" {
+			format = VersionFormatChart
+		}
+	}
+	if stringhelpers.StringArrayIndex(VersionFormats, format) < 0 {
+		return "", options.InvalidOption("version-format", format, VersionFormats)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read version file %s: %w", path, err)
+	}
+	if format == VersionFormatPlain {
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	field := o.ChartVersionField
+	if field == "" {
+		field = "version"
+	}
+	if stringhelpers.StringArrayIndex(ChartVersionFields, field) < 0 {
+		return "", options.InvalidOption("chart-version-field", field, ChartVersionFields)
+	}
+	chart := map[string]interface{}{}
+	err = yaml.Unmarshal(data, &chart)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse chart file %s: %w", path, err)
+	}
+	value, ok := chart[field]
+	if !ok || value == nil {
+		return "", fmt.Errorf("the chart file %s has no %s", path, field)
+	}
+	version := strings.TrimSpace(fmt.Sprint(value))
+	if version == "" {
+		return "", fmt.Errorf("the chart file %s has no %s", path, field)
+	}
+	return version, nil
+}
@@ -0,0 +1,81 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindVersionFile(t *testing.T) {
+	// outside/VERSION is above the git root so must not be found
+	outside := t.TempDir()
+	root := filepath.Join(outside, "repo")
+	appDir := filepath.Join(root, "services", "app")
+	for _, d := range []string{filepath.Join(root, ".git"), appDir} {
+		err := os.MkdirAll(d, files.DefaultDirWritePermissions)
+		require.NoError(t, err, "failed to create %s", d)
+	}
+	writeFile := func(path string) {
+		err := os.WriteFile(path, []byte("1.2.3\n"), files.DefaultFileWritePermissions)
+		require.NoError(t, err, "failed to write %s", path)
+	}
+	writeFile(filepath.Join(outside, "VERSION"))
+
+	path, err := pr.FindVersionFile(appDir, "VERSION")
+	require.NoError(t, err, "failed to search for version file")
+	assert.Empty(t, path, "should stop at the git root")
+
+	writeFile(filepath.Join(root, "VERSION"))
+	path, err = pr.FindVersionFile(appDir, "VERSION")
+	require.NoError(t, err, "failed to search for version file")
+	assert.Equal(t, filepath.Join(root, "VERSION"), path, "should find the version file in the git root")
+
+	writeFile(filepath.Join(appDir, "VERSION"))
+	path, err = pr.FindVersionFile(appDir, "VERSION")
+	require.NoError(t, err, "failed to search for version file")
+	assert.Equal(t, filepath.Join(appDir, "VERSION"), path, "should prefer the version file in the dir")
+}
+
+func TestReadVersionFile(t *testing.T) {
+	dir := t.TempDir()
+	chartFile := filepath.Join(dir, "Chart.yaml")
+	otherChartFile := filepath.Join(dir, "chart.yml")
+	plainFile := filepath.Join(dir, "VERSION")
+	chart := "apiVersion: v2\nname: myapp\nversion: 1.2.3\nappVersion: \"2.0.0\"\n"
+	for path, text := range map[string]string{chartFile: chart, otherChartFile: chart, plainFile: "3.0.0\n"} {
+		err := os.WriteFile(path, []byte(text), files.DefaultFileWritePermissions)
+		require.NoError(t, err, "failed to write %s", path)
+	}
+
+	testCases := []struct {
+		name     string
+		path     string
+		format   string
+		field    string
+		expected string
+		err      bool
+	}{
+		{name: "plain", path: plainFile, expected: "3.0.0"},
+		{name: "chart-by-name", path: chartFile, expected: "1.2.3"},
+		{name: "chart-app-version", path: chartFile, field: "appVersion", expected: "2.0.0"},
+		{name: "chart-by-format", path: otherChartFile, format: pr.VersionFormatChart, expected: "1.2.3"},
+		{name: "plain-chart", path: chartFile, format: pr.VersionFormatPlain, expected: chart[:len(chart)-1]},
+		{name: "invalid-field", path: chartFile, field: "name", err: true},
+		{name: "invalid-format", path: chartFile, format: "json", err: true},
+	}
+	for _, tc := range testCases {
+		o := &pr.Options{VersionFormat: tc.format, ChartVersionField: tc.field}
+		version, err := o.ReadVersionFile(tc.path)
+		if tc.err {
+			require.Error(t, err, "should fail for test %s", tc.name)
+			continue
+		}
+		require.NoError(t, err, "failed to read version for test %s", tc.name)
+		assert.Equal(t, tc.expected, version, "version for test %s", tc.name)
+	}
+}
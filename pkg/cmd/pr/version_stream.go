@@ -8,15 +8,18 @@ import (
 
 	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/helmer"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/options"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/versionstream"
-	"github.com/jenkins-x/jx-logging/v3/pkg/log"
 )
 
+// DefaultMaxPrune the default maximum number of resources a version stream change can prune from a repository
+const DefaultMaxPrune = 10
+
 // ApplyVersionStream applies the version stream change
-func (o *Options) ApplyVersionStream(dir string, vs *v1alpha1.VersionStreamChange) error {
+func (o *Options) ApplyVersionStream(dir, gitURL string, change v1alpha1.Change, vs *v1alpha1.VersionStreamChange) error {
 	kind := vs.Kind
 	if kind == "" {
 		return options.MissingOption("kind")
@@ -24,9 +27,35 @@ func (o *Options) ApplyVersionStream(dir string, vs *v1alpha1.VersionStreamChang
 	if stringhelpers.StringArrayIndex(versionstream.KindStrings, kind) < 0 {
 		return options.InvalidOption("kind", kind, versionstream.KindStrings)
 	}
+	if vs.Prune {
+		err := o.pruneVersionStream(dir, vs, kind)
+		if err != nil {
+			return fmt.Errorf("failed to prune kind %s: %w", kind, err)
+		}
+		return nil
+	}
+	if vs.Dir != "" {
+		dir = filepath.Join(dir, vs.Dir)
+	}
+
+	if len(vs.Sources) > 0 {
+		err := o.applyVersionStreamSources(dir, gitURL, change, vs, kind)
+		if err != nil {
+			return fmt.Errorf("failed to apply the sources of kind %s: %w", kind, err)
+		}
+		return nil
+	}
+
+	if vs.Version != "" {
+		err := o.applyVersionStreamVersion(dir, gitURL, change, vs, kind)
+		if err != nil {
+			return fmt.Errorf("failed to apply version %s of %s: %w", vs.Version, vs.Name, err)
+		}
+		return nil
+	}
 
 	if kind == string(versionstream.KindChart) {
-		err := o.applyVersionStreamCharts(dir, vs, kind)
+		err := o.applyVersionStreamCharts(dir, change, vs, kind)
 		if err != nil {
 			return fmt.Errorf("failed to apply kind %s: %w", kind, err)
 		}
@@ -35,7 +64,7 @@ func (o *Options) ApplyVersionStream(dir string, vs *v1alpha1.VersionStreamChang
 	return nil
 }
 
-func (o *Options) applyVersionStreamCharts(dir string, vs *v1alpha1.VersionStreamChange, kindStr string) error {
+func (o *Options) applyVersionStreamCharts(dir string, change v1alpha1.Change, vs *v1alpha1.VersionStreamChange, kindStr string) error {
 	prefixes, err := versionstream.GetRepositoryPrefixes(dir)
 	if err != nil {
 		return fmt.Errorf("failed to load chart repository prefixes: %w", err)
@@ -88,12 +117,12 @@ func (o *Options) applyVersionStreamCharts(dir string, vs *v1alpha1.VersionStrea
 	for repoPrefix, ci := range chartInfos {
 		urls := prefixes.URLsForPrefix(repoPrefix)
 		if len(urls) == 0 {
-			log.Logger().Warnf("repository prefix %s has no URL in charts/repositories.yml", repoPrefix)
+			o.Logger().Warnf("repository prefix %s has no URL in charts/repositories.yml", repoPrefix)
 			continue
 		}
 
 		ci.RepoURL = urls[0]
-		log.Logger().Infof("updating helm repository %s at %s", repoPrefix, ci.RepoURL)
+		o.Logger().Infof("updating helm repository %s at %s", repoPrefix, ci.RepoURL)
 
 		_, err = helmer.AddHelmRepoIfMissing(o.Helmer, ci.RepoURL, repoPrefix, "", "")
 		if err != nil {
@@ -103,7 +132,7 @@ func (o *Options) applyVersionStreamCharts(dir string, vs *v1alpha1.VersionStrea
 
 	err = o.Helmer.UpdateRepo()
 	if err != nil {
-		log.Logger().Warnf("failed to update helm repositories: %s", err.Error())
+		o.Logger().Warnf("failed to update helm repositories: %s", err.Error())
 	}
 
 	for repoPrefix, ci := range chartInfos {
@@ -120,7 +149,10 @@ func (o *Options) applyVersionStreamCharts(dir string, vs *v1alpha1.VersionStrea
 
 			oldVersion := sv.Version
 			if oldVersion == "" {
-				log.Logger().Debugf("no upgrade is done of chart %s since no version is set", name)
+				o.Logger().Debugf("no upgrade is done of chart %s since no version is set", name)
+				continue
+			}
+			if !o.matchesExpectedCurrent(change, oldVersion, "chart "+name) {
 				continue
 			}
 			info, err := o.Helmer.SearchCharts(name, true)
@@ -128,13 +160,13 @@ func (o *Options) applyVersionStreamCharts(dir string, vs *v1alpha1.VersionStrea
 				return fmt.Errorf("failed to search for chart %s: %w", name, err)
 			}
 			if len(info) == 0 {
-				log.Logger().Warnf("no version found for chart %s", name)
+				o.Logger().Warnf("no version found for chart %s", name)
 				continue
 			}
 			chartSummary := info[0]
 			version := chartSummary.ChartVersion
 			if version == "" {
-				log.Logger().Warnf("no chart version found for chart %s", name)
+				o.Logger().Warnf("no chart version found for chart %s", name)
 				continue
 			}
 
@@ -143,7 +175,7 @@ func (o *Options) applyVersionStreamCharts(dir string, vs *v1alpha1.VersionStrea
 				if err != nil {
 					return fmt.Errorf("failed to upgrade version of %s to %s: %w", name, version, err)
 				}
-				log.Logger().Infof("updated chart %s from %s to %s", name, oldVersion, version)
+				o.Logger().Infof("updated chart %s from %s to %s", name, oldVersion, version)
 
 				if o.CommitMessage != "" {
 					o.CommitMessage += "\n"
@@ -163,6 +195,195 @@ func (o *Options) applyVersionStreamCharts(dir string, vs *v1alpha1.VersionStrea
 	return nil
 }
 
+// applyVersionStreamVersion sets the version of the named resource if it is already in the version stream
+func (o *Options) applyVersionStreamVersion(dir, gitURL string, change v1alpha1.Change, vs *v1alpha1.VersionStreamChange, kindStr string) error {
+	name := vs.Name
+	if name == "" {
+		return options.MissingOption("name")
+	}
+	kind := versionstream.VersionKind(kindStr)
+	exists, err := stableVersionExists(dir, kind, name)
+	if err != nil {
+		return fmt.Errorf("failed to check for stable version of %s: %w", name, err)
+	}
+	if !exists {
+		path := filepath.Join(kindStr, name, "defaults.yaml")
+		create, err := o.handleMissingFile(change, path, gitURL, true)
+		if err != nil || !create {
+			return err
+		}
+		err = versionstream.SaveStableVersion(dir, kind, name, &versionstream.StableVersion{Version: vs.Version})
+		if err != nil {
+			return fmt.Errorf("failed to save version %s of %s: %w", vs.Version, name, err)
+		}
+		o.Logger().Infof("added %s %s with version %s", kindStr, name, vs.Version)
+		return nil
+	}
+	sv, err := versionstream.LoadStableVersion(dir, kind, name)
+	if err != nil {
+		return fmt.Errorf("failed to load stable version for %s: %w", name, err)
+	}
+	oldVersion := sv.Version
+	if oldVersion == "" {
+		o.Logger().Debugf("not updating %s %s since no version is set", kindStr, name)
+		return nil
+	}
+	if oldVersion == vs.Version || !o.matchesExpectedCurrent(change, oldVersion, kindStr+" "+name) {
+		return nil
+	}
+	sv.Version = vs.Version
+	err = versionstream.SaveStableVersion(dir, versionstream.VersionKind(kindStr), name, sv)
+	if err != nil {
+		return fmt.Errorf("failed to upgrade version of %s to %s: %w", name, vs.Version, err)
+	}
+	o.Logger().Infof("updated %s %s from %s to %s", kindStr, name, oldVersion, vs.Version)
+	return nil
+}
+
+// sourceVersion the version of a resource of a source version stream
+type sourceVersion struct {
+	source  string
+	version string
+}
+
+// applyVersionStreamSources copies the versions of the matching resources of each source version stream into the
+// version stream dir. The sources are applied in order so the result is deterministic. A resource in more than one
+// source is an error unless LastWins is enabled in which case the version of the last source is used
+func (o *Options) applyVersionStreamSources(dir, gitURL string, change v1alpha1.Change, vs *v1alpha1.VersionStreamChange, kind string) error {
+	versions := map[string]*sourceVersion{}
+	var names []string
+	for i := range vs.Sources {
+		source := &vs.Sources[i]
+		if source.Dir == "" {
+			return fmt.Errorf("no dir for source #%d", i)
+		}
+		sourceDir := o.ResolveConfigPath(source.Dir)
+		resources, err := listVersionStreamResources(sourceDir, kind)
+		if err != nil {
+			return fmt.Errorf("failed to list the source version stream %s: %w", source.Dir, err)
+		}
+		for _, r := range resources {
+			if !source.Matches(r.Name) {
+				continue
+			}
+			sv, err := versionstream.LoadStableVersionFile(r.Path)
+			if err != nil {
+				return fmt.Errorf("failed to load stable version file %s: %w", r.Path, err)
+			}
+			if sv.Version == "" {
+				continue
+			}
+			existing := versions[r.Name]
+			if existing == nil {
+				names = append(names, r.Name)
+			} else if !vs.LastWins {
+				return fmt.Errorf("the %s %s is in the source version streams %s and %s: enable lastWins to use the version of the last source", kind, r.Name, existing.source, source.Dir)
+			} else {
+				o.Logger().Infof("using version %s of %s %s from source %s rather than version %s from source %s", sv.Version, kind, r.Name, source.Dir, existing.version, existing.source)
+			}
+			versions[r.Name] = &sourceVersion{source: source.Dir, version: sv.Version}
+		}
+	}
+
+	for _, name := range names {
+		sv := versions[name]
+		nameChange := &v1alpha1.VersionStreamChange{
+			Pattern: v1alpha1.Pattern{Name: name},
+			Kind:    kind,
+			Version: sv.version,
+		}
+		err := o.applyVersionStreamVersion(dir, gitURL, change, nameChange, kind)
+		if err != nil {
+			return fmt.Errorf("failed to apply version %s of %s from source %s: %w", sv.version, name, sv.source, err)
+		}
+	}
+	return nil
+}
+
+// stableVersionExists returns true if the version stream has a file for the named resource
+func stableVersionExists(dir string, kind versionstream.VersionKind, name string) (bool, error) {
+	if kind == versionstream.KindGit {
+		name = versionstream.GitURLToName(name)
+	}
+	for _, path := range []string{filepath.Join(dir, string(kind), name, "defaults.yaml"), filepath.Join(dir, string(kind), name+".yml")} {
+		exists, err := files.FileExists(path)
+		if err != nil {
+			return false, fmt.Errorf("failed to check if path exists %s: %w", path, err)
+		}
+		if exists {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// pruneVersionStream removes the resources of the kind matching the pattern of the change from the version stream in
+// the repository dir which are not in the source version stream. Each removed file is listed in the Pull Request body.
+// Fails without removing anything if more than --max-prune resources would be removed so that a misconfigured source
+// cannot remove everything
+func (o *Options) pruneVersionStream(dir string, vs *v1alpha1.VersionStreamChange, kind string) error {
+	sourceDir := o.ResolveConfigPath(vs.Source)
+	if sourceDir == "" {
+		sourceDir = filepath.Join(o.Dir, "versionStream")
+	}
+	sourceKindDir := filepath.Join(sourceDir, kind)
+	exists, err := files.DirExists(sourceKindDir)
+	if err != nil {
+		return fmt.Errorf("failed to check for directory %s: %w", sourceKindDir, err)
+	}
+	if !exists {
+		return fmt.Errorf("the source version stream has no %s directory %s", kind, sourceKindDir)
+	}
+	sources, err := listVersionStreamResources(sourceDir, kind)
+	if err != nil {
+		return fmt.Errorf("failed to list the source version stream: %w", err)
+	}
+	targets, err := listVersionStreamResources(filepath.Join(dir, vs.Dir), kind)
+	if err != nil {
+		return fmt.Errorf("failed to list the version stream: %w", err)
+	}
+
+	names := map[string]bool{}
+	for _, r := range sources {
+		names[r.Name] = true
+	}
+	var removed []versionStreamResource
+	for _, r := range targets {
+		if !names[r.Name] && vs.Matches(r.Name) {
+			removed = append(removed, r)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	if o.MaxPrune > 0 && len(removed) > o.MaxPrune {
+		return fmt.Errorf("refusing to prune %d %s as it is more than the --max-prune limit of %d", len(removed), kind, o.MaxPrune)
+	}
+
+	for _, r := range removed {
+		err = os.Remove(r.Path)
+		if err != nil {
+			return fmt.Errorf("failed to remove file %s: %w", r.Path, err)
+		}
+		// lets remove the directory of the resource too if it is now empty
+		entries, err := os.ReadDir(filepath.Dir(r.Path))
+		if err == nil && len(entries) == 0 {
+			_ = os.Remove(filepath.Dir(r.Path))
+		}
+		rel, err := filepath.Rel(dir, r.Path)
+		if err != nil {
+			rel = r.Path
+		}
+		o.Logger().Infof("pruned %s %s as it is not in the source version stream", kind, r.Name)
+
+		if o.CommitMessage != "" {
+			o.CommitMessage += "\n"
+		}
+		o.CommitMessage += fmt.Sprintf("* removed %s %s as it is no longer in the source version stream: `%s`", kind, r.Name, filepath.ToSlash(rel))
+	}
+	return nil
+}
+
 type chartInfo struct {
 	RepoURL string
 	Names   []string
@@ -0,0 +1,129 @@
+package pr
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/options"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/versionstream"
+
+	"github.com/yargevad/filepathx"
+)
+
+// GenerateVersionStreamRules appends a rule to the configuration for each of the version stream rules
+func (o *Options) GenerateVersionStreamRules() error {
+	for i := range o.UpdateConfig.Spec.VersionStreamRules {
+		vr := &o.UpdateConfig.Spec.VersionStreamRules[i]
+		rule, err := o.GenerateVersionStreamRule(vr)
+		if err != nil {
+			return fmt.Errorf("failed to generate rule from version stream rule #%d: %w", i, err)
+		}
+		o.UpdateConfig.Spec.Rules = append(o.UpdateConfig.Spec.Rules, *rule)
+	}
+	return nil
+}
+
+// GenerateVersionStreamRule generates a rule with a VersionStream change for each matching resource in the source version stream
+func (o *Options) GenerateVersionStreamRule(vr *v1alpha1.VersionStreamRule) (*v1alpha1.Rule, error) {
+	kind := vr.Kind
+	if kind == "" {
+		kind = string(versionstream.KindChart)
+	}
+	if stringhelpers.StringArrayIndex(versionstream.KindStrings, kind) < 0 {
+		return nil, options.InvalidOption("kind", kind, versionstream.KindStrings)
+	}
+	dir := o.ResolveConfigPath(vr.Dir)
+	if dir == "" {
+		dir = filepath.Join(o.Dir, "versionStream")
+	}
+	targetDir := vr.TargetDir
+	if targetDir == "" {
+		targetDir = "versionStream"
+	}
+
+	resources, err := listVersionStreamResources(dir, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := vr.Rule
+	rule.Changes = append([]v1alpha1.Change{}, vr.Changes...)
+	for _, r := range resources {
+		if !vr.Matches(r.Name) {
+			continue
+		}
+		sv, err := versionstream.LoadStableVersionFile(r.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load stable version file %s: %w", r.Path, err)
+		}
+		if sv.Version == "" {
+			continue
+		}
+		o.Logger().Debugf("generating change for %s %s version %s", kind, r.Name, sv.Version)
+
+		rule.Changes = append(rule.Changes, v1alpha1.Change{
+			VersionStream: &v1alpha1.VersionStreamChange{
+				Pattern: v1alpha1.Pattern{Name: r.Name},
+				Kind:    kind,
+				Dir:     targetDir,
+				Version: sv.Version,
+			},
+		})
+	}
+	if vr.Prune {
+		source, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path of %s: %w", dir, err)
+		}
+		rule.Changes = append(rule.Changes, v1alpha1.Change{
+			VersionStream: &v1alpha1.VersionStreamChange{
+				Pattern: vr.Pattern,
+				Kind:    kind,
+				Dir:     targetDir,
+				Prune:   true,
+				Source:  source,
+			},
+		})
+	}
+	o.Logger().Infof("generated %d changes from version stream %s", len(rule.Changes)-len(vr.Changes), info(dir))
+	return &rule, nil
+}
+
+// versionStreamResource a resource of a version stream along with the path of its defaults.yaml file
+type versionStreamResource struct {
+	Name string
+	Path string
+}
+
+// listVersionStreamResources returns the resources of the kind in the version stream dir sorted by path
+func listVersionStreamResources(dir, kind string) ([]versionStreamResource, error) {
+	kindDir := filepath.Join(dir, kind)
+	glob := filepath.Join(kindDir, "**", "defaults.yaml")
+	globFn := filepathx.Glob
+	if kind == string(versionstream.KindChart) {
+		// charts are always stored as the repository prefix then the chart name
+		glob = filepath.Join(kindDir, "*", "*", "defaults.yaml")
+		globFn = filepath.Glob
+	}
+	paths, err := globFn(glob)
+	if err != nil {
+		return nil, fmt.Errorf("bad glob pattern %s: %w", glob, err)
+	}
+	sort.Strings(paths)
+
+	var answer []versionStreamResource
+	for _, path := range paths {
+		rel, err := filepath.Rel(kindDir, filepath.Dir(path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get relative path of %s: %w", path, err)
+		}
+		if rel == "." {
+			continue
+		}
+		answer = append(answer, versionStreamResource{Name: filepath.ToSlash(rel), Path: path})
+	}
+	return answer, nil
+}
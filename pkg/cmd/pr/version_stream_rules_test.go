@@ -0,0 +1,263 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/versionstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateVersionStreamRule(t *testing.T) {
+	dir := t.TempDir()
+	vsDir := filepath.Join(dir, "versionStream")
+	saveStableVersions(t, vsDir, versionstream.KindChart, map[string]string{
+		"jxgh/jx-build-controller": "0.1.2",
+		"jxgh/jx-preview":          "3.4.5",
+		"jxgh/no-version":          "",
+		"bitnami/nginx":            "6.7.8",
+	})
+	saveStableVersions(t, vsDir, versionstream.KindGit, map[string]string{
+		"github.com/myorg/myrepo": "1.0.0",
+	})
+
+	// a defaults.yaml in an intermediate folder should not be treated as a resource
+	err := os.WriteFile(filepath.Join(vsDir, "git", "defaults.yaml"), []byte("version: 9.9.9\n"), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to write intermediate defaults.yaml")
+
+	userChange := v1alpha1.Change{
+		Command: &v1alpha1.Command{Name: "echo"},
+	}
+
+	testCases := []struct {
+		name     string
+		rule     v1alpha1.VersionStreamRule
+		expected map[string]string
+		kind     string
+		target   string
+		userRule bool
+		err      bool
+	}{
+		{
+			name: "defaults",
+			rule: v1alpha1.VersionStreamRule{},
+			expected: map[string]string{
+				"jxgh/jx-build-controller": "0.1.2",
+				"jxgh/jx-preview":          "3.4.5",
+				"bitnami/nginx":            "6.7.8",
+			},
+			kind:   "charts",
+			target: "versionStream",
+		},
+		{
+			name: "includes",
+			rule: v1alpha1.VersionStreamRule{
+				Pattern: v1alpha1.Pattern{Includes: []string{"jxgh/*"}},
+			},
+			expected: map[string]string{
+				"jxgh/jx-build-controller": "0.1.2",
+				"jxgh/jx-preview":          "3.4.5",
+			},
+			kind:   "charts",
+			target: "versionStream",
+		},
+		{
+			name: "excludes",
+			rule: v1alpha1.VersionStreamRule{
+				Pattern: v1alpha1.Pattern{Excludes: []string{"jxgh/*"}},
+			},
+			expected: map[string]string{
+				"bitnami/nginx": "6.7.8",
+			},
+			kind:   "charts",
+			target: "versionStream",
+		},
+		{
+			name: "explicit",
+			rule: v1alpha1.VersionStreamRule{
+				Dir:       "versionStream",
+				Kind:      "git",
+				TargetDir: "vs",
+			},
+			expected: map[string]string{
+				"github.com/myorg/myrepo": "1.0.0",
+			},
+			kind:   "git",
+			target: "vs",
+		},
+		{
+			name: "user-changes",
+			rule: v1alpha1.VersionStreamRule{
+				Rule: v1alpha1.Rule{
+					Changes: []v1alpha1.Change{userChange},
+				},
+				Pattern: v1alpha1.Pattern{Name: "bitnami/nginx"},
+			},
+			expected: map[string]string{
+				"bitnami/nginx": "6.7.8",
+			},
+			kind:     "charts",
+			target:   "versionStream",
+			userRule: true,
+		},
+		{
+			name: "invalid-kind",
+			rule: v1alpha1.VersionStreamRule{
+				Kind: "cheese",
+			},
+			err: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		o := &pr.Options{Dir: dir}
+		vr := tc.rule
+		vr.URLs = []string{"https://github.com/myorg/my-gitops-repo"}
+
+		rule, err := o.GenerateVersionStreamRule(&vr)
+		if tc.err {
+			require.Error(t, err, "should have failed for test %s", tc.name)
+			continue
+		}
+		require.NoError(t, err, "failed to generate rule for test %s", tc.name)
+		assert.Equal(t, vr.URLs, rule.URLs, "rule URLs for test %s", tc.name)
+
+		changes := rule.Changes
+		if tc.userRule {
+			require.NotEmpty(t, changes, "changes for test %s", tc.name)
+			assert.Equal(t, userChange, changes[0], "user change should be first for test %s", tc.name)
+			changes = changes[1:]
+		}
+		actual := map[string]string{}
+		for _, ch := range changes {
+			vs := ch.VersionStream
+			require.NotNil(t, vs, "should have a VersionStream change for test %s", tc.name)
+			assert.Equal(t, tc.kind, vs.Kind, "kind of %s for test %s", vs.Name, tc.name)
+			assert.Equal(t, tc.target, vs.Dir, "dir of %s for test %s", vs.Name, tc.name)
+			actual[vs.Name] = vs.Version
+		}
+		assert.Equal(t, tc.expected, actual, "generated versions for test %s", tc.name)
+	}
+}
+
+func TestGenerateVersionStreamRules(t *testing.T) {
+	dir := t.TempDir()
+	saveStableVersions(t, filepath.Join(dir, "versionStream"), versionstream.KindChart, map[string]string{
+		"jxgh/jx-preview": "3.4.5",
+	})
+
+	existing := v1alpha1.Rule{URLs: []string{"https://github.com/myorg/existing"}}
+	o := &pr.Options{Dir: dir}
+	o.UpdateConfig.Spec.Rules = []v1alpha1.Rule{existing}
+	o.UpdateConfig.Spec.VersionStreamRules = []v1alpha1.VersionStreamRule{
+		{Rule: v1alpha1.Rule{URLs: []string{"https://github.com/myorg/generated"}}},
+	}
+
+	err := o.GenerateVersionStreamRules()
+	require.NoError(t, err, "failed to generate rules")
+
+	rules := o.UpdateConfig.Spec.Rules
+	require.Len(t, rules, 2, "rules")
+	assert.Equal(t, existing, rules[0], "existing rule should be kept first")
+	assert.Equal(t, []string{"https://github.com/myorg/generated"}, rules[1].URLs, "generated rule URLs")
+	require.Len(t, rules[1].Changes, 1, "generated changes")
+	assert.Equal(t, "jxgh/jx-preview", rules[1].Changes[0].VersionStream.Name, "generated change name")
+}
+
+func TestLoadVersionStreamRuleName(t *testing.T) {
+	dir := t.TempDir()
+	saveStableVersions(t, filepath.Join(dir, "versionStream"), versionstream.KindChart, map[string]string{
+		"jxgh/jx-preview":          "3.4.5",
+		"jxgh/jx-build-controller": "0.1.2",
+	})
+	config := `apiVersion: updatebot.jenkins-x.io/v1alpha1
+kind: UpdateConfig
+spec:
+  rules:
+  - id: lib
+    urls: []
+    changes: []
+  versionStreamRules:
+  - id: charts
+    dependsOn:
+    - lib
+    name: jxgh/jx-preview
+    urls: []
+`
+	o := &pr.Options{Dir: dir, ConfigFile: filepath.Join(dir, "updatebot.yaml")}
+	err := os.WriteFile(o.ConfigFile, []byte(config), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to write config")
+
+	err = o.LoadConfig()
+	require.NoError(t, err, "failed to load config")
+	require.Len(t, o.UpdateConfig.Spec.VersionStreamRules, 1, "version stream rules")
+	vr := o.UpdateConfig.Spec.VersionStreamRules[0]
+	assert.Equal(t, "jxgh/jx-preview", vr.Name, "name of the version stream rule")
+	assert.Equal(t, "charts", vr.ID, "id of the version stream rule")
+	assert.Equal(t, []string{"lib"}, vr.DependsOn, "dependencies of the version stream rule")
+
+	err = o.GenerateVersionStreamRules()
+	require.NoError(t, err, "failed to generate rules")
+	rules := o.UpdateConfig.Spec.Rules
+	require.Len(t, rules, 2, "rules")
+	assert.Equal(t, "charts", rules[1].ID, "id of the generated rule")
+	require.Len(t, rules[1].Changes, 1, "only the named chart should be changed")
+	assert.Equal(t, "jxgh/jx-preview", rules[1].Changes[0].VersionStream.Name, "generated change name")
+}
+
+func TestRunGeneratesVersionStreamRules(t *testing.T) {
+	dir := t.TempDir()
+	saveStableVersions(t, filepath.Join(dir, "versionStream"), versionstream.KindChart, map[string]string{
+		"jxgh/jx-preview": "3.4.5",
+	})
+	config := `apiVersion: updatebot.jenkins-x.io/v1alpha1
+kind: UpdateConfig
+spec:
+  versionStreamRules:
+  - include:
+    - jxgh/*
+`
+	initGitRepository(t, dir)
+	err := os.MkdirAll(filepath.Join(dir, ".jx"), files.DefaultDirWritePermissions)
+	require.NoError(t, err, "failed to create .jx dir")
+	err = os.WriteFile(filepath.Join(dir, ".jx", "updatebot.yaml"), []byte(config), files.DefaultFileWritePermissions)
+	require.NoError(t, err, "failed to write config")
+
+	_, o := pr.NewCmdPullRequest()
+	o.Dir = dir
+	o.CommandRunner = cmdrunner.QuietCommandRunner
+	o.ScmClientFactory.NoWriteGitCredentialsFile = true
+	o.Version = "1.2.3"
+	o.Application = "myapp"
+	o.EnvironmentPullRequestOptions.ScmClientFactory.GitServerURL = "https://github.com"
+	o.EnvironmentPullRequestOptions.ScmClientFactory.GitToken = "dummytoken"
+	o.EnvironmentPullRequestOptions.ScmClientFactory.GitUsername = "dummyuser"
+
+	// the generated rule has no URLs so no Pull Requests are created
+	err = o.Run()
+	require.NoError(t, err, "failed to run")
+
+	rules := o.UpdateConfig.Spec.Rules
+	require.Len(t, rules, 1, "rules")
+	require.Len(t, rules[0].Changes, 1, "generated changes")
+	assert.Equal(t, "3.4.5", rules[0].Changes[0].VersionStream.Version, "generated version")
+}
+
+func saveStableVersions(t *testing.T, dir string, kind versionstream.VersionKind, versions map[string]string) {
+	for name, version := range versions {
+		err := versionstream.SaveStableVersion(dir, kind, name, &versionstream.StableVersion{Version: version})
+		require.NoError(t, err, "failed to save version of %s", name)
+	}
+}
+
+// initGitRepository creates a git repository in the dir so the git user and email can be configured
+func initGitRepository(t *testing.T, dir string) {
+	_, err := cmdrunner.QuietCommandRunner(&cmdrunner.Command{Dir: dir, Name: "git", Args: []string{"init"}})
+	require.NoError(t, err, "failed to init git repository in %s", dir)
+}
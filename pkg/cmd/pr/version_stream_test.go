@@ -0,0 +1,228 @@
+package pr_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/versionstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyVersionStreamVersion(t *testing.T) {
+	testCases := []struct {
+		name     string
+		current  string
+		version  string
+		expected string
+	}{
+		{
+			name:     "jxgh/jx-preview",
+			current:  "1.0.0",
+			version:  "1.1.0",
+			expected: "1.1.0",
+		},
+		{
+			name:     "jxgh/jx-build-controller",
+			current:  "2.0.0",
+			version:  "2.0.0",
+			expected: "2.0.0",
+		},
+		{
+			name:    "jxgh/missing",
+			version: "3.0.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		vsDir := filepath.Join(dir, "versionStream")
+		if tc.current != "" {
+			err := versionstream.SaveStableVersion(vsDir, versionstream.KindChart, tc.name, &versionstream.StableVersion{Version: tc.current})
+			require.NoError(t, err, "failed to save version of %s", tc.name)
+		}
+
+		o := &pr.Options{}
+		vs := &v1alpha1.VersionStreamChange{
+			Pattern: v1alpha1.Pattern{Name: tc.name},
+			Kind:    "charts",
+			Dir:     "versionStream",
+			Version: tc.version,
+		}
+		err := o.ApplyVersionStream(dir, "", v1alpha1.Change{}, vs)
+		require.NoError(t, err, "failed to apply version stream change for %s", tc.name)
+
+		path := filepath.Join(vsDir, "charts", tc.name, "defaults.yaml")
+		if tc.expected == "" {
+			assert.NoFileExists(t, path, "should not add %s", tc.name)
+			continue
+		}
+		sv, err := versionstream.LoadStableVersionFile(path)
+		require.NoError(t, err, "failed to load %s", path)
+		assert.Equal(t, tc.expected, sv.Version, "version of %s", tc.name)
+	}
+
+	// the name is required when a version is specified
+	o := &pr.Options{}
+	err := o.ApplyVersionStream(t.TempDir(), "", v1alpha1.Change{}, &v1alpha1.VersionStreamChange{Kind: "charts", Version: "1.0.0"})
+	require.Error(t, err, "should fail without a name")
+}
+
+func TestPruneVersionStream(t *testing.T) {
+	sourceDir := t.TempDir()
+	for _, name := range []string{"jxgh/jx-preview", "jxgh/jx-build-controller"} {
+		err := versionstream.SaveStableVersion(sourceDir, versionstream.KindChart, name, &versionstream.StableVersion{Version: "1.0.0"})
+		require.NoError(t, err, "failed to save source version of %s", name)
+	}
+
+	testCases := []struct {
+		name     string
+		maxPrune int
+		pattern  v1alpha1.Pattern
+		removed  []string
+		err      bool
+	}{
+		{
+			name:    "prune",
+			removed: []string{"jxgh/removed", "other/removed"},
+		},
+		{
+			name:    "pattern",
+			pattern: v1alpha1.Pattern{Includes: []string{"jxgh/*"}},
+			removed: []string{"jxgh/removed"},
+		},
+		{
+			name:     "max-prune",
+			maxPrune: 1,
+			err:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		vsDir := filepath.Join(dir, "versionStream")
+		names := []string{"jxgh/jx-preview", "jxgh/jx-build-controller", "jxgh/removed", "other/removed"}
+		for _, name := range names {
+			err := versionstream.SaveStableVersion(vsDir, versionstream.KindChart, name, &versionstream.StableVersion{Version: "0.1.0"})
+			require.NoError(t, err, "failed to save version of %s for %s", name, tc.name)
+		}
+
+		o := &pr.Options{MaxPrune: tc.maxPrune}
+		vs := &v1alpha1.VersionStreamChange{
+			Pattern: tc.pattern,
+			Kind:    "charts",
+			Dir:     "versionStream",
+			Prune:   true,
+			Source:  sourceDir,
+		}
+		err := o.ApplyVersionStream(dir, "", v1alpha1.Change{}, vs)
+		if tc.err {
+			require.Error(t, err, "should fail for %s", tc.name)
+		} else {
+			require.NoError(t, err, "failed to prune for %s", tc.name)
+		}
+
+		for _, name := range names {
+			path := filepath.Join(vsDir, "charts", name, "defaults.yaml")
+			if stringhelpers.StringArrayIndex(tc.removed, name) >= 0 {
+				assert.NoFileExists(t, path, "should prune %s for %s", name, tc.name)
+				assert.Contains(t, o.CommitMessage, "versionStream/charts/"+name+"/defaults.yaml", "body for %s", tc.name)
+			} else {
+				assert.FileExists(t, path, "should keep %s for %s", name, tc.name)
+			}
+		}
+	}
+}
+
+func TestApplyVersionStreamSources(t *testing.T) {
+	platformDir := t.TempDir()
+	appsDir := t.TempDir()
+	for dir, versions := range map[string]map[string]string{
+		platformDir: {"jxgh/jx-preview": "2.0.0", "jxgh/jx-build-controller": "2.0.0"},
+		appsDir:     {"myorg/myapp": "3.0.0", "myorg/other": "3.0.0", "jxgh/jx-build-controller": "3.0.0"},
+	} {
+		for name, version := range versions {
+			err := versionstream.SaveStableVersion(dir, versionstream.KindChart, name, &versionstream.StableVersion{Version: version})
+			require.NoError(t, err, "failed to save source version of %s", name)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		sources  []v1alpha1.VersionStreamSource
+		lastWins bool
+		expected map[string]string
+		err      bool
+	}{
+		{
+			name: "disjoint",
+			sources: []v1alpha1.VersionStreamSource{
+				{Dir: platformDir, Pattern: v1alpha1.Pattern{Includes: []string{"jxgh/*"}}},
+				{Dir: appsDir, Pattern: v1alpha1.Pattern{Includes: []string{"myorg/*"}, Excludes: []string{"myorg/other"}}},
+			},
+			expected: map[string]string{
+				"jxgh/jx-preview":          "2.0.0",
+				"jxgh/jx-build-controller": "2.0.0",
+				"myorg/myapp":              "3.0.0",
+				"myorg/other":              "1.0.0",
+			},
+		},
+		{
+			name: "overlapping",
+			sources: []v1alpha1.VersionStreamSource{
+				{Dir: platformDir, Pattern: v1alpha1.Pattern{Includes: []string{"*"}}},
+				{Dir: appsDir, Pattern: v1alpha1.Pattern{Includes: []string{"*"}}},
+			},
+			err: true,
+		},
+		{
+			name: "overlapping-last-wins",
+			sources: []v1alpha1.VersionStreamSource{
+				{Dir: platformDir, Pattern: v1alpha1.Pattern{Includes: []string{"*"}}},
+				{Dir: appsDir, Pattern: v1alpha1.Pattern{Includes: []string{"*"}}},
+			},
+			lastWins: true,
+			expected: map[string]string{
+				"jxgh/jx-preview":          "2.0.0",
+				"jxgh/jx-build-controller": "3.0.0",
+				"myorg/myapp":              "3.0.0",
+				"myorg/other":              "3.0.0",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		vsDir := filepath.Join(dir, "versionStream")
+		for _, name := range []string{"jxgh/jx-preview", "jxgh/jx-build-controller", "myorg/myapp", "myorg/other"} {
+			err := versionstream.SaveStableVersion(vsDir, versionstream.KindChart, name, &versionstream.StableVersion{Version: "1.0.0"})
+			require.NoError(t, err, "failed to save version of %s", name)
+		}
+
+		o := &pr.Options{}
+		vs := &v1alpha1.VersionStreamChange{
+			Kind:     "charts",
+			Dir:      "versionStream",
+			Sources:  tc.sources,
+			LastWins: tc.lastWins,
+		}
+		err := o.ApplyVersionStream(dir, "", v1alpha1.Change{}, vs)
+		if tc.err {
+			require.Error(t, err, "should fail for %s", tc.name)
+			assert.Contains(t, err.Error(), "jxgh/jx-build-controller", "error for %s", tc.name)
+			assert.Contains(t, err.Error(), platformDir, "error for %s", tc.name)
+			assert.Contains(t, err.Error(), appsDir, "error for %s", tc.name)
+			continue
+		}
+		require.NoError(t, err, "failed to apply version stream sources for %s", tc.name)
+
+		for name, expected := range tc.expected {
+			sv, err := versionstream.LoadStableVersion(vsDir, versionstream.KindChart, name)
+			require.NoError(t, err, "failed to load version of %s", name)
+			assert.Equal(t, expected, sv.Version, "version of %s for %s", name, tc.name)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package pr
+
+import (
+	"strings"
+)
+
+// VersionFromGitTag returns the latest git tag in the --dir, matching the --tag-pattern if specified, with any v
+// prefix removed. An empty version is returned if there is no matching tag so that the version file or $VERSION is used
+func (o *Options) VersionFromGitTag() string {
+	args := []string{"describe", "--tags", "--abbrev=0"}
+	if o.TagPattern != "" {
+		args = append(args, "--match", o.TagPattern)
+	}
+	text, err := o.Git().Command(o.Dir, args...)
+	if err != nil {
+		o.Logger().Infof("no git tag found in %s so using the version file or $VERSION: %s", o.Dir, err.Error())
+		return ""
+	}
+	tag := strings.TrimSpace(text)
+	if tag == "" {
+		return ""
+	}
+	version := strings.TrimPrefix(tag, "v")
+	o.Logger().Infof("using version %s from git tag %s", info(version), tag)
+	return version
+}
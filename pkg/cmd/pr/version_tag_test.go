@@ -0,0 +1,33 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionFromGitTag(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepository(t, dir)
+
+	_, o := pr.NewCmdPullRequest()
+	o.Dir = dir
+	assert.Equal(t, "", o.VersionFromGitTag(), "version of a repository without tags")
+
+	for _, tag := range []string{"v1.0.0", "v1.1.0", "chart-2.0.0"} {
+		runGit(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "release "+tag)
+		runGit(t, dir, "tag", tag)
+	}
+	assert.Equal(t, "chart-2.0.0", o.VersionFromGitTag(), "version of the latest tag")
+
+	o.TagPattern = "v*"
+	assert.Equal(t, "1.1.0", o.VersionFromGitTag(), "version of the latest tag matching the pattern")
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	_, err := cmdrunner.QuietCommandRunner(&cmdrunner.Command{Dir: dir, Name: "git", Args: args})
+	require.NoError(t, err, "failed to run git %v in %s", args, dir)
+}
@@ -1,16 +1,50 @@
 package pr
 
 import (
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
 	"github.com/Masterminds/sprig/v3"
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/templater"
 )
 
+// ShortSHALength the number of characters of the git SHA used for the ShortSHA template value
+const ShortSHALength = 7
+
+// AddCommitTemplateData adds the SHA and ShortSHA of the --pipeline-commit-sha to the template data so that version and
+// body templates can reference them, such as for image tags with a git SHA suffix. The values are empty if there is
+// no commit SHA so that templates can still be evaluated
+func (o *Options) AddCommitTemplateData() {
+	sha := o.PipelineCommitSha
+	shortSHA := sha
+	if len(shortSHA) > ShortSHALength {
+		shortSHA = shortSHA[:ShortSHALength]
+	}
+	o.TemplateData["SHA"] = sha
+	o.TemplateData["ShortSHA"] = shortSHA
+}
+
+// AddVersionTemplateData adds the Version and its semantic version components to the template data so that templates
+// can reference the release line, such as a base branch of release/{{.VersionMajorMinor}}. The components are omitted
+// if the version is not a semantic version
+func (o *Options) AddVersionTemplateData() {
+	o.TemplateData["Version"] = o.Version
+	v, err := semver.NewVersion(o.Version)
+	if err != nil {
+		o.Logger().Debugf("not adding semantic version template data as %s is not a semantic version: %s", o.Version, err.Error())
+		return
+	}
+	o.TemplateData["VersionMajor"] = v.Major()
+	o.TemplateData["VersionMinor"] = v.Minor()
+	o.TemplateData["VersionPatch"] = v.Patch()
+	o.TemplateData["VersionMajorMinor"] = fmt.Sprintf("%d.%d", v.Major(), v.Minor())
+	o.TemplateData["VersionPrerelease"] = v.Prerelease()
+}
+
 func (o *Options) EvaluateVersionTemplate(templateText, gitURL string) (string, error) {
 	funcMap := sprig.TxtFuncMap()
-	funcMap["pullRequestSha"] = func(name string) string {
-		return o.PullRequestSHAs[name]
-	}
+	funcMap["pullRequestSha"] = o.pullRequestSha
 
 	return templater.Evaluate(funcMap, o.TemplateData, templateText, "template.gotmpl", "version template for "+gitURL)
 }
@@ -31,7 +65,18 @@ func (o *Options) AddPullRequest(pr *scm.PullRequest) {
 	}
 	sha := pr.Head.Sha
 	if sha != "" {
-		o.PullRequestSHAs[repoName] = sha
-		o.PullRequestSHAs[fullName] = sha
+		o.withLock(func() {
+			o.PullRequestSHAs[repoName] = sha
+			o.PullRequestSHAs[fullName] = sha
+		})
 	}
 }
+
+// pullRequestSha returns the head SHA of the Pull Request created earlier in the run for the repository name
+func (o *Options) pullRequestSha(name string) string {
+	sha := ""
+	o.withLock(func() {
+		sha = o.PullRequestSHAs[name]
+	})
+	return sha
+}
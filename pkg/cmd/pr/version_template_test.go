@@ -66,3 +66,43 @@ func TestVersionTemplate(t *testing.T) {
 		assert.Equal(t, tc.expected, actual, "for template %s", tc.template)
 	}
 }
+
+func TestCommitTemplateData(t *testing.T) {
+	testCases := []struct {
+		sha      string
+		template string
+		expected string
+	}{
+		{
+			sha:      "b054df5e2c1f0d6a9e8b7c6d5e4f3a2b1c0d9e8f",
+			template: "1.2.3-{{ .ShortSHA }}",
+			expected: "1.2.3-b054df5",
+		},
+		{
+			sha:      "b054df5e2c1f0d6a9e8b7c6d5e4f3a2b1c0d9e8f",
+			template: "{{ .SHA }}",
+			expected: "b054df5e2c1f0d6a9e8b7c6d5e4f3a2b1c0d9e8f",
+		},
+		{
+			sha:      "b054",
+			template: "{{ .ShortSHA }}",
+			expected: "b054",
+		},
+		{
+			template: "1.2.3{{ with .ShortSHA }}-{{ . }}{{ end }}",
+			expected: "1.2.3",
+		},
+	}
+
+	for _, tc := range testCases {
+		o := &pr.Options{
+			PipelineCommitSha: tc.sha,
+			TemplateData:      map[string]interface{}{},
+		}
+		o.AddCommitTemplateData()
+
+		actual, err := o.EvaluateVersionTemplate(tc.template, "sampleGitURL")
+		require.NoError(t, err, "failed to evaluate template %s", tc.template)
+		assert.Equal(t, tc.expected, actual, "for template %s with sha %s", tc.template, tc.sha)
+	}
+}
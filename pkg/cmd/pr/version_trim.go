@@ -0,0 +1,24 @@
+package pr
+
+import (
+	"strings"
+)
+
+// TrimVersion normalizes the version by removing the --version-trim-prefix, such as refs/tags/, and the
+// --version-trim-suffix then a leading v if --strip-v is enabled. The version is returned unchanged if no option is set
+func (o *Options) TrimVersion(version string) string {
+	answer := strings.TrimSpace(version)
+	if o.VersionTrimPrefix != "" {
+		answer = strings.TrimPrefix(answer, o.VersionTrimPrefix)
+	}
+	if o.VersionTrimSuffix != "" {
+		answer = strings.TrimSuffix(answer, o.VersionTrimSuffix)
+	}
+	if o.StripV {
+		answer = strings.TrimPrefix(answer, "v")
+	}
+	if answer != version {
+		o.Logger().Infof("normalized version %s to %s", version, info(answer))
+	}
+	return answer
+}
@@ -0,0 +1,56 @@
+package pr_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner/fakerunner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrimVersion(t *testing.T) {
+	testCases := []struct {
+		name     string
+		version  string
+		prefix   string
+		suffix   string
+		stripV   bool
+		expected string
+	}{
+		{name: "none", version: "v1.2.3", expected: "v1.2.3"},
+		{name: "prefix", version: "refs/tags/1.2.3", prefix: "refs/tags/", expected: "1.2.3"},
+		{name: "prefix-strip-v", version: "refs/tags/v1.2.3", prefix: "refs/tags/", stripV: true, expected: "1.2.3"},
+		{name: "suffix", version: "1.2.3-release", suffix: "-release", expected: "1.2.3"},
+		{name: "prefix-suffix-strip-v", version: "refs/tags/v1.2.3-release", prefix: "refs/tags/", suffix: "-release", stripV: true, expected: "1.2.3"},
+		{name: "missing-prefix", version: "v1.2.3", prefix: "refs/tags/", stripV: true, expected: "1.2.3"},
+		{name: "whitespace", version: " 1.2.3\n", expected: "1.2.3"},
+	}
+
+	for _, tc := range testCases {
+		o := &pr.Options{VersionTrimPrefix: tc.prefix, VersionTrimSuffix: tc.suffix, StripV: tc.stripV}
+		assert.Equal(t, tc.expected, o.TrimVersion(tc.version), "version for %s", tc.name)
+	}
+}
+
+func TestValidateTrimsVersion(t *testing.T) {
+	t.Setenv("VERSION", "refs/tags/v2.0.0")
+
+	_, o := pr.NewCmdPullRequest()
+	o.Dir = t.TempDir()
+	o.CommandRunner = (&fakerunner.FakeRunner{}).Run
+	o.ScmClientFactory.GitServerURL = "https://github.com"
+	o.ScmClientFactory.GitToken = "dummytoken"
+	o.VersionTrimPrefix = "refs/tags/"
+	o.StripV = true
+
+	err := o.Validate()
+	require.NoError(t, err, "failed to validate")
+	assert.Equal(t, "2.0.0", o.Version, "version")
+	assert.Equal(t, "2.0.0", o.TemplateData["Version"], "template data version")
+	assert.Equal(t, uint64(2), o.TemplateData["VersionMajor"], "template data major version")
+
+	err = o.SetCommitDetails(o.Dir)
+	require.NoError(t, err, "failed to set commit details")
+	assert.Contains(t, o.CommitTitle, "to version 2.0.0", "commit title")
+}
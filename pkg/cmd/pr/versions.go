@@ -0,0 +1,101 @@
+package pr
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+)
+
+// DefaultVersionsFile the file in the --dir directory the named versions are loaded from if no --versions-file is specified
+const DefaultVersionsFile = "VERSIONS"
+
+// LoadVersions loads the named versions used by changes with a versionKey from the --versions-file which defaults to
+// the VERSIONS file in --dir if it exists. Each line of the file is a name=version pair. Versions specified by the
+// --versions option take precedence over the file
+func (o *Options) LoadVersions() error {
+	path := o.VersionsFile
+	if path == "" {
+		path = filepath.Join(o.Dir, DefaultVersionsFile)
+	}
+	exists, err := files.FileExists(path)
+	if err != nil {
+		return fmt.Errorf("failed to check for file %s: %w", path, err)
+	}
+	if !exists {
+		if o.VersionsFile != "" {
+			return fmt.Errorf("versions file %s does not exist", path)
+		}
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read versions file %s: %w", path, err)
+	}
+	versions, err := ParseVersions(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse versions file %s: %w", path, err)
+	}
+	if o.Versions == nil {
+		o.Versions = map[string]string{}
+	}
+	for name, version := range versions {
+		if _, ok := o.Versions[name]; !ok {
+			o.Versions[name] = version
+		}
+	}
+	return nil
+}
+
+// ParseVersions parses the name=version lines of a versions file ignoring empty lines and # comments
+func ParseVersions(data []byte) (map[string]string, error) {
+	versions := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for i := 1; scanner.Scan(); i++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, version, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		version = strings.TrimSpace(version)
+		if !ok || name == "" || version == "" {
+			return nil, fmt.Errorf("line %d is not of the form name=version: %s", i, line)
+		}
+		versions[name] = version
+	}
+	return versions, scanner.Err()
+}
+
+// useChangeVersion uses the named version of the change, if it has a versionKey, as the version until the returned
+// function is called to restore the version
+func (o *Options) useChangeVersion(change v1alpha1.Change) (func(), error) {
+	if change.VersionKey == "" {
+		return func() {}, nil
+	}
+	version, ok := o.Versions[change.VersionKey]
+	if !ok {
+		return nil, fmt.Errorf("no version named %s in the --versions option or versions file", change.VersionKey)
+	}
+	oldVersion := o.Version
+	oldTemplateData := o.TemplateData
+
+	// the template data is copied as it is shared with other repositories processed concurrently
+	o.TemplateData = map[string]interface{}{}
+	for k, v := range oldTemplateData {
+		if !strings.HasPrefix(k, "Version") {
+			o.TemplateData[k] = v
+		}
+	}
+	o.Version = version
+	o.AddVersionTemplateData()
+	return func() {
+		o.Version = oldVersion
+		o.TemplateData = oldTemplateData
+	}, nil
+}
@@ -0,0 +1,69 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadVersions(t *testing.T) {
+	dir := t.TempDir()
+	o := &pr.Options{Dir: dir}
+	err := o.LoadVersions()
+	require.NoError(t, err, "should ignore a missing default versions file")
+	assert.Empty(t, o.Versions, "versions")
+
+	err = os.WriteFile(filepath.Join(dir, pr.DefaultVersionsFile), []byte("# the monorepo versions\napi=1.2.3\n\nworker = 2.0.0\n"), 0o600)
+	require.NoError(t, err, "failed to write versions file")
+
+	o = &pr.Options{Dir: dir, Versions: map[string]string{"api": "1.3.0"}}
+	err = o.LoadVersions()
+	require.NoError(t, err, "failed to load versions")
+	assert.Equal(t, map[string]string{"api": "1.3.0", "worker": "2.0.0"}, o.Versions, "the --versions option should take precedence")
+
+	o = &pr.Options{Dir: dir, VersionsFile: filepath.Join(dir, "missing")}
+	err = o.LoadVersions()
+	require.Error(t, err, "should fail for a missing versions file")
+
+	_, err = pr.ParseVersions([]byte("api\n"))
+	require.Error(t, err, "should fail for a line without a version")
+}
+
+func TestApplyChangesVersionKey(t *testing.T) {
+	gitURL := "https://github.com/myorg/myrepo"
+	dir := t.TempDir()
+	path := filepath.Join(dir, "values.yaml")
+	err := os.WriteFile(path, []byte("api: 1.0.0\nworker: 1.0.0\napp: 1.0.0\n"), 0o600)
+	require.NoError(t, err, "failed to write %s", path)
+
+	o := &pr.Options{
+		Version:      "3.0.0",
+		Versions:     map[string]string{"api": "1.2.3", "worker": "2.0.0"},
+		TemplateData: map[string]interface{}{},
+	}
+	o.AddVersionTemplateData()
+
+	changes := []v1alpha1.Change{
+		{VersionKey: "api", Regex: &v1alpha1.Regex{Pattern: `api: (.*)`, Globs: []string{"values.yaml"}}},
+		{VersionKey: "worker", VersionTemplate: "{{ .VersionMajor }}.x", Regex: &v1alpha1.Regex{Pattern: `worker: (.*)`, Globs: []string{"values.yaml"}}},
+		{Regex: &v1alpha1.Regex{Pattern: `app: (.*)`, Globs: []string{"values.yaml"}}},
+	}
+	for _, change := range changes {
+		err = o.ApplyChanges(dir, gitURL, change)
+		require.NoError(t, err, "failed to apply change")
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err, "failed to read %s", path)
+	assert.Equal(t, "api: 1.2.3\nworker: 2.x\napp: 3.0.0\n", string(data), "modified file")
+	assert.Equal(t, "3.0.0", o.Version, "the version should be restored")
+	assert.Equal(t, "3.0.0", o.TemplateData["Version"], "the template data should be restored")
+
+	err = o.ApplyChanges(dir, gitURL, v1alpha1.Change{VersionKey: "missing", Regex: &v1alpha1.Regex{Pattern: `app: (.*)`, Globs: []string{"values.yaml"}}})
+	require.Error(t, err, "should fail for an unknown version key")
+}
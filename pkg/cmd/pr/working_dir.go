@@ -0,0 +1,37 @@
+package pr
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+)
+
+// ChangeWorkingDir returns the directory the change is applied in which is the working dir of the change within the
+// repository dir, or the repository dir if the change has no working dir
+func ChangeWorkingDir(dir string, change v1alpha1.Change) (string, error) {
+	if change.WorkingDir == "" {
+		return dir, nil
+	}
+	if !filepath.IsLocal(change.WorkingDir) {
+		return "", fmt.Errorf("the workingDir %s of the change must be a relative path within the repository", change.WorkingDir)
+	}
+	return filepath.Join(dir, change.WorkingDir), nil
+}
+
+// WorkingDirPatterns prefixes the sparse checkout patterns of the change with its working dir
+func WorkingDirPatterns(change v1alpha1.Change, patterns []string) []string {
+	if change.WorkingDir == "" {
+		return patterns
+	}
+	answer := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		if p == "" {
+			continue
+		}
+		answer = append(answer, path.Join("/", filepath.ToSlash(change.WorkingDir), strings.TrimPrefix(p, "/")))
+	}
+	return answer
+}
@@ -0,0 +1,76 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/cmdrunner/fakerunner"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkingDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"values.yaml", filepath.Join("services", "app", "values.yaml")} {
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), files.DefaultDirWritePermissions)
+		require.NoError(t, err, "failed to create dir for %s", name)
+		err = os.WriteFile(path, []byte("version: 1.0.0\n"), files.DefaultFileWritePermissions)
+		require.NoError(t, err, "failed to write %s", name)
+	}
+
+	runner := &fakerunner.FakeRunner{}
+	o := &pr.Options{Version: "2.0.0"}
+	o.CommandRunner = runner.Run
+	gitURL := "https://github.com/myorg/monorepo"
+
+	change := v1alpha1.Change{
+		WorkingDir: "services/app",
+		Regex:      &v1alpha1.Regex{Pattern: `version: (.*)`, Globs: []string{"*.yaml"}},
+	}
+	err := o.ApplyChanges(dir, gitURL, change)
+	require.NoError(t, err, "failed to apply regex change")
+
+	for name, expected := range map[string]string{
+		"values.yaml":              "version: 1.0.0\n",
+		"services/app/values.yaml": "version: 2.0.0\n",
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err, "failed to read %s", name)
+		assert.Equal(t, expected, string(data), "file %s", name)
+	}
+
+	err = o.ApplyChanges(dir, gitURL, v1alpha1.Change{WorkingDir: "services/app", Command: &v1alpha1.Command{Name: "make", Args: []string{"build"}}})
+	require.NoError(t, err, "failed to apply command change")
+	require.Len(t, runner.OrderedCommands, 1)
+	assert.Equal(t, filepath.Join(dir, "services", "app"), runner.OrderedCommands[0].Dir, "command dir")
+
+	err = o.ApplyChanges(dir, gitURL, v1alpha1.Change{WorkingDir: "services/missing", Command: &v1alpha1.Command{Name: "make"}})
+	require.NoError(t, err, "should skip a missing working dir")
+	assert.Len(t, runner.OrderedCommands, 1, "should not run the command in a missing working dir")
+
+	err = o.ApplyChanges(dir, gitURL, v1alpha1.Change{WorkingDir: "services/missing", IfMissing: pr.IfMissingError, Command: &v1alpha1.Command{Name: "make"}})
+	require.Error(t, err, "should fail for a missing working dir with ifMissing error")
+
+	err = o.ApplyChanges(dir, gitURL, v1alpha1.Change{WorkingDir: "../other", Command: &v1alpha1.Command{Name: "make"}})
+	require.Error(t, err, "should fail for a working dir outside the repository")
+}
+
+func TestWorkingDirPatterns(t *testing.T) {
+	o := &pr.Options{}
+	rule := &v1alpha1.Rule{
+		Changes: []v1alpha1.Change{
+			{WorkingDir: "services/app", Regex: &v1alpha1.Regex{Globs: []string{"charts/**/values.yaml"}}},
+			{WorkingDir: "tools", Go: &v1alpha1.GoChange{}},
+			{Set: &v1alpha1.SetValue{Globs: []string{"config.yaml"}}},
+		},
+	}
+	patterns, err := o.GetSparseCheckoutPatterns(rule)
+	require.NoError(t, err, "failed to get sparse checkout patterns")
+	assert.Subset(t, patterns, []string{"/services/app/charts/**/values.yaml", "/tools/go.mod", "/tools/go.sum", "/config.yaml"})
+	assert.NotContains(t, patterns, "/go.mod")
+}
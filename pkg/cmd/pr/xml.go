@@ -0,0 +1,305 @@
+package pr
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/yargevad/filepathx"
+)
+
+// DefaultXMLGlobs the files of an xml change if none are specified
+var DefaultXMLGlobs = []string{"pom.xml"}
+
+// xmlPathStep a step of an xml change path such as //dependency[artifactId='my-lib']
+type xmlPathStep struct {
+	descendant bool
+	name       string
+	predicate  string
+	value      string
+}
+
+// xmlNode an element of an XML document along with the offsets of its tags and content
+type xmlNode struct {
+	name         string
+	children     []*xmlNode
+	text         strings.Builder
+	mixed        bool
+	tagStart     int64
+	contentStart int64
+	contentEnd   int64
+}
+
+// ApplyXML sets the text of the elements at the path in the XML files. Only the text of the elements is replaced so
+// the namespaces, comments and formatting of the files are kept. Fails if the path matches no element in a file
+func (o *Options) ApplyXML(dir, gitURL string, change v1alpha1.Change, xmlChange *v1alpha1.XMLChange) error {
+	if xmlChange.Path == "" {
+		return fmt.Errorf("no path for xml change %#v", change)
+	}
+	_, err := parseXMLPath(xmlChange.Path)
+	if err != nil {
+		return err
+	}
+	value, err := o.changeValue(gitURL, change, xmlChange.Value)
+	if err != nil {
+		return err
+	}
+
+	matched := 0
+	for _, g := range XMLGlobs(xmlChange) {
+		matches, err := filepathx.Glob(filepath.Join(dir, g))
+		if err != nil {
+			return fmt.Errorf("failed to evaluate glob %s: %w", g, err)
+		}
+		if len(matches) == 0 {
+			_, err = o.handleMissingFile(change, g, gitURL, false)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		for _, f := range matches {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return fmt.Errorf("failed to load file %s: %w", f, err)
+			}
+			data2, count, err := o.SetXMLText(change, data, xmlChange.Path, value, xmlChange.Path+" in "+f)
+			if err != nil {
+				return fmt.Errorf("failed to modify file %s: %w", f, err)
+			}
+			if count == 0 {
+				return fmt.Errorf("the path %s does not match any element in file %s", xmlChange.Path, f)
+			}
+			matched += count
+			if bytes.Equal(data, data2) {
+				continue
+			}
+			err = os.WriteFile(f, data2, files.DefaultFileWritePermissions)
+			if err != nil {
+				return fmt.Errorf("failed to save file %s: %w", f, err)
+			}
+			o.Logger().Infof("modified file %s setting %s to %s", info(f), xmlChange.Path, value)
+		}
+	}
+	return checkRequireMatch(change, matched, "xml", xmlChange.Path, XMLGlobs(xmlChange), gitURL)
+}
+
+// XMLGlobs returns the files of the xml change
+func XMLGlobs(xmlChange *v1alpha1.XMLChange) []string {
+	if len(xmlChange.Globs) == 0 {
+		return DefaultXMLGlobs
+	}
+	return xmlChange.Globs
+}
+
+// SetXMLText sets the text of the elements matching the path in the XML data returning the new data and the number of
+// matching elements
+func (o *Options) SetXMLText(change v1alpha1.Change, data []byte, path, value, location string) ([]byte, int, error) {
+	steps, err := parseXMLPath(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	root, err := parseXMLNodes(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	nodes := []*xmlNode{root}
+	for _, step := range steps {
+		nodes = step.match(nodes)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].tagStart < nodes[j].tagStart
+	})
+
+	buf := &bytes.Buffer{}
+	err = xml.EscapeText(buf, []byte(value))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to escape value %s: %w", value, err)
+	}
+	escaped := buf.Bytes()
+
+	// lets replace from the end of the file so that the offsets of the earlier elements are unchanged
+	answer := data
+	for i := len(nodes) - 1; i >= 0; i-- {
+		n := nodes[i]
+		if n.mixed {
+			return nil, 0, fmt.Errorf("the element %s at %s has child elements or comments so its text cannot be set", n.name, location)
+		}
+		current := strings.TrimSpace(n.text.String())
+		if current == value || !o.matchesExpectedCurrent(change, current, location) {
+			continue
+		}
+		var replacement []byte
+		start, end := n.contentStart, n.contentEnd
+		if bytes.HasSuffix(answer[n.tagStart:n.contentStart], []byte("/>")) {
+			// lets expand a self closing element such as <version/> so it can have text
+			tag := answer[n.tagStart+1 : n.contentStart-2]
+			qualifiedName := strings.Fields(string(tag))[0]
+			replacement = append(append(append([]byte{}, answer[n.tagStart:n.contentStart-2]...), '>'), escaped...)
+			replacement = append(replacement, []byte("</"+qualifiedName+">")...)
+			start = n.tagStart
+		} else {
+			// lets keep any whitespace around the text
+			content := answer[start:end]
+			start += int64(len(content) - len(bytes.TrimLeft(content, " \t\r\n")))
+			end -= int64(len(content) - len(bytes.TrimRight(content, " \t\r\n")))
+			if end < start {
+				end = start
+			}
+			replacement = escaped
+		}
+		answer = append(append(append([]byte{}, answer[:start]...), replacement...), answer[end:]...)
+	}
+	return answer, len(nodes), nil
+}
+
+// parseXMLPath parses a path such as /project/properties/my.lib.version or //dependency[artifactId='my-lib']/version
+func parseXMLPath(path string) ([]xmlPathStep, error) {
+	text := strings.TrimSpace(path)
+	if !strings.HasPrefix(text, "/") {
+		return nil, fmt.Errorf("invalid xml path %s: must start with / or //", path)
+	}
+	var steps []xmlPathStep
+	for text != "" {
+		step := xmlPathStep{}
+		switch {
+		case strings.HasPrefix(text, "//"):
+			step.descendant = true
+			text = text[2:]
+		case strings.HasPrefix(text, "/"):
+			text = text[1:]
+		default:
+			return nil, fmt.Errorf("invalid xml path %s", path)
+		}
+		end := strings.IndexAny(text, "/[")
+		if end < 0 {
+			end = len(text)
+		}
+		step.name = strings.TrimSpace(text[:end])
+		if step.name == "" {
+			return nil, fmt.Errorf("invalid xml path %s: expected an element name", path)
+		}
+		if i := strings.Index(step.name, ":"); i >= 0 {
+			step.name = step.name[i+1:]
+		}
+		text = text[end:]
+		if strings.HasPrefix(text, "[") {
+			var err error
+			step.predicate, step.value, text, err = parseXMLPredicate(text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid xml path %s: %w", path, err)
+			}
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// parseXMLPredicate parses a predicate such as [artifactId='my-lib'] returning the child name, text and the rest of the path
+func parseXMLPredicate(text string) (string, string, string, error) {
+	eq := strings.Index(text, "=")
+	if eq < 0 {
+		return "", "", "", fmt.Errorf("unsupported predicate %s: expected [child='text']", text)
+	}
+	name := strings.TrimSpace(text[1:eq])
+	rest := strings.TrimSpace(text[eq+1:])
+	if name == "" || rest == "" || (rest[0] != '\'' && rest[0] != '"') {
+		return "", "", "", fmt.Errorf("unsupported predicate %s: expected [child='text']", text)
+	}
+	end := strings.IndexByte(rest[1:], rest[0])
+	if end < 0 {
+		return "", "", "", fmt.Errorf("missing closing quote in predicate %s", text)
+	}
+	value := rest[1 : end+1]
+	rest = strings.TrimSpace(rest[end+2:])
+	if !strings.HasPrefix(rest, "]") {
+		return "", "", "", fmt.Errorf("missing ']' in predicate %s", text)
+	}
+	if i := strings.Index(name, ":"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name, value, rest[1:], nil
+}
+
+// match returns the elements matching the step which are children, or descendants, of the nodes
+func (s *xmlPathStep) match(nodes []*xmlNode) []*xmlNode {
+	var answer []*xmlNode
+	found := map[*xmlNode]bool{}
+	var visit func(n *xmlNode)
+	visit = func(n *xmlNode) {
+		for _, c := range n.children {
+			if !found[c] && s.matches(c) {
+				found[c] = true
+				answer = append(answer, c)
+			}
+			if s.descendant {
+				visit(c)
+			}
+		}
+	}
+	for _, n := range nodes {
+		visit(n)
+	}
+	return answer
+}
+
+// matches returns true if the element has the name of the step and a child element matching the predicate
+func (s *xmlPathStep) matches(n *xmlNode) bool {
+	if s.name != "*" && s.name != n.name {
+		return false
+	}
+	if s.predicate == "" {
+		return true
+	}
+	for _, c := range n.children {
+		if c.name == s.predicate && strings.TrimSpace(c.text.String()) == s.value {
+			return true
+		}
+	}
+	return false
+}
+
+// parseXMLNodes parses the XML data into a tree of elements under a document node recording the offsets of their
+// tags and content
+func parseXMLNodes(data []byte) (*xmlNode, error) {
+	root := &xmlNode{}
+	stack := []*xmlNode{root}
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		offset := d.InputOffset()
+		token, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %w", err)
+		}
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			n := &xmlNode{name: t.Name.Local, tagStart: offset, contentStart: d.InputOffset()}
+			parent.children = append(parent.children, n)
+			parent.mixed = true
+			stack = append(stack, n)
+		case xml.EndElement:
+			parent.contentEnd = offset
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			parent.text.Write(t)
+		case xml.Comment, xml.ProcInst:
+			parent.mixed = true
+		}
+	}
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("failed to parse XML: unclosed element %s", stack[len(stack)-1].name)
+	}
+	return root, nil
+}
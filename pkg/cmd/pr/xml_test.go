@@ -0,0 +1,98 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyXML(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <modelVersion>4.0.0</modelVersion>
+  <artifactId>myapp</artifactId>
+  <properties>
+    <!-- the version of my-lib -->
+    <my-lib.version>1.2.3</my-lib.version>
+  </properties>
+  <dependencies>
+    <dependency>
+      <artifactId>my-lib</artifactId>
+      <version>1.2.3</version>
+    </dependency>
+    <dependency>
+      <artifactId>other</artifactId>
+      <version>0.1.0</version>
+    </dependency>
+    <dependency>
+      <artifactId>new-lib</artifactId>
+      <version/>
+    </dependency>
+  </dependencies>
+</project>
+`
+	testCases := []struct {
+		name     string
+		change   v1alpha1.XMLChange
+		expected string
+		err      bool
+	}{
+		{
+			name:     "property",
+			change:   v1alpha1.XMLChange{Path: "/project/properties/my-lib.version"},
+			expected: strings.Replace(source, "<my-lib.version>1.2.3<", "<my-lib.version>2.0.0<", 1),
+		},
+		{
+			name:     "dependency",
+			change:   v1alpha1.XMLChange{Path: "//dependency[artifactId='my-lib']/version"},
+			expected: strings.Replace(source, "<version>1.2.3</version>", "<version>2.0.0</version>", 1),
+		},
+		{
+			name:     "self-closing",
+			change:   v1alpha1.XMLChange{Path: `//dependency[artifactId="new-lib"]/version`, Value: "v{{ .Version }}"},
+			expected: strings.Replace(source, "<version/>", "<version>v2.0.0</version>", 1),
+		},
+		{
+			name:   "mixed-content",
+			change: v1alpha1.XMLChange{Path: "/project/properties"},
+			err:    true,
+		},
+		{
+			name:   "no-match",
+			change: v1alpha1.XMLChange{Path: "//dependency[artifactId='missing']/version"},
+			err:    true,
+		},
+		{
+			name:   "invalid-path",
+			change: v1alpha1.XMLChange{Path: "project/version"},
+			err:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "pom.xml")
+		err := os.WriteFile(path, []byte(source), files.DefaultFileWritePermissions)
+		require.NoError(t, err, "failed to write %s", path)
+
+		o := &pr.Options{Version: "2.0.0", TemplateData: map[string]interface{}{"Version": "2.0.0"}}
+		change := v1alpha1.Change{XML: &tc.change}
+		err = o.ApplyChanges(dir, "https://github.com/myorg/myrepo", change)
+		if tc.err {
+			require.Error(t, err, "expected error for %s", tc.name)
+			continue
+		}
+		require.NoError(t, err, "failed to apply change for %s", tc.name)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err, "failed to read %s", path)
+		assert.Equal(t, tc.expected, string(data), "pom.xml for %s", tc.name)
+	}
+}
@@ -0,0 +1,253 @@
+package pr
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/options"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+	"github.com/yargevad/filepathx"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// YAMLModeReplace replaces the elements of a sequence with the value
+	YAMLModeReplace = "replace"
+
+	// YAMLModeAppend appends the value to a sequence if it is not already an element
+	YAMLModeAppend = "append"
+)
+
+// YAMLModeValues the valid values of the mode field of a yaml change
+var YAMLModeValues = []string{YAMLModeReplace, YAMLModeAppend}
+
+// ApplyYAML sets the value of the node at the path in the YAML files. The node is modified in place so that comments
+// and anchors are kept
+func (o *Options) ApplyYAML(dir, gitURL string, change v1alpha1.Change, yamlChange *v1alpha1.YAMLChange) error {
+	if yamlChange.Path == "" {
+		return fmt.Errorf("no path for yaml change %#v", change)
+	}
+	mode := yamlChange.Mode
+	if mode == "" {
+		mode = YAMLModeReplace
+	}
+	if stringhelpers.StringArrayIndex(YAMLModeValues, mode) < 0 {
+		return options.InvalidOption("mode", mode, YAMLModeValues)
+	}
+	fields, err := ParseYAMLPath(yamlChange.Path)
+	if err != nil {
+		return err
+	}
+	value, err := o.changeValue(gitURL, change, yamlChange.Value)
+	if err != nil {
+		return err
+	}
+	digest := ""
+	if yamlChange.Digest != nil {
+		digest, err = o.ChangeDigest(yamlChange.Digest, "", value)
+		if err != nil {
+			return err
+		}
+	}
+
+	matched := 0
+	for _, g := range yamlChange.Globs {
+		matches, err := filepathx.Glob(filepath.Join(dir, g))
+		if err != nil {
+			return fmt.Errorf("failed to evaluate glob %s: %w", g, err)
+		}
+		if len(matches) == 0 {
+			_, err = o.handleMissingFile(change, g, gitURL, false)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		for _, f := range matches {
+			node, err := yaml.ReadFile(f)
+			if err != nil {
+				return fmt.Errorf("failed to load YAML file %s: %w", f, err)
+			}
+			current, err := node.Pipe(yaml.Lookup(fields...))
+			if err != nil {
+				return fmt.Errorf("failed to find %s in file %s: %w", yamlChange.Path, f, err)
+			}
+			if current == nil {
+				return fmt.Errorf("the path %s does not exist in file %s", yamlChange.Path, f)
+			}
+			matched++
+			n := current.YNode()
+			if n.Kind == yaml.AliasNode {
+				n = n.Alias
+			}
+			var modified bool
+			switch n.Kind {
+			case yaml.ScalarNode:
+				if !o.matchesExpectedCurrent(change, n.Value, yamlChange.Path+" in "+f) {
+					continue
+				}
+				if digest != "" {
+					modified = setYAMLScalar(n, ReplaceReferenceDigest(n.Value, value, digest))
+					break
+				}
+				modified = setYAMLScalar(n, value)
+			case yaml.SequenceNode:
+				modified, err = setYAMLSequence(n, value, mode)
+				if err != nil {
+					return fmt.Errorf("failed to update %s in file %s: %w", yamlChange.Path, f, err)
+				}
+			default:
+				return fmt.Errorf("the path %s in file %s is not a scalar or sequence", yamlChange.Path, f)
+			}
+			if !modified {
+				continue
+			}
+			err = yaml.WriteFile(node, f)
+			if err != nil {
+				return fmt.Errorf("failed to save file %s: %w", f, err)
+			}
+			o.Logger().Infof("modified file %s setting %s to %s", info(f), yamlChange.Path, value)
+		}
+	}
+	return checkRequireMatch(change, matched, "yaml", yamlChange.Path, yamlChange.Globs, gitURL)
+}
+
+// setYAMLScalar sets the value of the scalar node returning true if it changed. Strings stay strings so they are quoted
+// if required, otherwise the value is written as it is such as a number replacing a number
+func setYAMLScalar(n *yaml.Node, value string) bool {
+	if n.Value == value {
+		return false
+	}
+	n.Value = value
+	if n.ShortTag() != yaml.NodeTagString {
+		n.Tag = ""
+	}
+	return true
+}
+
+// setYAMLSequence replaces or appends the elements of the value to the sequence node returning true if it changed
+func setYAMLSequence(n *yaml.Node, value, mode string) (bool, error) {
+	elements, err := yamlElements(value)
+	if err != nil {
+		return false, err
+	}
+	if mode == YAMLModeAppend {
+		modified := false
+		for _, e := range elements {
+			if yamlSequenceContains(n, e.Value) {
+				continue
+			}
+			n.Content = append(n.Content, e)
+			modified = true
+		}
+		return modified, nil
+	}
+	if len(n.Content) == len(elements) {
+		same := true
+		for i, e := range elements {
+			if n.Content[i].Kind != yaml.ScalarNode || n.Content[i].Value != e.Value {
+				same = false
+				break
+			}
+		}
+		if same {
+			return false, nil
+		}
+	}
+	// lets keep the comments of the existing elements where we can
+	for i, e := range elements {
+		if i < len(n.Content) && n.Content[i].Kind == yaml.ScalarNode {
+			setYAMLScalar(n.Content[i], e.Value)
+			elements[i] = n.Content[i]
+		}
+	}
+	n.Content = elements
+	return true, nil
+}
+
+// yamlElements returns the string elements of a value which may be a flow sequence such as [a, b]
+func yamlElements(value string) ([]*yaml.Node, error) {
+	var values []string
+	if strings.HasPrefix(strings.TrimSpace(value), "[") {
+		seq, err := yaml.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse value %s: %w", value, err)
+		}
+		if seq.YNode().Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("the value %s is not a sequence", value)
+		}
+		for _, e := range seq.YNode().Content {
+			if e.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("the value %s must only contain scalars", value)
+			}
+			values = append(values, e.Value)
+		}
+	} else {
+		values = []string{value}
+	}
+	var answer []*yaml.Node
+	for _, v := range values {
+		answer = append(answer, &yaml.Node{Kind: yaml.ScalarNode, Tag: yaml.NodeTagString, Value: v})
+	}
+	return answer, nil
+}
+
+// yamlSequenceContains returns true if the sequence node has a scalar element with the value
+func yamlSequenceContains(n *yaml.Node, value string) bool {
+	for _, e := range n.Content {
+		if e.Kind == yaml.ScalarNode && e.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseYAMLPath converts a dotted or yq style path such as image.tag or .spec.containers[0].image to the fields and
+// list indexes used to look up the node. Names containing dots can be quoted in brackets such as ["example.com/version"]
+func ParseYAMLPath(path string) ([]string, error) {
+	text := strings.TrimSpace(path)
+	if !strings.HasPrefix(text, ".") && !strings.HasPrefix(text, "[") {
+		text = "." + text
+	}
+	var fields []string
+	for len(text) > 0 {
+		switch text[0] {
+		case '.':
+			text = text[1:]
+			end := strings.IndexAny(text, ".[")
+			if end < 0 {
+				end = len(text)
+			}
+			name := text[:end]
+			if name == "" {
+				return nil, fmt.Errorf("invalid YAML path %s: expected a name after '.'", path)
+			}
+			fields = append(fields, name)
+			text = text[end:]
+		case '[':
+			end := strings.Index(text, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid YAML path %s: missing ']'", path)
+			}
+			key := text[1:end]
+			text = text[end+1:]
+			if len(key) >= 2 && (key[0] == '\'' || key[0] == '"') && key[len(key)-1] == key[0] {
+				fields = append(fields, key[1:len(key)-1])
+				continue
+			}
+			if idx, err := strconv.Atoi(key); err != nil || idx < 0 {
+				return nil, fmt.Errorf("unsupported YAML path %s: expected a quoted name or list index in brackets", path)
+			}
+			fields = append(fields, key)
+		default:
+			return nil, fmt.Errorf("invalid YAML path %s", path)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("invalid YAML path %s: no names", path)
+	}
+	return fields, nil
+}
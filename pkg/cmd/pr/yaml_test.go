@@ -0,0 +1,158 @@
+package pr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/apis/updatebot/v1alpha1"
+	"github.com/jenkins-x-plugins/jx-updatebot/pkg/cmd/pr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const valuesYAML = `# the default values
+defaults: &defaults
+  tag: 1.0.0 # the default tag
+image:
+  repository: ghcr.io/myorg/myapp
+  # the image tag
+  tag: "1.0.0"
+replicas: 1
+sidecar: *defaults
+containers:
+- name: myapp
+  image: ghcr.io/myorg/myapp:1.0.0
+annotations:
+  example.com/version: 1.0.0
+versions:
+- 0.9.0 # previous
+- 1.0.0
+`
+
+func TestApplyYAML(t *testing.T) {
+	testCases := []struct {
+		name     string
+		change   v1alpha1.YAMLChange
+		expected string
+		err      bool
+	}{
+		{
+			name:   "dotted-path",
+			change: v1alpha1.YAMLChange{Path: "image.tag"},
+			expected: `  # the image tag
+  tag: "2.0.0"
+`,
+		},
+		{
+			name:     "yq-path-list-index",
+			change:   v1alpha1.YAMLChange{Path: ".containers[0].image", Value: "ghcr.io/myorg/myapp:{{ .Version }}"},
+			expected: "  image: ghcr.io/myorg/myapp:2.0.0\n",
+		},
+		{
+			name:     "quoted-name",
+			change:   v1alpha1.YAMLChange{Path: `.annotations["example.com/version"]`},
+			expected: "  example.com/version: 2.0.0\n",
+		},
+		{
+			name:   "anchor",
+			change: v1alpha1.YAMLChange{Path: "defaults.tag"},
+			expected: `defaults: &defaults
+  tag: 2.0.0 # the default tag
+`,
+		},
+		{
+			name:     "number",
+			change:   v1alpha1.YAMLChange{Path: "replicas", Value: "3"},
+			expected: "replicas: 3\nsidecar: *defaults\n",
+		},
+		{
+			name:     "string-stays-string",
+			change:   v1alpha1.YAMLChange{Path: "annotations[\"example.com/version\"]", Value: "1.10"},
+			expected: "  example.com/version: \"1.10\"\n",
+		},
+		{
+			name:   "sequence-replace",
+			change: v1alpha1.YAMLChange{Path: "versions"},
+			expected: `versions:
+- 2.0.0 # previous
+`,
+		},
+		{
+			name:   "sequence-append",
+			change: v1alpha1.YAMLChange{Path: "versions", Mode: pr.YAMLModeAppend},
+			expected: `versions:
+- 0.9.0 # previous
+- 1.0.0
+- 2.0.0
+`,
+		},
+		{
+			name:   "sequence-append-existing",
+			change: v1alpha1.YAMLChange{Path: "versions", Value: "[1.0.0, 1.1.0]", Mode: pr.YAMLModeAppend},
+			expected: `versions:
+- 0.9.0 # previous
+- 1.0.0
+- 1.1.0
+`,
+		},
+		{
+			name:   "missing-path",
+			change: v1alpha1.YAMLChange{Path: "image.digest"},
+			err:    true,
+		},
+		{
+			name:   "invalid-mode",
+			change: v1alpha1.YAMLChange{Path: "versions", Mode: "merge"},
+			err:    true,
+		},
+		{
+			name:   "mapping",
+			change: v1alpha1.YAMLChange{Path: "image"},
+			err:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "values.yaml")
+		err := os.WriteFile(path, []byte(valuesYAML), 0o600)
+		require.NoError(t, err, "failed to write %s", path)
+
+		tc.change.Globs = []string{"*.yaml"}
+		o := &pr.Options{Version: "2.0.0", TemplateData: map[string]interface{}{"Version": "2.0.0"}}
+		err = o.ApplyChanges(dir, "https://github.com/myorg/myrepo", v1alpha1.Change{YAML: &tc.change})
+		if tc.err {
+			require.Error(t, err, "should fail for test %s", tc.name)
+			t.Logf("test %s got expected error: %s", tc.name, err.Error())
+			continue
+		}
+		require.NoError(t, err, "failed to apply change for test %s", tc.name)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err, "failed to read %s", path)
+		text := string(data)
+		assert.Contains(t, text, tc.expected, "modified file for test %s", tc.name)
+		assert.Contains(t, text, "# the default values\n", "kept the comments for test %s", tc.name)
+		assert.Contains(t, text, "sidecar: *defaults\n", "kept the alias for test %s", tc.name)
+	}
+}
+
+func TestParseYAMLPath(t *testing.T) {
+	testCases := map[string][]string{
+		"image.tag":                     {"image", "tag"},
+		".spec.containers[0].image":     {"spec", "containers", "0", "image"},
+		`.annotations["example.com/v"]`: {"annotations", "example.com/v"},
+		"['a.b'][1]":                    {"a.b", "1"},
+	}
+	for path, expected := range testCases {
+		actual, err := pr.ParseYAMLPath(path)
+		require.NoError(t, err, "failed to parse %s", path)
+		assert.Equal(t, expected, actual, "parsed path %s", path)
+	}
+
+	for _, path := range []string{"", "image..tag", "containers[x]", "containers[0"} {
+		_, err := pr.ParseYAMLPath(path)
+		assert.Error(t, err, "should fail to parse %s", path)
+	}
+}
@@ -14,6 +14,7 @@ type ChartVersion struct {
 	Chart         string
 	Version       string
 	SourceRefName string
+	Namespace     string
 }
 
 // Key returns a unique key for the helm chart version
@@ -36,6 +37,12 @@ func GetChartVersion(node *yaml.RNode, path string) *ChartVersion {
 	v.Chart = kyamls.GetStringField(node, path, "spec", "chart", "spec", "chart")
 	v.Version = kyamls.GetStringField(node, path, "spec", "chart", "spec", "version")
 	v.SourceRefName = kyamls.GetStringField(node, path, "spec", "chart", "spec", "sourceRef", "name")
+
+	// the release is installed into the target namespace if it is set rather than the namespace of the HelmRelease
+	v.Namespace = kyamls.GetStringField(node, path, "spec", "targetNamespace")
+	if v.Namespace == "" {
+		v.Namespace = kyamls.GetStringField(node, path, "metadata", "namespace")
+	}
 	return v
 }
 
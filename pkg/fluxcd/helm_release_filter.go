@@ -10,6 +10,7 @@ import (
 type HelmReleaseFilter struct {
 	Chart         gitops.TextFilter
 	SourceRefName gitops.TextFilter
+	Namespace     gitops.TextFilter
 }
 
 // Matches return true if the app version matches the filter
@@ -20,10 +21,14 @@ func (o *HelmReleaseFilter) Matches(v *ChartVersion) bool {
 	if !stringhelpers.StringContainsAny(v.SourceRefName, o.SourceRefName.Includes, o.SourceRefName.Excludes) {
 		return false
 	}
+	if !stringhelpers.StringContainsAny(v.Namespace, o.Namespace.Includes, o.Namespace.Excludes) {
+		return false
+	}
 	return true
 }
 
 func (o *HelmReleaseFilter) AddFlags(cmd *cobra.Command) {
 	o.Chart.AddFlags(cmd, "chart", "chart name")
 	o.Chart.AddFlags(cmd, "source-ref-name", "the sourceRef name of the chart repository or bucket")
+	o.Namespace.AddFlags(cmd, "namespace", "targetNamespace or namespace of the HelmRelease")
 }
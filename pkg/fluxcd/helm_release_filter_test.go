@@ -46,6 +46,19 @@ func TestAppFilter(t *testing.T) {
 				{Chart: "https://github.com/myorg/app1", SourceRefName: "cheese"},
 			},
 		},
+		{
+			filter: fluxcd.HelmReleaseFilter{
+				Namespace: gitops.TextFilter{
+					Includes: []string{"team-a"},
+				},
+			},
+			matches: []fluxcd.ChartVersion{
+				{Chart: "https://github.com/myorg/app1", SourceRefName: "cheese", Namespace: "team-a"},
+			},
+			notMatches: []fluxcd.ChartVersion{
+				{Chart: "https://github.com/myorg/app1", SourceRefName: "cheese", Namespace: "team-b"},
+			},
+		},
 	}
 
 	for _, tc := range testCases {